@@ -0,0 +1,125 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Attachment is a file attached to a note.
+type Attachment struct {
+	// Filename is the name of the attached file.
+	Filename string
+	// MIMEType is the file's MIME type.
+	MIMEType string
+	// Data is the content of the file.
+	Data []byte
+	// Hash is the MD5 hash of the data.
+	Hash []byte
+}
+
+// NewAttachmentFromFile reads the file and returns it as an attachment.
+// The MIME type is guessed from the file extension or the content.
+func NewAttachmentFromFile(path string) (*Attachment, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	// Evernote doesn't expect any parameters in the MIME type.
+	if i := strings.Index(mimeType, ";"); i != -1 {
+		mimeType = mimeType[:i]
+	}
+	return &Attachment{
+		Filename: filepath.Base(path),
+		MIMEType: mimeType,
+		Data:     data,
+	}, nil
+}
+
+// computeHash calculates the MD5 hash of the data, unless the
+// attachment already has a hash.
+func (a *Attachment) computeHash() {
+	if len(a.Hash) != 0 {
+		return
+	}
+	sum := md5.Sum(a.Data)
+	a.Hash = sum[:]
+}
+
+// mediaTag returns the en-media tag that references the attachment.
+func (a *Attachment) mediaTag() string {
+	return fmt.Sprintf(`<en-media type="%s" hash="%s"/>`, html.EscapeString(a.MIMEType), hex.EncodeToString(a.Hash))
+}
+
+// mediaTypes returns the MIME types of the attachments by their hex encoded hash.
+func mediaTypes(resources []*Attachment) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	media := make(map[string]string, len(resources))
+	for _, a := range resources {
+		a.computeHash()
+		media[hex.EncodeToString(a.Hash)] = a.MIMEType
+	}
+	return media
+}
+
+// addMediaTags adds an en-media tag to the end of the note body for each
+// attachment that isn't already referenced by the body.
+func addMediaTags(body string, resources []*Attachment) string {
+	if len(resources) == 0 {
+		return body
+	}
+	tags := new(bytes.Buffer)
+	for _, a := range resources {
+		a.computeHash()
+		if strings.Contains(body, `hash="`+hex.EncodeToString(a.Hash)+`"`) {
+			continue
+		}
+		tags.WriteString("<div>" + a.mediaTag() + "</div>")
+	}
+	i := strings.LastIndex(body, "</en-note>")
+	if i == -1 {
+		return body + tags.String()
+	}
+	return body[:i] + tags.String() + body[i:]
+}
+
+// removeMediaTag removes the en-media tags referencing the attachment with
+// the hash from the ENML content.
+func removeMediaTag(content string, hash []byte) string {
+	if len(hash) == 0 {
+		return content
+	}
+	tag := regexp.MustCompile(`(?i)<en-media\b[^>]*\bhash="` + hex.EncodeToString(hash) + `"[^>]*?(?:/>|>\s*</en-media>)`)
+	return tag.ReplaceAllString(content, "")
+}
@@ -0,0 +1,98 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAttachmentFromFile(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-attachment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("type from extension", func(t *testing.T) {
+		path := filepath.Join(dir, "photo.png")
+		assert.NoError(ioutil.WriteFile(path, []byte("png data"), 0600))
+		a, err := NewAttachmentFromFile(path)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("photo.png", a.Filename, "Wrong filename")
+		assert.Equal("image/png", a.MIMEType, "Wrong MIME type")
+		assert.Equal([]byte("png data"), a.Data, "Wrong data")
+	})
+
+	t.Run("type from content", func(t *testing.T) {
+		path := filepath.Join(dir, "notes")
+		assert.NoError(ioutil.WriteFile(path, []byte("plain text"), 0600))
+		a, err := NewAttachmentFromFile(path)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("text/plain", a.MIMEType, "Wrong MIME type")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewAttachmentFromFile(filepath.Join(dir, "missing"))
+		assert.Error(err, "Should return an error")
+	})
+}
+
+func TestSaveNewNoteWithAttachments(t *testing.T) {
+	assert := assert.New(t)
+	data := []byte("image data")
+	sum := md5.Sum(data)
+	a := &Attachment{Filename: "image.png", MIMEType: "image/png", Data: data}
+	ns := new(mockNS)
+	var created *Note
+	ns.createNote = func(n *Note) error { created = n; return nil }
+
+	err := SaveNewNote(ns, &Note{MD: "content", Resources: []*Attachment{a}}, DefaultNoteOption)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(sum[:], a.Hash, "Hash not computed")
+	assert.Equal([]*Attachment{a}, created.Resources, "Resources not passed to the notestore")
+	expected := `<div><en-media type="image/png" hash="` + hex.EncodeToString(sum[:]) + `"/></div></en-note>`
+	assert.Contains(created.Body, expected, "Media tag not added to the end of the body")
+
+	t.Run("keep existing hash", func(t *testing.T) {
+		hash := []byte("existing hash")
+		a := &Attachment{MIMEType: "image/png", Hash: hash}
+		err := SaveNewNote(ns, &Note{MD: "content", Resources: []*Attachment{a}}, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(hash, a.Hash, "Hash should not be changed")
+		assert.Contains(created.Body, hex.EncodeToString(hash), "Media tag should use the existing hash")
+	})
+
+	t.Run("referenced in content", func(t *testing.T) {
+		a := &Attachment{MIMEType: "image/png", Data: data}
+		md := "Before\n\n![image](en-media:" + hex.EncodeToString(sum[:]) + ")\n\nAfter"
+		err := SaveNewNote(ns, &Note{MD: md, Resources: []*Attachment{a}}, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		tag := `<en-media type="image/png" hash="` + hex.EncodeToString(sum[:]) + `"/>`
+		assert.Contains(created.Body, "<p>"+tag+"</p>", "Placeholder not converted to a media tag")
+		assert.Len(strings.Split(created.Body, tag), 2, "Media tag should only be added once")
+	})
+}
@@ -20,7 +20,10 @@ package clinote
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -28,6 +31,15 @@ var (
 	// DefaultNotebookCacheTime is the default time limit for when the
 	// list is considered outdated.
 	DefaultNotebookCacheTime = 24 * time.Hour
+	// DefaultTagCacheTime is the default time limit for when the
+	// tag list is considered outdated.
+	DefaultTagCacheTime = 24 * time.Hour
+	// DefaultNoteCountCacheTime is the default time limit for when the
+	// note counts are considered outdated.
+	DefaultNoteCountCacheTime = 5 * time.Minute
+	// DefaultCacheFileAge is the default age for when a cache file left
+	// behind after an edit is considered stale.
+	DefaultCacheFileAge = 24 * time.Hour
 )
 
 // NewNotebookCacheListWithLimit creates a new cache list with the given expiration limit.
@@ -59,6 +71,90 @@ func (n *NotebookCacheList) IsOutdated() bool {
 	return time.Since(n.Timestamp) > n.Limit
 }
 
+// NewTagCacheList creates a tag cache list with the default expiration limit.
+func NewTagCacheList(tags []*Tag) *TagCacheList {
+	return &TagCacheList{
+		Tags:      tags,
+		Limit:     DefaultTagCacheTime,
+		Timestamp: time.Now(),
+	}
+}
+
+// TagCacheList is a list of cached tags.
+type TagCacheList struct {
+	// Tags is the list of tags.
+	Tags []*Tag
+	// Timestamp of when the list was created.
+	Timestamp time.Time
+	// Limit is the until the list outdated.
+	Limit time.Duration
+}
+
+// IsOutdated returns true if the list has expired.
+func (t *TagCacheList) IsOutdated() bool {
+	return time.Since(t.Timestamp) > t.Limit
+}
+
+// NewNoteCountCacheList creates a note count cache list with the default expiration limit.
+func NewNoteCountCacheList(counts map[string]int32) *NoteCountCacheList {
+	return &NoteCountCacheList{
+		Counts:    counts,
+		Limit:     DefaultNoteCountCacheTime,
+		Timestamp: time.Now(),
+	}
+}
+
+// NoteCountCacheList is a list of cached note counts.
+type NoteCountCacheList struct {
+	// Counts is the number of notes in each notebook, by notebook GUID.
+	Counts map[string]int32
+	// Timestamp of when the list was created.
+	Timestamp time.Time
+	// Limit is the until the list outdated.
+	Limit time.Duration
+}
+
+// IsOutdated returns true if the list has expired.
+func (c *NoteCountCacheList) IsOutdated() bool {
+	return time.Since(c.Timestamp) > c.Limit
+}
+
+// CleanCacheFiles removes the cache files in the folder that are older than
+// maxAge. Cache files are normally removed once the edit is done, this
+// removes the ones left behind if clinote crashed during an edit. Only files
+// named like cache files are removed. The number of removed files is returned.
+func CleanCacheFiles(dir string, maxAge time.Duration) (int, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, info := range infos {
+		if info.IsDir() || !isCacheFileName(info.Name()) || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, info.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// isCacheFileName returns true if the name is the name editNote gives the
+// cache files, the note's GUID or a random new note name with the file
+// extension of the content format.
+func isCacheFileName(name string) bool {
+	ext := filepath.Ext(name)
+	switch ext {
+	case ".md", ".xml", ".txt":
+	default:
+		return false
+	}
+	base := strings.TrimPrefix(strings.TrimSuffix(name, ext), newNotePrependString)
+	return guidPattern.MatchString(base)
+}
+
 // CacheFile has the note content written and the user
 // edits the content in the CacheFile to update the note's
 // content.
@@ -1,6 +1,9 @@
 package clinote
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -36,3 +39,46 @@ func TestNewNotebookCacheList(t *testing.T) {
 		assert.Equal(expectedLimit, list.Limit, "Incorrect limit.")
 	})
 }
+
+func TestCleanCacheFiles(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old := time.Now().Add(-2 * DefaultCacheFileAge)
+	files := []struct {
+		name    string
+		old     bool
+		removed bool
+	}{
+		{"2c5b3c4e-1f0d-4d7a-9a5e-0b8f6a1c2d3e.md", true, true},
+		{"2c5b3c4e-1f0d-4d7a-9a5e-0b8f6a1c2d3e.xml", true, true},
+		{newNotePrependString + "7d9e0f1a-2b3c-4d5e-8f6a-1b2c3d4e5f6a.txt", true, true},
+		{"9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d.md", false, false},
+		{"session", true, false},
+		{"notes.md", true, false},
+		{"todo.txt", true, false},
+		{newNotePrependString + "draft.md", true, false},
+	}
+	for _, f := range files {
+		fp := filepath.Join(dir, f.name)
+		if err := ioutil.WriteFile(fp, []byte("content"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if f.old {
+			if err := os.Chtimes(fp, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	n, err := CleanCacheFiles(dir, DefaultCacheFileAge)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(3, n, "Wrong number of removed files")
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(dir, f.name))
+		assert.Equal(f.removed, os.IsNotExist(err), "Wrong state for "+f.name)
+	}
+}
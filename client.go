@@ -19,8 +19,10 @@ package clinote
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 )
 
@@ -42,6 +44,9 @@ func NewClient(config Configuration, store Storager, ns NotestoreClient, opts Cl
 	} else {
 		c.Editor = new(EnvEditor)
 	}
+	if opts&OfflineMode != 0 {
+		c.NoteStore = OfflineNotestore{}
+	}
 	return c
 }
 
@@ -57,6 +62,9 @@ const (
 	MemoryBasedCacheFile
 	// VimEditer for using Vim as the editor.
 	VimEditer
+	// OfflineMode replaces the notestore with OfflineNotestore, so only
+	// the cached data can be used.
+	OfflineMode
 )
 
 // Client is a client for all note operations.
@@ -68,7 +76,30 @@ type Client struct {
 	// Notestore is a client to interact with the note store.
 	NoteStore NotestoreClient
 	// Editor is the editor.
-	Editor       Editer
+	Editor Editer
+	// EditorOverride is an editor command used instead of Editor
+	// if it is set.
+	EditorOverride string
+	// CacheDir is the folder the cache files for editing are created in.
+	// If it is not set, the configuration's cache folder is used.
+	CacheDir string
+	// ConfirmSave is called after the diff of the changes has been shown
+	// with the ShowDiffNote option. The changes are only saved if it
+	// returns true. If it is not set, the changes are saved.
+	ConfirmSave func() bool
+	// ContentSource returns the edited content once the editor has
+	// exited. If it is not set, the content is read from the cache file.
+	ContentSource ContentSource
+	// Converter converts the note content between markdown and ENML for
+	// the note operations of the client, for example with a CommonMark or
+	// pandoc based converter. If it is not set, MarkdownConverter is used.
+	Converter Converter
+	// PinTag is the tag used to pin notes. If it is not set,
+	// DefaultPinTag is used.
+	PinTag string
+	// Opener is the command, with its arguments, used to open notes. If it
+	// is not set, the platform's default opener is used.
+	Opener       string
 	newCacheFile func(c *Client, filename string) (CacheFile, error)
 	clientOpts   ClientOption
 }
@@ -78,8 +109,64 @@ func (c *Client) NewCacheFile(filename string) (CacheFile, error) {
 	return c.newCacheFile(c, filename)
 }
 
+// CacheFolder returns the folder the cache files for editing are created in.
+func (c *Client) CacheFolder() string {
+	if c.CacheDir != "" {
+		return c.CacheDir
+	}
+	return c.Config.GetCacheFolder()
+}
+
+// editedContent returns the edited content of the cache file from the
+// client's ContentSource.
+func (c *Client) editedContent(file CacheFile) (io.Reader, error) {
+	if c.ContentSource == nil {
+		return FileContentSource{}.EditedContent(file)
+	}
+	return c.ContentSource.EditedContent(file)
+}
+
+// converter returns the client's Converter, or MarkdownConverter if it is
+// not set.
+func (c *Client) converter() Converter {
+	if c.Converter == nil {
+		return MarkdownConverter{}
+	}
+	return c.Converter
+}
+
+// PinTagName returns the name of the tag used to pin notes.
+func (c *Client) PinTagName() string {
+	if tag := strings.TrimSpace(c.PinTag); tag != "" {
+		return tag
+	}
+	return DefaultPinTag
+}
+
+// OpenerCommand returns the command and its arguments used to open notes.
+func (c *Client) OpenerCommand() []string {
+	if opener := strings.Fields(c.Opener); len(opener) > 0 {
+		return opener
+	}
+	return strings.Fields(defaultOpener)
+}
+
+// cacheFilePath returns the path for the cache file. A configured cache
+// folder is created if it doesn't exist.
+func (c *Client) cacheFilePath(filename string) (string, error) {
+	if c.CacheDir != "" {
+		if err := os.MkdirAll(c.CacheDir, os.ModeDir|0700); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(c.CacheFolder(), filename), nil
+}
+
 func newFileCacheFile(c *Client, filename string) (CacheFile, error) {
-	fp := filepath.Join(c.Config.GetCacheFolder(), filename)
+	fp, err := c.cacheFilePath(filename)
+	if err != nil {
+		return nil, err
+	}
 	f, err := os.OpenFile(fp, os.O_CREATE|os.O_RDWR, 0600)
 	if err != nil {
 		return nil, err
@@ -89,8 +176,11 @@ func newFileCacheFile(c *Client, filename string) (CacheFile, error) {
 }
 
 func newMemoryCacheFile(c *Client, filename string) (CacheFile, error) {
-	fp := filepath.Join(c.Config.GetCacheFolder(), filename)
-	err := syscall.Mkfifo(fp, 0600)
+	fp, err := c.cacheFilePath(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Mkfifo(fp, 0600)
 	if err != nil {
 		return nil, err
 	}
@@ -99,6 +189,28 @@ func newMemoryCacheFile(c *Client, filename string) (CacheFile, error) {
 }
 
 // Edit edits the cache file using the client's editor.
+// If EditorOverride is set, it is used instead. If the client's
+// ContentSource is a PreparedContentSource, it's prepared before the editor
+// is started.
 func (c *Client) Edit(file CacheFile) error {
+	p, prepared := c.ContentSource.(PreparedContentSource)
+	if prepared {
+		if err := p.Prepare(); err != nil {
+			return err
+		}
+	}
+	err := c.edit(file)
+	if err != nil && prepared {
+		// The content isn't used, but it's collected so the content
+		// source isn't left waiting for it.
+		p.EditedContent(file)
+	}
+	return err
+}
+
+func (c *Client) edit(file CacheFile) error {
+	if c.EditorOverride != "" {
+		return (&CommandEditor{Command: c.EditorOverride}).Edit(file)
+	}
 	return c.Editor.Edit(file)
 }
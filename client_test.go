@@ -18,6 +18,10 @@
 package clinote
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -44,4 +48,35 @@ func TestClient(t *testing.T) {
 		assert.NotNil(c, "None nil client")
 		assert.IsType(new(VimEditor), c.Editor, "Wrong editer type")
 	})
+
+	t.Run("cache folder", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "clinote-client")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		c := NewClient(cfg, store, ns, DefaultClientOptions)
+		c.CacheDir = filepath.Join(dir, "edit")
+		f, err := c.NewCacheFile("GUID.md")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(filepath.Join(dir, "edit", "GUID.md"), f.FilePath(), "Should use the configured cache folder")
+		assert.NoError(f.CloseAndRemove(), "Should remove the cache file")
+	})
+
+	t.Run("editor override", func(t *testing.T) {
+		called := false
+		c := NewClient(cfg, store, ns, DefaultClientOptions)
+		c.Editor = &mockEditor{edit: func(CacheFile) error {
+			called = true
+			return nil
+		}}
+		c.EditorOverride = "clinote-no-such-editor"
+		err := c.Edit(new(mockCacheFile))
+		assert.True(errors.Is(err, ErrNoEditorFound), "Should fail on missing editor")
+		assert.False(called, "Configured editor should not be used")
+
+		c.EditorOverride = ""
+		assert.NoError(c.Edit(new(mockCacheFile)))
+		assert.True(called, "Configured editor should be used without override")
+	})
 }
@@ -0,0 +1,91 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrClipboardNotSupported is returned if images can't be read from the
+	// clipboard on the platform.
+	ErrClipboardNotSupported = errors.New("reading images from the clipboard isn't supported")
+	// ErrNoClipboardImage is returned if the clipboard doesn't have an
+	// image.
+	ErrNoClipboardImage = errors.New("the clipboard doesn't have an image")
+)
+
+// clipboardFilename is the name, without the extension, given to images
+// read from the clipboard.
+const clipboardFilename = "clipboard"
+
+// ClipboardImageReader reads an image from a clipboard.
+type ClipboardImageReader interface {
+	// ReadImage returns the encoded image on the clipboard. If the
+	// clipboard doesn't have an image, ErrNoClipboardImage is returned.
+	ReadImage() ([]byte, error)
+}
+
+// SystemClipboard reads images from the platform's clipboard. If the
+// platform isn't supported, ErrClipboardNotSupported is returned.
+type SystemClipboard struct{}
+
+// NewAttachmentFromClipboard reads the image from the clipboard and returns
+// it as an attachment. ErrNoClipboardImage is returned if the clipboard
+// content isn't an image.
+func NewAttachmentFromClipboard(r ClipboardImageReader) (*Attachment, error) {
+	data, err := r.ReadImage()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrNoClipboardImage
+	}
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("%w: the content is %s", ErrNoClipboardImage, mimeType)
+	}
+	filename := clipboardFilename
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		filename += exts[0]
+	}
+	return &Attachment{
+		Filename: filename,
+		MIMEType: mimeType,
+		Data:     data,
+	}, nil
+}
+
+// EmbedImage attaches the image to the note and adds a reference to it at
+// the end of the note's markdown, so it's shown where it's referenced
+// instead of at the end of the note.
+func EmbedImage(n *Note, a *Attachment) {
+	a.computeHash()
+	n.Resources = append(n.Resources, a)
+	ref := "![image](en-media:" + hex.EncodeToString(a.Hash) + ")\n"
+	if strings.TrimSpace(n.MD) == "" {
+		n.MD = ref
+		return
+	}
+	n.MD = strings.TrimRight(n.MD, "\n") + "\n\n" + ref
+}
@@ -0,0 +1,84 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAttachmentFromClipboard(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	if !assert.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1)))) {
+		return
+	}
+	img := buf.Bytes()
+
+	t.Run("read image", func(t *testing.T) {
+		a, err := NewAttachmentFromClipboard(&mockClipboard{readImage: func() ([]byte, error) { return img, nil }})
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(a) {
+			assert.Equal("clipboard.png", a.Filename)
+			assert.Equal("image/png", a.MIMEType)
+			assert.Equal(img, a.Data)
+		}
+	})
+	t.Run("clipboard without an image", func(t *testing.T) {
+		_, err := NewAttachmentFromClipboard(&mockClipboard{readImage: func() ([]byte, error) { return []byte("some text"), nil }})
+		assert.True(errors.Is(err, ErrNoClipboardImage), "Should return ErrNoClipboardImage")
+		_, err = NewAttachmentFromClipboard(&mockClipboard{readImage: func() ([]byte, error) { return nil, nil }})
+		assert.Equal(ErrNoClipboardImage, err, "Wrong error returned for an empty clipboard")
+	})
+	t.Run("reader error", func(t *testing.T) {
+		_, err := NewAttachmentFromClipboard(&mockClipboard{readImage: func() ([]byte, error) { return nil, ErrClipboardNotSupported }})
+		assert.Equal(ErrClipboardNotSupported, err, "Wrong error returned")
+	})
+
+	t.Run("embed in a new note", func(t *testing.T) {
+		a, err := NewAttachmentFromClipboard(&mockClipboard{readImage: func() ([]byte, error) { return img, nil }})
+		if !assert.NoError(err) {
+			return
+		}
+		sum := md5.Sum(img)
+		hash := hex.EncodeToString(sum[:])
+		n := &Note{Title: "Screenshot", MD: "Captured:\n"}
+		EmbedImage(n, a)
+		assert.Equal("Captured:\n\n![image](en-media:"+hash+")\n", n.MD)
+
+		var saved *Note
+		ns := new(mockNS)
+		ns.createNote = func(n *Note) error { saved = n; return nil }
+		assert.NoError(SaveNewNote(ns, n, DefaultNoteOption), "Should not return an error")
+		assert.Equal(XMLHeader+`<en-note><p>Captured:</p>`+"\n\n"+`<p><en-media type="image/png" hash="`+hash+`"/></p>`+"\n</en-note>", saved.Body)
+		assert.Equal([]*Attachment{a}, saved.Resources, "Should attach the image")
+	})
+	t.Run("embed in an empty note", func(t *testing.T) {
+		a := &Attachment{Filename: "clipboard.png", MIMEType: "image/png", Data: img}
+		n := new(Note)
+		EmbedImage(n, a)
+		assert.Equal("![image](en-media:"+hex.EncodeToString(a.Hash)+")\n", n.MD)
+	})
+}
@@ -0,0 +1,90 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var appendNoteCmd = &cobra.Command{
+	Use:   "append",
+	Short: "Add content from stdin to a note.",
+	Long: `
+Append reads content from stdin and adds it to the end of the
+note. Use the prepend flag to add the content to the beginning
+of the note instead, for example to keep a log with the newest
+entry first.
+
+The content is added as markdown unless the raw flag is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		prepend, err := cmd.Flags().GetBool("prepend")
+		if err != nil {
+			fmt.Println("Error when parsing prepend parameter:", err)
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw parameter:", err)
+			return
+		}
+		content, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Println("Error when reading from stdin:", err)
+			os.Exit(1)
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		add := clinote.AppendNote
+		if prepend {
+			add = clinote.PrependNote
+		}
+		if err = add(client.Config.Store(), ns, title, string(content), opts); err != nil {
+			fmt.Println("Error when adding to the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(appendNoteCmd)
+	appendNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+	appendNoteCmd.Flags().Bool("prepend", false, "Add the content to the beginning of the note.")
+	appendNoteCmd.Flags().Bool("raw", false, "Add the content as raw ENML.")
+}
@@ -0,0 +1,75 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/storage"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache.",
+	Long:  `Manage the cache.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove stale cache files.",
+	Long: `
+Clean removes the files left behind in the cache folder if clinote
+was stopped while a note was being edited. Only files older than the
+given age are removed, so a note currently being edited is kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		age, err := cmd.Flags().GetDuration("older-than")
+		if err != nil {
+			fmt.Println("Error when parsing the age:", err)
+			return
+		}
+		cfg := new(clinote.DefaultConfig)
+		db, err := storage.Open(cfg.GetConfigFolder())
+		if err != nil {
+			fmt.Println("Error when opening the database:", err)
+			return
+		}
+		defer db.Close()
+		dir := cacheDir(db)
+		if dir == "" {
+			dir = cfg.GetCacheFolder()
+		}
+		n, err := clinote.CleanCacheFiles(dir, age)
+		fmt.Printf("Removed %d cache files.\n", n)
+		if err != nil {
+			fmt.Println("Error when cleaning the cache:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	cacheCleanCmd.Flags().Duration("older-than", clinote.DefaultCacheFileAge, "Remove cache files older than this.")
+}
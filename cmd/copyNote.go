@@ -0,0 +1,76 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var copyNoteCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy a note.",
+	Long: `
+Copy creates a new note with the content of an existing
+note. The new note is given the title from the name flag.
+
+The copy is saved to the same notebook as the original note,
+unless another notebook is given with the notebook flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		newTitle, err := cmd.Flags().GetString("name")
+		if err != nil {
+			fmt.Println("Error when parsing the new title:", err)
+			return
+		}
+		if title == "" || newTitle == "" {
+			fmt.Println("Note title and the new title have to be given")
+			return
+		}
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing notebook name:", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		_, err = clinote.CopyNote(client.Config.Store(), ns, title, newTitle, notebook)
+		if err != nil {
+			fmt.Println("Error when copying the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(copyNoteCmd)
+	copyNoteCmd.Flags().StringP("title", "t", "", "Title of the note to copy.")
+	copyNoteCmd.Flags().StringP("name", "n", "", "Title of the new note.")
+	copyNoteCmd.Flags().StringP("notebook", "b", "", "The notebook to save the copy to.")
+}
@@ -29,15 +29,46 @@ var deleteNoteCmd = &cobra.Command{
 	Use:   "delete \"note title\"",
 	Short: "Delete note.",
 	Long: `Moves the note into the trash. The note may still be undeleted, unless it is expunged.
-To expunge the note you need to use the official client or the web client.`,
+
+Use the permanent flag to expunge the note instead. The note is
+removed from the server and can't be restored, so you will be asked
+to confirm, unless the yes flag is given. The permanent flag can't
+be used together with the search flag.
+
+All notes matching a search can be deleted by using the search flag
+together with the all flag. You will be asked to confirm, unless the
+yes flag is given.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		nb, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing the notebook name:", err)
+			return
+		}
+		search, err := cmd.Flags().GetString("search")
+		if err != nil {
+			fmt.Println("Error when parsing the search term:", err)
+			return
+		}
+		permanent, err := cmd.Flags().GetBool("permanent")
+		if err != nil {
+			fmt.Println("Error when parsing the permanent flag:", err)
+			return
+		}
+		if search != "" && permanent {
+			fmt.Println("Error, the permanent flag can't be used with the search flag")
+			os.Exit(1)
+		}
+		if search != "" {
+			deleteNotesMatching(cmd, search, nb)
+			return
+		}
 		if len(args) != 1 {
 			fmt.Println("Error, a note title has to be given")
 			return
 		}
-		nb, err := cmd.Flags().GetString("notebook")
+		yes, err := cmd.Flags().GetBool("yes")
 		if err != nil {
-			fmt.Println("Error when parsing the notebook name:", err)
+			fmt.Println("Error when parsing the yes flag:", err)
 			return
 		}
 		client := defaultClient()
@@ -46,6 +77,17 @@ To expunge the note you need to use the official client or the web client.`,
 		if err != nil {
 			return
 		}
+		if permanent {
+			if !yes && !confirm(fmt.Sprintf("Permanently delete the note %q? This can't be undone.", args[0])) {
+				return
+			}
+			err = clinote.ExpungeNote(client.Config.Store(), ns, args[0], nb)
+			if err != nil {
+				fmt.Println("Error when expunging the note:", err)
+				os.Exit(1)
+			}
+			return
+		}
 		err = clinote.DeleteNote(client.Config.Store(), ns, args[0], nb)
 		if err != nil {
 			fmt.Println("Error when deleting the note:", err)
@@ -57,4 +99,49 @@ To expunge the note you need to use the official client or the web client.`,
 func init() {
 	noteCmd.AddCommand(deleteNoteCmd)
 	deleteNoteCmd.Flags().StringP("notebook", "b", "", "The notebook of the note.")
+	deleteNoteCmd.Flags().StringP("search", "s", "", "Delete the notes matching the search.")
+	deleteNoteCmd.Flags().Bool("all", false, "Delete all notes matching the search.")
+	deleteNoteCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+	deleteNoteCmd.Flags().Bool("permanent", false, "Expunge the note instead of moving it to the trash.")
+}
+
+func deleteNotesMatching(cmd *cobra.Command, search, notebook string) {
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		fmt.Println("Error when parsing the all flag:", err)
+		return
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		fmt.Println("Error when parsing the yes flag:", err)
+		return
+	}
+	if !all {
+		fmt.Println("Error, the all flag has to be given to delete the notes matching the search")
+		os.Exit(1)
+	}
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	filter := &clinote.NoteFilter{Words: search}
+	if notebook != "" {
+		book, err := clinote.FindNotebook(client.Config.Store(), ns, notebook)
+		if err != nil {
+			fmt.Println("Error when trying to filter by notebook:", err)
+			os.Exit(1)
+		}
+		filter.NotebookGUID = book.GUID
+	}
+	if !yes && !confirm(fmt.Sprintf("Move all notes matching %q to the trash?", search)) {
+		return
+	}
+	n, err := clinote.DeleteNotesMatching(client.Config.Store(), ns, filter, 0)
+	fmt.Printf("Deleted %d notes.\n", n)
+	if err != nil {
+		fmt.Println("Error when deleting the notes:", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,92 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var deleteNotebookCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a notebook.",
+	Long: `
+Delete permanently removes the notebook. The notebook can't be
+restored afterwards. Only empty notebooks are deleted, unless the
+force flag is given. With the force flag, the notes in the notebook
+are removed together with it.
+
+You will be asked to confirm, unless the yes flag is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			fmt.Println("Error when parsing the notebook name:", err)
+			return
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Println("Error when parsing the force flag:", err)
+			return
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			fmt.Println("Error when parsing the yes flag:", err)
+			return
+		}
+		if name == "" {
+			fmt.Println("Error, a notebook name has to be given.")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		question := fmt.Sprintf("Permanently delete the notebook %q?", name)
+		if force {
+			question = fmt.Sprintf("Permanently delete the notebook %q and all its notes?", name)
+		}
+		if !yes && !confirm(question) {
+			return
+		}
+		if force {
+			err = clinote.ForceDeleteNotebook(client.Config.Store(), ns, name)
+		} else {
+			err = clinote.DeleteNotebook(client.Config.Store(), ns, name)
+		}
+		if err == clinote.ErrNotebookNotEmpty {
+			fmt.Println("Error, the notebook has notes in it. Use the force flag to delete it anyway.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when deleting the notebook:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	notebookCmd.AddCommand(deleteNotebookCmd)
+	deleteNotebookCmd.Flags().StringP("name", "n", "", "The name of the notebook.")
+	deleteNotebookCmd.Flags().Bool("force", false, "Delete the notebook even if it has notes.")
+	deleteNotebookCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+}
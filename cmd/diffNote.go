@@ -0,0 +1,89 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var diffNoteCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show the differences between two notes.",
+	Long: `
+Diff prints a unified diff of the markdown content of the note given
+by the title flag against the note given by the against flag. The
+lines only in the against note are marked with "-" and the lines
+only in the title note are marked with "+".
+
+The notes can be given by their title, their index in the last
+listing or their GUID.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		against, err := cmd.Flags().GetString("against")
+		if err != nil {
+			fmt.Println("Error when parsing the against flag:", err)
+			return
+		}
+		if title == "" || against == "" {
+			fmt.Println("Error, the title and against flags have to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		lines, err := clinote.DiffNotes(client.Config.Store(), ns, title, against)
+		if err != nil {
+			fmt.Println("Error when getting the notes:", err)
+			os.Exit(1)
+		}
+		if !hasChanges(lines) {
+			fmt.Println("The notes have the same content.")
+			return
+		}
+		if err = clinote.WriteDiff(os.Stdout, against, title, lines); err != nil {
+			fmt.Println("Error when writing the diff:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(diffNoteCmd)
+	diffNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+	diffNoteCmd.Flags().String("against", "", "Title of the note to compare against.")
+}
+
+func hasChanges(lines []clinote.DiffLine) bool {
+	for _, l := range lines {
+		if l.Kind != ' ' {
+			return true
+		}
+	}
+	return false
+}
@@ -18,6 +18,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -30,7 +31,8 @@ var editNoteCmd = &cobra.Command{
 	Short: "Edit note.",
 	Long: `
 Edit allows you to edit the note. If no flags are set, the note is opened
-with the editor defined by the environment variable $EDITOR.
+with the editor defined by the environment variable $EDITOR. Another
+editor can be used for this edit with the editor flag.
 
 The first line will be used as the note title and the rest is encoded as
 the note content.
@@ -38,13 +40,45 @@ the note content.
 To change to title, the title flag can be used.
 
 The note can be moved to another notebook by defining the new notebook
-with the notebook flag.`,
+with the notebook flag.
+
+If the note has been changed on the server while it was being edited,
+the changes are not saved. Instead, they are saved as a recovery point
+which can be opened with the recover flag. Use the force flag to save
+the changes anyway.
+
+Use the stdin flag to replace the note's content with the content read
+from stdin, without opening the editor. If the content starts with a
+header, the title, notebook and tags in it are used for the note.
+
+The dry-run flag prints the content that would be uploaded instead of
+saving the changes.
+
+The show-diff flag prints a diff of the changes and asks for
+confirmation before they are saved.
+
+Editors that send the edited content back over a named pipe instead
+of writing the file can be used with the content-pipe flag. The
+content is read from the pipe once the editor has exited.
+
+The strict flag refuses to save the changes if the markdown has
+constructs that can't be represented in the note, like raw HTML or
+table rows with more cells than the header. The changes are saved
+as a recovery point instead.
+
+The emoji flag converts shortcodes like :tada: to their emoji.
+Shortcodes in code spans and code blocks are left as is.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		raw, err := cmd.Flags().GetBool("raw")
 		if err != nil {
 			fmt.Println("Error when paring raw flag:", err)
 			return
 		}
+		plain, err := cmd.Flags().GetBool("plain")
+		if err != nil {
+			fmt.Println("Error when parsing plain flag:", err)
+			return
+		}
 		title, err := cmd.Flags().GetString("title")
 		if err != nil {
 			fmt.Println("Error parsing the title:", err)
@@ -59,6 +93,46 @@ with the notebook flag.`,
 		if err != nil {
 			return
 		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Println("Error when parsing force flag:", err)
+			return
+		}
+		editor, err := cmd.Flags().GetString("editor")
+		if err != nil {
+			fmt.Println("Error when parsing editor flag:", err)
+			return
+		}
+		contentPipe, err := cmd.Flags().GetString("content-pipe")
+		if err != nil {
+			fmt.Println("Error when parsing content-pipe flag:", err)
+			return
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Println("Error when parsing dry-run flag:", err)
+			return
+		}
+		stdin, err := cmd.Flags().GetBool("stdin")
+		if err != nil {
+			fmt.Println("Error when parsing stdin flag:", err)
+			return
+		}
+		showDiff, err := cmd.Flags().GetBool("show-diff")
+		if err != nil {
+			fmt.Println("Error when parsing show-diff flag:", err)
+			return
+		}
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			fmt.Println("Error when parsing strict flag:", err)
+			return
+		}
+		emoji, err := cmd.Flags().GetBool("emoji")
+		if err != nil {
+			fmt.Println("Error when parsing emoji flag:", err)
+			return
+		}
 		client := defaultClient()
 		defer client.Close()
 		ns, err := client.GetNoteStore()
@@ -70,9 +144,40 @@ with the notebook flag.`,
 		if raw {
 			opts = opts | clinote.RawNote
 		}
+		if plain {
+			opts = opts | clinote.PlainTextNote
+		}
+		if force {
+			opts = opts | clinote.ForceNote
+		}
+		if dryRun {
+			opts = opts | clinote.DryRunNote
+		}
+		if stdin {
+			opts = opts | clinote.StdinNote
+		}
+		if showDiff {
+			opts = opts | clinote.ShowDiffNote
+		}
+		if strict {
+			opts = opts | clinote.StrictMarkdownNote
+		}
+		if emoji {
+			opts = opts | clinote.EmojiNote
+		}
 		if recover {
 			c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
+			c.EditorOverride = editor
+			c.CacheDir = cacheDir(client.Config.Store())
+			setContentPipe(c, contentPipe)
+			if showDiff && !stdin {
+				c.ConfirmSave = func() bool { return confirm("Save the changes?") }
+			}
 			err := clinote.EditNote(c, "", opts|clinote.UseRecoveryPointNote)
+			if err == clinote.ErrSaveCancelled {
+				fmt.Println("No changes were saved.")
+				return
+			}
 			if err != nil {
 				fmt.Println("Error when edit recovery note:", err)
 				os.Exit(1)
@@ -92,7 +197,31 @@ with the notebook flag.`,
 
 		if title == "" && notebook == "" {
 			c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
+			c.EditorOverride = editor
+			c.CacheDir = cacheDir(client.Config.Store())
+			setContentPipe(c, contentPipe)
+			if showDiff && !stdin {
+				c.ConfirmSave = func() bool { return confirm("Save the changes?") }
+			}
 			err := clinote.EditNote(c, args[0], opts)
+			if err == clinote.ErrEditorAborted {
+				fmt.Println("The editor was aborted, no changes were saved.")
+				return
+			}
+			if err == clinote.ErrSaveCancelled {
+				fmt.Println("No changes were saved.")
+				return
+			}
+			if errors.Is(err, clinote.ErrUnsupportedMarkdown) {
+				fmt.Println("Error when editing the note:", err)
+				fmt.Println("Your changes have been saved as a recovery point. Use --recover to open them.")
+				os.Exit(1)
+			}
+			if err == clinote.ErrNoteConflict {
+				fmt.Println("Error when editing the note:", err)
+				fmt.Println("Your changes have been saved as a recovery point. Use --recover to open them and --force to overwrite the server version.")
+				os.Exit(1)
+			}
 			if err != nil {
 				fmt.Println("Error when editing the note:", err)
 				os.Exit(1)
@@ -106,5 +235,14 @@ func init() {
 	editNoteCmd.Flags().StringP("title", "t", "", "Change the note title to.")
 	editNoteCmd.Flags().StringP("notebook", "b", "", "Move the note to notebook.")
 	editNoteCmd.Flags().Bool("raw", false, "Use raw content instead of markdown version.")
+	editNoteCmd.Flags().Bool("plain", false, "Edit the content as plain text instead of markdown.")
 	editNoteCmd.Flags().Bool("recover", false, "Recover previous note that failed to save.")
+	editNoteCmd.Flags().Bool("force", false, "Save the note even if it has been changed on the server.")
+	editNoteCmd.Flags().String("editor", "", "Editor to use instead of the configured one.")
+	editNoteCmd.Flags().String("content-pipe", "", "Read the edited content from the named pipe.")
+	editNoteCmd.Flags().Bool("dry-run", false, "Print the note content instead of saving it.")
+	editNoteCmd.Flags().Bool("stdin", false, "Replace the content with the content read from stdin.")
+	editNoteCmd.Flags().Bool("show-diff", false, "Show the changes before saving them.")
+	editNoteCmd.Flags().Bool("strict", false, "Refuse to save markdown that can't be represented in the note.")
+	editNoteCmd.Flags().Bool("emoji", false, "Convert emoji shortcodes like :tada: to emoji.")
 }
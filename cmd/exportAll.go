@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var exportAllCmd = &cobra.Command{
+	Use:   "export-all directory",
+	Short: "Export all notes to a directory.",
+	Long: `
+Export-all writes every note in all the notebooks to the
+directory, one subdirectory per notebook. The notes are written
+the same way as with the export command and are named after the
+note title.
+
+A manifest file is kept in the directory. When the same directory
+is exported to again, notes that haven't been updated since the
+last export are skipped. The notes are fetched at a slow pace to
+stay below the Evernote API rate limit. A note that fails to be
+exported is reported and the rest of the notes are still exported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, a directory has to be given")
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw parameter:", err)
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		n, err := clinote.ExportAll(client.Config.Store(), ns, args[0], opts)
+		fmt.Printf("Exported %d notes.\n", n)
+		if err != nil {
+			fmt.Println("Error when exporting the notes:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(exportAllCmd)
+	exportAllCmd.Flags().Bool("raw", false, "Export the raw content instead of markdown.")
+}
@@ -0,0 +1,129 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var exportNoteCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a note to a file.",
+	Long: `
+Export writes the note, including the header, to a file.
+The content is written as markdown unless the raw flag is
+given, in which case the ENML content is written.
+
+Long lines of markdown can be wrapped with the wrap flag. The
+content of code blocks and tables is never wrapped.
+
+With the format flag set to enex, the note is written in the
+Evernote export format (ENEX) instead, which can be imported
+by the Evernote client and other tools that read ENEX files.
+
+If no output file is given, the note is written to a file in
+the current directory named after the note title. Characters
+that aren't safe to use in a filename are replaced with a dash.
+
+An existing file is only overwritten if the force flag is
+given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			fmt.Println("Error when parsing output file:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw parameter:", err)
+			return
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Println("Error when parsing force parameter:", err)
+			return
+		}
+		wrap, err := cmd.Flags().GetInt("wrap")
+		if err != nil {
+			fmt.Println("Error when parsing wrap parameter:", err)
+			return
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			fmt.Println("Error when parsing format parameter:", err)
+			return
+		}
+		if format != "markdown" && format != "enex" {
+			fmt.Printf("Error, %q is not a valid format. Use markdown or enex.\n", format)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(path); path != "" && err == nil && !force {
+			fmt.Println("Error, the file " + path + " already exists. Use --force to overwrite it.")
+			os.Exit(1)
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		if force {
+			opts |= clinote.ForceNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if format == "enex" {
+			err = clinote.ExportNoteENEX(client.Config.Store(), ns, title, path, opts)
+		} else {
+			err = clinote.ExportNoteWrapped(client.Config.Store(), ns, title, path, opts, wrap)
+		}
+		if err == clinote.ErrExportFileExists {
+			fmt.Println("Error, the export file already exists. Use --force to overwrite it.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when exporting the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(exportNoteCmd)
+	exportNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+	exportNoteCmd.Flags().StringP("output", "o", "", "The file to export the note to. Defaults to the note title.")
+	exportNoteCmd.Flags().Bool("raw", false, "Export the raw content instead of markdown.")
+	exportNoteCmd.Flags().Bool("force", false, "Overwrite the file if it exists.")
+	exportNoteCmd.Flags().Int("wrap", 0, "Wrap markdown lines at the column.")
+	exportNoteCmd.Flags().String("format", "markdown", "The format to export the note in, markdown or enex.")
+}
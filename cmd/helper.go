@@ -1,6 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/evernote"
 	"github.com/TcM1911/clinote/storage"
@@ -14,7 +22,12 @@ func defaultClient() *evernote.Client {
 	}
 	cfg.DB = db
 	cfg.UDB = db
-	return evernote.NewClient(cfg)
+	c := evernote.NewClient(cfg)
+	c.Offline = offlineMode
+	c.Timeout = notestoreTimeout
+	c.MaxNoteSize = maxNoteSize
+	c.Context = interruptContext()
+	return c
 }
 
 func newClient(opts clinote.ClientOption) *clinote.Client {
@@ -26,9 +39,93 @@ func newClient(opts clinote.ClientOption) *clinote.Client {
 	cfg.DB = db
 	cfg.UDB = db
 	ec := evernote.NewClient(cfg)
+	ec.Offline = offlineMode
+	ec.Timeout = notestoreTimeout
+	ec.MaxNoteSize = maxNoteSize
+	ec.Context = interruptContext()
+	if offlineMode {
+		opts |= clinote.OfflineMode
+	}
 	ns, err := ec.GetNoteStore()
 	if err != nil {
 		panic("Error when getting notestore: " + err.Error())
 	}
-	return clinote.NewClient(cfg, db, ns, opts)
+	c := clinote.NewClient(cfg, db, ns, opts)
+	c.CacheDir = cacheDir(db)
+	c.PinTag = pinTag(db)
+	c.Opener = opener(db)
+	return c
+}
+
+var (
+	interruptOnce sync.Once
+	interruptCtx  context.Context
+)
+
+// interruptContext returns a context that is cancelled when the user
+// interrupts clinote, so the notestore calls in progress are stopped. After
+// the first interrupt, the signal is handled as usual again, so a second one
+// ends clinote right away.
+func interruptContext() context.Context {
+	interruptOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		interruptCtx = ctx
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt)
+		go func() {
+			<-ch
+			signal.Stop(ch)
+			cancel()
+		}()
+	})
+	return interruptCtx
+}
+
+// cacheDir returns the cache folder from the user's settings. An empty
+// string is returned if it isn't set.
+func cacheDir(db clinote.Storager) string {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return ""
+	}
+	return settings.CacheDir
+}
+
+// pinTag returns the tag used to pin notes from the user's settings. The
+// DefaultPinTag is returned if it isn't set.
+func pinTag(db clinote.Storager) string {
+	settings, err := db.GetSettings()
+	if err != nil || settings.PinTag == "" {
+		return clinote.DefaultPinTag
+	}
+	return settings.PinTag
+}
+
+// opener returns the command used to open notes from the user's settings.
+// An empty string is returned if it isn't set.
+func opener(db clinote.Storager) string {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return ""
+	}
+	return settings.Opener
+}
+
+// setContentPipe makes the client read the edited content from the named
+// pipe, if a path is given.
+func setContentPipe(c *clinote.Client, path string) {
+	if path != "" {
+		c.ContentSource = &clinote.PipeContentSource{Path: path}
+	}
+}
+
+// confirm asks the user the question and returns true if the answer is yes.
+func confirm(question string) bool {
+	fmt.Print(question + " [y/N]: ")
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes"
 }
@@ -0,0 +1,97 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var historyNoteCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List or restore previous versions of a note.",
+	Long: `
+History lists the versions of the note saved by Evernote. Each
+version is identified by its update sequence number (USN).
+
+Use the restore flag with the USN of a version to replace the
+title and content of the note with the ones in the version. The
+current version is kept in the history, so a restore can be undone.
+
+Note versions are only kept for premium accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		usn, err := cmd.Flags().GetInt32("restore")
+		if err != nil {
+			fmt.Println("Error when parsing restore value:", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		if cmd.Flags().Changed("restore") {
+			n, err := clinote.RestoreNoteVersion(db, ns, title, usn)
+			if errors.Is(err, clinote.ErrVersionsUnsupported) {
+				fmt.Println("Your account doesn't keep versions of notes.")
+				os.Exit(1)
+			}
+			if err != nil {
+				fmt.Println("Error when restoring the note version:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Restored %q to version %d.\n", n.Title, usn)
+			return
+		}
+		versions, err := clinote.ListNoteVersions(db, ns, title)
+		if errors.Is(err, clinote.ErrVersionsUnsupported) {
+			fmt.Println("Your account doesn't keep versions of notes.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when listing the note versions:", err)
+			os.Exit(1)
+		}
+		if len(versions) == 0 {
+			fmt.Println("The note doesn't have any previous versions.")
+			return
+		}
+		clinote.WriteNoteVersionListing(os.Stdout, versions)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(historyNoteCmd)
+	historyNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+	historyNoteCmd.Flags().Int32("restore", 0, "Restore the version with the USN.")
+}
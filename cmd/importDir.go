@@ -0,0 +1,78 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var importDirCmd = &cobra.Command{
+	Use:   "import-dir directory",
+	Short: "Import all markdown files in a directory.",
+	Long: `
+Import-dir creates a new note from each markdown file in the
+directory and its subdirectories. The files are imported the
+same way as with the import command. If a notebook is given,
+all the notes are saved to it. A file that fails to be imported
+is reported and the rest of the files are still imported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, a directory has to be given")
+			return
+		}
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing notebook parameter:", err)
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw parameter:", err)
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		notes, err := clinote.ImportDirectory(ns, args[0], notebook, opts)
+		for _, n := range notes {
+			fmt.Println("Imported note:", n.Title)
+		}
+		fmt.Printf("Imported %d notes.\n", len(notes))
+		if err != nil {
+			fmt.Println("Error when importing the notes:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(importDirCmd)
+	importDirCmd.Flags().StringP("notebook", "b", "", "Save all the notes to the notebook.")
+	importDirCmd.Flags().Bool("raw", false, "Import the content in raw mode.")
+}
@@ -0,0 +1,109 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var importNoteCmd = &cobra.Command{
+	Use:   "import file",
+	Short: "Import a note from a file.",
+	Long: `
+Import creates a new note from a file. The title, notebook
+and tags are read from the note header. If the file doesn't
+have a header, the filename without the extension is used
+as the title and the note is saved to the default notebook.
+
+The original dates of the note can be kept with the created and
+updated header fields, given in RFC 3339 format or in milliseconds
+since the epoch.
+
+Files with the .enex extension are read as an Evernote export
+and a note is created for each note in the file. The notes are
+saved to the notebook given by the notebook flag, or to the
+default notebook. Their content is kept as is, with its
+formatting. A note that fails to be imported is reported and
+the rest of the notes are still imported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, a file has to be given")
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw parameter:", err)
+			return
+		}
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing notebook parameter:", err)
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if strings.EqualFold(filepath.Ext(args[0]), ".enex") {
+			importENEX(ns, args[0], notebook, opts)
+			return
+		}
+		n, err := clinote.ImportNote(ns, args[0], opts)
+		if err != nil {
+			fmt.Println("Error when importing the note:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Imported note:", n.Title)
+	},
+}
+
+func importENEX(ns clinote.NotestoreClient, path, notebook string, opts clinote.NoteOption) {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Println("Error when opening the file:", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	notes, err := clinote.ImportENEX(ns, f, notebook, opts)
+	for _, n := range notes {
+		fmt.Println("Imported note:", n.Title)
+	}
+	fmt.Printf("Imported %d notes.\n", len(notes))
+	if err != nil {
+		fmt.Println("Error when importing the notes:", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	noteCmd.AddCommand(importNoteCmd)
+	importNoteCmd.Flags().Bool("raw", false, "Import the content in raw mode.")
+	importNoteCmd.Flags().StringP("notebook", "b", "", "Save the notes from an ENEX file to the notebook.")
+}
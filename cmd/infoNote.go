@@ -0,0 +1,70 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var infoNoteCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show note metadata.",
+	Long: `
+Info prints the metadata of the note: the title, GUID, notebook,
+tags, when it was created and updated, the number of words, and
+whether it has any attachments. The dates are shown in the local
+timezone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		n, err := clinote.GetNoteWithContent(db, ns, title)
+		if err != nil {
+			fmt.Println("Error when getting the note:", err)
+			os.Exit(1)
+		}
+		nbs, err := clinote.NotebooksForNotes(db, ns, []*clinote.Note{n})
+		if err != nil {
+			fmt.Println("Failed to get the notebooks:", err)
+			return
+		}
+		clinote.WriteNoteInfo(os.Stdout, n, nbs)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(infoNoteCmd)
+	infoNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+}
@@ -0,0 +1,69 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var linkNoteCmd = &cobra.Command{
+	Use:   "link",
+	Short: "Print links to the note.",
+	Long: `
+Link prints the links that open the note in the official clients.
+The first link opens the note in the Evernote app and the second
+link opens it in the web client.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		n, err := clinote.GetNote(client.Config.Store(), ns, title, "")
+		if err != nil {
+			fmt.Println("Error when getting the note:", err)
+			os.Exit(1)
+		}
+		shardID, userID, err := client.UserInfo()
+		if err != nil {
+			fmt.Println("Error when getting the user information:", err)
+			os.Exit(1)
+		}
+		fmt.Println(clinote.NoteLink(n, shardID, userID))
+		fmt.Println(clinote.NoteWebLink(n, shardID, userID))
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(linkNoteCmd)
+	linkNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+}
@@ -18,9 +18,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -33,13 +36,44 @@ var listNoteCmd = &cobra.Command{
 List returns a list of notes based on a search filter.
 The search term flag can be used to define a search term
 to be used. The search can be restricted to a notebook
-by using the notebook flag.
+by using the notebook flag, given as a name or GUID.
 
 Count can be used to restrict the maximum number of notes
-returned.
+returned. A count of 0 lists all the matching notes.
+
+The search can be restricted to notes with a tag by using the
+tag flag. The flag can be given multiple times to only match
+notes that have all the tags. Use the stack flag to restrict the
+search to the notebooks in a stack. The pinned flag only lists
+the notes tagged with the pin tag, see "note pin".
+
+The since and until flags restrict the search to notes created
+in the date range. The dates are given as 2006-01-02 and both
+days are included. Use the updated flag to filter on the time
+the notes were modified instead.
 
 If no search term is given, a wild card search will be used.
-The notes will be sorted by the modified time.`,
+The notes will be sorted by the modified time, unless another
+order is given with the sort flag. Valid sort orders are:
+created, updated, title and relevance. The newest notes are listed
+first and titles are listed from A to Z. Use the reverse flag to
+reverse the order.
+
+Use the offset flag to skip the first notes in the result, or the
+page flag to get a page of notes where each page holds count notes.
+
+Use the show-guid flag to include the GUID of each note in the
+listing. The notes can still be referred to by their index, or
+by the GUID instead of the title. Use the relative-time flag to show
+the modified time as "3 days ago" instead of the date.
+
+The listing is printed as a table by default. Use "--output json"
+or "--output yaml" to print the notes as a JSON array or a YAML
+sequence instead. Use the titles-only flag
+to print only the note titles, one per line, for example for shell
+completion. Notes with a line break in the title are left out and
+the search isn't saved, so the note indexes from the last listing
+can still be used.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		findNotes(cmd, args)
 	},
@@ -47,9 +81,111 @@ The notes will be sorted by the modified time.`,
 
 func init() {
 	noteCmd.AddCommand(listNoteCmd)
-	listNoteCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
+	listNoteCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result, 0 for all.")
 	listNoteCmd.Flags().StringP("search", "s", "", "Search term.")
-	listNoteCmd.Flags().StringP("notebook", "b", "", "Restrict search to notebook.")
+	listNoteCmd.Flags().StringP("notebook", "b", "", "Restrict search to the notebook name or GUID.")
+	listNoteCmd.Flags().StringSlice("tag", nil, "Restrict search to notes with the tag.")
+	listNoteCmd.Flags().Bool("pinned", false, "Only list the pinned notes.")
+	listNoteCmd.Flags().String("stack", "", "Restrict search to notebooks in the stack.")
+	listNoteCmd.Flags().String("since", "", "Only list notes from the date, given as 2006-01-02.")
+	listNoteCmd.Flags().String("until", "", "Only list notes up to and including the date, given as 2006-01-02.")
+	listNoteCmd.Flags().Bool("updated", false, "Use the modified time for the since and until flags.")
+	listNoteCmd.Flags().String("sort", "updated", "Sort order of the notes.")
+	listNoteCmd.Flags().BoolP("reverse", "r", false, "Reverse the sort order.")
+	listNoteCmd.Flags().Int("offset", 0, "Number of notes to skip.")
+	listNoteCmd.Flags().Bool("show-guid", false, "Include the note GUIDs in the listing.")
+	listNoteCmd.Flags().Bool("relative-time", false, "Show the modified time relative to now.")
+	listNoteCmd.Flags().Int("page", 0, "Page of notes to list, starting at 1.")
+	listNoteCmd.Flags().StringP("output", "o", outputFormats[0], outputFormatUsage())
+	listNoteCmd.Flags().Bool("titles-only", false, "Only print the note titles, one per line.")
+}
+
+var noteSortOrders = map[string]int32{
+	"created":   clinote.NoteFilterOrderCreated,
+	"updated":   clinote.NoteFilterOrderUpdated,
+	"title":     clinote.NoteFilterOrderTitle,
+	"relevance": clinote.NoteFilterOrderRelevance,
+}
+
+func noteSortOrderNames() string {
+	names := make([]string, 0, len(noteSortOrders))
+	for name := range noteSortOrders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// dateFlagFormat is the format of the since and until flags.
+const dateFlagFormat = "2006-01-02"
+
+// setDateRange sets the filter's time range from the since and until flags.
+func setDateRange(cmd *cobra.Command, filter *clinote.NoteFilter) error {
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return err
+	}
+	until, err := cmd.Flags().GetString("until")
+	if err != nil {
+		return err
+	}
+	updated, err := cmd.Flags().GetBool("updated")
+	if err != nil {
+		return err
+	}
+	var after, before int64
+	if since != "" {
+		t, err := time.ParseInLocation(dateFlagFormat, since, time.Local)
+		if err != nil {
+			return err
+		}
+		after = toMillis(t)
+	}
+	if until != "" {
+		t, err := time.ParseInLocation(dateFlagFormat, until, time.Local)
+		if err != nil {
+			return err
+		}
+		// Include the whole day.
+		before = toMillis(t.AddDate(0, 0, 1))
+	}
+	if after != 0 && before != 0 && after >= before {
+		return errors.New("since has to be before until")
+	}
+	if updated {
+		filter.UpdatedAfter, filter.UpdatedBefore = after, before
+	} else {
+		filter.CreatedAfter, filter.CreatedBefore = after, before
+	}
+	return nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// progressCount is the number of notes listed before the progress of the
+// search is shown.
+const progressCount = 100
+
+// fetchNotes returns the notes matching the filter. If progress is true, the
+// number of fetched notes is written to stderr as they arrive.
+func fetchNotes(ns clinote.NotestoreClient, filter *clinote.NoteFilter, offset, count int, progress bool) ([]*clinote.Note, error) {
+	notes, errs := clinote.FindNotesStream(ns, filter, offset, count)
+	var list []*clinote.Note
+	status := ""
+	for n := range notes {
+		list = append(list, n)
+		if progress {
+			status = fmt.Sprintf("Fetched %d notes...", len(list))
+			fmt.Fprint(os.Stderr, "\r"+status)
+		}
+	}
+	if status != "" {
+		// Clear the progress line before the listing is written.
+		fmt.Fprint(os.Stderr, "\r"+strings.Repeat(" ", len(status))+"\r")
+	}
+	return list, <-errs
 }
 
 func findNotes(cmd *cobra.Command, args []string) {
@@ -58,12 +194,77 @@ func findNotes(cmd *cobra.Command, args []string) {
 
 	// Create filter
 	filter := &clinote.NoteFilter{}
-	filter.Order = clinote.NoteFilterOrderUpdated
+	sortName, err := cmd.Flags().GetString("sort")
+	if err != nil {
+		fmt.Println("Error when parsing sort order:", err)
+		return
+	}
+	order, ok := noteSortOrders[strings.ToLower(sortName)]
+	if !ok {
+		fmt.Printf("Invalid sort order %q, valid values are: %s\n", sortName, noteSortOrderNames())
+		os.Exit(1)
+	}
+	filter.Order = order
+	reverse, err := cmd.Flags().GetBool("reverse")
+	if err != nil {
+		fmt.Println("Error when parsing reverse flag:", err)
+		return
+	}
+	filter.Reverse = reverse
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		fmt.Println("Error when parsing output format:", err)
+		return
+	}
+	if !validOutputFormat(output) {
+		fmt.Println(invalidOutputFormat(output))
+		os.Exit(1)
+	}
+	titlesOnly, err := cmd.Flags().GetBool("titles-only")
+	if err != nil {
+		fmt.Println("Error when parsing titles-only flag:", err)
+		return
+	}
+	if titlesOnly && cmd.Flags().Changed("output") {
+		fmt.Println("Error, the output flag can't be used with the titles-only flag")
+		os.Exit(1)
+	}
+	showGUID, err := cmd.Flags().GetBool("show-guid")
+	if err != nil {
+		fmt.Println("Error when parsing show-guid flag:", err)
+		return
+	}
+	relativeTime, err := cmd.Flags().GetBool("relative-time")
+	if err != nil {
+		fmt.Println("Error when parsing relative-time flag:", err)
+		return
+	}
 	c, err := cmd.Flags().GetInt("count")
 	if err != nil {
 		fmt.Println("Error when parsing count value, using default:", err)
 		c = 20
 	}
+	offset, err := cmd.Flags().GetInt("offset")
+	if err != nil {
+		fmt.Println("Error when parsing offset value:", err)
+		return
+	}
+	page, err := cmd.Flags().GetInt("page")
+	if err != nil {
+		fmt.Println("Error when parsing page value:", err)
+		return
+	}
+	if offset < 0 || page < 0 || c < 0 {
+		fmt.Println("Error, count, offset and page can't be negative")
+		os.Exit(1)
+	}
+	if page > 0 && c == 0 {
+		fmt.Println("Error, the page flag can't be used with a count of 0")
+		os.Exit(1)
+	}
+	if page > 0 {
+		offset = (page - 1) * c
+	}
 	searchBook, err := cmd.Flags().GetString("notebook")
 	if err != nil {
 		fmt.Println("Error when parsing notebook:", err)
@@ -74,6 +275,26 @@ func findNotes(cmd *cobra.Command, args []string) {
 		fmt.Println("Error when parsing search term", err)
 		return
 	}
+	tagNames, err := cmd.Flags().GetStringSlice("tag")
+	if err != nil {
+		fmt.Println("Error when parsing tags:", err)
+		return
+	}
+	pinned, err := cmd.Flags().GetBool("pinned")
+	if err != nil {
+		fmt.Println("Error when parsing pinned flag:", err)
+		return
+	}
+	stack, err := cmd.Flags().GetString("stack")
+	if err != nil {
+		fmt.Println("Error when parsing stack name:", err)
+		return
+	}
+	filter.Stack = stack
+	if err = setDateRange(cmd, filter); err != nil {
+		fmt.Println("Error when parsing the date range:", err)
+		os.Exit(1)
+	}
 
 	if search != "" {
 		filter.Words = search
@@ -91,21 +312,82 @@ func findNotes(cmd *cobra.Command, args []string) {
 		}
 		filter.NotebookGUID = book.GUID
 	}
+	if pinned {
+		pin := pinTag(client.Config.Store())
+		// Without the tag, no notes have been pinned.
+		if _, err = clinote.FindTags(ns, []string{pin}); errors.Is(err, clinote.ErrNoTagFound) {
+			fmt.Println("No notes have been pinned.")
+			return
+		}
+		tagNames = append(tagNames, pin)
+	}
+	if len(tagNames) > 0 {
+		tags, err := clinote.FindTags(ns, tagNames)
+		if err != nil {
+			fmt.Println("Error when trying to filter by tag:", err)
+			os.Exit(1)
+		}
+		for _, tag := range tags {
+			filter.TagGUIDs = append(filter.TagGUIDs, tag.GUID)
+		}
+	}
 
-	list, err := clinote.FindNotes(ns, filter, 0, c)
-	if err != nil {
-		log.Fatal(err)
+	// Ask for one more note than requested to know if there are more results.
+	// A count of 0 fetches all the notes.
+	fetchCount := c + 1
+	if c == 0 {
+		fetchCount = 0
+	}
+	list, err := fetchNotes(ns, filter, offset, fetchCount, !titlesOnly && output == "table" && (c == 0 || c > progressCount))
+	if errors.Is(err, clinote.ErrNoteLimitReached) {
+		fmt.Fprintf(os.Stderr, "Warning: the listing was stopped after %d notes, there may be more.\n", clinote.MaxFindNotes)
+		err = nil
 	}
+	if err != nil && !errors.Is(err, clinote.ErrNoNoteFound) {
+		fmt.Println("Error when searching for notes:", err)
+		os.Exit(1)
+	}
+	more := c > 0 && len(list) > c
+	if more {
+		list = list[:c]
+	}
+	if titlesOnly {
+		if err = clinote.WriteNoteTitles(os.Stdout, list); err != nil {
+			fmt.Println("Error when writing the note titles:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// The search is saved even if it's empty so the note indexes from an
+	// older search aren't used by mistake.
 	err = client.Config.Store().SaveSearch(list)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Println("Error when saving the search:", err)
+		os.Exit(1)
+	}
+	if len(list) == 0 && output == "table" {
+		fmt.Println("No notes matched your search.")
+		return
 	}
 
-	nbs, err := clinote.GetNotebooks(client.Config.Store(), ns, false)
+	nbs, err := clinote.NotebooksForNotes(client.Config.Store(), ns, list)
 	if err != nil {
-		fmt.Println("Failed to get all notebooks:", err)
+		fmt.Println("Failed to get the notebooks:", err)
 		return
 	}
 
-	clinote.WriteNoteListing(os.Stdout, list, nbs)
+	opts := clinote.DefaultListingOption
+	if showGUID {
+		opts |= clinote.GUIDListing
+	}
+	if relativeTime {
+		opts |= clinote.RelativeTimeListing
+	}
+	if err = writeNotes(os.Stdout, output, list, nbs, opts); err != nil {
+		fmt.Println("Error when writing the note listing:", err)
+		os.Exit(1)
+	}
+	if more && output == "table" {
+		fmt.Printf("More notes are available, use --offset %d to list them.\n", offset+c)
+	}
 }
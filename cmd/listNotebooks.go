@@ -29,23 +29,39 @@ var listNotebooksCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List notebooks.",
 	Long: `
-List notebooks returns all active notebooks.`,
+List notebooks returns all active notebooks.
+
+The listing can be restricted to the notebooks in a stack
+by using the stack flag. Use the counts flag to include the
+number of notes in each notebook.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		sync, err := cmd.Flags().GetBool("sync")
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
-		listNotebooks(sync)
+		stack, err := cmd.Flags().GetString("stack")
+		if err != nil {
+			fmt.Println("Error when parsing stack name:", err)
+			return
+		}
+		counts, err := cmd.Flags().GetBool("counts")
+		if err != nil {
+			fmt.Println("Error when parsing counts flag:", err)
+			return
+		}
+		listNotebooks(sync, stack, counts)
 	},
 }
 
 func init() {
 	notebookCmd.AddCommand(listNotebooksCmd)
 	listNotebooksCmd.Flags().BoolP("sync", "s", false, "Force a resync of notebooks from the server.")
+	listNotebooksCmd.Flags().String("stack", "", "Only list notebooks in the stack.")
+	listNotebooksCmd.Flags().Bool("counts", false, "Show the number of notes in each notebook.")
 }
 
-func listNotebooks(sync bool) {
+func listNotebooks(sync bool, stack string, counts bool) {
 	client := defaultClient()
 	defer client.Close()
 	ns, err := client.GetNoteStore()
@@ -57,5 +73,17 @@ func listNotebooks(sync bool) {
 		fmt.Println("Error when getting notebooks:", err)
 		os.Exit(1)
 	}
-	clinote.WriteNotebookListing(os.Stdout, bs)
+	if stack != "" {
+		bs = clinote.NotebooksInStack(bs, stack)
+	}
+	if !counts {
+		clinote.WriteNotebookListing(os.Stdout, bs)
+		return
+	}
+	nc, err := clinote.NoteCounts(client.Config.Store(), ns, bs, sync)
+	if err != nil {
+		fmt.Println("Error when getting the note counts:", err)
+		os.Exit(1)
+	}
+	clinote.WriteNotebookListingWithCounts(os.Stdout, bs, nc)
 }
@@ -0,0 +1,83 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var moveNoteCmd = &cobra.Command{
+	Use:   "move \"note title\"",
+	Short: "Move notes to another notebook.",
+	Long: `
+Move moves the note into the notebook given by the notebook flag.
+
+All notes matching a search can be moved at once by using the search
+flag instead of giving a note title. A note that fails to be moved
+doesn't stop the rest, a summary of the failures is printed at the end.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nb, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing the notebook name:", err)
+			return
+		}
+		if nb == "" {
+			fmt.Println("Error, a notebook has to be given")
+			return
+		}
+		search, err := cmd.Flags().GetString("search")
+		if err != nil {
+			fmt.Println("Error when parsing the search term:", err)
+			return
+		}
+		if search == "" && len(args) != 1 {
+			fmt.Println("Error, a note title or a search has to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		if search == "" {
+			if err = clinote.MoveNote(db, ns, args[0], nb); err != nil {
+				fmt.Println("Error when moving the note:", err)
+				os.Exit(1)
+			}
+			return
+		}
+		n, err := clinote.MoveNotesMatching(db, ns, &clinote.NoteFilter{Words: search}, nb)
+		fmt.Printf("Moved %d notes.\n", n)
+		if err != nil {
+			fmt.Println("Error when moving the notes:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(moveNoteCmd)
+	moveNoteCmd.Flags().StringP("notebook", "b", "", "The notebook name or GUID to move the notes to.")
+	moveNoteCmd.Flags().StringP("search", "s", "", "Move the notes matching the search.")
+}
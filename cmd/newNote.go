@@ -18,7 +18,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -29,12 +31,47 @@ var newNoteCmd = &cobra.Command{
 	Short: "Create a new note.",
 	Long: `
 New creates a new note. A title needs to be given for the
-note.
+note, unless the content is read from stdin. In that case, a
+markdown heading on the first line of the content is used as
+the title.
 
-If no notebook is given, the default notebook will be used.
+If no notebook is given, the notebook set with "user set notebook"
+will be used. If none has been set, the notebook in the environment
+variable CLINOTE_DEFAULT_NOTEBOOK is used, and otherwise the default
+notebook.
 
 The new note can be open in the $EDITOR by using the edit
-flag.`,
+flag. Another editor can be used with the editor flag. Use the
+content-pipe flag to read the edited content from a named pipe
+instead of the file, for editors that send it back over a pipe.
+
+Files can be attached to the note with the attach flag. The
+flag can be given multiple times to attach more than one file.
+The from-clipboard-image flag attaches the image on the clipboard
+and adds a reference to it at the end of the note. On Linux,
+xclip or wl-paste on Wayland is used to read the clipboard.
+
+The template flag creates the note from a template file. The
+file can start with a header, like an edited note, to set the
+title, notebook and tags. The placeholders {{date}} and {{title}}
+are replaced with the current date and the given title. The
+title and notebook flags take precedence over the template.
+
+The dry-run flag prints the content that would be uploaded
+instead of saving the note.
+
+A note isn't created if a note with the same title already exists
+in the notebook. Use the allow-duplicate flag to create it anyway.
+If the title is changed in the editor to match an existing note,
+a warning is printed and the note is still created.
+
+The strict flag refuses to save the note if the markdown has
+constructs that can't be represented in the note, like raw HTML
+or table rows with more cells than the header. The constructs
+are listed in the error.
+
+The emoji flag converts shortcodes like :tada: to their emoji.
+Shortcodes in code spans and code blocks are left as is.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		title, err := cmd.Flags().GetString("title")
 		if err != nil {
@@ -46,7 +83,17 @@ flag.`,
 			fmt.Println("Error when parsing edit flag:", err)
 			return
 		}
-		if title == "" && !edit {
+		stdin, err := cmd.Flags().GetBool("stdin")
+		if err != nil {
+			fmt.Println("Error when parsing stdin parameter:", err)
+			return
+		}
+		template, err := cmd.Flags().GetString("template")
+		if err != nil {
+			fmt.Println("Error when parsing template parameter:", err)
+			return
+		}
+		if title == "" && !edit && template == "" && !stdin {
 			fmt.Println("Note title has to be given")
 			return
 		}
@@ -60,35 +107,151 @@ flag.`,
 			fmt.Println("Error when parsing raw parameter:", err)
 			return
 		}
-		stdin, err := cmd.Flags().GetBool("stdin")
+		plain, err := cmd.Flags().GetBool("plain")
 		if err != nil {
-			fmt.Println("Error when parsing stdin parameter:", err)
+			fmt.Println("Error when parsing plain parameter:", err)
+			return
+		}
+		attach, err := cmd.Flags().GetStringSlice("attach")
+		if err != nil {
+			fmt.Println("Error when parsing attach parameter:", err)
+			return
+		}
+		editor, err := cmd.Flags().GetString("editor")
+		if err != nil {
+			fmt.Println("Error when parsing editor parameter:", err)
+			return
+		}
+		contentPipe, err := cmd.Flags().GetString("content-pipe")
+		if err != nil {
+			fmt.Println("Error when parsing content-pipe parameter:", err)
+			return
+		}
+		clipboardImage, err := cmd.Flags().GetBool("from-clipboard-image")
+		if err != nil {
+			fmt.Println("Error when parsing from-clipboard-image parameter:", err)
+			return
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Println("Error when parsing dry-run parameter:", err)
+			return
+		}
+
+		allowDuplicate, err := cmd.Flags().GetBool("allow-duplicate")
+		if err != nil {
+			fmt.Println("Error when parsing allow-duplicate parameter:", err)
+			return
+		}
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			fmt.Println("Error when parsing strict parameter:", err)
+			return
+		}
+		emoji, err := cmd.Flags().GetBool("emoji")
+		if err != nil {
+			fmt.Println("Error when parsing emoji parameter:", err)
 			return
 		}
 
-		createNote(title, notebook, edit, raw, stdin)
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		if plain {
+			opts |= clinote.PlainTextNote
+		}
+		if stdin {
+			opts |= clinote.StdinNote
+		}
+		if dryRun {
+			opts |= clinote.DryRunNote
+		}
+		if !allowDuplicate {
+			opts |= clinote.UniqueTitleNote
+		}
+		if strict {
+			opts |= clinote.StrictMarkdownNote
+		}
+		if emoji {
+			opts |= clinote.EmojiNote
+		}
+		createNote(newNoteParams{
+			title:          title,
+			notebook:       notebook,
+			template:       template,
+			edit:           edit,
+			clipboardImage: clipboardImage,
+			attach:         attach,
+			editor:         editor,
+			contentPipe:    contentPipe,
+		}, opts)
 	},
 }
 
 func init() {
 	noteCmd.AddCommand(newNoteCmd)
 	newNoteCmd.Flags().StringP("title", "t", "", "Note title.")
-	newNoteCmd.Flags().StringP("notebook", "b", "", "The notebook to save note to, if not set the default notebook will be used.")
+	newNoteCmd.Flags().StringP("notebook", "b", "", "The notebook name or GUID to save note to, if not set the default notebook will be used.")
 	newNoteCmd.Flags().BoolP("edit", "e", false, "Open note in the editor.")
 	newNoteCmd.Flags().Bool("raw", false, "Edit the content in raw mode.")
+	newNoteCmd.Flags().Bool("plain", false, "Write the content as plain text instead of markdown.")
 	newNoteCmd.Flags().Bool("stdin", false, "Read content from stdin.")
+	newNoteCmd.Flags().StringSlice("attach", nil, "Attach the file to the note.")
+	newNoteCmd.Flags().Bool("from-clipboard-image", false, "Attach the image on the clipboard and embed it in the note.")
+	newNoteCmd.Flags().String("editor", "", "Editor to use instead of the configured one.")
+	newNoteCmd.Flags().String("content-pipe", "", "Read the edited content from the named pipe.")
+	newNoteCmd.Flags().Bool("dry-run", false, "Print the note content instead of saving it.")
+	newNoteCmd.Flags().String("template", "", "Create the note from the template file.")
+	newNoteCmd.Flags().Bool("allow-duplicate", false, "Create the note even if the notebook has a note with the title.")
+	newNoteCmd.Flags().Bool("strict", false, "Refuse to save markdown that can't be represented in the note.")
+	newNoteCmd.Flags().Bool("emoji", false, "Convert emoji shortcodes like :tada: to emoji.")
 }
 
-func createNote(title, notebook string, edit, raw bool, stdin bool) {
+// newNoteParams are the flags the new note is created from, other than the
+// note options.
+type newNoteParams struct {
+	title, notebook, template string
+	edit, clipboardImage      bool
+	attach                    []string
+	editor, contentPipe       string
+}
+
+func createNote(p newNoteParams, opts clinote.NoteOption) {
 	c := newClient(clinote.DefaultClientOptions)
 	defer c.Store.Close()
+	c.EditorOverride = p.editor
+	setContentPipe(c, p.contentPipe)
 
+	title, notebook := p.title, p.notebook
 	note := new(clinote.Note)
-	if title == "" {
-		note.Title = "Untitled note"
+	if p.template != "" {
+		var err error
+		note, err = clinote.NewNoteFromTemplate(p.template, title, opts)
+		if err != nil {
+			fmt.Println("Error when reading the template:", err)
+			return
+		}
+		if notebook == "" && note.Notebook != nil {
+			notebook = note.Notebook.Name
+		}
+		note.Notebook = nil
+	} else if title == "" {
+		note.Title = clinote.DefaultNoteTitle
 	} else {
 		note.Title = title
 	}
+	if opts&clinote.StdinNote != 0 && !p.edit {
+		if err := clinote.ReadNewNoteFromStdin(note, opts); err != nil {
+			fmt.Println("Error when reading the note from stdin:", err)
+			return
+		}
+		if notebook == "" && note.Notebook != nil {
+			notebook = note.Notebook.Name
+		}
+		note.Notebook = nil
+	}
+	notebook = clinote.NotebookForNewNote(c.Config, notebook)
 	if notebook != "" {
 		nb, err := clinote.FindNotebook(c.Store, c.NoteStore, notebook)
 		if err != nil {
@@ -97,19 +260,64 @@ func createNote(title, notebook string, edit, raw bool, stdin bool) {
 		}
 		note.Notebook = nb
 	}
-	opts := clinote.DefaultNoteOption
-	if raw {
-		opts |= clinote.RawNote
+	for _, path := range p.attach {
+		a, err := clinote.NewAttachmentFromFile(path)
+		if err != nil {
+			fmt.Println("Error when reading the attachment:", err)
+			return
+		}
+		note.Resources = append(note.Resources, a)
 	}
-	if stdin {
-		opts |= clinote.StdinNote
+	if p.clipboardImage {
+		a, err := clinote.NewAttachmentFromClipboard(clinote.SystemClipboard{})
+		if err != nil {
+			fmt.Println("Error when reading the image from the clipboard:", err)
+			return
+		}
+		if opts&(clinote.RawNote|clinote.PlainTextNote) != 0 {
+			// The markdown isn't converted, so the image is added to
+			// the end of the note.
+			note.Resources = append(note.Resources, a)
+		} else {
+			clinote.EmbedImage(note, a)
+		}
 	}
 
-	if edit {
-		if err := clinote.CreateAndEditNewNote(c, note, opts); err != nil {
+	if p.edit {
+		err := clinote.CreateAndEditNewNote(c, note, opts)
+		if err == clinote.ErrEditorAborted {
+			fmt.Println("The editor was aborted, the note was not created.")
+			return
+		}
+		if errors.Is(err, clinote.ErrDuplicateNote) {
+			printDuplicateNote(note)
+			return
+		}
+		if errors.Is(err, clinote.ErrUnsupportedMarkdown) {
+			// The note only exists in the editor's file, so print the
+			// content to not lose it.
+			fmt.Println("Error when saving the note:", err)
+			fmt.Println("The note was not created, its content was:")
+			fmt.Println(note.MD)
+			return
+		}
+		if err != nil {
 			fmt.Println("Error when editing the note:", err)
 		}
 		return
 	}
-	clinote.SaveNewNote(c.NoteStore, note, raw)
+	err := clinote.SaveNewNote(c.NoteStore, note, opts)
+	if errors.Is(err, clinote.ErrDuplicateNote) {
+		printDuplicateNote(note)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Println("Error when saving the note:", err)
+		os.Exit(1)
+	}
+}
+
+func printDuplicateNote(note *clinote.Note) {
+	fmt.Printf("A note with the title %q already exists in the notebook, the note was not created.\n", note.Title)
+	fmt.Println("Use --allow-duplicate to create it anyway.")
 }
@@ -26,10 +26,14 @@ import (
 )
 
 var newBookCmd = &cobra.Command{
-	Use:   "new \"notebook name\"",
-	Short: "Create a new notebook.",
+	Use:     "new \"notebook name\"",
+	Aliases: []string{"create"},
+	Short:   "Create a new notebook.",
 	Long: `
-New creates a new notebook.`,
+New creates a new notebook. The name can be given as an argument
+or with the name flag.
+
+The notebook is added to a stack by using the stack flag.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		createNotebook(cmd, args)
 	},
@@ -37,17 +41,26 @@ New creates a new notebook.`,
 
 func init() {
 	notebookCmd.AddCommand(newBookCmd)
+	newBookCmd.Flags().StringP("name", "n", "", "Notebook name.")
 	newBookCmd.Flags().StringP("stack", "s", "", "Add notebook to stack.")
 	newBookCmd.Flags().BoolP("default", "d", false, "If notebook should be set to the default notebook.")
 }
 
 func createNotebook(cmd *cobra.Command, args []string) {
-	if len(args) != 1 {
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		fmt.Println("Error when parsing notebook name:", err)
+		os.Exit(1)
+	}
+	if name == "" && len(args) == 1 {
+		name = args[0]
+	}
+	if name == "" || len(args) > 1 {
 		fmt.Println("No notebook name given")
 		os.Exit(1)
 	}
 	nb := &clinote.Notebook{}
-	nb.Name = args[0]
+	nb.Name = name
 
 	stack, err := cmd.Flags().GetString("stack")
 	if err != nil {
@@ -0,0 +1,178 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var noteTagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Change the note's tags.",
+	Long:  `Change the note's tags without opening the editor.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var addNoteTagCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Tag the note.",
+	Long: `
+Add tags the note with the tag. If you don't have a tag with the
+name, the create flag has to be given to create it.
+
+All notes matching a search can be tagged by using the search flag
+together with the all flag instead of the title flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, tag, search, ok := noteTagFlags(cmd)
+		if !ok {
+			return
+		}
+		create, err := cmd.Flags().GetBool("create")
+		if err != nil {
+			fmt.Println("Error when parsing the create flag:", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if !create {
+			_, err = clinote.FindTags(ns, []string{tag})
+			if errors.Is(err, clinote.ErrNoTagFound) {
+				fmt.Printf("No tag named %q, use the create flag to create it.\n", tag)
+				os.Exit(1)
+			}
+			if err != nil {
+				fmt.Println("Error when getting the tags:", err)
+				os.Exit(1)
+			}
+		}
+		if search != "" {
+			tagNotesMatching(client.Config.Store(), ns, search, tag, true)
+			return
+		}
+		if err = clinote.AddTagToNote(client.Config.Store(), ns, title, tag); err != nil {
+			fmt.Println("Error when tagging the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var removeNoteTagCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a tag from the note.",
+	Long: `
+Remove removes the tag from the note. The tag itself is not deleted.
+
+The tag can be removed from all notes matching a search by using the
+search flag together with the all flag instead of the title flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, tag, search, ok := noteTagFlags(cmd)
+		if !ok {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if search != "" {
+			tagNotesMatching(client.Config.Store(), ns, search, tag, false)
+			return
+		}
+		if err = clinote.RemoveTagFromNote(client.Config.Store(), ns, title, tag); err != nil {
+			fmt.Println("Error when removing the tag:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(noteTagCmd)
+	noteTagCmd.AddCommand(addNoteTagCmd)
+	noteTagCmd.AddCommand(removeNoteTagCmd)
+	addNoteTagCmd.Flags().StringP("title", "t", "", "Note title.")
+	addNoteTagCmd.Flags().String("tag", "", "Tag name.")
+	addNoteTagCmd.Flags().Bool("create", false, "Create the tag if it doesn't exist.")
+	addNoteTagCmd.Flags().StringP("search", "s", "", "Tag the notes matching the search.")
+	addNoteTagCmd.Flags().Bool("all", false, "Tag all notes matching the search.")
+	removeNoteTagCmd.Flags().StringP("title", "t", "", "Note title.")
+	removeNoteTagCmd.Flags().String("tag", "", "Tag name.")
+	removeNoteTagCmd.Flags().StringP("search", "s", "", "Remove the tag from the notes matching the search.")
+	removeNoteTagCmd.Flags().Bool("all", false, "Remove the tag from all notes matching the search.")
+}
+
+// noteTagFlags returns the note title, tag name and search flags. Either
+// the title or the search together with the all flag has to be given. If
+// any of them are missing, an error is printed and false is returned.
+func noteTagFlags(cmd *cobra.Command) (string, string, string, bool) {
+	title, err := cmd.Flags().GetString("title")
+	if err != nil {
+		fmt.Println("Error when parsing note title:", err)
+		return "", "", "", false
+	}
+	tag, err := cmd.Flags().GetString("tag")
+	if err != nil {
+		fmt.Println("Error when parsing tag name:", err)
+		return "", "", "", false
+	}
+	search, err := cmd.Flags().GetString("search")
+	if err != nil {
+		fmt.Println("Error when parsing the search term:", err)
+		return "", "", "", false
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		fmt.Println("Error when parsing the all flag:", err)
+		return "", "", "", false
+	}
+	if search != "" && title != "" {
+		fmt.Println("Error, the title flag can't be used with the search flag")
+		return "", "", "", false
+	}
+	if search != "" && !all {
+		fmt.Println("Error, the all flag has to be given to change the notes matching the search")
+		return "", "", "", false
+	}
+	if (title == "" && search == "") || tag == "" {
+		fmt.Println("Note title or search and tag name have to be given")
+		return "", "", "", false
+	}
+	return title, tag, search, true
+}
+
+// tagNotesMatching adds or removes the tag from the notes matching the
+// search and prints the number of modified notes.
+func tagNotesMatching(db clinote.Storager, ns clinote.NotestoreClient, search, tag string, add bool) {
+	n, err := clinote.TagNotesMatching(db, ns, &clinote.NoteFilter{Words: search}, tag, add)
+	fmt.Printf("Modified %d notes.\n", n)
+	if err != nil {
+		fmt.Println("Error when changing the tags of the notes:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// offlineMode is set by the offline flag. The notestore isn't used in
+// offline mode, only the data in the local cache.
+var offlineMode bool
+
+// offlineCommands are the commands that can be used in offline mode. They
+// only use the local database, or the cache with the OfflineNotestore.
+var offlineCommands = make(map[*cobra.Command]bool)
+
+// allowOffline marks the commands as usable in offline mode.
+func allowOffline(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		offlineCommands[c] = true
+	}
+}
+
+// checkOffline stops commands that need the network before they do
+// anything if clinote runs in offline mode.
+func checkOffline(cmd *cobra.Command, args []string) {
+	if !offlineMode || offlineCommands[cmd] || cmd.Name() == "help" {
+		return
+	}
+	fmt.Printf("Error, %q needs network access and can't be used in offline mode.\n", cmd.CommandPath())
+	os.Exit(1)
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Only use the local cache, without network access.")
+	RootCmd.PersistentPreRun = checkOffline
+	allowOffline(
+		RootCmd,
+		noteCmd, viewNoteCmd, noteTagCmd,
+		notebookCmd, listNotebooksCmd,
+		tagCmd, listTagsCmd,
+		userCmd, userListCmd, userAddCmd, userRmCmd, userSetCmd, logoutCmd,
+		cacheCmd, cacheCleanCmd,
+	)
+}
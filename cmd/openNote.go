@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var openNoteCmd = &cobra.Command{
+	Use:   "open",
+	Short: "Open the note in the Evernote app.",
+	Long: `
+Open opens the note in the Evernote app with the system's default
+opener, open on macOS and xdg-open on Linux. If no app is registered
+for Evernote links, the note is opened in the web client instead.
+
+The opener can be changed with "user set opener", for example:
+
+    clinote user set opener "firefox --new-tab"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		shardID, userID, err := client.UserInfo()
+		if err != nil {
+			fmt.Println("Error when getting the user information:", err)
+			os.Exit(1)
+		}
+		c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
+		c.Opener = opener(client.Config.Store())
+		link, err := clinote.OpenNote(c, title, shardID, userID)
+		if err != nil {
+			fmt.Println("Error when opening the note:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Opened", link)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(openNoteCmd)
+	openNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+}
@@ -0,0 +1,65 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+)
+
+// outputFormats are the valid values of the output flag of the listing
+// commands. The first one is the default.
+var outputFormats = []string{"table", "json", "yaml"}
+
+// validOutputFormat returns true if the format is one of the output formats.
+func validOutputFormat(format string) bool {
+	for _, f := range outputFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// outputFormatUsage is the usage text of the output flag.
+func outputFormatUsage() string {
+	return "Output format, one of: " + strings.Join(outputFormats, ", ") + "."
+}
+
+// invalidOutputFormat returns the message printed for an unknown output
+// format, including the valid choices.
+func invalidOutputFormat(format string) string {
+	return fmt.Sprintf("Invalid output format %q, valid values are: %s", format, strings.Join(outputFormats, ", "))
+}
+
+// writeNotes writes the note listing in the output format. The listing
+// options are only used by the table format.
+func writeNotes(w io.Writer, format string, ns []*clinote.Note, nbs map[string]*clinote.Notebook, opts clinote.ListingOption) error {
+	switch format {
+	case "json":
+		return clinote.WriteNoteListingJSON(w, ns, nbs)
+	case "yaml":
+		return clinote.WriteNoteListingYAML(w, ns, nbs)
+	default:
+		clinote.WriteNoteListingWithOptions(w, ns, nbs, opts)
+		return nil
+	}
+}
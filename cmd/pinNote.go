@@ -0,0 +1,73 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var pinNoteCmd = &cobra.Command{
+	Use:   "pin \"note title\"",
+	Short: "Pin a note.",
+	Long: `
+Pin marks the note as a favorite by tagging it with the pin tag. The
+tag is "favorite" unless another tag is set with "user set pintag".
+The tag is created if you don't have it.
+
+Use "note list --pinned" to list the pinned notes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, a note title has to be given")
+			return
+		}
+		c := newClient(clinote.DefaultClientOptions)
+		defer c.Store.Close()
+		if err := clinote.PinNote(c, args[0]); err != nil {
+			fmt.Println("Error when pinning the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var unpinNoteCmd = &cobra.Command{
+	Use:   "unpin \"note title\"",
+	Short: "Unpin a note.",
+	Long: `
+Unpin removes the pin tag from the note. The tag itself is not deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, a note title has to be given")
+			return
+		}
+		c := newClient(clinote.DefaultClientOptions)
+		defer c.Store.Close()
+		if err := clinote.UnpinNote(c, args[0]); err != nil {
+			fmt.Println("Error when unpinning the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(pinNoteCmd)
+	noteCmd.AddCommand(unpinNoteCmd)
+}
@@ -0,0 +1,109 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var recoverNoteCmd = &cobra.Command{
+	Use:   "recover [index]",
+	Short: "Recover notes that failed to save.",
+	Long: `
+Recover lists the notes that failed to be saved. Pick a note
+by its index to open it in the editor and try to save it again.
+If no index is given, you are asked which note to recover.
+
+Once the note has been saved, its recovery point is removed.
+Use the force flag to save the note even if it has been changed
+on the server.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := cmd.Flags().GetBool("list")
+		if err != nil {
+			fmt.Println("Error when parsing the list flag:", err)
+			return
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Println("Error when parsing force flag:", err)
+			return
+		}
+		c := newClient(clinote.DefaultClientOptions)
+		defer c.Store.Close()
+		notes, err := clinote.ListRecoveryPoints(c.Store)
+		if err != nil {
+			fmt.Println("Error when listing the recovery points:", err)
+			os.Exit(1)
+		}
+		if len(notes) == 0 {
+			fmt.Println("No notes to recover.")
+			return
+		}
+		var input string
+		if len(args) == 1 {
+			input = args[0]
+		} else {
+			nbs, err := clinote.NotebooksForNotes(c.Store, c.NoteStore, notes)
+			if err != nil {
+				fmt.Println("Failed to get the notebooks:", err)
+			}
+			clinote.WriteNoteListing(os.Stdout, notes, nbs)
+			if list {
+				return
+			}
+			fmt.Printf("Select a note to recover [1-%d]: ", len(notes))
+			scanner := bufio.NewScanner(os.Stdin)
+			if !scanner.Scan() {
+				return
+			}
+			input = strings.TrimSpace(scanner.Text())
+		}
+		index, err := strconv.Atoi(input)
+		if err != nil || index < 1 || index > len(notes) {
+			fmt.Printf("Error, %s is not a valid index\n", input)
+			os.Exit(1)
+		}
+		opts := clinote.DefaultNoteOption
+		if force {
+			opts |= clinote.ForceNote
+		}
+		err = clinote.EditRecoveryPoint(c, notes[index-1].GUID, opts)
+		if err == clinote.ErrNoteConflict {
+			fmt.Println("Error when saving the note:", err)
+			fmt.Println("Use --force to overwrite the server version.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when saving the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(recoverNoteCmd)
+	recoverNoteCmd.Flags().BoolP("list", "l", false, "Only list the notes that can be recovered.")
+	recoverNoteCmd.Flags().Bool("force", false, "Save the note even if it has been changed on the server.")
+}
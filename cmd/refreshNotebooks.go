@@ -0,0 +1,58 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var refreshNotebooksCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the notebook cache.",
+	Long: `
+Refresh fetches the notebooks from the server and replaces the
+cached notebooks.
+
+The notebooks are cached for 24 hours. Notebooks renamed, updated
+or deleted with clinote are changed in the cache right away, but
+notebooks created with clinote or changed in other clients aren't
+seen until the cache has expired. Use refresh to see them without
+waiting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		bs, err := clinote.GetNotebooks(client.Config.Store(), ns, true)
+		if err != nil {
+			fmt.Println("Error when refreshing the notebooks:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Refreshed %d notebooks.\n", len(bs))
+	},
+}
+
+func init() {
+	notebookCmd.AddCommand(refreshNotebooksCmd)
+}
@@ -0,0 +1,90 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var remindNoteCmd = &cobra.Command{
+	Use:   "remind",
+	Short: "Set a reminder on a note.",
+	Long: `
+Remind sets a reminder on the note. The time is given with the
+at flag, like 2024-06-01T09:00. A time without a timezone is in
+the local time, a full RFC 3339 time can also be given.
+
+Use the clear flag to remove the note's reminder.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		at, err := cmd.Flags().GetString("at")
+		if err != nil {
+			fmt.Println("Error when parsing the reminder time:", err)
+			return
+		}
+		clearReminder, err := cmd.Flags().GetBool("clear")
+		if err != nil {
+			fmt.Println("Error when parsing the clear flag:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		if (at == "") == !clearReminder {
+			fmt.Println("Error, either the at flag or the clear flag has to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		if clearReminder {
+			err = clinote.ClearNoteReminder(db, ns, title)
+		} else {
+			t, parseErr := clinote.ParseReminderTime(at)
+			if parseErr != nil {
+				fmt.Println("Error when parsing the reminder time:", parseErr)
+				os.Exit(1)
+			}
+			err = clinote.SetNoteReminder(db, ns, title, t)
+		}
+		if err != nil {
+			fmt.Println("Error when setting the reminder:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(remindNoteCmd)
+	remindNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+	remindNoteCmd.Flags().String("at", "", "When the reminder is due, like 2024-06-01T09:00.")
+	remindNoteCmd.Flags().Bool("clear", false, "Remove the reminder.")
+}
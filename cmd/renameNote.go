@@ -0,0 +1,77 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var renameNoteCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename a note.",
+	Long: `
+Rename changes the title of the note given by the title flag to the
+title given by the new-title flag.
+
+The note can be given by its title, its index in the last listing
+or its GUID. Use the GUID to rename a note that shares its title
+with other notes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		newTitle, err := cmd.Flags().GetString("new-title")
+		if err != nil {
+			fmt.Println("Error when parsing the new title:", err)
+			return
+		}
+		if title == "" || strings.TrimSpace(newTitle) == "" {
+			fmt.Println("Error, the note and the new title have to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.ChangeTitle(client.Config.Store(), ns, title, newTitle)
+		if errors.Is(err, clinote.ErrNoNoteFound) {
+			fmt.Printf("No note found for %q.\n", title)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when renaming the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(renameNoteCmd)
+	renameNoteCmd.Flags().StringP("title", "t", "", "Title, index or GUID of the note to rename.")
+	renameNoteCmd.Flags().StringP("new-title", "n", "", "The new title of the note.")
+}
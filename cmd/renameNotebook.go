@@ -0,0 +1,55 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var renameNotebookCmd = &cobra.Command{
+	Use:   "rename \"notebook name\" \"new name\"",
+	Short: "Rename a notebook.",
+	Long: `
+Rename changes the name of the notebook. The new name can't
+be the name of another notebook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("Error, the notebook and the new name have to be given.")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.RenameNotebook(client.Config.Store(), ns, args[0], args[1])
+		if err != nil {
+			fmt.Println("Error when renaming the notebook:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	notebookCmd.AddCommand(renameNotebookCmd)
+}
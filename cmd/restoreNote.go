@@ -0,0 +1,83 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var restoreNoteCmd = &cobra.Command{
+	Use:   "restore \"note title\"",
+	Short: "Restore a note from the trash.",
+	Long: `
+Restore moves a note out of the trash. If the notebook the note
+belonged to has been deleted, the note is restored to the default
+notebook.
+
+Use the list flag to list the notes in the trash.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := cmd.Flags().GetBool("list")
+		if err != nil {
+			fmt.Println("Error when parsing the list flag:", err)
+			return
+		}
+		if !list && len(args) != 1 {
+			fmt.Println("Error, a note title has to be given")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if list {
+			listTrash(client.Config.Store(), ns)
+			return
+		}
+		err = clinote.RestoreNote(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Println("Error when restoring the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(restoreNoteCmd)
+	restoreNoteCmd.Flags().BoolP("list", "l", false, "List the notes in the trash.")
+}
+
+func listTrash(db clinote.Storager, ns clinote.NotestoreClient) {
+	notes, err := clinote.ListTrash(ns)
+	if err != nil {
+		fmt.Println("Error when listing the trash:", err)
+		os.Exit(1)
+	}
+	// The notebook of a deleted note may have been removed too, so the notes
+	// are listed without the notebook names if they can't be resolved.
+	nbs, err := clinote.NotebooksForNotes(db, ns, notes)
+	if err != nil {
+		fmt.Println("Failed to get the notebooks:", err)
+	}
+	clinote.WriteNoteListing(os.Stdout, notes, nbs)
+}
@@ -20,7 +20,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +30,14 @@ const version string = "0.7.0-SNAPSHOT"
 
 var cfgFile string
 
+// notestoreTimeout is set by the timeout flag. It's the longest time to
+// wait for each notestore call.
+var notestoreTimeout time.Duration
+
+// maxNoteSize is set by the max-note-size flag. It's the largest note
+// content that is uploaded.
+var maxNoteSize int
+
 var RootCmd = &cobra.Command{
 	Use:   "clinote",
 	Short: "CLInote is a cli client for Evernote.",
@@ -54,4 +64,6 @@ func Execute() {
 
 func init() {
 	RootCmd.Flags().Bool("version", false, "Show the version")
+	RootCmd.PersistentFlags().DurationVar(&notestoreTimeout, "timeout", 0, "Longest time to wait for each call to Evernote, for example 30s. No timeout is used if it isn't set.")
+	RootCmd.PersistentFlags().IntVar(&maxNoteSize, "max-note-size", clinote.DefaultMaxNoteSize, "Largest note content in bytes that is uploaded. Use 0 for no limit.")
 }
@@ -0,0 +1,187 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+// snippetRadius is the number of characters shown on each side of the match.
+const snippetRadius = 40
+
+var searchNoteCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search the content of notes.",
+	Long: `
+Search finds the notes matching the search term. By default, the
+matching notes are listed like the list command does.
+
+Use the content flag to print a snippet of each note's content
+around the matched term instead. The content of each note has to
+be fetched, so this is slower than a regular listing.
+
+The notes can be referred to by their index in the result, like
+after a list.
+
+Use the save flag to save the search with a name, and the run flag
+to run a saved search again. A saved search is run against the
+notestore each time, so the result is always up to date.
+
+The result is printed as a table by default. Use "--output json" or
+"--output yaml" to print the notes as a JSON array or a YAML sequence
+instead. The output flag can't be used with the content flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		query, err := cmd.Flags().GetString("search")
+		if err != nil {
+			fmt.Println("Error when parsing search term:", err)
+			return
+		}
+		run, err := cmd.Flags().GetString("run")
+		if err != nil {
+			fmt.Println("Error when parsing run parameter:", err)
+			return
+		}
+		save, err := cmd.Flags().GetString("save")
+		if err != nil {
+			fmt.Println("Error when parsing save parameter:", err)
+			return
+		}
+		if query == "" && run == "" {
+			fmt.Println("Search term has to be given")
+			return
+		}
+		if query != "" && run != "" {
+			fmt.Println("Error, a search term can't be used when running a saved search")
+			return
+		}
+		count, err := cmd.Flags().GetInt("count")
+		if err != nil {
+			fmt.Println("Error when parsing count value:", err)
+			return
+		}
+		content, err := cmd.Flags().GetBool("content")
+		if err != nil {
+			fmt.Println("Error when parsing content flag:", err)
+			return
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			fmt.Println("Error when parsing output format:", err)
+			return
+		}
+		if !validOutputFormat(output) {
+			fmt.Println(invalidOutputFormat(output))
+			os.Exit(1)
+		}
+		if content && cmd.Flags().Changed("output") {
+			fmt.Println("Error, the output flag can't be used with the content flag")
+			os.Exit(1)
+		}
+		searchNotes(query, run, save, output, count, content)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(searchNoteCmd)
+	searchNoteCmd.Flags().StringP("search", "s", "", "Search term.")
+	searchNoteCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
+	searchNoteCmd.Flags().Bool("content", false, "Print a snippet of the content around the match.")
+	searchNoteCmd.Flags().String("save", "", "Save the search with the name.")
+	searchNoteCmd.Flags().String("run", "", "Run the search saved with the name.")
+	searchNoteCmd.Flags().StringP("output", "o", outputFormats[0], outputFormatUsage())
+}
+
+func searchNotes(query, run, save, output string, count int, content bool) {
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	db := client.Config.Store()
+	filter := &clinote.NoteFilter{Words: query}
+	if run != "" {
+		filter, err = db.GetNamedSearch(run)
+		if errors.Is(err, clinote.ErrNoSavedSearch) {
+			fmt.Printf("No search is saved with the name %q.\n", run)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when getting the saved search:", err)
+			os.Exit(1)
+		}
+	}
+	if save != "" {
+		if err = db.SaveNamedSearch(save, filter); err != nil {
+			fmt.Println("Error when saving the search:", err)
+			os.Exit(1)
+		}
+	}
+	if !content {
+		notes, err := clinote.FindNotes(ns, filter, 0, count)
+		if err != nil && !errors.Is(err, clinote.ErrNoNoteFound) {
+			fmt.Println("Error when searching for notes:", err)
+			os.Exit(1)
+		}
+		if err = db.SaveSearch(notes); err != nil {
+			fmt.Println("Error when saving the search:", err)
+			os.Exit(1)
+		}
+		if len(notes) == 0 && output == "table" {
+			fmt.Println("No notes matched your search.")
+			return
+		}
+		nbs, err := clinote.NotebooksForNotes(db, ns, notes)
+		if err != nil {
+			fmt.Println("Failed to get the notebooks:", err)
+			return
+		}
+		if err = writeNotes(os.Stdout, output, notes, nbs, clinote.DefaultListingOption); err != nil {
+			fmt.Println("Error when writing the note listing:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	results, err := clinote.SearchNotesWithFilter(db, ns, filter, count, snippetRadius)
+	if err != nil && !errors.Is(err, clinote.ErrNoNoteFound) {
+		fmt.Println("Error when searching for notes:", err)
+		os.Exit(1)
+	}
+	notes := make([]*clinote.Note, len(results))
+	for i, r := range results {
+		notes[i] = r.Note
+	}
+	if err = db.SaveSearch(notes); err != nil {
+		fmt.Println("Error when saving the search:", err)
+		os.Exit(1)
+	}
+	if len(results) == 0 {
+		fmt.Println("No notes matched your search.")
+		return
+	}
+	if err = clinote.WriteSearchResults(os.Stdout, results); err != nil {
+		fmt.Println("Error when writing the search result:", err)
+		os.Exit(1)
+	}
+}
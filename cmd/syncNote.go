@@ -0,0 +1,82 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var syncNoteCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Show the notes changed since the last sync.",
+	Long: `
+Sync fetches the notes that have been changed since the last
+sync and prints what changed. Only the changes are fetched,
+not the content of the notes. The first sync lists all notes.
+
+The since flag can be used to list the changes after an
+update sequence number instead of the last sync.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		since, err := cmd.Flags().GetInt32("since")
+		if err != nil {
+			fmt.Println("Error when parsing since parameter:", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		var notes []*clinote.Note
+		if cmd.Flags().Changed("since") {
+			notes, err = clinote.SyncChangesSince(db, ns, since)
+		} else {
+			notes, err = clinote.SyncChanges(db, ns)
+		}
+		if err != nil {
+			fmt.Println("Error when syncing the notes:", err)
+			os.Exit(1)
+		}
+		if len(notes) == 0 {
+			fmt.Println("No changes since the last sync.")
+			return
+		}
+		for _, n := range notes {
+			switch {
+			case n.Deleted && n.Title == "":
+				fmt.Println("Expunged:", n.GUID)
+			case n.Deleted:
+				fmt.Println("Trashed:", n.Title)
+			default:
+				fmt.Println("Changed:", n.Title)
+			}
+		}
+		fmt.Printf("Synced %d changes.\n", len(notes))
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(syncNoteCmd)
+	syncNoteCmd.Flags().Int32("since", 0, "List the changes after the update sequence number.")
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/storage"
@@ -107,6 +108,10 @@ var setConfigOpts = []struct {
 	desc string
 }{
 	{"credential", "An index value.", "Set the active credential for the user."},
+	{"notebook", "A notebook name.", "Set the notebook new notes are saved to. Use \"\" to unset."},
+	{"cachedir", "A folder path.", "Set the folder used for the files when editing notes. Use \"\" to unset."},
+	{"pintag", "A tag name.", "Set the tag used to pin notes. Use \"\" for the default."},
+	{"opener", "A command.", "Set the command used to open notes. Use \"\" for the platform's default."},
 }
 
 func setConfig(store clinote.UserCredentialStore, db clinote.Storager, args []string) {
@@ -117,6 +122,14 @@ func setConfig(store clinote.UserCredentialStore, db clinote.Storager, args []st
 	switch args[0] {
 	case "credential":
 		setCredential(store, db, args[1])
+	case "notebook":
+		setDefaultNotebook(db, args[1])
+	case "cachedir":
+		setCacheDir(db, args[1])
+	case "pintag":
+		setPinTag(db, args[1])
+	case "opener":
+		setOpener(db, args[1])
 	default:
 		printConfigOptions()
 	}
@@ -151,6 +164,58 @@ func setCredential(store clinote.UserCredentialStore, db clinote.Storager, strIn
 	}
 }
 
+func setDefaultNotebook(db clinote.Storager, name string) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		fmt.Println("Error when getting the settings:", err)
+		return
+	}
+	settings.DefaultNotebook = name
+	err = db.StoreSettings(settings)
+	if err != nil {
+		fmt.Println("Error when saving the settings:", err)
+	}
+}
+
+func setCacheDir(db clinote.Storager, dir string) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		fmt.Println("Error when getting the settings:", err)
+		return
+	}
+	settings.CacheDir = dir
+	err = db.StoreSettings(settings)
+	if err != nil {
+		fmt.Println("Error when saving the settings:", err)
+	}
+}
+
+func setPinTag(db clinote.Storager, tag string) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		fmt.Println("Error when getting the settings:", err)
+		return
+	}
+	settings.PinTag = strings.TrimSpace(tag)
+	err = db.StoreSettings(settings)
+	if err != nil {
+		fmt.Println("Error when saving the settings:", err)
+	}
+}
+
+func setOpener(db clinote.Storager, opener string) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		fmt.Println("Error when getting the settings:", err)
+		return
+	}
+	settings.Opener = strings.TrimSpace(opener)
+	err = db.StoreSettings(settings)
+	if err != nil {
+		fmt.Println("Error when saving the settings:", err)
+	}
+}
+
 func printConfigOptions() {
 	n := len(setConfigOpts)
 	vals, args, descs := make([]string, n, n), make([]string, n, n), make([]string, n, n)
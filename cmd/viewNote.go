@@ -0,0 +1,106 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var viewNoteCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print a note.",
+	Long: `
+View prints the note content to stdout without opening
+an editor. The content is printed as markdown unless the
+raw flag is given, in which case the ENML content is printed.
+
+Use the no-header flag to only print the content, for example
+when piping the note to another program. When both the raw and
+no-header flags are given, the ENML document is printed as it is
+returned by Evernote. The content isn't converted, which uses less
+memory for large notes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Println("Error when parsing note title:", err)
+			return
+		}
+		if title == "" {
+			fmt.Println("Note title has to be given")
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw parameter:", err)
+			return
+		}
+		noHeader, err := cmd.Flags().GetBool("no-header")
+		if err != nil {
+			fmt.Println("Error when parsing no-header parameter:", err)
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		if noHeader {
+			opts |= clinote.NoHeaderNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if raw && noHeader {
+			n, err := clinote.GetNote(client.Config.Store(), ns, title, "")
+			if err != nil {
+				fmt.Println("Error when getting the note:", err)
+				os.Exit(1)
+			}
+			if err = clinote.StreamNoteContent(ns, n.GUID, os.Stdout); err != nil {
+				fmt.Println("Error when writing the note:", err)
+				os.Exit(1)
+			}
+			return
+		}
+		n, err := clinote.GetNoteWithContent(client.Config.Store(), ns, title)
+		if err == nil {
+			err = clinote.LoadTagNames(ns, n)
+		}
+		if err != nil {
+			fmt.Println("Error when getting the note:", err)
+			os.Exit(1)
+		}
+		if err = clinote.WriteNote(os.Stdout, n, opts); err != nil {
+			fmt.Println("Error when writing the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(viewNoteCmd)
+	viewNoteCmd.Flags().StringP("title", "t", "", "Note title.")
+	viewNoteCmd.Flags().Bool("raw", false, "Print the raw content instead of markdown.")
+	viewNoteCmd.Flags().Bool("no-header", false, "Don't print the note header.")
+}
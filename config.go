@@ -23,6 +23,26 @@ import (
 	"os"
 )
 
+// DefaultNotebookEnv is the environment variable with the name of the
+// notebook new notes are saved to if no default notebook is set in the
+// user's settings.
+const DefaultNotebookEnv = "CLINOTE_DEFAULT_NOTEBOOK"
+
+// NotebookForNewNote returns the name of the notebook a new note is saved
+// to. The given name, for example from a flag, is used if it is set. Next
+// is the default notebook from the configuration and last the notebook in
+// the CLINOTE_DEFAULT_NOTEBOOK environment variable. An empty string means
+// the notestore's default notebook.
+func NotebookForNewNote(cfg Configuration, name string) string {
+	if name != "" {
+		return name
+	}
+	if name = cfg.DefaultNotebook(); name != "" {
+		return name
+	}
+	return os.Getenv(DefaultNotebookEnv)
+}
+
 // Configuration is the interface for a configuration struct.
 type Configuration interface {
 	io.Closer
@@ -34,6 +54,9 @@ type Configuration interface {
 	Store() Storager
 	// UserStore returns the user storage.
 	UserStore() UserCredentialStore
+	// DefaultNotebook returns the name of the notebook new notes are
+	// saved to. An empty string means the notestore's default notebook.
+	DefaultNotebook() string
 }
 
 // DefaultConfig uses shared config and cache folder with other
@@ -79,6 +102,18 @@ func (c *DefaultConfig) UserStore() UserCredentialStore {
 	return c.UDB
 }
 
+// DefaultNotebook returns the default notebook from the user's settings.
+func (c *DefaultConfig) DefaultNotebook() string {
+	if c.DB == nil {
+		return ""
+	}
+	settings, err := c.DB.GetSettings()
+	if err != nil || settings == nil {
+		return ""
+	}
+	return settings.DefaultNotebook
+}
+
 // Close closes the BoltDB handler.
 func (c *DefaultConfig) Close() error {
 	return c.DB.Close()
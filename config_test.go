@@ -0,0 +1,60 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotebookForNewNote(t *testing.T) {
+	assert := assert.New(t)
+	old, set := os.LookupEnv(DefaultNotebookEnv)
+	defer func() {
+		if set {
+			os.Setenv(DefaultNotebookEnv, old)
+		} else {
+			os.Unsetenv(DefaultNotebookEnv)
+		}
+	}()
+	settings := new(Settings)
+	cfg := &DefaultConfig{DB: &mockStore{getSettings: func() (*Settings, error) { return settings, nil }}}
+
+	tests := []struct {
+		name     string
+		flag     string
+		config   string
+		env      string
+		expected string
+	}{
+		{"flag over config and env", "Flag", "Config", "Env", "Flag"},
+		{"config over env", "", "Config", "Env", "Config"},
+		{"env", "", "", "Env", "Env"},
+		{"flag only", "Flag", "", "", "Flag"},
+		{"none", "", "", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings.DefaultNotebook = test.config
+			os.Setenv(DefaultNotebookEnv, test.env)
+			assert.Equal(test.expected, NotebookForNewNote(cfg, test.flag), "Wrong notebook")
+		})
+	}
+}
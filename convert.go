@@ -0,0 +1,113 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+var (
+	// ErrUnsupportedMarkdown is returned with the StrictMarkdownNote option
+	// if the markdown has constructs that can't be represented in ENML.
+	ErrUnsupportedMarkdown = markdown.ErrUnsupportedMarkdown
+	// ErrStrictNotSupported is returned with the StrictMarkdownNote option
+	// if the converter isn't a StrictConverter.
+	ErrStrictNotSupported = errors.New("the converter doesn't support strict markdown")
+)
+
+// Converter converts the content of notes between markdown and ENML. The
+// ENML is the body of the note, the content of the en-note element.
+type Converter interface {
+	// ToENML converts the markdown to the ENML body.
+	ToENML(md string) (string, error)
+	// FromENML converts the ENML body to markdown.
+	FromENML(enml string) (string, error)
+}
+
+// MediaConverter is a Converter that also converts the image references to
+// the note's attachments into en-media tags. Converters that don't
+// implement it get the attachments added to the end of the note instead.
+type MediaConverter interface {
+	Converter
+	// ToENMLWithMedia converts the markdown to the ENML body. The media
+	// map holds the MIME type of the attachments by their hex encoded hash.
+	ToENMLWithMedia(md string, media map[string]string) (string, error)
+}
+
+// StrictConverter is a MediaConverter that can refuse markdown it can't
+// faithfully represent in ENML. It's used with the StrictMarkdownNote
+// option.
+type StrictConverter interface {
+	MediaConverter
+	// ToENMLStrict converts the markdown like ToENMLWithMedia but returns
+	// an error wrapping ErrUnsupportedMarkdown instead of dropping the
+	// constructs that can't be represented.
+	ToENMLStrict(md string, media map[string]string) (string, error)
+}
+
+// MarkdownConverter is the default converter. It uses the markdown package.
+type MarkdownConverter struct{}
+
+// ToENML converts the markdown to the ENML body.
+func (MarkdownConverter) ToENML(md string) (string, error) {
+	return string(markdown.ToXML(md)), nil
+}
+
+// ToENMLWithMedia converts the markdown to the ENML body, including the
+// references to the attachments.
+func (MarkdownConverter) ToENMLWithMedia(md string, media map[string]string) (string, error) {
+	return string(markdown.ToXMLWithMedia(md, media)), nil
+}
+
+// ToENMLStrict converts the markdown to the ENML body. Raw HTML, header IDs,
+// table cells outside of the columns, references to missing attachments and
+// invalid encrypted sections are returned as an error.
+func (MarkdownConverter) ToENMLStrict(md string, media map[string]string) (string, error) {
+	body, err := markdown.ToXMLStrict(md, media)
+	return string(body), err
+}
+
+// FromENML converts the ENML body to markdown.
+func (MarkdownConverter) FromENML(enml string) (string, error) {
+	return markdown.FromHTML(enml)
+}
+
+// NoteToMarkdown converts the ENML content of a note to markdown. The
+// content is expected in the form Evernote returns it: the body wrapped in
+// an en-note element, optionally preceded by XMLHeader. Malformed content,
+// as found in some old notes, is repaired before it's converted. No store
+// or notestore is needed.
+func NoteToMarkdown(enml string) (string, error) {
+	content, _ := sanitizeXML(enml)
+	n := new(Note)
+	if err := decodeXML(content, n); err != nil {
+		return "", err
+	}
+	return MarkdownConverter{}.FromENML(n.Body)
+}
+
+// MarkdownToENML converts markdown to the ENML content of a note. The
+// converted body is wrapped in an en-note element and prefixed with
+// XMLHeader, so the result can be used as the content of a note as is.
+// Images referencing attachments are dropped, since there are no
+// attachments to match them with. No store or notestore is needed.
+func MarkdownToENML(md string) (string, error) {
+	return toXML(MarkdownConverter{}, md, nil, DefaultNoteOption)
+}
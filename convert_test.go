@@ -0,0 +1,180 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteToMarkdown(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("convert content", func(t *testing.T) {
+		md, err := NoteToMarkdown(XMLHeader + "<en-note><div><b>Bold</b> text</div><div>Second line</div></en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("**Bold** text\nSecond line", md)
+	})
+
+	t.Run("without xml header", func(t *testing.T) {
+		md, err := NoteToMarkdown("<en-note><h1>Title</h1></en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("# Title", md)
+	})
+
+	t.Run("repair malformed content", func(t *testing.T) {
+		md, err := NoteToMarkdown("<en-note><div>R&D < 5<br></div><hr><div>After</div></en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("R&D < 5\n\n---\n\nAfter", md)
+	})
+
+	t.Run("error on empty content", func(t *testing.T) {
+		_, err := NoteToMarkdown("")
+		assert.Error(err, "Should return an error")
+	})
+}
+
+func TestMarkdownToENML(t *testing.T) {
+	assert := assert.New(t)
+	md := "# Title\n\nSome **bold** text"
+	enml, err := MarkdownToENML(md)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(XMLHeader+"<en-note><h1>Title</h1>\n\n<p>Some <strong>bold</strong> text</p>\n</en-note>", enml)
+
+	back, err := NoteToMarkdown(enml)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(md, back, "Markdown not preserved")
+}
+
+type testConverter struct {
+	err error
+}
+
+func (c testConverter) ToENML(md string) (string, error) {
+	return "<div>" + strings.ToUpper(md) + "</div>", c.err
+}
+
+func (c testConverter) FromENML(enml string) (string, error) {
+	return "converted: " + enml, c.err
+}
+
+func TestClientConverter(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("convert content with the converter", func(t *testing.T) {
+		ns := nsWithNote(&Note{Title: "Note", GUID: "GUID"})
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><p>Text</p></en-note>", nil }
+		client := &Client{Store: new(mockStore), NoteStore: ns, Converter: testConverter{}}
+		n, err := client.GetNoteWithContent("Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("converted: <p>Text</p>", n.MD, "Converter should be used for the markdown")
+
+		var saved *Note
+		ns.createNote = func(n *Note) error { saved = n; return nil }
+		a := &Attachment{MIMEType: "image/png", Data: []byte("image")}
+		err = client.SaveNewNote(&Note{Title: "New", MD: "text", Resources: []*Attachment{a}}, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div>TEXT</div><div>"+a.mediaTag()+"</div></en-note>", saved.Body, "Converter should be used for the ENML")
+
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		err = client.SaveChanges(&Note{Title: "Note", MD: "changed"}, DefaultNoteOption|ForceNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div>CHANGED</div></en-note>", saved.Body, "Converter should be used for the changes")
+	})
+
+	t.Run("return error from the converter", func(t *testing.T) {
+		expectedError := errors.New("expected error")
+		client := &Client{NoteStore: new(mockNS), Converter: testConverter{err: expectedError}}
+		err := client.SaveNewNote(&Note{Title: "New", MD: "text"}, DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+
+	t.Run("default converter", func(t *testing.T) {
+		assert.Equal(MarkdownConverter{}, new(Client).converter(), "Default converter should be used")
+		ns := nsWithNote(&Note{Title: "Note", GUID: "GUID"})
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><p>Text</p></en-note>", nil }
+		client := &Client{Store: new(mockStore), NoteStore: ns, Converter: testConverter{}}
+		n, err := GetNoteWithContent(client.Store, ns, "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Text", n.MD, "The client's converter should only be used through the client")
+	})
+}
+
+func TestStrictMarkdown(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("refuse unsupported markdown", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.createNote = func(n *Note) error {
+			assert.Fail("Should not save the note")
+			return nil
+		}
+		err := SaveNewNote(ns, &Note{Title: "New", MD: "Text <span>html</span>"}, StrictMarkdownNote)
+		assert.True(errors.Is(err, ErrUnsupportedMarkdown), "Should return ErrUnsupportedMarkdown")
+	})
+
+	t.Run("save supported markdown", func(t *testing.T) {
+		var saved *Note
+		ns := new(mockNS)
+		ns.createNote = func(n *Note) error { saved = n; return nil }
+		err := SaveNewNote(ns, &Note{Title: "New", MD: "**Text**"}, StrictMarkdownNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><p><strong>Text</strong></p>\n</en-note>", saved.Body)
+	})
+
+	t.Run("refuse changes with unsupported markdown", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.updateNote = func(n *Note) error {
+			assert.Fail("Should not save the note")
+			return nil
+		}
+		err := SaveChanges(ns, &Note{Title: "Note", MD: "# Title {#id}"}, StrictMarkdownNote|ForceNote)
+		assert.True(errors.Is(err, ErrUnsupportedMarkdown), "Should return ErrUnsupportedMarkdown")
+	})
+
+	t.Run("converter without strict support", func(t *testing.T) {
+		client := &Client{NoteStore: new(mockNS), Converter: testConverter{}}
+		err := client.SaveNewNote(&Note{Title: "New", MD: "text"}, StrictMarkdownNote)
+		assert.Equal(ErrStrictNotSupported, err, "Wrong error returned")
+	})
+}
+
+func TestEmojiNote(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("convert shortcodes", func(t *testing.T) {
+		var saved *Note
+		ns := new(mockNS)
+		ns.createNote = func(n *Note) error { saved = n; return nil }
+		err := SaveNewNote(ns, &Note{Title: "New", MD: "Done :tada: `:tada:`"}, EmojiNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><p>Done \U0001F389 <code>:tada:</code></p>\n</en-note>", saved.Body)
+	})
+
+	t.Run("keep shortcodes without the option", func(t *testing.T) {
+		var saved *Note
+		ns := new(mockNS)
+		ns.createNote = func(n *Note) error { saved = n; return nil }
+		err := SaveNewNote(ns, &Note{Title: "New", MD: "Done :tada:"}, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><p>Done :tada:</p>\n</en-note>", saved.Body)
+	})
+}
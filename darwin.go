@@ -20,10 +20,17 @@
 package clinote
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 )
 
+// defaultOpener is the command used to open links.
+const defaultOpener = "open"
+
 var (
 	// configDir is the folder used to store the configuration files.
 	configDir string
@@ -39,3 +46,24 @@ func init() {
 	configDir = filepath.Join(home, "Library", "Application Support", "clinote")
 	cacheDir = filepath.Join(home, "Library", "Caches", "clinote")
 }
+
+// hasLinkHandler returns true since the opener fails by itself if no
+// application handles links with the scheme.
+func hasLinkHandler(scheme string) bool {
+	return true
+}
+
+// ReadImage reads the PNG image on the clipboard with osascript. The image
+// is returned by osascript as hex encoded data, «data PNGf...».
+func (SystemClipboard) ReadImage() ([]byte, error) {
+	out, err := exec.Command("osascript", "-e", "the clipboard as «class PNGf»").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoClipboardImage, err)
+	}
+	out = bytes.TrimSpace(out)
+	prefix, suffix := []byte("«data PNGf"), []byte("»")
+	if !bytes.HasPrefix(out, prefix) || !bytes.HasSuffix(out, suffix) {
+		return nil, ErrNoClipboardImage
+	}
+	return hex.DecodeString(string(out[len(prefix) : len(out)-len(suffix)]))
+}
@@ -0,0 +1,191 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// DiffLine is a line in a diff. Kind is ' ' for an unchanged line, '-'
+// for a removed line and '+' for an added line.
+type DiffLine struct {
+	// Kind is the kind of change.
+	Kind byte
+	// Line is the text of the line, without the newline.
+	Line string
+}
+
+// Diff returns the lines of the diff turning the old text into the new
+// text.
+func Diff(oldText, newText string) []DiffLine {
+	return diffLines(splitLines(oldText), splitLines(newText))
+}
+
+// DiffNotes returns the diff of the markdown content of the note matching
+// title against the note matching against. The note matching against is
+// the old side of the diff. If one of the titles doesn't match a note, the
+// error includes the title.
+func DiffNotes(db Storager, ns NotestoreClient, title, against string) ([]DiffLine, error) {
+	n, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", err, title)
+	}
+	base, err := GetNoteWithContent(db, ns, against)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", err, against)
+	}
+	return Diff(base.MD, n.MD), nil
+}
+
+// diffLines returns the operations turning the lines a into the lines b,
+// based on the longest common subsequence of the lines.
+func diffLines(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	ops := make([]DiffLine, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, DiffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, DiffLine{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, DiffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, DiffLine{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, DiffLine{'+', b[j]})
+	}
+	return ops
+}
+
+// splitLines splits the text into lines. A trailing newline doesn't give
+// an extra empty line.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// writeDiff writes a unified diff between the old and new text to w. The
+// names are used in the diff header. Nothing is written if the texts have
+// the same lines.
+func writeDiff(w io.Writer, oldName, newName, oldText, newText string) error {
+	return WriteDiff(w, oldName, newName, Diff(oldText, newText))
+}
+
+// WriteDiff writes the diff as a unified diff to w. The names are used in
+// the diff header. Nothing is written if the diff has no changes.
+func WriteDiff(w io.Writer, oldName, newName string, ops []DiffLine) error {
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].Kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		if buf.Len() == 0 {
+			fmt.Fprintf(buf, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		// A hunk continues until the unchanged lines between two changes
+		// are more than the context on both sides of them.
+		last := i
+		for end := i; end < len(ops) && end-last <= 2*diffContext+1; end++ {
+			if ops[end].Kind != ' ' {
+				last = end
+			}
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := last + 1 + diffContext
+		if end > len(ops) {
+			end = len(ops)
+		}
+		writeHunk(buf, ops, start, end)
+		i = end
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeHunk writes the operations from start to end as a hunk.
+func writeHunk(buf *bytes.Buffer, ops []DiffLine, start, end int) {
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.Kind != '+' {
+			oldLine++
+		}
+		if op.Kind != '-' {
+			newLine++
+		}
+	}
+	var oldCount, newCount int
+	for _, op := range ops[start:end] {
+		if op.Kind != '+' {
+			oldCount++
+		}
+		if op.Kind != '-' {
+			newCount++
+		}
+	}
+	// An empty range starts at the line before it.
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, op := range ops[start:end] {
+		buf.WriteByte(op.Kind)
+		buf.WriteString(op.Line)
+		buf.WriteByte('\n')
+	}
+}
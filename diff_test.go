@@ -0,0 +1,97 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{"no change", "a\nb\n", "a\nb", ""},
+		{"added line", "a\nb\n", "a\nb\nc\n", "--- old\n+++ new\n@@ -1,2 +1,3 @@\n a\n b\n+c\n"},
+		{"removed line", "a\nb\nc", "a\nc", "--- old\n+++ new\n@@ -1,3 +1,2 @@\n a\n-b\n c\n"},
+		{"changed line", "a\nb\nc", "a\nx\nc", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"from empty", "", "a\n", "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n"},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			"x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			"merged hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8",
+			"x\n2\n3\n4\n5\n6\n7\ny",
+			"--- old\n+++ new\n@@ -1,8 +1,8 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := writeDiff(buf, "old", "new", test.old, test.new)
+			assert.NoError(t, err, "Should not return an error")
+			assert.Equal(t, test.expected, buf.String(), "Wrong diff")
+		})
+	}
+}
+
+func TestDiffNotes(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	notes := []*Note{{Title: "Draft", GUID: "GUID1"}, {Title: "Copy", GUID: "GUID2"}}
+	content := map[string]string{
+		"GUID1": "<en-note><p>Same</p><p>Draft</p></en-note>",
+		"GUID2": "<en-note><p>Same</p><p>Copy</p></en-note>",
+	}
+	ns := new(mockNS)
+	ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+	ns.getNoteContent = func(guid string) (string, error) { return content[guid], nil }
+
+	t.Run("diff the notes", func(t *testing.T) {
+		lines, err := DiffNotes(store, ns, "Draft", "Copy")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]DiffLine{{' ', "Same"}, {' ', ""}, {'-', "Copy"}, {'+', "Draft"}}, lines, "Wrong diff")
+	})
+
+	t.Run("same note", func(t *testing.T) {
+		lines, err := DiffNotes(store, ns, "Draft", "Draft")
+		assert.NoError(err, "Should not return an error")
+		for _, l := range lines {
+			assert.Equal(byte(' '), l.Kind, "Should not have changes")
+		}
+	})
+
+	t.Run("title not found", func(t *testing.T) {
+		_, err := DiffNotes(store, ns, "Draft", "Missing")
+		assert.True(errors.Is(err, ErrNoNoteFound), "Should return ErrNoNoteFound")
+		assert.Contains(err.Error(), `"Missing"`, "Should name the missing note")
+	})
+}
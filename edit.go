@@ -18,14 +18,29 @@
 package clinote
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"syscall"
+	"time"
 )
 
 var (
 	// ErrNoEditorFound is returned if no editor was found.
 	ErrNoEditorFound = errors.New("no editor found")
+	// ErrEditorAborted is returned if the editor exited with an error, for
+	// example if the user aborted the edit. The changes are not saved.
+	ErrEditorAborted = errors.New("editor aborted")
+	// ErrSaveCancelled is returned if the user didn't confirm that the
+	// changes should be saved. The changes are not saved.
+	ErrSaveCancelled = errors.New("save cancelled")
+	// ErrNoEditedContent is returned if nothing was written to the content
+	// pipe before the editor exited.
+	ErrNoEditedContent = errors.New("no edited content was written to the pipe")
 )
 
 // Editer is an object that can edit notes.
@@ -34,6 +49,114 @@ type Editer interface {
 	Edit(CacheFile) error
 }
 
+// ContentSource returns the edited content once the editor has exited.
+type ContentSource interface {
+	// EditedContent returns a reader with the edited content. The cache
+	// file has been reopened when it's called.
+	EditedContent(file CacheFile) (io.Reader, error)
+}
+
+// PreparedContentSource is a ContentSource that has to be prepared before
+// the editor is started.
+type PreparedContentSource interface {
+	ContentSource
+	// Prepare is called before the editor is started. EditedContent is
+	// called once the editor has exited, even if the edit failed.
+	Prepare() error
+}
+
+// FileContentSource reads the edited content from the cache file. It's
+// used if the client doesn't have a ContentSource set.
+type FileContentSource struct{}
+
+// EditedContent returns the cache file.
+func (FileContentSource) EditedContent(file CacheFile) (io.Reader, error) {
+	return file, nil
+}
+
+// ReaderContentSource reads the edited content from the reader instead of
+// the cache file, for editors that send the content back another way.
+type ReaderContentSource struct {
+	// Reader is where the edited content is read from.
+	Reader io.Reader
+}
+
+// EditedContent returns the reader.
+func (s *ReaderContentSource) EditedContent(CacheFile) (io.Reader, error) {
+	return s.Reader, nil
+}
+
+// PipeContentSource reads the edited content from the named pipe at Path.
+// The pipe is opened for reading when it's prepared, before the editor is
+// started, so the editor doesn't block when it writes the content.
+type PipeContentSource struct {
+	// Path is the path to the named pipe.
+	Path   string
+	result chan pipeContent
+}
+
+type pipeContent struct {
+	data []byte
+	err  error
+}
+
+// Prepare starts reading the pipe in the background.
+func (s *PipeContentSource) Prepare() error {
+	s.result = make(chan pipeContent, 1)
+	go func(result chan<- pipeContent) {
+		var content pipeContent
+		content.data, content.err = readPipe(s.Path)
+		result <- content
+	}(s.result)
+	return nil
+}
+
+// EditedContent returns all the content written to the pipe. If the editor
+// didn't open the pipe, ErrNoEditedContent is returned.
+func (s *PipeContentSource) EditedContent(CacheFile) (io.Reader, error) {
+	if s.result == nil {
+		// Without Prepare, the call blocks until the pipe is opened
+		// for writing.
+		data, err := readPipe(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(data), nil
+	}
+	result := s.result
+	s.result = nil
+	var content pipeContent
+	for received := false; !received; {
+		select {
+		case content = <-result:
+			received = true
+		case <-time.After(10 * time.Millisecond):
+			// The reader is still waiting for a writer. Opening the
+			// pipe for writing releases it, and if no content was
+			// written, it's read as empty.
+			if f, err := os.OpenFile(s.Path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+				f.Close()
+			}
+		}
+	}
+	if content.err != nil {
+		return nil, content.err
+	}
+	if len(content.data) == 0 {
+		return nil, ErrNoEditedContent
+	}
+	return bytes.NewReader(content.data), nil
+}
+
+func readPipe(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
 // VimEditor opens the note in VIM and lets the user edit
 // the note.
 type VimEditor struct{}
@@ -69,10 +192,33 @@ func (e *EnvEditor) Edit(file CacheFile) error {
 	return executeEditorViaCommand(editor, file.FilePath())
 }
 
+// CommandEditor opens the note with the given command.
+type CommandEditor struct {
+	// Command is the name or path of the editor binary.
+	Command string
+}
+
+// Edit opens the CacheFile with the editor command.
+func (e *CommandEditor) Edit(file CacheFile) error {
+	if e.Command == "" {
+		return ErrNoEditorFound
+	}
+	return executeEditorViaCommand(e.Command, file.FilePath())
+}
+
 func executeEditorViaCommand(editor, filepath string) error {
-	cmd := exec.Command(editor, filepath)
+	path, err := exec.LookPath(editor)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNoEditorFound, editor)
+	}
+	cmd := exec.Command(path, filepath)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%w: %s", ErrEditorAborted, exitErr)
+	}
+	return err
 }
@@ -0,0 +1,104 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteEditor(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("editor exits with an error", func(t *testing.T) {
+		if _, err := exec.LookPath("false"); err != nil {
+			t.Skip("false is not available")
+		}
+		err := executeEditorViaCommand("false", "file")
+		assert.True(errors.Is(err, ErrEditorAborted), "Should return ErrEditorAborted")
+	})
+	t.Run("editor not found", func(t *testing.T) {
+		err := executeEditorViaCommand("no-such-editor-binary", "file")
+		assert.True(errors.Is(err, ErrNoEditorFound), "Should return ErrNoEditorFound")
+	})
+}
+
+func TestPipeContentSource(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pipe")
+	if err = syscall.Mkfifo(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	writePipe := func(content string) error {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write([]byte(content))
+		return err
+	}
+
+	t.Run("read without prepare", func(t *testing.T) {
+		go writePipe("edited content")
+		r, err := (&PipeContentSource{Path: path}).EditedContent(nil)
+		assert.NoError(err, "Should not return an error")
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("edited content", string(data), "Should read the content written to the pipe")
+	})
+
+	t.Run("editor writes to the pipe", func(t *testing.T) {
+		source := &PipeContentSource{Path: path}
+		client := &Client{ContentSource: source, Editor: &mockEditor{edit: func(CacheFile) error {
+			return writePipe("edited content")
+		}}}
+		assert.NoError(client.Edit(nil), "Should not return an error")
+		r, err := client.editedContent(nil)
+		assert.NoError(err, "Should not return an error")
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("edited content", string(data), "Should read the content written to the pipe")
+	})
+
+	t.Run("editor doesn't write to the pipe", func(t *testing.T) {
+		source := &PipeContentSource{Path: path}
+		client := &Client{ContentSource: source, Editor: &mockEditor{edit: func(CacheFile) error { return nil }}}
+		assert.NoError(client.Edit(nil), "Should not return an error")
+		_, err := client.editedContent(nil)
+		assert.Equal(ErrNoEditedContent, err, "Wrong error returned")
+	})
+
+	t.Run("editor aborted", func(t *testing.T) {
+		source := &PipeContentSource{Path: path}
+		client := &Client{ContentSource: source, Editor: &mockEditor{edit: func(CacheFile) error { return ErrEditorAborted }}}
+		assert.Equal(ErrEditorAborted, client.Edit(nil), "Wrong error returned")
+		assert.Nil(source.result, "The pipe should not be read anymore")
+	})
+}
@@ -0,0 +1,312 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// enexHeader is the XML header of an ENEX file.
+	enexHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">` + "\n"
+	// enexTimeFormat is the format of the times in an ENEX file.
+	enexTimeFormat = "20060102T150405Z"
+)
+
+// enexExport is the en-export envelope of an ENEX file.
+type enexExport struct {
+	XMLName     xml.Name    `xml:"en-export"`
+	ExportDate  string      `xml:"export-date,attr"`
+	Application string      `xml:"application,attr"`
+	Notes       []*enexNote `xml:"note"`
+}
+
+type enexNote struct {
+	Title      string              `xml:"title"`
+	Content    enexContent         `xml:"content"`
+	Created    string              `xml:"created,omitempty"`
+	Updated    string              `xml:"updated,omitempty"`
+	Tags       []string            `xml:"tag"`
+	Attributes *enexNoteAttributes `xml:"note-attributes,omitempty"`
+	Resources  []*enexResource     `xml:"resource"`
+}
+
+type enexContent struct {
+	ENML string `xml:",cdata"`
+}
+
+type enexNoteAttributes struct {
+	SourceURL         string `xml:"source-url,omitempty"`
+	SourceApplication string `xml:"source-application,omitempty"`
+	ReminderOrder     string `xml:"reminder-order,omitempty"`
+	ReminderTime      string `xml:"reminder-time,omitempty"`
+	ReminderDoneTime  string `xml:"reminder-done-time,omitempty"`
+}
+
+type enexResource struct {
+	Data       enexData                `xml:"data"`
+	MIMEType   string                  `xml:"mime"`
+	Attributes *enexResourceAttributes `xml:"resource-attributes,omitempty"`
+}
+
+type enexData struct {
+	Encoding string `xml:"encoding,attr"`
+	Data     string `xml:",chardata"`
+}
+
+type enexResourceAttributes struct {
+	Filename string `xml:"file-name,omitempty"`
+}
+
+// ExportENEX writes the notes to the writer in the Evernote export format
+// (ENEX). The notes need to have their content loaded. The tags are only
+// written if the note has the tag names set, and attachments are only
+// written if they have their data. The en-media tags of attachments without
+// data are removed from the content with a warning, so the imported note
+// doesn't reference missing attachments.
+func ExportENEX(w io.Writer, notes []*Note) error {
+	export := &enexExport{
+		ExportDate:  enexTime(toMillis(time.Now())),
+		Application: DefaultSourceApplication,
+		Notes:       make([]*enexNote, 0, len(notes)),
+	}
+	for _, n := range notes {
+		export.Notes = append(export.Notes, newENEXNote(n))
+	}
+	if _, err := io.WriteString(w, enexHeader); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(export); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newENEXNote(n *Note) *enexNote {
+	content := rawNoteXML(n)
+	en := &enexNote{
+		Title:   n.Title,
+		Created: enexTime(n.Created),
+		Updated: enexTime(n.Updated),
+		Tags:    n.Tags,
+	}
+	attrs := &enexNoteAttributes{
+		SourceURL:         n.SourceURL,
+		SourceApplication: n.SourceApplication,
+	}
+	if n.Reminder != nil {
+		if n.Reminder.Order != 0 {
+			attrs.ReminderOrder = strconv.FormatInt(n.Reminder.Order, 10)
+		}
+		attrs.ReminderTime = enexTime(n.Reminder.Time)
+		attrs.ReminderDoneTime = enexTime(n.Reminder.DoneTime)
+	}
+	if *attrs != (enexNoteAttributes{}) {
+		en.Attributes = attrs
+	}
+	for _, a := range n.Resources {
+		if len(a.Data) == 0 {
+			fmt.Fprintf(warningOutput, "Warning: the attachment %q of the note %q doesn't have its data and was left out.\n", a.Filename, n.Title)
+			content = removeMediaTag(content, a.Hash)
+			continue
+		}
+		r := &enexResource{
+			Data:     enexData{Encoding: "base64", Data: base64.StdEncoding.EncodeToString(a.Data)},
+			MIMEType: a.MIMEType,
+		}
+		if a.Filename != "" {
+			r.Attributes = &enexResourceAttributes{Filename: a.Filename}
+		}
+		en.Resources = append(en.Resources, r)
+	}
+	en.Content = enexContent{ENML: content}
+	return en
+}
+
+// enexTime formats the time in milliseconds for an ENEX file. An empty
+// string is returned for a zero time.
+func enexTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return noteTime(ms).UTC().Format(enexTimeFormat)
+}
+
+// parseENEXTime parses the time in an ENEX file and returns it in
+// milliseconds. Zero is returned for an empty string.
+func parseENEXTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(enexTimeFormat, strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return toMillis(t), nil
+}
+
+// ExportNoteENEX writes the note matching the title to the file at path in
+// the Evernote export format. If path is empty, the note is written to a
+// file in the current directory named after the note title. In this case,
+// an existing file is only overwritten if the ForceNote option is given.
+// The data of the attachments is fetched so they are included.
+func ExportNoteENEX(db Storager, ns NotestoreClient, title, path string, opts NoteOption) error {
+	n, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return err
+	}
+	if len(n.Resources) > 0 {
+		full, err := ns.GetNoteWithResources(n.GUID)
+		if err != nil {
+			return err
+		}
+		n.Resources = full.Resources
+	}
+	ts, err := ns.ListTags()
+	if err != nil {
+		return err
+	}
+	setTagNames(n, ts)
+	f, err := createExportFile(path, n, ".enex", opts)
+	if err != nil {
+		return err
+	}
+	if err = ExportENEX(f, []*Note{n}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ImportENEX creates a new note from each note in the ENEX file read from r.
+// The title, content, tags, created and updated time, source and reminder
+// are kept, and the resources are added as attachments. If a notebook is
+// given, all the notes are saved to it, otherwise to the default notebook.
+// The ENML content is saved as is, so the formatting that can't be written
+// in markdown, like tables and styles, isn't lost. A note that fails to be
+// imported doesn't stop the rest from being imported, the failures are
+// returned as one error together with the imported notes.
+func ImportENEX(ns NotestoreClient, r io.Reader, notebook string, opts NoteOption) ([]*Note, error) {
+	var nb *Notebook
+	if notebook != "" {
+		var err error
+		if nb, err = findNotebookByName(ns, notebook); err != nil {
+			return nil, err
+		}
+	}
+	var notes []*Note
+	var failed []string
+	count := 0
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return notes, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+		count++
+		en := new(enexNote)
+		if err = d.DecodeElement(en, &start); err != nil {
+			return notes, err
+		}
+		n, err := en.toNote(opts)
+		if err == nil {
+			n.Notebook = nb
+			err = checkNewNoteTitle(ns, n, opts)
+		}
+		if err == nil {
+			err = saveNewNoteBody(ns, n, rawNoteXML(n), opts)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %s", en.Title, err))
+			continue
+		}
+		notes = append(notes, n)
+	}
+	if len(failed) > 0 {
+		return notes, fmt.Errorf("failed to import %d of %d notes: %s", len(failed), count, strings.Join(failed, "; "))
+	}
+	return notes, nil
+}
+
+// toNote converts the ENEX note to a note. Resources without base64
+// encoded data are skipped with a warning.
+func (en *enexNote) toNote(opts NoteOption) (*Note, error) {
+	n := &Note{Title: strings.TrimSpace(en.Title), Tags: en.Tags}
+	if n.Title == "" {
+		n.Title = DefaultNoteTitle
+	}
+	var err error
+	if n.Created, err = parseENEXTime(en.Created); err != nil {
+		return nil, err
+	}
+	if n.Updated, err = parseENEXTime(en.Updated); err != nil {
+		return nil, err
+	}
+	if a := en.Attributes; a != nil {
+		n.SourceURL = a.SourceURL
+		n.SourceApplication = a.SourceApplication
+		rem := new(Reminder)
+		if a.ReminderOrder != "" {
+			if rem.Order, err = strconv.ParseInt(strings.TrimSpace(a.ReminderOrder), 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		if rem.Time, err = parseENEXTime(a.ReminderTime); err != nil {
+			return nil, err
+		}
+		if rem.DoneTime, err = parseENEXTime(a.ReminderDoneTime); err != nil {
+			return nil, err
+		}
+		if !rem.IsEmpty() {
+			n.Reminder = rem
+		}
+	}
+	for _, r := range en.Resources {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(r.Data.Data), ""))
+		if r.Data.Encoding != "base64" || err != nil || len(data) == 0 {
+			fmt.Fprintf(warningOutput, "Warning: an attachment of the note %q can't be decoded and was skipped.\n", n.Title)
+			continue
+		}
+		a := &Attachment{MIMEType: r.MIMEType, Data: data}
+		if r.Attributes != nil {
+			a.Filename = r.Attributes.Filename
+		}
+		n.Resources = append(n.Resources, a)
+	}
+	if err = setNoteContent(MarkdownConverter{}, n, en.Content.ENML, opts); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
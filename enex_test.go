@@ -0,0 +1,203 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportENEX(t *testing.T) {
+	assert := assert.New(t)
+	warnings := new(bytes.Buffer)
+	warningOutput = warnings
+	defer func() { warningOutput = os.Stderr }()
+	missing := &Attachment{Filename: "no data", MIMEType: "text/plain", Hash: []byte{0xab, 0xcd}}
+	n := &Note{
+		Title:             "Note <title>",
+		Body:              "<p>Content with ]]> in it</p>" + missing.mediaTag(),
+		Created:           1514808000000,
+		Updated:           1514894400000,
+		Tags:              []string{"tag1", "tag2"},
+		SourceURL:         "https://example.com",
+		SourceApplication: "clinote",
+		Reminder:          &Reminder{Order: 1514808000000, Time: 1514980800000},
+		Resources:         []*Attachment{&Attachment{Filename: "a.txt", MIMEType: "text/plain", Data: []byte("data")}, missing, &Attachment{Filename: "no hash"}},
+	}
+	buf := new(bytes.Buffer)
+	assert.NoError(ExportENEX(buf, []*Note{n, &Note{Title: "Empty"}}), "Should not return an error")
+	out := buf.String()
+	assert.True(strings.HasPrefix(out, enexHeader), "ENEX header should be written")
+	assert.Contains(out, `application="clinote"`)
+	assert.Contains(out, "<created>20180101T120000Z</created>")
+	assert.Contains(out, "<updated>20180102T120000Z</updated>")
+	assert.Contains(out, "<tag>tag1</tag>")
+	assert.Contains(out, "<source-url>https://example.com</source-url>")
+	assert.Contains(out, "<reminder-order>1514808000000</reminder-order>")
+	assert.Contains(out, "<reminder-time>20180103T120000Z</reminder-time>")
+	assert.NotContains(out, "reminder-done-time", "Zero times should be skipped")
+	assert.Contains(out, `<data encoding="base64">ZGF0YQ==</data>`)
+	assert.Contains(out, "<file-name>a.txt</file-name>")
+
+	var export enexExport
+	assert.NoError(xml.Unmarshal(buf.Bytes(), &export), "Output should be valid XML")
+	assert.Len(export.Notes, 2, "Wrong number of notes")
+	assert.Equal("Note <title>", export.Notes[0].Title, "Wrong title")
+	assert.Equal(XMLHeader+"<en-note><p>Content with ]]> in it</p></en-note>", export.Notes[0].Content.ENML, "Wrong content")
+	assert.Len(export.Notes[0].Resources, 1, "Attachments without data should be skipped")
+	assert.Contains(warnings.String(), `attachment "no data"`, "Should warn about the skipped attachment")
+	assert.Contains(warnings.String(), `attachment "no hash"`, "Should warn about the skipped attachment")
+	assert.Nil(export.Notes[1].Attributes, "Empty attributes should be skipped")
+}
+
+func TestExportNoteENEX(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-enex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ns := nsWithNote(&Note{Title: "Note", GUID: "GUID", TagGUIDs: []string{"T1"}})
+	ns.getNoteContent = func(string) (string, error) { return "<en-note><p>Content</p></en-note>", nil }
+	ns.listTags = func() ([]*Tag, error) { return []*Tag{&Tag{Name: "todo", GUID: "T1"}}, nil }
+	path := filepath.Join(dir, "note.enex")
+
+	err = ExportNoteENEX(new(mockStore), ns, "Note", path, DefaultNoteOption)
+	assert.NoError(err, "Should not return an error")
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(err, "Should be able to read the exported file")
+	assert.Contains(string(data), "<title>Note</title>")
+	assert.Contains(string(data), "<tag>todo</tag>")
+	assert.Contains(string(data), "<![CDATA["+XMLHeader+"<en-note><p>Content</p></en-note>]]>")
+
+	t.Run("include the attachment data", func(t *testing.T) {
+		ns.getNote = func(guid string) (*Note, error) {
+			return &Note{Title: "Note", GUID: guid, Resources: []*Attachment{&Attachment{Filename: "a.txt"}}}, nil
+		}
+		ns.getNoteWithResources = func(guid string) (*Note, error) {
+			assert.Equal("GUID", guid, "Wrong note fetched")
+			return &Note{GUID: guid, Resources: []*Attachment{&Attachment{Filename: "a.txt", Data: []byte("data")}}}, nil
+		}
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) {
+			return []*Note{&Note{Title: "Note", GUID: "GUID", Resources: []*Attachment{&Attachment{Filename: "a.txt"}}}}, nil
+		}
+		assert.NoError(ExportNoteENEX(new(mockStore), ns, "Note", path, DefaultNoteOption|ForceNote), "Should not return an error")
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(err, "Should be able to read the exported file")
+		assert.Contains(string(data), `<data encoding="base64">ZGF0YQ==</data>`)
+	})
+}
+
+func TestImportENEX(t *testing.T) {
+	assert := assert.New(t)
+	warnings := new(bytes.Buffer)
+	warningOutput = warnings
+	defer func() { warningOutput = os.Stderr }()
+	attachment := &Attachment{Filename: "a.txt", MIMEType: "text/plain", Data: []byte("data")}
+	attachment.computeHash()
+	src := &Note{
+		Title:             "Imported",
+		Body:              "<p>Some <b>bold</b> text</p><div>" + attachment.mediaTag() + "</div>",
+		Created:           1514808000000,
+		Updated:           1514894400000,
+		Tags:              []string{"tag1"},
+		SourceURL:         "https://example.com",
+		SourceApplication: "Evernote",
+		Reminder:          &Reminder{Order: 1514808000000, Time: 1514980800000},
+		Resources:         []*Attachment{attachment},
+	}
+	buf := new(bytes.Buffer)
+	if err := ExportENEX(buf, []*Note{src, &Note{Title: "Failing", Body: "<p>Fail</p>"}}); err != nil {
+		t.Fatal(err)
+	}
+	enex := buf.String()
+	book := &Notebook{Name: "Imported", GUID: "NB"}
+	var saved []*Note
+	ns := new(mockNS)
+	ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{book}, nil }
+	ns.createNote = func(n *Note) error {
+		if n.Title == "Failing" {
+			return errors.New("expected error")
+		}
+		saved = append(saved, n)
+		return nil
+	}
+
+	t.Run("import notes", func(t *testing.T) {
+		notes, err := ImportENEX(ns, strings.NewReader(enex), "Imported", DefaultNoteOption)
+		assert.Error(err, "Should return an error for the failing note")
+		assert.Contains(err.Error(), "failed to import 1 of 2 notes", "Wrong error returned")
+		assert.Len(notes, 1, "Wrong number of imported notes")
+		n := saved[0]
+		assert.Equal("Imported", n.Title, "Wrong title")
+		assert.Equal(book, n.Notebook, "Wrong notebook")
+		assert.Equal([]string{"tag1"}, n.Tags, "Wrong tags")
+		assert.Equal(src.Created, n.Created, "Wrong created time")
+		assert.Equal(src.Updated, n.Updated, "Wrong updated time")
+		assert.Equal("https://example.com", n.SourceURL, "Wrong source URL")
+		assert.Equal("Evernote", n.SourceApplication, "Wrong source application")
+		assert.Equal(src.Reminder, n.Reminder, "Wrong reminder")
+		assert.Len(n.Resources, 1, "Resource should be added as attachment")
+		assert.Equal([]byte("data"), n.Resources[0].Data, "Wrong attachment data")
+		assert.Equal("a.txt", n.Resources[0].Filename, "Wrong attachment filename")
+		assert.Contains(n.Body, "<p>Some <b>bold</b> text</p>", "Content should be kept")
+		assert.Equal(1, strings.Count(n.Body, attachment.mediaTag()), "Attachment should be referenced once")
+	})
+
+	t.Run("skip resource without data", func(t *testing.T) {
+		saved = nil
+		in := `<en-export><note><title>Note</title><content><![CDATA[<en-note><p>Text</p></en-note>]]></content>` +
+			`<resource><data encoding="base64"></data><mime>image/png</mime></resource></note></en-export>`
+		notes, err := ImportENEX(ns, strings.NewReader(in), "", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(notes, 1, "Note should be imported")
+		assert.Nil(saved[0].Notebook, "Default notebook should be used")
+		assert.Empty(saved[0].Resources, "Resource should be skipped")
+		assert.Contains(warnings.String(), "was skipped", "A warning should be printed")
+	})
+
+	t.Run("keep formatting that markdown can't represent", func(t *testing.T) {
+		saved = nil
+		body := `<table style="border: 1px solid"><tr><td><span style="color: red">Cell</span></td></tr></table>`
+		in := `<en-export><note><title>Table</title><content><![CDATA[` + XMLHeader + `<en-note style="font-size: 14px">` + body + `</en-note>]]></content></note></en-export>`
+		notes, err := ImportENEX(ns, strings.NewReader(in), "", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(notes, 1, "Note should be imported")
+		assert.Equal(XMLHeader+`<en-note style="font-size: 14px">`+body+"</en-note>", saved[0].Body, "Content should be saved as is")
+	})
+
+	t.Run("return error for unknown notebook", func(t *testing.T) {
+		_, err := ImportENEX(ns, strings.NewReader(enex), "Missing", DefaultNoteOption)
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+	})
+
+	t.Run("return error for invalid time", func(t *testing.T) {
+		in := `<en-export><note><title>Note</title><content></content><created>yesterday</created></note></en-export>`
+		notes, err := ImportENEX(ns, strings.NewReader(in), "", DefaultNoteOption)
+		assert.Error(err, "Should return an error")
+		assert.Empty(notes, "No notes should be imported")
+	})
+}
@@ -35,6 +35,8 @@ type Notestore interface {
 	CreateNote(apiKey string, note *types.Note) (r *types.Note, err error)
 	// DeleteNote moves a note to the trash can.
 	DeleteNote(apiKey string, guid types.GUID) (int32, error)
+	// ExpungeNote permanently removes the note, and all of its resources, from the service.
+	ExpungeNote(authenticationToken string, guid types.GUID) (r int32, err error)
 	// UpdateNote submits a set of changes to a note to the service.  The provided data
 	// must include the note's guid field for identification. The note's title must also be set.
 	UpdateNote(authenticationToken string, note *types.Note) (r *types.Note, err error)
@@ -43,4 +45,20 @@ type Notestore interface {
 	// GetNoteContent returns XHTML contents of the note with the provided GUID.
 	// If the Note is found in a public notebook, the authenticationToken will be ignored (so it could be an empty string).
 	GetNoteContent(authenticationToken string, guid types.GUID) (r string, err error)
+	// ListTags returns a list of all the user's tags.
+	ListTags(authenticationToken string) (r []*types.Tag, err error)
+	// GetDefaultNotebook returns the notebook that should be used to store new notes.
+	GetDefaultNotebook(authenticationToken string) (r *types.Notebook, err error)
+	// GetNote returns the current state of the note in the service with the provided GUID.
+	GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error)
+	// FindNoteCounts returns the number of notes matching the filter in each notebook and tag.
+	FindNoteCounts(authenticationToken string, filter *notestore.NoteFilter, withTrash bool) (r *notestore.NoteCollectionCounts, err error)
+	// ExpungeNotebook permanently removes the notebook from the user's account.
+	ExpungeNotebook(authenticationToken string, guid types.GUID) (r int32, err error)
+	// ListNoteVersions returns the versions of the note saved by the service.
+	ListNoteVersions(authenticationToken string, noteGuid types.GUID) (r []*notestore.NoteVersionId, err error)
+	// GetNoteVersion returns the note as it was in the version with the update sequence number.
+	GetNoteVersion(authenticationToken string, noteGuid types.GUID, updateSequenceNum int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error)
+	// GetFilteredSyncChunk returns the objects matching the filter that have changed after the update sequence number.
+	GetFilteredSyncChunk(authenticationToken string, afterUSN int32, maxEntries int32, filter *notestore.SyncChunkFilter) (r *notestore.SyncChunk, err error)
 }
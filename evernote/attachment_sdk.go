@@ -0,0 +1,63 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+)
+
+func convertAttachments(as []*clinote.Attachment) []*types.Resource {
+	a := make([]*types.Resource, len(as))
+	for i, att := range as {
+		size := int32(len(att.Data))
+		mime := att.MIMEType
+		filename := att.Filename
+		a[i] = &types.Resource{
+			Data: &types.Data{
+				BodyHash: att.Hash,
+				Size:     &size,
+				Body:     att.Data,
+			},
+			Mime:       &mime,
+			Attributes: &types.ResourceAttributes{FileName: &filename},
+		}
+	}
+	return a
+}
+
+// convertResources converts the note's resources to attachments. The
+// resource data is only included if it was returned by the server.
+func convertResources(rs []*types.Resource) []*clinote.Attachment {
+	if len(rs) == 0 {
+		return nil
+	}
+	a := make([]*clinote.Attachment, len(rs))
+	for i, r := range rs {
+		att := &clinote.Attachment{MIMEType: r.GetMime()}
+		if r.IsSetAttributes() {
+			att.Filename = r.Attributes.GetFileName()
+		}
+		if r.IsSetData() {
+			att.Data = r.Data.Body
+			att.Hash = r.Data.BodyHash
+		}
+		a[i] = att
+	}
+	return a
+}
@@ -80,6 +80,10 @@ func (c *cfgMock) UserStore() clinote.UserCredentialStore {
 	return c.getUserStore()
 }
 
+func (c *cfgMock) DefaultNotebook() string {
+	return ""
+}
+
 func (c *cfgMock) Close() error {
 	return nil
 }
@@ -161,8 +165,8 @@ func loginHelperFunction(t *testing.T, settings *clinote.Settings, verify, token
 		fmt.Println("Sending request to", url)
 		r, err := http.Get(url)
 		if err != nil {
-			fmt.Println(err)
-			t.FailNow()
+			t.Error(err)
+			return
 		}
 		r.Body.Close()
 	}()
@@ -1,6 +1,10 @@
 package evernote
 
 import (
+	"context"
+	"strconv"
+	"time"
+
 	"github.com/TcM1911/clinote"
 	ec "github.com/TcM1911/evernote-sdk-golang/client"
 	"github.com/TcM1911/evernote-sdk-golang/notestore"
@@ -15,6 +19,25 @@ var devBuild = false
 type Client struct {
 	// Config holds all the configurations.
 	Config clinote.Configuration
+	// MaxAttempts is the number of times a notestore call is tried when it
+	// fails with a rate limit or a transient error. DefaultMaxAttempts is
+	// used if it isn't set.
+	MaxAttempts int
+	// Offline makes the client use clinote.OfflineNotestore, so the
+	// calls that need the network fail with clinote.ErrOffline.
+	Offline bool
+	// Timeout is the longest time to wait for each notestore call. Calls
+	// that take longer fail with an error wrapping
+	// context.DeadlineExceeded. No timeout is used if it isn't set.
+	Timeout time.Duration
+	// MaxNoteSize is the largest ENML content, in bytes, that is uploaded
+	// when a note is created or updated. NewClient sets it to
+	// clinote.DefaultMaxNoteSize. No limit is checked if it's zero or less.
+	MaxNoteSize int
+	// Context is used for the notestore calls. Once it's done, the calls
+	// in progress stop waiting and new calls fail with its error. The
+	// background context is used if it isn't set.
+	Context context.Context
 	// APIToken is the access token for the user's account.
 	apiToken   string
 	ns         clinote.NotestoreClient
@@ -42,17 +65,67 @@ func (c *Client) GetNoteStore() (clinote.NotestoreClient, error) {
 	if c.ns != nil {
 		return c.ns, nil
 	}
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ns, err := c.newNoteStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.ns = ns
+	return ns, nil
+}
+
+// newNoteStore returns a notestore client for the user that stops waiting
+// for the notestore calls once the context is done. The client's Timeout is
+// used as the deadline of each call.
+func (c *Client) newNoteStore(ctx context.Context) (clinote.NotestoreClient, error) {
+	if c.Offline {
+		return clinote.OfflineNotestore{}, nil
+	}
 	if c.apiToken == "" {
 		return nil, ErrNotLoggedIn
 	}
-	ns, err := c.evernote.GetNoteStore(c.apiToken)
+	var ns *notestore.NoteStoreClient
+	err := callWithContext(ctx, c.Timeout, "GetNoteStore", func() (err error) {
+		ns, err = c.evernote.GetNoteStore(c.apiToken)
+		return err
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
 	c.evernoteNS = ns
-	store := &Notestore{apiToken: c.apiToken, evernoteNS: ns}
-	c.ns = store
-	return store, nil
+	attempts := c.MaxAttempts
+	if attempts < 1 {
+		attempts = DefaultMaxAttempts
+	}
+	retry := &retryNotestore{Notestore: ns, maxAttempts: attempts}
+	return &Notestore{
+		apiToken:    c.apiToken,
+		evernoteNS:  &contextNotestore{Notestore: retry, ctx: ctx, timeout: c.Timeout},
+		maxNoteSize: c.MaxNoteSize,
+	}, nil
+}
+
+// UserInfo returns the shard and the user ID of the user's account. They
+// are needed to build links to the user's notes.
+func (c *Client) UserInfo() (shardID, userID string, err error) {
+	if c.Offline {
+		return "", "", clinote.ErrOffline
+	}
+	if c.apiToken == "" {
+		return "", "", ErrNotLoggedIn
+	}
+	us, err := c.evernote.GetUserStore()
+	if err != nil {
+		return "", "", err
+	}
+	user, err := us.GetUser(c.apiToken)
+	if err != nil {
+		return "", "", err
+	}
+	return user.GetShardId(), strconv.Itoa(int(user.GetID())), nil
 }
 
 // GetAuthorizedToken gets the authorized token from the server.
@@ -73,6 +146,7 @@ func (c *Client) GetRequestToken(callback string) (*oauth.RequestToken, string,
 func NewClient(cfg clinote.Configuration) *Client {
 	client := new(Client)
 	client.Config = cfg
+	client.MaxNoteSize = clinote.DefaultMaxNoteSize
 	env := ec.PRODUCTION
 
 	key := migrateOldSession(cfg)
@@ -105,6 +105,14 @@ func (m *mockStore) GetNoteRecoveryPoint() (*clinote.Note, error) {
 	panic("not implemented")
 }
 
+func (m *mockStore) ListRecoveryPoints() ([]*clinote.Note, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveRecoveryPoint(guid string) error {
+	panic("not implemented")
+}
+
 func (m *mockStore) SaveSearch([]*clinote.Note) error {
 	panic("not implemented")
 }
@@ -113,6 +121,14 @@ func (m *mockStore) GetSearch() ([]*clinote.Note, error) {
 	panic("not implemented")
 }
 
+func (m *mockStore) SaveNamedSearch(name string, filter *clinote.NoteFilter) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetNamedSearch(name string) (*clinote.NoteFilter, error) {
+	panic("not implemented")
+}
+
 func (m *mockStore) GetNotebookCache() (*clinote.NotebookCacheList, error) {
 	panic("not implemented")
 }
@@ -121,6 +137,42 @@ func (m *mockStore) StoreNotebookList(list *clinote.NotebookCacheList) error {
 	panic("not implemented")
 }
 
+func (m *mockStore) UpdateNotebookCache(fn func(*clinote.NotebookCacheList) (*clinote.NotebookCacheList, error)) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetTagCache() (*clinote.TagCacheList, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) StoreTagList(list *clinote.TagCacheList) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetNoteCountCache() (*clinote.NoteCountCacheList, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) StoreNoteCountList(list *clinote.NoteCountCacheList) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetCachedContent(guid string, updated int64) (string, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveCachedContent(guid string, updated int64, content string) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetSyncUSN() (int32, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveSyncUSN(usn int32) error {
+	panic("not implemented")
+}
+
 func (m *mockStore) Close() error {
 	return nil
 }
@@ -0,0 +1,314 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TcM1911/clinote/evernote/api"
+	"github.com/TcM1911/evernote-sdk-golang/notestore"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+)
+
+// contextNotestore stops waiting for the calls to the notestore API once
+// the context is done. If a timeout is set, each call also gets a deadline
+// of the timeout. The error returned for a call that didn't finish wraps
+// the context's error, for example context.DeadlineExceeded.
+type contextNotestore struct {
+	api.Notestore
+	ctx     context.Context
+	timeout time.Duration
+
+	mu sync.Mutex
+	// pending is the name of the call that didn't finish in time and is
+	// still running.
+	pending string
+}
+
+// call runs the function and waits for it to return or the context to be
+// done. The underlying SDK can't cancel a request, so a call that doesn't
+// finish in time is left running in the background and its result is
+// dropped. The connection of the notestore can't be shared with it, so
+// the calls made before it returns fail with ErrNotestoreBusy.
+func (c *contextNotestore) call(name string, fn func() error) error {
+	c.mu.Lock()
+	pending := c.pending
+	c.mu.Unlock()
+	if pending != "" {
+		return fmt.Errorf("%w: %s wasn't called while %s is running", ErrNotestoreBusy, name, pending)
+	}
+	return callWithContext(c.ctx, c.timeout, name, fn, func(done <-chan error) {
+		c.mu.Lock()
+		c.pending = name
+		c.mu.Unlock()
+		go func() {
+			<-done
+			c.mu.Lock()
+			c.pending = ""
+			c.mu.Unlock()
+		}()
+	})
+}
+
+// callWithContext runs the function with the context, see
+// contextNotestore.call. If the call doesn't finish in time and abandoned
+// isn't nil, it's given the channel the call's error is sent to once it
+// returns.
+func callWithContext(ctx context.Context, timeout time.Duration, name string, fn func() error, abandoned func(<-chan error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %s wasn't called", err, name)
+	}
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if abandoned != nil {
+			abandoned(done)
+		}
+		return fmt.Errorf("%w: no response to %s", ctx.Err(), name)
+	}
+}
+
+func (c *contextNotestore) ListNotebooks(apiKey string) ([]*types.Notebook, error) {
+	var nbs []*types.Notebook
+	err := c.call("ListNotebooks", func() (err error) {
+		nbs, err = c.Notestore.ListNotebooks(apiKey)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nbs, nil
+}
+
+func (c *contextNotestore) CreateNotebook(apiKey string, notebook *types.Notebook) (*types.Notebook, error) {
+	var nb *types.Notebook
+	err := c.call("CreateNotebook", func() (err error) {
+		nb, err = c.Notestore.CreateNotebook(apiKey, notebook)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nb, nil
+}
+
+func (c *contextNotestore) UpdateNotebook(apiKey string, notebook *types.Notebook) (int32, error) {
+	var usn int32
+	err := c.call("UpdateNotebook", func() (err error) {
+		usn, err = c.Notestore.UpdateNotebook(apiKey, notebook)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return usn, nil
+}
+
+func (c *contextNotestore) GetNotebook(authenticationToken string, guid types.GUID) (*types.Notebook, error) {
+	var nb *types.Notebook
+	err := c.call("GetNotebook", func() (err error) {
+		nb, err = c.Notestore.GetNotebook(authenticationToken, guid)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nb, nil
+}
+
+func (c *contextNotestore) CreateNote(apiKey string, note *types.Note) (*types.Note, error) {
+	var n *types.Note
+	err := c.call("CreateNote", func() (err error) {
+		n, err = c.Notestore.CreateNote(apiKey, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (c *contextNotestore) DeleteNote(apiKey string, guid types.GUID) (int32, error) {
+	var usn int32
+	err := c.call("DeleteNote", func() (err error) {
+		usn, err = c.Notestore.DeleteNote(apiKey, guid)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return usn, nil
+}
+
+func (c *contextNotestore) UpdateNote(authenticationToken string, note *types.Note) (*types.Note, error) {
+	var n *types.Note
+	err := c.call("UpdateNote", func() (err error) {
+		n, err = c.Notestore.UpdateNote(authenticationToken, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (c *contextNotestore) FindNotes(apiKey string, filter *notestore.NoteFilter, offset int32, maxNumNotes int32) (*notestore.NoteList, error) {
+	var list *notestore.NoteList
+	err := c.call("FindNotes", func() (err error) {
+		list, err = c.Notestore.FindNotes(apiKey, filter, offset, maxNumNotes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (c *contextNotestore) GetNoteContent(authenticationToken string, guid types.GUID) (string, error) {
+	var content string
+	err := c.call("GetNoteContent", func() (err error) {
+		content, err = c.Notestore.GetNoteContent(authenticationToken, guid)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+func (c *contextNotestore) ListTags(authenticationToken string) ([]*types.Tag, error) {
+	var tags []*types.Tag
+	err := c.call("ListTags", func() (err error) {
+		tags, err = c.Notestore.ListTags(authenticationToken)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (c *contextNotestore) GetDefaultNotebook(authenticationToken string) (*types.Notebook, error) {
+	var nb *types.Notebook
+	err := c.call("GetDefaultNotebook", func() (err error) {
+		nb, err = c.Notestore.GetDefaultNotebook(authenticationToken)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nb, nil
+}
+
+func (c *contextNotestore) GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (*types.Note, error) {
+	var n *types.Note
+	err := c.call("GetNote", func() (err error) {
+		n, err = c.Notestore.GetNote(authenticationToken, guid, withContent, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (c *contextNotestore) FindNoteCounts(authenticationToken string, filter *notestore.NoteFilter, withTrash bool) (*notestore.NoteCollectionCounts, error) {
+	var counts *notestore.NoteCollectionCounts
+	err := c.call("FindNoteCounts", func() (err error) {
+		counts, err = c.Notestore.FindNoteCounts(authenticationToken, filter, withTrash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (c *contextNotestore) ExpungeNotebook(authenticationToken string, guid types.GUID) (int32, error) {
+	var usn int32
+	err := c.call("ExpungeNotebook", func() (err error) {
+		usn, err = c.Notestore.ExpungeNotebook(authenticationToken, guid)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return usn, nil
+}
+
+func (c *contextNotestore) ListNoteVersions(authenticationToken string, noteGuid types.GUID) ([]*notestore.NoteVersionId, error) {
+	var versions []*notestore.NoteVersionId
+	err := c.call("ListNoteVersions", func() (err error) {
+		versions, err = c.Notestore.ListNoteVersions(authenticationToken, noteGuid)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (c *contextNotestore) GetNoteVersion(authenticationToken string, noteGuid types.GUID, updateSequenceNum int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (*types.Note, error) {
+	var n *types.Note
+	err := c.call("GetNoteVersion", func() (err error) {
+		n, err = c.Notestore.GetNoteVersion(authenticationToken, noteGuid, updateSequenceNum, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (c *contextNotestore) ExpungeNote(authenticationToken string, guid types.GUID) (int32, error) {
+	var usn int32
+	err := c.call("ExpungeNote", func() (err error) {
+		usn, err = c.Notestore.ExpungeNote(authenticationToken, guid)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return usn, nil
+}
+
+func (c *contextNotestore) GetFilteredSyncChunk(authenticationToken string, afterUSN int32, maxEntries int32, filter *notestore.SyncChunkFilter) (*notestore.SyncChunk, error) {
+	var chunk *notestore.SyncChunk
+	err := c.call("GetFilteredSyncChunk", func() (err error) {
+		chunk, err = c.Notestore.GetFilteredSyncChunk(authenticationToken, afterUSN, maxEntries, filter)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
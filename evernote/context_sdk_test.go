@@ -0,0 +1,109 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/evernote-sdk-golang/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextNotestore(t *testing.T) {
+	assert := assert.New(t)
+	blocking := func() (*mockAPI, chan struct{}) {
+		release := make(chan struct{})
+		api := &mockAPI{listNotebooks: func(string) ([]*types.Notebook, error) {
+			<-release
+			return []*types.Notebook{}, nil
+		}}
+		return api, release
+	}
+
+	t.Run("return the result", func(t *testing.T) {
+		nb := types.NewNotebook()
+		api := &mockAPI{listNotebooks: func(string) ([]*types.Notebook, error) { return []*types.Notebook{nb}, nil }}
+		ns := &Notestore{evernoteNS: &contextNotestore{Notestore: api, ctx: context.Background(), timeout: time.Second}}
+		nbs, err := ns.GetAllNotebooks()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(nbs, 1, "Should return the notebooks")
+	})
+
+	t.Run("return the error from the call", func(t *testing.T) {
+		expected := errors.New("expected error")
+		api := &mockAPI{listNotebooks: func(string) ([]*types.Notebook, error) { return nil, expected }}
+		ns := &Notestore{evernoteNS: &contextNotestore{Notestore: api, ctx: context.Background(), timeout: time.Second}}
+		_, err := ns.GetAllNotebooks()
+		assert.Equal(expected, err, "Wrong error returned")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		api, release := blocking()
+		defer close(release)
+		ns := &Notestore{evernoteNS: &contextNotestore{Notestore: api, ctx: context.Background(), timeout: 10 * time.Millisecond}}
+		_, err := ns.GetAllNotebooks()
+		assert.True(errors.Is(err, context.DeadlineExceeded), "Should wrap context.DeadlineExceeded")
+		assert.Contains(err.Error(), "ListNotebooks", "Should name the call")
+	})
+
+	t.Run("fail fast while a timed out call is running", func(t *testing.T) {
+		api, release := blocking()
+		ns := &Notestore{evernoteNS: &contextNotestore{Notestore: api, ctx: context.Background(), timeout: 10 * time.Millisecond}}
+		_, err := ns.GetAllNotebooks()
+		assert.True(errors.Is(err, context.DeadlineExceeded), "Should wrap context.DeadlineExceeded")
+
+		called := false
+		api.listTags = func(string) ([]*types.Tag, error) { called = true; return nil, nil }
+		_, err = ns.ListTags()
+		assert.True(errors.Is(err, ErrNotestoreBusy), "Should return ErrNotestoreBusy")
+		assert.False(called, "Should not call the notestore")
+
+		close(release)
+		for end := time.Now().Add(time.Second); time.Now().Before(end); time.Sleep(time.Millisecond) {
+			if _, err = ns.ListTags(); err == nil {
+				break
+			}
+		}
+		assert.NoError(err, "Should call the notestore once the call returned")
+		assert.True(called, "Should call the notestore")
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		api, release := blocking()
+		defer close(release)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		ns := &Notestore{evernoteNS: &contextNotestore{Notestore: api, ctx: ctx}}
+		_, err := ns.GetAllNotebooks()
+		assert.True(errors.Is(err, context.DeadlineExceeded), "Should wrap context.DeadlineExceeded")
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		called := false
+		api := &mockAPI{listNotebooks: func(string) ([]*types.Notebook, error) { called = true; return nil, nil }}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ns := &Notestore{evernoteNS: &contextNotestore{Notestore: api, ctx: ctx}}
+		_, err := ns.GetAllNotebooks()
+		assert.True(errors.Is(err, context.Canceled), "Should wrap context.Canceled")
+		assert.False(called, "Should not call the notestore")
+	})
+}
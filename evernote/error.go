@@ -18,4 +18,11 @@ var (
 	ErrNoGUIDSet = errors.New("no GUID set.")
 	// ErrNoTitleSet is returned if the not does not have a title.
 	ErrNoTitleSet = errors.New("no title set")
+	// ErrNotestoreBusy is returned if an earlier call to the notestore
+	// didn't finish in time and is still using the connection.
+	ErrNotestoreBusy = errors.New("the notestore is busy with a call that timed out")
+	// ErrRateLimited is returned if Evernote's rate limit was reached and
+	// the wait before the call can be retried is longer than
+	// MaxRateLimitWait.
+	ErrRateLimited = errors.New("rate limited by Evernote")
 )
@@ -46,9 +46,73 @@ func convert(note *types.Note) *clinote.Note {
 	n.Notebook.GUID = notebookGUID
 	n.Created = int64(note.GetCreated())
 	n.Updated = int64(note.GetUpdated())
+	n.Tags = note.GetTagNames()
+	n.TagGUIDs = note.GetTagGuids()
+	n.Resources = convertResources(note.GetResources())
+	n.Reminder = convertReminder(note.GetAttributes())
+	if attrs := note.GetAttributes(); attrs != nil {
+		n.SourceApplication = attrs.GetSourceApplication()
+		n.SourceURL = attrs.GetSourceURL()
+	}
 	return n
 }
 
+// convertReminder returns the reminder set in the note attributes, or nil
+// if the note doesn't have a reminder.
+func convertReminder(attrs *types.NoteAttributes) *clinote.Reminder {
+	if attrs == nil {
+		return nil
+	}
+	r := &clinote.Reminder{
+		Order:    attrs.GetReminderOrder(),
+		Time:     int64(attrs.GetReminderTime()),
+		DoneTime: int64(attrs.GetReminderDoneTime()),
+	}
+	if r.IsEmpty() {
+		return nil
+	}
+	return r
+}
+
+// setReminderAttributes sets the reminder in the note attributes. The
+// values that are zero are unset, so an empty reminder removes it.
+func setReminderAttributes(attrs *types.NoteAttributes, r *clinote.Reminder) {
+	attrs.ReminderOrder = nil
+	attrs.ReminderTime = nil
+	attrs.ReminderDoneTime = nil
+	if r.Order != 0 {
+		order := r.Order
+		attrs.ReminderOrder = &order
+	}
+	if r.Time != 0 {
+		t := types.Timestamp(r.Time)
+		attrs.ReminderTime = &t
+	}
+	if r.DoneTime != 0 {
+		t := types.Timestamp(r.DoneTime)
+		attrs.ReminderDoneTime = &t
+	}
+}
+
+// setSourceAttributes sets the source in the note attributes. Empty values
+// are left untouched.
+func setSourceAttributes(attrs *types.NoteAttributes, n *clinote.Note) {
+	if n.SourceApplication != "" {
+		app := n.SourceApplication
+		attrs.SourceApplication = &app
+	}
+	if n.SourceURL != "" {
+		url := n.SourceURL
+		attrs.SourceURL = &url
+	}
+}
+
+// hasSourceAttributes returns true if the note has source attributes to
+// set when it is saved.
+func hasSourceAttributes(n *clinote.Note) bool {
+	return n.SourceApplication != "" || n.SourceURL != ""
+}
+
 func convertNotes(notes []*types.Note) []*clinote.Note {
 	a := make([]*clinote.Note, len(notes))
 	for i, n := range notes {
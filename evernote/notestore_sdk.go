@@ -1,10 +1,13 @@
 package evernote
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/evernote/api"
+	"github.com/TcM1911/evernote-sdk-golang/errors"
 	"github.com/TcM1911/evernote-sdk-golang/notestore"
 	"github.com/TcM1911/evernote-sdk-golang/types"
 )
@@ -13,6 +16,9 @@ import (
 type Notestore struct {
 	evernoteNS api.Notestore
 	apiToken   string
+	// maxNoteSize is the largest ENML content, in bytes, that is uploaded.
+	// No limit is checked if it's zero or less.
+	maxNoteSize int
 }
 
 // GetAllNotebooks returns all the of users notebooks.
@@ -24,6 +30,15 @@ func (s *Notestore) GetAllNotebooks() ([]*clinote.Notebook, error) {
 	return convertNotebooks(bs), nil
 }
 
+// ListTags returns all the user's tags.
+func (s *Notestore) ListTags() ([]*clinote.Tag, error) {
+	ts, err := s.evernoteNS.ListTags(s.apiToken)
+	if err != nil {
+		return nil, err
+	}
+	return convertTags(ts), nil
+}
+
 // UpdateNotebook updates the notebook on the server.
 func (s *Notestore) UpdateNotebook(b *clinote.Notebook) error {
 	nb, err := getCachedNotebook(types.GUID(b.GUID))
@@ -47,17 +62,31 @@ func (s *Notestore) CreateNotebook(b *clinote.Notebook, defaultNotebook bool) er
 // GetNotebook returns the notebook with the specific GUID.
 func (s *Notestore) GetNotebook(guid string) (*clinote.Notebook, error) {
 	nb, err := s.evernoteNS.GetNotebook(s.apiToken, types.GUID(guid))
+	if isNotFound(err) {
+		return nil, clinote.ErrNoNotebookFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return convertNotebooks([]*types.Notebook{nb})[0], nil
 }
 
-// CreateNote creates a new note and saves it to the server.
+// CreateNote creates a new note and saves it to the server. The note is
+// created now unless it has a created time set.
 func (s *Notestore) CreateNote(n *clinote.Note) error {
+	if err := s.checkNoteSize(n.Body); err != nil {
+		return err
+	}
 	note := types.NewNote()
-	now := types.Timestamp(time.Now().Unix() * 1000)
-	note.Created = &now
+	created := types.Timestamp(time.Now().Unix() * 1000)
+	if n.Created != 0 {
+		created = types.Timestamp(n.Created)
+	}
+	note.Created = &created
+	if n.Updated != 0 {
+		updated := types.Timestamp(n.Updated)
+		note.Updated = &updated
+	}
 	note.Title = &n.Title
 	if n.Body != "" {
 		note.Content = &n.Body
@@ -66,10 +95,33 @@ func (s *Notestore) CreateNote(n *clinote.Note) error {
 		guid := string(n.Notebook.GUID)
 		note.NotebookGuid = &guid
 	}
+	if len(n.Tags) > 0 {
+		note.TagNames = n.Tags
+	}
+	if len(n.Resources) > 0 {
+		note.Resources = convertAttachments(n.Resources)
+	}
+	if (n.Reminder != nil && !n.Reminder.IsEmpty()) || hasSourceAttributes(n) {
+		note.Attributes = types.NewNoteAttributes()
+		if n.Reminder != nil {
+			setReminderAttributes(note.Attributes, n.Reminder)
+		}
+		setSourceAttributes(note.Attributes, n)
+	}
 	_, err := s.evernoteNS.CreateNote(s.apiToken, note)
 	return err
 }
 
+// checkNoteSize returns clinote.ErrNoteTooLarge if the ENML body is larger
+// than the notestore's limit. The size is checked before the note is sent,
+// so the limit isn't reported as a server error.
+func (s *Notestore) checkNoteSize(body string) error {
+	if s.maxNoteSize > 0 && len(body) > s.maxNoteSize {
+		return fmt.Errorf("%w: the content is %d bytes but the limit is %d bytes", clinote.ErrNoteTooLarge, len(body), s.maxNoteSize)
+	}
+	return nil
+}
+
 // DeleteNote removes a note from the user's notebook.
 func (s *Notestore) DeleteNote(guid string) error {
 	_, err := s.evernoteNS.DeleteNote(s.apiToken, types.GUID(guid))
@@ -84,6 +136,9 @@ func (s *Notestore) UpdateNote(note *clinote.Note) error {
 	if note.Title == "" {
 		return ErrNoTitleSet
 	}
+	if err := s.checkNoteSize(note.Body); err != nil {
+		return err
+	}
 	n := types.NewNote()
 	n.Title = &note.Title
 	guid := types.GUID(note.GUID)
@@ -91,9 +146,63 @@ func (s *Notestore) UpdateNote(note *clinote.Note) error {
 	if note.Body != "" {
 		n.Content = &note.Body
 	}
-	n.NotebookGuid = &note.Notebook.GUID
-	_, err := s.evernoteNS.UpdateNote(s.apiToken, n)
-	return err
+	// Without a notebook, the note is kept in its current notebook.
+	if note.Notebook != nil {
+		n.NotebookGuid = &note.Notebook.GUID
+	}
+	// The updated time isn't sent, so the server sets it to now.
+	if note.Created != 0 {
+		created := types.Timestamp(note.Created)
+		n.Created = &created
+	}
+	// A nil tag list leaves the note's tags untouched while an empty
+	// list removes all the tags from the note.
+	if note.Tags != nil {
+		n.TagNames = note.Tags
+	}
+	// The attributes are replaced as a whole, so the reminder and the
+	// source are set in a copy of the note's current attributes.
+	if note.Reminder != nil || hasSourceAttributes(note) {
+		attrs, err := s.noteAttributes(guid)
+		if err != nil {
+			return err
+		}
+		if note.Reminder != nil {
+			setReminderAttributes(attrs, note.Reminder)
+		}
+		setSourceAttributes(attrs, note)
+		n.Attributes = attrs
+	}
+	updated, err := s.evernoteNS.UpdateNote(s.apiToken, n)
+	if err != nil {
+		return err
+	}
+	// Keep the updated time in sync with the server so saving the note
+	// again isn't seen as a conflict.
+	if updated != nil && updated.Updated != nil {
+		note.Updated = int64(*updated.Updated)
+	}
+	return nil
+}
+
+// noteAttributes returns a copy of the note's attributes. The cached note
+// is used if there is one, otherwise the note is fetched from the server.
+func (s *Notestore) noteAttributes(guid types.GUID) (*types.NoteAttributes, error) {
+	noteMu.Lock()
+	cached, ok := cache[guid]
+	noteMu.Unlock()
+	if !ok {
+		var err error
+		cached, err = s.evernoteNS.GetNote(s.apiToken, guid, false, false, false, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	attrs := types.NewNoteAttributes()
+	if cached.Attributes != nil {
+		*attrs = *cached.Attributes
+	}
+	return attrs, nil
 }
 
 // FindNotes searches for the notes based on the filter.
@@ -105,19 +214,238 @@ func (s *Notestore) FindNotes(filter *clinote.NoteFilter, offset, count int) ([]
 	return convertNotes(r.GetNotes()), nil
 }
 
+// GetNote gets the note's metadata from the notestore.
+func (s *Notestore) GetNote(guid string) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNote(s.apiToken, types.GUID(guid), false, false, false, false)
+	if isNotFound(err) {
+		return nil, clinote.ErrNoNoteFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convert(n), nil
+}
+
+// GetNoteWithResources gets the note's metadata from the notestore
+// together with the data of its resources.
+func (s *Notestore) GetNoteWithResources(guid string) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNote(s.apiToken, types.GUID(guid), false, true, false, false)
+	if isNotFound(err) {
+		return nil, clinote.ErrNoNoteFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convert(n), nil
+}
+
+// trashListSize is the maximum number of notes returned from the trash.
+const trashListSize = 250
+
+// ListTrash returns the notes in the trash.
+func (s *Notestore) ListTrash() ([]*clinote.Note, error) {
+	filter := notestore.NewNoteFilter()
+	inactive := true
+	filter.Inactive = &inactive
+	r, err := s.evernoteNS.FindNotes(s.apiToken, filter, 0, trashListSize)
+	if err != nil {
+		return nil, err
+	}
+	return convertNotes(r.GetNotes()), nil
+}
+
+// RestoreNote moves the note out of the trash.
+func (s *Notestore) RestoreNote(guid string) error {
+	noteMu.Lock()
+	cached, ok := cache[types.GUID(guid)]
+	noteMu.Unlock()
+	if !ok {
+		var err error
+		cached, err = s.evernoteNS.GetNote(s.apiToken, types.GUID(guid), false, false, false, false)
+		if err != nil {
+			return err
+		}
+	}
+	n := types.NewNote()
+	g := types.GUID(guid)
+	n.GUID = &g
+	title := cached.GetTitle()
+	n.Title = &title
+	active := true
+	n.Active = &active
+	_, err := s.evernoteNS.UpdateNote(s.apiToken, n)
+	return err
+}
+
+// GetDefaultNotebook returns the user's default notebook.
+func (s *Notestore) GetDefaultNotebook() (*clinote.Notebook, error) {
+	nb, err := s.evernoteNS.GetDefaultNotebook(s.apiToken)
+	if err != nil {
+		return nil, err
+	}
+	return convertNotebooks([]*types.Notebook{nb})[0], nil
+}
+
 // GetNoteContent gets the note's content from the notestore.
 func (s *Notestore) GetNoteContent(guid string) (string, error) {
 	return s.evernoteNS.GetNoteContent(s.apiToken, types.GUID(guid))
 }
 
+// NoteCount returns the number of notes in the notebook.
+func (s *Notestore) NoteCount(notebookGUID string) (int32, error) {
+	filter := notestore.NewNoteFilter()
+	guid := types.GUID(notebookGUID)
+	filter.NotebookGuid = &guid
+	counts, err := s.evernoteNS.FindNoteCounts(s.apiToken, filter, false)
+	if err != nil {
+		return 0, err
+	}
+	// Notebooks without any notes are left out of the counts.
+	return counts.GetNotebookCounts()[guid], nil
+}
+
+// ExpungeNote permanently removes the note from the server.
+func (s *Notestore) ExpungeNote(guid string) error {
+	_, err := s.evernoteNS.ExpungeNote(s.apiToken, types.GUID(guid))
+	return err
+}
+
+// ExpungeNotebook permanently removes the notebook from the server.
+func (s *Notestore) ExpungeNotebook(guid string) error {
+	_, err := s.evernoteNS.ExpungeNotebook(s.apiToken, types.GUID(guid))
+	return err
+}
+
+// ListNoteVersions returns the saved versions of the note. If the account
+// doesn't keep versions of notes, clinote.ErrVersionsUnsupported is
+// returned.
+func (s *Notestore) ListNoteVersions(guid string) ([]clinote.NoteVersion, error) {
+	ids, err := s.evernoteNS.ListNoteVersions(s.apiToken, types.GUID(guid))
+	if isPermissionDenied(err) {
+		return nil, clinote.ErrVersionsUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]clinote.NoteVersion, len(ids))
+	for i, id := range ids {
+		versions[i] = clinote.NoteVersion{
+			USN:     id.GetUpdateSequenceNum(),
+			Title:   id.GetTitle(),
+			Updated: int64(id.GetUpdated()),
+			Saved:   int64(id.GetSaved()),
+		}
+	}
+	return versions, nil
+}
+
+// GetNoteVersion returns the note as it was in the version, including the
+// content.
+func (s *Notestore) GetNoteVersion(guid string, usn int32) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNoteVersion(s.apiToken, types.GUID(guid), usn, false, false, false)
+	if isPermissionDenied(err) {
+		return nil, clinote.ErrVersionsUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	note := convert(n)
+	note.Body = n.GetContent()
+	return note, nil
+}
+
+// GetSyncChunk returns the notes changed after the update sequence number,
+// without their content, and the GUIDs of the expunged notes.
+func (s *Notestore) GetSyncChunk(afterUSN, maxEntries int32) (*clinote.SyncChunk, error) {
+	include := true
+	filter := &notestore.SyncChunkFilter{
+		IncludeNotes:          &include,
+		IncludeNoteAttributes: &include,
+		IncludeExpunged:       &include,
+	}
+	chunk, err := s.evernoteNS.GetFilteredSyncChunk(s.apiToken, afterUSN, maxEntries, filter)
+	if err != nil {
+		return nil, err
+	}
+	c := &clinote.SyncChunk{
+		Notes:         make([]*clinote.Note, 0, len(chunk.GetNotes())),
+		ExpungedNotes: chunk.GetExpungedNotes(),
+		ChunkHighUSN:  chunk.GetChunkHighUSN(),
+		UpdateCount:   chunk.GetUpdateCount(),
+	}
+	for _, n := range chunk.GetNotes() {
+		note := convert(n)
+		note.Deleted = n.IsSetDeleted()
+		c.Notes = append(c.Notes, note)
+	}
+	return c, nil
+}
+
+// isPermissionDenied returns true if the error is the service denying the
+// call, for example because the account's tier doesn't include it.
+func isPermissionDenied(err error) bool {
+	e, ok := err.(*errors.EDAMUserException)
+	return ok && e.ErrorCode == errors.EDAMErrorCode_PERMISSION_DENIED
+}
+
+// isNotFound returns true if the error is the service not finding the
+// requested object.
+func isNotFound(err error) bool {
+	_, ok := err.(*errors.EDAMNotFoundException)
+	return ok
+}
+
+// searchTimeFormat is the date format used by the search grammar.
+const searchTimeFormat = "20060102T150405Z"
+
+// dateTerm returns the search term for the time in milliseconds. The term
+// matches notes at or after the time, or before the time if negated.
+func dateTerm(field string, ms int64, negate bool) []string {
+	if ms == 0 {
+		return nil
+	}
+	term := field + ":" + time.Unix(0, ms*int64(time.Millisecond)).UTC().Format(searchTimeFormat)
+	if negate {
+		term = "-" + term
+	}
+	return []string{term}
+}
+
 func createFilter(filter *clinote.NoteFilter) *notestore.NoteFilter {
 	searchFilter := notestore.NewNoteFilter()
 	if filter.NotebookGUID != "" {
 		guid := types.GUID(filter.NotebookGUID)
 		searchFilter.NotebookGuid = &guid
 	}
+	var terms []string
+	if filter.Stack != "" {
+		terms = append(terms, fmt.Sprintf("stack:%q", filter.Stack))
+	}
+	terms = append(terms, dateTerm("created", filter.CreatedAfter, false)...)
+	terms = append(terms, dateTerm("created", filter.CreatedBefore, true)...)
+	terms = append(terms, dateTerm("updated", filter.UpdatedAfter, false)...)
+	terms = append(terms, dateTerm("updated", filter.UpdatedBefore, true)...)
 	if filter.Words != "" {
-		searchFilter.Words = &(filter.Words)
+		terms = append(terms, filter.Words)
+	}
+	if len(terms) > 0 {
+		words := strings.Join(terms, " ")
+		searchFilter.Words = &words
+	}
+	if len(filter.TagGUIDs) > 0 {
+		searchFilter.TagGuids = filter.TagGUIDs
+	}
+	if filter.Order != 0 {
+		searchFilter.Order = &(filter.Order)
+	}
+	// Evernote sorts in descending order unless told otherwise. Titles
+	// are expected in alphabetical order, so they are sorted ascending.
+	ascending := filter.Order == clinote.NoteFilterOrderTitle
+	if filter.Reverse {
+		ascending = !ascending
+	}
+	if ascending {
+		searchFilter.Ascending = &ascending
 	}
 	return searchFilter
 }
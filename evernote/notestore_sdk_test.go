@@ -1,10 +1,15 @@
 package evernote
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TcM1911/clinote"
+	edamErrors "github.com/TcM1911/evernote-sdk-golang/errors"
 	"github.com/TcM1911/evernote-sdk-golang/notestore"
 	"github.com/TcM1911/evernote-sdk-golang/types"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +39,62 @@ func TestGetAllNotebooks(t *testing.T) {
 	})
 }
 
+func TestGetNotebookSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("Notebook GUID")
+	t.Run("return notebook", func(t *testing.T) {
+		name := "Notebook"
+		api := &mockAPI{getNotebook: func(_ string, g types.GUID) (*types.Notebook, error) {
+			return &types.Notebook{GUID: &g, Name: &name}, nil
+		}}
+		ns := &Notestore{evernoteNS: api}
+		nb, err := ns.GetNotebook(string(guid))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(&clinote.Notebook{GUID: string(guid), Name: name}, nb, "Wrong notebook returned")
+	})
+	t.Run("not found", func(t *testing.T) {
+		api := &mockAPI{getNotebook: func(string, types.GUID) (*types.Notebook, error) {
+			return nil, &edamErrors.EDAMNotFoundException{}
+		}}
+		ns := &Notestore{evernoteNS: api}
+		_, err := ns.GetNotebook(string(guid))
+		assert.Equal(clinote.ErrNoNotebookFound, err, "Wrong error returned")
+	})
+}
+
+func TestGetSyncChunkSDK(t *testing.T) {
+	assert := assert.New(t)
+	title, guid, high := "Note", types.GUID("GUID"), int32(15)
+	deleted := types.Timestamp(1000)
+	var usedFilter *notestore.SyncChunkFilter
+	api := &mockAPI{getSyncChunk: func(_ string, after, max int32, f *notestore.SyncChunkFilter) (*notestore.SyncChunk, error) {
+		usedFilter = f
+		return &notestore.SyncChunk{
+			ChunkHighUSN:  &high,
+			UpdateCount:   20,
+			Notes:         []*types.Note{&types.Note{Title: &title, GUID: &guid, Deleted: &deleted}},
+			ExpungedNotes: []string{"Expunged GUID"},
+		}, nil
+	}}
+	ns := &Notestore{evernoteNS: api}
+	chunk, err := ns.GetSyncChunk(10, 100)
+	assert.NoError(err, "Should not return an error")
+	assert.True(usedFilter.GetIncludeNotes(), "Notes should be included")
+	assert.True(usedFilter.GetIncludeExpunged(), "Expunged notes should be included")
+	assert.Equal(high, chunk.ChunkHighUSN, "Wrong chunk USN")
+	assert.Equal(int32(20), chunk.UpdateCount, "Wrong update count")
+	assert.Equal([]string{"Expunged GUID"}, chunk.ExpungedNotes, "Wrong expunged notes")
+	assert.Len(chunk.Notes, 1, "Wrong number of notes")
+	assert.Equal(title, chunk.Notes[0].Title, "Wrong title")
+	assert.True(chunk.Notes[0].Deleted, "Note should be marked as deleted")
+
+	api.getSyncChunk = func(string, int32, int32, *notestore.SyncChunkFilter) (*notestore.SyncChunk, error) {
+		return nil, errExpected
+	}
+	_, err = ns.GetSyncChunk(10, 100)
+	assert.Equal(errExpected, err, "Wrong error returned")
+}
+
 func TestUpdateNotebookSDK(t *testing.T) {
 	assert := assert.New(t)
 	token := "token"
@@ -114,6 +175,70 @@ func TestCreateNoteSDK(t *testing.T) {
 	assert.Equal(&note.Body, saved.Content, "Body not saved")
 	assert.Equal(&note.Title, saved.Title, "Title not saved")
 	assert.Equal(notebookGUID, *saved.NotebookGuid, "Notebook GUID doesn't match")
+
+	t.Run("with tags", func(t *testing.T) {
+		note.Tags = []string{"work", "urgent"}
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.Equal(note.Tags, saved.TagNames, "Tags not saved")
+	})
+
+	t.Run("with reminder", func(t *testing.T) {
+		note.Reminder = &clinote.Reminder{Order: 10, Time: 2000}
+		defer func() { note.Reminder = nil }()
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.Equal(int64(10), saved.GetAttributes().GetReminderOrder(), "Wrong reminder order")
+		assert.Equal(types.Timestamp(2000), saved.GetAttributes().GetReminderTime(), "Wrong reminder time")
+		assert.False(saved.GetAttributes().IsSetReminderDoneTime(), "Done time should not be set")
+	})
+
+	t.Run("without reminder", func(t *testing.T) {
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.Nil(saved.Attributes, "Attributes should not be set")
+	})
+
+	t.Run("with source", func(t *testing.T) {
+		note.SourceApplication = "clinote"
+		note.SourceURL = "https://example.com"
+		defer func() { note.SourceApplication, note.SourceURL = "", "" }()
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.Equal("clinote", saved.GetAttributes().GetSourceApplication(), "Wrong source application")
+		assert.Equal("https://example.com", saved.GetAttributes().GetSourceURL(), "Wrong source URL")
+		assert.False(saved.GetAttributes().IsSetReminderTime(), "Reminder should not be set")
+	})
+
+	t.Run("with timestamps", func(t *testing.T) {
+		note.Created, note.Updated = 1000, 2000
+		defer func() { note.Created, note.Updated = 0, 0 }()
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.Equal(types.Timestamp(1000), saved.GetCreated(), "Wrong created time")
+		assert.Equal(types.Timestamp(2000), saved.GetUpdated(), "Wrong updated time")
+	})
+
+	t.Run("without timestamps", func(t *testing.T) {
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.NotZero(saved.GetCreated(), "Created time should be set to now")
+		assert.False(saved.IsSetUpdated(), "Updated time should not be set")
+	})
+
+	t.Run("with attachments", func(t *testing.T) {
+		data := []byte("image data")
+		note.Resources = []*clinote.Attachment{&clinote.Attachment{Filename: "image.png", MIMEType: "image/png", Data: data, Hash: []byte("hash")}}
+		err := ns.CreateNote(note)
+		assert.Equal(errExpected, err, "Wrong error")
+		assert.Len(saved.Resources, 1, "Resource not saved")
+		r := saved.Resources[0]
+		assert.Equal("image/png", r.GetMime(), "Wrong MIME type")
+		assert.Equal(data, r.GetData().GetBody(), "Wrong data")
+		assert.Equal([]byte("hash"), r.GetData().GetBodyHash(), "Wrong hash")
+		assert.Equal(int32(len(data)), r.GetData().GetSize(), "Wrong size")
+		assert.Equal("image.png", r.GetAttributes().GetFileName(), "Wrong filename")
+	})
 }
 
 func TestDeleteNoteSDK(t *testing.T) {
@@ -146,6 +271,25 @@ func TestUpdateNoteSDK(t *testing.T) {
 		assert.Equal(ErrNoTitleSet, err, "Wrong error returned")
 	})
 
+	t.Run("send created but not updated time", func(t *testing.T) {
+		var saved *types.Note
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { saved = n; return nil, nil }}
+		err := ns.UpdateNote(&clinote.Note{GUID: "some guid", Title: "Title", Created: 1000, Updated: 2000})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(types.Timestamp(1000), saved.GetCreated(), "Wrong created time")
+		assert.False(saved.IsSetUpdated(), "Updated time should be set by the server")
+	})
+
+	t.Run("set the updated time from the server", func(t *testing.T) {
+		updated := types.Timestamp(3000)
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) {
+			return &types.Note{GUID: n.GUID, Updated: &updated}, nil
+		}}
+		note := &clinote.Note{GUID: "some guid", Title: "Title", Updated: 2000}
+		assert.NoError(ns.UpdateNote(note), "Should not return an error")
+		assert.Equal(int64(3000), note.Updated, "Should have the server's updated time")
+	})
+
 	t.Run("Skip body if empty", func(t *testing.T) {
 		var expectedNote *types.Note
 		expectedGUID := "Expected GUID"
@@ -179,6 +323,102 @@ func TestUpdateNoteSDK(t *testing.T) {
 		assert.Equal(expectedTitle, expectedNote.GetTitle(), "Wrong Title")
 		assert.Equal(expectedContent, expectedNote.GetContent(), "Content should be empty")
 	})
+
+	t.Run("Leave notebook if not set", func(t *testing.T) {
+		var expectedNote *types.Note
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil }}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "GUID"})
+		assert.NoError(err, "No error should be returned")
+		assert.Nil(expectedNote.NotebookGuid, "Notebook should not be set")
+	})
+
+	t.Run("Leave tags if not set", func(t *testing.T) {
+		var expectedNote *types.Note
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil }}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "GUID", Notebook: new(clinote.Notebook)})
+		assert.NoError(err, "No error should be returned")
+		assert.Nil(expectedNote.TagNames, "Tags should not be set")
+	})
+
+	t.Run("Include tags", func(t *testing.T) {
+		var expectedNote *types.Note
+		tags := []string{"work"}
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil }}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "GUID", Notebook: new(clinote.Notebook), Tags: tags})
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(tags, expectedNote.TagNames, "Wrong tags")
+	})
+
+	t.Run("Leave attributes if no reminder", func(t *testing.T) {
+		var expectedNote *types.Note
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil }}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "GUID", Notebook: new(clinote.Notebook)})
+		assert.NoError(err, "No error should be returned")
+		assert.Nil(expectedNote.Attributes, "Attributes should not be set")
+	})
+
+	t.Run("Set reminder and keep attributes", func(t *testing.T) {
+		guid := types.GUID("Reminder GUID")
+		source := "web.clip"
+		order := int64(5)
+		cached := &types.Note{GUID: &guid, Attributes: &types.NoteAttributes{Source: &source, ReminderOrder: &order}}
+		noteMu.Lock()
+		cache[guid] = cached
+		noteMu.Unlock()
+		defer func() {
+			noteMu.Lock()
+			delete(cache, guid)
+			noteMu.Unlock()
+		}()
+		var expectedNote *types.Note
+		ns.evernoteNS = &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil }}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: string(guid), Notebook: new(clinote.Notebook), Reminder: &clinote.Reminder{Order: 7, Time: 3000}})
+		assert.NoError(err, "No error should be returned")
+		attrs := expectedNote.GetAttributes()
+		assert.Equal(source, attrs.GetSource(), "Other attributes should be kept")
+		assert.Equal(int64(7), attrs.GetReminderOrder(), "Wrong reminder order")
+		assert.Equal(types.Timestamp(3000), attrs.GetReminderTime(), "Wrong reminder time")
+		assert.Equal(int64(5), cached.Attributes.GetReminderOrder(), "Cached note should not be changed")
+	})
+
+	t.Run("Set source and keep attributes", func(t *testing.T) {
+		order := int64(5)
+		app := "other app"
+		var expectedNote *types.Note
+		ns.evernoteNS = &mockAPI{
+			getNote: func(_ string, guid types.GUID, _, _, _, _ bool) (*types.Note, error) {
+				return &types.Note{GUID: &guid, Attributes: &types.NoteAttributes{ReminderOrder: &order, SourceApplication: &app}}, nil
+			},
+			updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil },
+		}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "Source GUID", SourceURL: "https://example.com"})
+		assert.NoError(err, "No error should be returned")
+		attrs := expectedNote.GetAttributes()
+		if assert.NotNil(attrs, "Attributes should be set") {
+			assert.Equal("https://example.com", attrs.GetSourceURL(), "Wrong source URL")
+			assert.Equal(app, attrs.GetSourceApplication(), "Source application should be kept")
+			assert.Equal(order, attrs.GetReminderOrder(), "Reminder should be kept")
+		}
+	})
+
+	t.Run("Clear reminder of uncached note", func(t *testing.T) {
+		order := int64(5)
+		reminderTime := types.Timestamp(3000)
+		var expectedNote *types.Note
+		ns.evernoteNS = &mockAPI{
+			getNote: func(_ string, guid types.GUID, _, _, _, _ bool) (*types.Note, error) {
+				return &types.Note{GUID: &guid, Attributes: &types.NoteAttributes{ReminderOrder: &order, ReminderTime: &reminderTime}}, nil
+			},
+			updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil },
+		}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "Uncached GUID", Notebook: new(clinote.Notebook), Reminder: new(clinote.Reminder)})
+		assert.NoError(err, "No error should be returned")
+		attrs := expectedNote.GetAttributes()
+		if assert.NotNil(attrs, "Attributes should be set") {
+			assert.False(attrs.IsSetReminderOrder(), "Reminder order should be cleared")
+			assert.False(attrs.IsSetReminderTime(), "Reminder time should be cleared")
+		}
+	})
 }
 
 func TestFindNotes(t *testing.T) {
@@ -203,6 +443,34 @@ func TestFindNotes(t *testing.T) {
 		assert.Equal(string(GUID), notes[0].GUID, "Wrong GUID")
 	})
 
+	t.Run("reminder", func(t *testing.T) {
+		order := int64(10)
+		reminderTime := types.Timestamp(2000)
+		expectedNote.Attributes = &types.NoteAttributes{ReminderOrder: &order, ReminderTime: &reminderTime}
+		defer func() { expectedNote.Attributes = nil }()
+		notes, err := ns.FindNotes(&clinote.NoteFilter{}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(&clinote.Reminder{Order: 10, Time: 2000}, notes[0].Reminder, "Wrong reminder")
+	})
+
+	t.Run("source", func(t *testing.T) {
+		app, url := "clinote", "https://example.com"
+		expectedNote.Attributes = &types.NoteAttributes{SourceApplication: &app, SourceURL: &url}
+		defer func() { expectedNote.Attributes = nil }()
+		notes, err := ns.FindNotes(&clinote.NoteFilter{}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(app, notes[0].SourceApplication, "Wrong source application")
+		assert.Equal(url, notes[0].SourceURL, "Wrong source URL")
+	})
+
+	t.Run("tag GUIDs", func(t *testing.T) {
+		expectedNote.TagGuids = []string{"Tag GUID"}
+		defer func() { expectedNote.TagGuids = nil }()
+		notes, err := ns.FindNotes(&clinote.NoteFilter{}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"Tag GUID"}, notes[0].TagGUIDs, "Wrong tag GUIDs")
+	})
+
 	t.Run("one notebook", func(t *testing.T) {
 		filter := &clinote.NoteFilter{NotebookGUID: "Book GUID"}
 		notes, err := ns.FindNotes(filter, 0, 20)
@@ -212,6 +480,75 @@ func TestFindNotes(t *testing.T) {
 		assert.Equal(string(GUID), notes[0].GUID, "Wrong GUID")
 	})
 
+	t.Run("with tags", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		tagGUIDs := []string{"Tag GUID1", "Tag GUID2"}
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		_, err := ns.FindNotes(&clinote.NoteFilter{TagGUIDs: tagGUIDs}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(tagGUIDs, actual.TagGuids, "Wrong tags in filter")
+	})
+
+	t.Run("with order", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		_, err := ns.FindNotes(&clinote.NoteFilter{Order: clinote.NoteFilterOrderTitle}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(clinote.NoteFilterOrderTitle, actual.GetOrder(), "Wrong order in filter")
+		assert.True(actual.GetAscending(), "Titles should be sorted ascending")
+	})
+
+	t.Run("with reverse order", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		_, err := ns.FindNotes(&clinote.NoteFilter{Order: clinote.NoteFilterOrderUpdated}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.False(actual.IsSetAscending(), "Default order should be used")
+
+		_, err = ns.FindNotes(&clinote.NoteFilter{Order: clinote.NoteFilterOrderUpdated, Reverse: true}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.True(actual.GetAscending(), "Reversed order should be ascending")
+
+		_, err = ns.FindNotes(&clinote.NoteFilter{Order: clinote.NoteFilterOrderTitle, Reverse: true}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.False(actual.GetAscending(), "Reversed titles should be descending")
+	})
+
+	t.Run("with date range", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		after := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC).Unix() * 1000
+		before := time.Date(2019, 2, 1, 12, 30, 0, 0, time.UTC).Unix() * 1000
+		filter := &clinote.NoteFilter{Words: "meeting", CreatedAfter: after, CreatedBefore: before, UpdatedAfter: after}
+		_, err := ns.FindNotes(filter, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		expected := "created:20190101T000000Z -created:20190201T123000Z updated:20190101T000000Z meeting"
+		assert.Equal(expected, actual.GetWords(), "Wrong search words in filter")
+	})
+
+	t.Run("with stack", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		_, err := ns.FindNotes(&clinote.NoteFilter{Stack: "My Work", Words: "meeting"}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(`stack:"My Work" meeting`, actual.GetWords(), "Wrong search words in filter")
+	})
+
 	t.Run("return error", func(t *testing.T) {
 		filter := &clinote.NoteFilter{NotebookGUID: "Book GUID"}
 		expectedErr := errors.New("expected")
@@ -238,6 +575,272 @@ func TestGetNoteContentSDK(t *testing.T) {
 	assert.Equal(expectedContent, content, "Wrong content")
 }
 
+func TestListTagsSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("Tag GUID")
+	parent := types.GUID("Parent GUID")
+	name := "work"
+	ns := &Notestore{
+		apiToken: "token",
+		evernoteNS: &mockAPI{listTags: func(string) ([]*types.Tag, error) {
+			return []*types.Tag{&types.Tag{GUID: &guid, Name: &name, ParentGuid: &parent}}, nil
+		}},
+	}
+	tags, err := ns.ListTags()
+	assert.NoError(err, "No error should be returned")
+	assert.Equal([]*clinote.Tag{&clinote.Tag{GUID: string(guid), Name: name, ParentGUID: string(parent)}}, tags, "Wrong tags")
+}
+
+func TestGetNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("Note GUID")
+	title := "Note title"
+	updated := types.Timestamp(1500000000000)
+	expectedError := errors.New("expected error")
+
+	t.Run("without content", func(t *testing.T) {
+		var withContent bool
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{getNote: func(_ string, g types.GUID, content, _, _, _ bool) (*types.Note, error) {
+				withContent = content
+				return &types.Note{GUID: &g, Title: &title, Updated: &updated}, nil
+			}},
+		}
+		n, err := ns.GetNote(string(guid))
+		assert.NoError(err, "No error should be returned")
+		assert.False(withContent, "Content should not be requested")
+		assert.Equal(string(guid), n.GUID, "Wrong GUID")
+		assert.Equal(title, n.Title, "Wrong title")
+		assert.Equal(int64(updated), n.Updated, "Wrong updated time")
+	})
+
+	t.Run("return error", func(t *testing.T) {
+		ns := &Notestore{
+			evernoteNS: &mockAPI{getNote: func(string, types.GUID, bool, bool, bool, bool) (*types.Note, error) {
+				return nil, expectedError
+			}},
+		}
+		_, err := ns.GetNote(string(guid))
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ns := &Notestore{
+			evernoteNS: &mockAPI{getNote: func(string, types.GUID, bool, bool, bool, bool) (*types.Note, error) {
+				return nil, &edamErrors.EDAMNotFoundException{}
+			}},
+		}
+		_, err := ns.GetNote(string(guid))
+		assert.Equal(clinote.ErrNoNoteFound, err, "Wrong error returned")
+	})
+}
+
+func TestTrashSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("Trash GUID")
+	title := "Deleted note"
+
+	t.Run("list trash", func(t *testing.T) {
+		var filter *notestore.NoteFilter
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+				filter = f
+				return &notestore.NoteList{Notes: []*types.Note{&types.Note{GUID: &guid, Title: &title}}}, nil
+			}},
+		}
+		notes, err := ns.ListTrash()
+		assert.NoError(err, "No error should be returned")
+		assert.True(filter.GetInactive(), "Should search for inactive notes")
+		assert.Len(notes, 1, "Wrong number of notes")
+		assert.Equal(title, notes[0].Title, "Wrong note")
+	})
+
+	t.Run("restore cached note", func(t *testing.T) {
+		var updated *types.Note
+		noteMu.Lock()
+		cache[guid] = &types.Note{GUID: &guid, Title: &title}
+		noteMu.Unlock()
+		ns := &Notestore{
+			apiToken:   "token",
+			evernoteNS: &mockAPI{updateNote: func(_ string, n *types.Note) (*types.Note, error) { updated = n; return n, nil }},
+		}
+		err := ns.RestoreNote(string(guid))
+		assert.NoError(err, "No error should be returned")
+		assert.True(updated.GetActive(), "Note should be active")
+		assert.Equal(title, updated.GetTitle(), "Title should be set")
+		assert.Equal(guid, updated.GetGUID(), "Wrong GUID")
+	})
+
+	t.Run("restore note not in cache", func(t *testing.T) {
+		other := types.GUID("Uncached GUID")
+		var updated *types.Note
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{
+				getNote: func(_ string, g types.GUID, _, _, _, _ bool) (*types.Note, error) {
+					return &types.Note{GUID: &g, Title: &title}, nil
+				},
+				updateNote: func(_ string, n *types.Note) (*types.Note, error) { updated = n; return n, nil },
+			},
+		}
+		err := ns.RestoreNote(string(other))
+		assert.NoError(err, "No error should be returned")
+		assert.True(updated.GetActive(), "Note should be active")
+		assert.Equal(title, updated.GetTitle(), "Title should be set")
+	})
+
+	t.Run("note count", func(t *testing.T) {
+		nbGUID := types.GUID("NB GUID")
+		var filter *notestore.NoteFilter
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{findNoteCounts: func(token string, f *notestore.NoteFilter, withTrash bool) (*notestore.NoteCollectionCounts, error) {
+				filter = f
+				return &notestore.NoteCollectionCounts{NotebookCounts: map[types.GUID]int32{nbGUID: 4}}, nil
+			}},
+		}
+		count, err := ns.NoteCount(string(nbGUID))
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(int32(4), count, "Wrong count")
+		assert.Equal(nbGUID, filter.GetNotebookGuid(), "Filter should be restricted to the notebook")
+
+		count, err = ns.NoteCount("Empty")
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(int32(0), count, "Notebook without notes should have a zero count")
+	})
+
+	t.Run("expunge notebook", func(t *testing.T) {
+		var expunged types.GUID
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{expungeNB: func(token string, guid types.GUID) (int32, error) {
+				expunged = guid
+				return 0, nil
+			}},
+		}
+		err := ns.ExpungeNotebook("NB GUID")
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(types.GUID("NB GUID"), expunged, "Wrong notebook expunged")
+	})
+
+	t.Run("expunge note", func(t *testing.T) {
+		var expunged types.GUID
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{expungeNote: func(token string, guid types.GUID) (int32, error) {
+				expunged = guid
+				return 0, nil
+			}},
+		}
+		err := ns.ExpungeNote("NOTE GUID")
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(types.GUID("NOTE GUID"), expunged, "Wrong note expunged")
+	})
+
+	t.Run("list note versions", func(t *testing.T) {
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{listVersions: func(token string, guid types.GUID) ([]*notestore.NoteVersionId, error) {
+				assert.Equal(types.GUID("Note GUID"), guid, "Wrong note GUID")
+				return []*notestore.NoteVersionId{{UpdateSequenceNum: 12, Title: "Old", Updated: 1000, Saved: 2000}}, nil
+			}},
+		}
+		versions, err := ns.ListNoteVersions("Note GUID")
+		assert.NoError(err, "No error should be returned")
+		assert.Equal([]clinote.NoteVersion{{USN: 12, Title: "Old", Updated: 1000, Saved: 2000}}, versions, "Wrong versions")
+	})
+
+	t.Run("note versions unsupported", func(t *testing.T) {
+		denied := &edamErrors.EDAMUserException{ErrorCode: edamErrors.EDAMErrorCode_PERMISSION_DENIED}
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{
+				listVersions: func(string, types.GUID) ([]*notestore.NoteVersionId, error) { return nil, denied },
+				getVersion:   func(string, types.GUID, int32, bool, bool, bool) (*types.Note, error) { return nil, denied },
+			},
+		}
+		_, err := ns.ListNoteVersions("Note GUID")
+		assert.Equal(clinote.ErrVersionsUnsupported, err, "Wrong error returned")
+		_, err = ns.GetNoteVersion("Note GUID", 12)
+		assert.Equal(clinote.ErrVersionsUnsupported, err, "Wrong error returned")
+	})
+
+	t.Run("get note version", func(t *testing.T) {
+		title, content := "Old", "<en-note>Old</en-note>"
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{getVersion: func(token string, guid types.GUID, usn int32, _, _, _ bool) (*types.Note, error) {
+				assert.Equal(int32(12), usn, "Wrong USN")
+				return &types.Note{GUID: &guid, Title: &title, Content: &content}, nil
+			}},
+		}
+		n, err := ns.GetNoteVersion("Note GUID", 12)
+		assert.NoError(err, "No error should be returned")
+		assert.Equal("Note GUID", n.GUID, "Wrong GUID")
+		assert.Equal(title, n.Title, "Wrong title")
+		assert.Equal(content, n.Body, "Content should be included")
+	})
+
+	t.Run("default notebook", func(t *testing.T) {
+		nbGUID := types.GUID("NB GUID")
+		name := "Default"
+		ns := &Notestore{
+			apiToken: "token",
+			evernoteNS: &mockAPI{getDefaultNB: func(string) (*types.Notebook, error) {
+				return &types.Notebook{GUID: &nbGUID, Name: &name}, nil
+			}},
+		}
+		nb, err := ns.GetDefaultNotebook()
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(name, nb.Name, "Wrong notebook")
+		assert.Equal(string(nbGUID), nb.GUID, "Wrong notebook GUID")
+	})
+}
+
+func TestCopyNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("0b4cb6f4-5d49-4c7c-9f6e-2a0c3b7d8e9f")
+	title := "Screenshot"
+	image := []byte("image data")
+	sum := md5.Sum(image)
+	hash := hex.EncodeToString(sum[:])
+	mime := "image/png"
+	filename := "screen.png"
+	resource := func(withData bool) *types.Resource {
+		r := &types.Resource{
+			Mime:       &mime,
+			Data:       &types.Data{BodyHash: sum[:]},
+			Attributes: &types.ResourceAttributes{FileName: &filename},
+		}
+		if withData {
+			r.Data.Body = image
+		}
+		return r
+	}
+	var created *types.Note
+	ns := &Notestore{
+		apiToken: "token",
+		evernoteNS: &mockAPI{
+			getNote: func(_ string, g types.GUID, _, withData, _, _ bool) (*types.Note, error) {
+				return &types.Note{GUID: &g, Title: &title, Resources: []*types.Resource{resource(withData)}}, nil
+			},
+			getNoteContent: func(string, types.GUID) (string, error) {
+				return `<en-note><div><en-media type="image/png" hash="` + hash + `"/></div></en-note>`, nil
+			},
+			createNote: func(_ string, n *types.Note) (*types.Note, error) { created = n; return n, nil },
+		},
+	}
+	_, err := clinote.CopyNote(new(mockStore), ns, string(guid), "Copy", "")
+	assert.NoError(err, "Should not return an error")
+	if assert.NotNil(created, "Copy should be created") && assert.Len(created.Resources, 1, "Attachment should be copied") {
+		assert.Equal(image, created.Resources[0].Data.Body, "Data should be copied")
+		assert.Equal(sum[:], created.Resources[0].Data.BodyHash, "Hash should be kept")
+		assert.Contains(created.GetContent(), `hash="`+hash+`"`, "Media reference should be kept")
+	}
+}
+
 type mockAPI struct {
 	listNotebooks  func(string) ([]*types.Notebook, error)
 	updateNotebook func(string, *types.Notebook) (int32, error)
@@ -247,6 +850,52 @@ type mockAPI struct {
 	updateNote     func(string, *types.Note) (*types.Note, error)
 	findNote       func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error)
 	getNoteContent func(string, types.GUID) (string, error)
+	listTags       func(string) ([]*types.Tag, error)
+	getNote        func(string, types.GUID, bool, bool, bool, bool) (*types.Note, error)
+	getDefaultNB   func(string) (*types.Notebook, error)
+	findNoteCounts func(string, *notestore.NoteFilter, bool) (*notestore.NoteCollectionCounts, error)
+	expungeNB      func(string, types.GUID) (int32, error)
+	expungeNote    func(string, types.GUID) (int32, error)
+	listVersions   func(string, types.GUID) ([]*notestore.NoteVersionId, error)
+	getVersion     func(string, types.GUID, int32, bool, bool, bool) (*types.Note, error)
+	getNotebook    func(string, types.GUID) (*types.Notebook, error)
+	getSyncChunk   func(string, int32, int32, *notestore.SyncChunkFilter) (*notestore.SyncChunk, error)
+}
+
+func (a *mockAPI) GetFilteredSyncChunk(authenticationToken string, afterUSN int32, maxEntries int32, filter *notestore.SyncChunkFilter) (*notestore.SyncChunk, error) {
+	return a.getSyncChunk(authenticationToken, afterUSN, maxEntries, filter)
+}
+
+func (a *mockAPI) ListNoteVersions(authenticationToken string, noteGuid types.GUID) ([]*notestore.NoteVersionId, error) {
+	return a.listVersions(authenticationToken, noteGuid)
+}
+
+func (a *mockAPI) GetNoteVersion(authenticationToken string, noteGuid types.GUID, updateSequenceNum int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (*types.Note, error) {
+	return a.getVersion(authenticationToken, noteGuid, updateSequenceNum, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+}
+
+func (a *mockAPI) ExpungeNote(authenticationToken string, guid types.GUID) (int32, error) {
+	return a.expungeNote(authenticationToken, guid)
+}
+
+func (a *mockAPI) ExpungeNotebook(authenticationToken string, guid types.GUID) (int32, error) {
+	return a.expungeNB(authenticationToken, guid)
+}
+
+func (a *mockAPI) FindNoteCounts(authenticationToken string, filter *notestore.NoteFilter, withTrash bool) (*notestore.NoteCollectionCounts, error) {
+	return a.findNoteCounts(authenticationToken, filter, withTrash)
+}
+
+func (a *mockAPI) GetDefaultNotebook(authenticationToken string) (*types.Notebook, error) {
+	return a.getDefaultNB(authenticationToken)
+}
+
+func (a *mockAPI) GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (*types.Note, error) {
+	return a.getNote(authenticationToken, guid, withContent, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+}
+
+func (a *mockAPI) ListTags(apiKey string) ([]*types.Tag, error) {
+	return a.listTags(apiKey)
 }
 
 func (a *mockAPI) ListNotebooks(apiKey string) (r []*types.Notebook, err error) {
@@ -282,5 +931,45 @@ func (a *mockAPI) GetNoteContent(authenticationToken string, guid types.GUID) (r
 }
 
 func (a *mockAPI) GetNotebook(authenticationToken string, guid types.GUID) (r *types.Notebook, err error) {
-	panic("not implemented")
+	return a.getNotebook(authenticationToken, guid)
+}
+
+func TestNoteSizeLimit(t *testing.T) {
+	assert := assert.New(t)
+	body := strings.Repeat("a", 201)
+	api := &mockAPI{
+		createNote: func(string, *types.Note) (*types.Note, error) {
+			assert.Fail("Should not create the note")
+			return nil, nil
+		},
+		updateNote: func(string, *types.Note) (*types.Note, error) {
+			assert.Fail("Should not update the note")
+			return nil, nil
+		},
+	}
+
+	t.Run("refuse a new note", func(t *testing.T) {
+		ns := &Notestore{evernoteNS: api, maxNoteSize: 200}
+		err := ns.CreateNote(&clinote.Note{Title: "New", Body: body})
+		assert.True(errors.Is(err, clinote.ErrNoteTooLarge), "Should return ErrNoteTooLarge")
+		if assert.Error(err) {
+			assert.Contains(err.Error(), "the content is 201 bytes but the limit is 200 bytes", "Should include the size")
+		}
+	})
+
+	t.Run("refuse changes", func(t *testing.T) {
+		ns := &Notestore{evernoteNS: api, maxNoteSize: 200}
+		err := ns.UpdateNote(&clinote.Note{GUID: "GUID", Title: "Note", Body: body})
+		assert.True(errors.Is(err, clinote.ErrNoteTooLarge), "Should return ErrNoteTooLarge")
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		saved := false
+		ns := &Notestore{evernoteNS: &mockAPI{createNote: func(string, *types.Note) (*types.Note, error) {
+			saved = true
+			return nil, nil
+		}}}
+		assert.NoError(ns.CreateNote(&clinote.Note{Title: "New", Body: body}), "Should not return an error")
+		assert.True(saved, "Should save the note")
+	})
 }
@@ -0,0 +1,255 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/TcM1911/clinote/evernote/api"
+	"github.com/TcM1911/evernote-sdk-golang/errors"
+	"github.com/TcM1911/evernote-sdk-golang/notestore"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// DefaultMaxAttempts is the number of times a notestore call is tried
+// before the error is returned, unless the client sets another limit.
+const DefaultMaxAttempts = 3
+
+// MaxRateLimitWait is the longest wait for the rate limit before a call is
+// retried. If Evernote asks for a longer wait, ErrRateLimited is returned
+// instead.
+var MaxRateLimitWait = time.Minute
+
+var (
+	// retryBackoff is the wait before the first retry when the server
+	// doesn't say how long to wait. The wait is doubled for each retry.
+	retryBackoff = time.Second
+	// sleep is used to wait between the attempts.
+	sleep = time.Sleep
+	// retryOutput is where the notice is written before waiting to retry.
+	retryOutput io.Writer = os.Stderr
+)
+
+// retryNotestore retries the calls to the notestore API that fail with a
+// rate limit or a transient error. Other errors are returned right away.
+type retryNotestore struct {
+	api.Notestore
+	maxAttempts int
+}
+
+// retry calls the function until it succeeds, fails with an error that
+// can't be retried or the maximum number of attempts is reached. Transport
+// errors are only retried for idempotent calls, since the request may have
+// reached the server before the error.
+func (r *retryNotestore) retry(idempotent bool, call func() error) error {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= r.maxAttempts {
+			return err
+		}
+		wait, ok := retryWait(err, backoff, idempotent)
+		if !ok {
+			return err
+		}
+		if isRateLimit(err) {
+			if wait > MaxRateLimitWait {
+				return fmt.Errorf("%w, retry in %d s", ErrRateLimited, int(wait.Seconds()))
+			}
+			fmt.Fprintf(retryOutput, "Rate limited by Evernote, retrying in %s.\n", wait)
+		} else {
+			fmt.Fprintf(retryOutput, "The call to Evernote failed, retrying in %s.\n", wait)
+		}
+		sleep(wait)
+		backoff *= 2
+	}
+}
+
+// isRateLimit returns true if the error is from reaching the rate limit.
+func isRateLimit(err error) bool {
+	e, ok := err.(*errors.EDAMSystemException)
+	return ok && e.ErrorCode == errors.EDAMErrorCode_RATE_LIMIT_REACHED
+}
+
+// retryWait returns how long to wait before the call is retried and false
+// if it shouldn't be retried.
+func retryWait(err error, backoff time.Duration, idempotent bool) (time.Duration, bool) {
+	switch e := err.(type) {
+	case *errors.EDAMSystemException:
+		switch e.ErrorCode {
+		case errors.EDAMErrorCode_RATE_LIMIT_REACHED:
+			if e.RateLimitDuration != nil {
+				return time.Duration(*e.RateLimitDuration) * time.Second, true
+			}
+			return backoff, true
+		case errors.EDAMErrorCode_SHARD_UNAVAILABLE:
+			return backoff, idempotent
+		}
+	case thrift.TTransportException:
+		return backoff, idempotent
+	}
+	return 0, false
+}
+
+func (r *retryNotestore) ListNotebooks(apiKey string) (nbs []*types.Notebook, err error) {
+	err = r.retry(true, func() error {
+		nbs, err = r.Notestore.ListNotebooks(apiKey)
+		return err
+	})
+	return nbs, err
+}
+
+func (r *retryNotestore) CreateNotebook(apiKey string, notebook *types.Notebook) (nb *types.Notebook, err error) {
+	err = r.retry(false, func() error {
+		nb, err = r.Notestore.CreateNotebook(apiKey, notebook)
+		return err
+	})
+	return nb, err
+}
+
+func (r *retryNotestore) UpdateNotebook(apiKey string, notebook *types.Notebook) (usn int32, err error) {
+	err = r.retry(true, func() error {
+		usn, err = r.Notestore.UpdateNotebook(apiKey, notebook)
+		return err
+	})
+	return usn, err
+}
+
+func (r *retryNotestore) GetNotebook(authenticationToken string, guid types.GUID) (nb *types.Notebook, err error) {
+	err = r.retry(true, func() error {
+		nb, err = r.Notestore.GetNotebook(authenticationToken, guid)
+		return err
+	})
+	return nb, err
+}
+
+func (r *retryNotestore) CreateNote(apiKey string, note *types.Note) (n *types.Note, err error) {
+	err = r.retry(false, func() error {
+		n, err = r.Notestore.CreateNote(apiKey, note)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryNotestore) DeleteNote(apiKey string, guid types.GUID) (usn int32, err error) {
+	err = r.retry(true, func() error {
+		usn, err = r.Notestore.DeleteNote(apiKey, guid)
+		return err
+	})
+	return usn, err
+}
+
+func (r *retryNotestore) UpdateNote(authenticationToken string, note *types.Note) (n *types.Note, err error) {
+	err = r.retry(true, func() error {
+		n, err = r.Notestore.UpdateNote(authenticationToken, note)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryNotestore) FindNotes(apiKey string, filter *notestore.NoteFilter, offset int32, maxNumNotes int32) (list *notestore.NoteList, err error) {
+	err = r.retry(true, func() error {
+		list, err = r.Notestore.FindNotes(apiKey, filter, offset, maxNumNotes)
+		return err
+	})
+	return list, err
+}
+
+func (r *retryNotestore) GetNoteContent(authenticationToken string, guid types.GUID) (content string, err error) {
+	err = r.retry(true, func() error {
+		content, err = r.Notestore.GetNoteContent(authenticationToken, guid)
+		return err
+	})
+	return content, err
+}
+
+func (r *retryNotestore) ListTags(authenticationToken string) (tags []*types.Tag, err error) {
+	err = r.retry(true, func() error {
+		tags, err = r.Notestore.ListTags(authenticationToken)
+		return err
+	})
+	return tags, err
+}
+
+func (r *retryNotestore) GetDefaultNotebook(authenticationToken string) (nb *types.Notebook, err error) {
+	err = r.retry(true, func() error {
+		nb, err = r.Notestore.GetDefaultNotebook(authenticationToken)
+		return err
+	})
+	return nb, err
+}
+
+func (r *retryNotestore) GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (n *types.Note, err error) {
+	err = r.retry(true, func() error {
+		n, err = r.Notestore.GetNote(authenticationToken, guid, withContent, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryNotestore) FindNoteCounts(authenticationToken string, filter *notestore.NoteFilter, withTrash bool) (counts *notestore.NoteCollectionCounts, err error) {
+	err = r.retry(true, func() error {
+		counts, err = r.Notestore.FindNoteCounts(authenticationToken, filter, withTrash)
+		return err
+	})
+	return counts, err
+}
+
+func (r *retryNotestore) ExpungeNotebook(authenticationToken string, guid types.GUID) (usn int32, err error) {
+	err = r.retry(true, func() error {
+		usn, err = r.Notestore.ExpungeNotebook(authenticationToken, guid)
+		return err
+	})
+	return usn, err
+}
+
+func (r *retryNotestore) ListNoteVersions(authenticationToken string, noteGuid types.GUID) (versions []*notestore.NoteVersionId, err error) {
+	err = r.retry(true, func() error {
+		versions, err = r.Notestore.ListNoteVersions(authenticationToken, noteGuid)
+		return err
+	})
+	return versions, err
+}
+
+func (r *retryNotestore) GetNoteVersion(authenticationToken string, noteGuid types.GUID, updateSequenceNum int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (n *types.Note, err error) {
+	err = r.retry(true, func() error {
+		n, err = r.Notestore.GetNoteVersion(authenticationToken, noteGuid, updateSequenceNum, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryNotestore) ExpungeNote(authenticationToken string, guid types.GUID) (usn int32, err error) {
+	err = r.retry(true, func() error {
+		usn, err = r.Notestore.ExpungeNote(authenticationToken, guid)
+		return err
+	})
+	return usn, err
+}
+
+func (r *retryNotestore) GetFilteredSyncChunk(authenticationToken string, afterUSN int32, maxEntries int32, filter *notestore.SyncChunkFilter) (chunk *notestore.SyncChunk, err error) {
+	err = r.retry(true, func() error {
+		chunk, err = r.Notestore.GetFilteredSyncChunk(authenticationToken, afterUSN, maxEntries, filter)
+		return err
+	})
+	return chunk, err
+}
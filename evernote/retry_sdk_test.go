@@ -0,0 +1,140 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"bytes"
+	stderrors "errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/evernote-sdk-golang/errors"
+	"github.com/TcM1911/evernote-sdk-golang/notestore"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryNotestore(t *testing.T) {
+	assert := assert.New(t)
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+	notices := new(bytes.Buffer)
+	retryOutput = notices
+	defer func() { retryOutput = os.Stderr }()
+
+	rateLimit := func(seconds int32) error {
+		return &errors.EDAMSystemException{ErrorCode: errors.EDAMErrorCode_RATE_LIMIT_REACHED, RateLimitDuration: &seconds}
+	}
+	failing := func(errs ...error) (*mockAPI, *int) {
+		calls := 0
+		api := &mockAPI{
+			findNote: func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error) {
+				calls++
+				if calls <= len(errs) {
+					return nil, errs[calls-1]
+				}
+				return notestore.NewNoteList(), nil
+			},
+			createNote: func(string, *types.Note) (*types.Note, error) {
+				calls++
+				if calls <= len(errs) {
+					return nil, errs[calls-1]
+				}
+				return types.NewNote(), nil
+			},
+		}
+		return api, &calls
+	}
+
+	t.Run("honor the rate limit duration", func(t *testing.T) {
+		waits = nil
+		api, calls := failing(rateLimit(30))
+		r := &retryNotestore{Notestore: api, maxAttempts: 3}
+		_, err := r.FindNotes("token", nil, 0, 1)
+		assert.NoError(err, "Should succeed after a retry")
+		assert.Equal(2, *calls, "Wrong number of calls")
+		assert.Equal([]time.Duration{30 * time.Second}, waits, "Should wait for the rate limit duration")
+		assert.Contains(notices.String(), "retrying in 30s", "Should print a notice before waiting")
+	})
+
+	t.Run("give up on a long rate limit duration", func(t *testing.T) {
+		waits = nil
+		api, calls := failing(rateLimit(3600))
+		r := &retryNotestore{Notestore: api, maxAttempts: 3}
+		_, err := r.FindNotes("token", nil, 0, 1)
+		assert.True(stderrors.Is(err, ErrRateLimited), "Should return ErrRateLimited")
+		assert.Contains(err.Error(), "retry in 3600 s", "Should say when to retry")
+		assert.Equal(1, *calls, "Should not retry")
+		assert.Empty(waits, "Should not wait")
+	})
+
+	t.Run("back off on transient errors", func(t *testing.T) {
+		waits = nil
+		transient := thrift.NewTTransportException(thrift.TIMED_OUT, "timeout")
+		api, calls := failing(transient, transient)
+		r := &retryNotestore{Notestore: api, maxAttempts: 3}
+		_, err := r.FindNotes("token", nil, 0, 1)
+		assert.NoError(err, "Should succeed after the retries")
+		assert.Equal(3, *calls, "Wrong number of calls")
+		assert.Equal([]time.Duration{retryBackoff, 2 * retryBackoff}, waits, "Wait should be doubled")
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		waits = nil
+		expected := rateLimit(1)
+		api, calls := failing(expected, expected, expected)
+		r := &retryNotestore{Notestore: api, maxAttempts: 2}
+		_, err := r.FindNotes("token", nil, 0, 1)
+		assert.Equal(expected, err, "Should return the last error")
+		assert.Equal(2, *calls, "Wrong number of calls")
+	})
+
+	t.Run("fail fast on other errors", func(t *testing.T) {
+		waits = nil
+		for _, expected := range []error{
+			&errors.EDAMUserException{ErrorCode: errors.EDAMErrorCode_AUTH_EXPIRED},
+			&errors.EDAMSystemException{ErrorCode: errors.EDAMErrorCode_INVALID_AUTH},
+			&errors.EDAMNotFoundException{},
+		} {
+			api, calls := failing(expected)
+			r := &retryNotestore{Notestore: api, maxAttempts: 3}
+			_, err := r.FindNotes("token", nil, 0, 1)
+			assert.Equal(expected, err, "Should return the error")
+			assert.Equal(1, *calls, "Should not retry")
+		}
+		assert.Empty(waits, "Should not wait")
+	})
+
+	t.Run("only retry rate limits for creates", func(t *testing.T) {
+		transient := thrift.NewTTransportException(thrift.TIMED_OUT, "timeout")
+		api, calls := failing(transient)
+		r := &retryNotestore{Notestore: api, maxAttempts: 3}
+		_, err := r.CreateNote("token", types.NewNote())
+		assert.Equal(transient, err, "Should return the error")
+		assert.Equal(1, *calls, "Should not retry a create on a transport error")
+
+		api, calls = failing(rateLimit(1))
+		r = &retryNotestore{Notestore: api, maxAttempts: 3}
+		_, err = r.CreateNote("token", types.NewNote())
+		assert.NoError(err, "Should succeed after a retry")
+		assert.Equal(2, *calls, "Should retry a create on a rate limit")
+	})
+}
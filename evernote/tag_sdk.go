@@ -0,0 +1,31 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+)
+
+func convertTags(ts []*types.Tag) []*clinote.Tag {
+	a := make([]*clinote.Tag, len(ts))
+	for i, t := range ts {
+		a[i] = &clinote.Tag{GUID: string(t.GetGUID()), Name: t.GetName(), ParentGUID: string(t.GetParentGuid())}
+	}
+	return a
+}
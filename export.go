@@ -0,0 +1,233 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExportManifestFile is the name of the manifest file written by ExportAll.
+const ExportManifestFile = "manifest.json"
+
+// exportPace is the pause between fetching the content of two notes when
+// all the notes are exported, to stay below the API rate limit.
+var exportPace = 200 * time.Millisecond
+
+// exportManifestEntry is the manifest entry for an exported note.
+type exportManifestEntry struct {
+	// Path is the path of the exported file, relative to the export
+	// directory.
+	Path string `json:"path"`
+	// Updated is the update time of the note when it was exported.
+	Updated int64 `json:"updated"`
+}
+
+// ExportAll writes every note in all the notebooks to dir. The notes are
+// written to dir/<notebook>/<title>, where the notebook name and title are
+// made safe to use as filenames, in the same format as ExportNote. A
+// manifest mapping the note GUIDs to the exported files is kept in dir, so
+// notes that haven't been updated since the last export are skipped. A
+// note that fails to be exported doesn't stop the rest from being exported,
+// the failures are returned as one error together with the number of
+// exported notes.
+func ExportAll(db Storager, ns NotestoreClient, dir string, opts NoteOption) (int, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, err
+	}
+	manifest, err := readExportManifest(dir)
+	if err != nil {
+		return 0, err
+	}
+	bs, err := GetNotebooks(db, ns, false)
+	if err != nil {
+		return 0, err
+	}
+	ts, err := ns.ListTags()
+	if err != nil {
+		return 0, err
+	}
+	exported, fetched := 0, 0
+	var failed []string
+	var notes []*Note
+	for _, b := range bs {
+		found, err := findAllNotes(ns, &NoteFilter{NotebookGUID: b.GUID}, 0)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("notebook %q: %s", b.Name, err))
+			continue
+		}
+		for _, n := range found {
+			n.Notebook = b
+		}
+		notes = append(notes, found...)
+	}
+	paths := exportPaths(notes, opts, manifest)
+	claimed := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		claimed[strings.ToLower(path)] = true
+	}
+	for _, n := range notes {
+		path := paths[n.GUID]
+		if e, ok := manifest[n.GUID]; ok && e.Path == path && e.Updated == n.Updated && fileExists(filepath.Join(dir, path)) {
+			continue
+		}
+		if fetched > 0 {
+			time.Sleep(exportPace)
+		}
+		fetched++
+		if err = exportNoteTo(ns, n, ts, filepath.Join(dir, path), opts); err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %s", n.Title, err))
+			continue
+		}
+		// The old file is kept if another note is exported to it now.
+		if e, ok := manifest[n.GUID]; ok && e.Path != path && !claimed[strings.ToLower(e.Path)] {
+			os.Remove(filepath.Join(dir, e.Path))
+		}
+		manifest[n.GUID] = &exportManifestEntry{Path: path, Updated: n.Updated}
+		exported++
+	}
+	if err = writeExportManifest(dir, manifest); err != nil {
+		return exported, err
+	}
+	if len(failed) > 0 {
+		return exported, fmt.Errorf("failed to export all the notes: %s", strings.Join(failed, "; "))
+	}
+	return exported, nil
+}
+
+// exportPaths returns the paths, relative to the export directory, that
+// the notes are exported to by their GUID. If the filenames of notes in a
+// notebook collide, the note already exported to the path keeps it, or if
+// none of them is, the note with the lowest GUID. The GUID is added to the
+// filenames of the other notes. The paths don't depend on the order the
+// notes are listed in, so a note isn't moved to another note's file
+// between exports. Paths in the manifest of notes that aren't listed are
+// kept for them.
+func exportPaths(notes []*Note, opts NoteOption, manifest map[string]*exportManifestEntry) map[string]string {
+	listed := make(map[string]bool, len(notes))
+	base := make(map[string]string, len(notes))
+	groups := make(map[string][]*Note)
+	for _, n := range notes {
+		listed[n.GUID] = true
+		nbDir := slugifyTitle(n.Notebook.Name)
+		if nbDir == "" {
+			nbDir = n.Notebook.GUID
+		}
+		path := filepath.Join(nbDir, exportFilename(n, noteFileExtension(opts)))
+		base[n.GUID] = path
+		key := strings.ToLower(path)
+		groups[key] = append(groups[key], n)
+	}
+	reserved := make(map[string]bool)
+	for guid, e := range manifest {
+		if !listed[guid] {
+			reserved[strings.ToLower(e.Path)] = true
+		}
+	}
+	paths := make(map[string]string, len(notes))
+	for key, group := range groups {
+		owner := ""
+		if !reserved[key] {
+			owner = pathOwner(key, group, manifest)
+		}
+		for _, n := range group {
+			path := base[n.GUID]
+			if n.GUID != owner {
+				ext := filepath.Ext(path)
+				path = strings.TrimSuffix(path, ext) + "-" + n.GUID + ext
+			}
+			paths[n.GUID] = path
+		}
+	}
+	return paths
+}
+
+// pathOwner returns the GUID of the note that is exported to the path
+// without the GUID added.
+func pathOwner(key string, group []*Note, manifest map[string]*exportManifestEntry) string {
+	owner := ""
+	for _, n := range group {
+		if e, ok := manifest[n.GUID]; ok && strings.ToLower(e.Path) == key {
+			return n.GUID
+		}
+		if owner == "" || n.GUID < owner {
+			owner = n.GUID
+		}
+	}
+	return owner
+}
+
+// exportNoteTo fetches the content of the note and writes the note to the
+// file at path. The note's tag names are looked up from the user's tags. The
+// content isn't cached, so exporting all notes doesn't copy them into the
+// database.
+func exportNoteTo(ns NotestoreClient, n *Note, ts []*Tag, path string, opts NoteOption) error {
+	content, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return err
+	}
+	if err = setNoteContent(MarkdownConverter{}, n, content, opts); err != nil {
+		return err
+	}
+	setTagNames(n, ts)
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err = WriteNote(f, n, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readExportManifest(dir string) (map[string]*exportManifestEntry, error) {
+	manifest := make(map[string]*exportManifestEntry)
+	data, err := ioutil.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeExportManifest(dir string, manifest map[string]*exportManifestEntry) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ExportManifestFile), data, 0600)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
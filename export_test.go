@@ -0,0 +1,175 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportAll(t *testing.T) {
+	assert := assert.New(t)
+	pace := exportPace
+	exportPace = 0
+	defer func() { exportPace = pace }()
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+		saveCachedContent: func(string, int64, string) error {
+			t.Error("The exported content should not be cached")
+			return nil
+		},
+	}
+	books := []*Notebook{&Notebook{Name: "Work", GUID: "NB1"}, &Notebook{Name: "Personal/Home", GUID: "NB2"}}
+	updated := map[string]int64{"N1": 1, "N2": 1, "N3": 1}
+	newNS := func(fetched *[]string) *mockNS {
+		ns := new(mockNS)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return books, nil }
+		ns.listTags = func() ([]*Tag, error) { return []*Tag{&Tag{Name: "todo", GUID: "T1"}}, nil }
+		ns.findNotes = func(f *NoteFilter, o, max int) ([]*Note, error) {
+			if f.NotebookGUID == "NB1" {
+				return []*Note{
+					&Note{Title: "Meeting: notes", GUID: "N1", Updated: updated["N1"], TagGUIDs: []string{"T1"}},
+					&Note{Title: "Meeting/notes", GUID: "N2", Updated: updated["N2"]},
+				}, nil
+			}
+			return []*Note{&Note{Title: "Shopping", GUID: "N3", Updated: updated["N3"]}}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) {
+			*fetched = append(*fetched, guid)
+			return "<en-note><p>Content " + guid + "</p></en-note>", nil
+		}
+		return ns
+	}
+	dir, err := ioutil.TempDir("", "clinote-export-all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("export all notes", func(t *testing.T) {
+		var fetched []string
+		n, err := ExportAll(store, newNS(&fetched), dir, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(3, n, "Wrong number of exported notes")
+		data, err := ioutil.ReadFile(filepath.Join(dir, "Work", "Meeting-notes.md"))
+		assert.NoError(err, "Note should be exported")
+		assert.Equal("---\ntitle: Meeting: notes\nnotebook: Work\ntags: todo\nupdated: "+noteTime(1).Format(headTimeFormat)+"\n---\nContent N1\n", string(data))
+		_, err = os.Stat(filepath.Join(dir, "Work", "Meeting-notes-N2.md"))
+		assert.NoError(err, "Note with the same filename should have the GUID added")
+		_, err = os.Stat(filepath.Join(dir, "Personal-Home", "Shopping.md"))
+		assert.NoError(err, "Note should be exported to the notebook directory")
+		_, err = os.Stat(filepath.Join(dir, ExportManifestFile))
+		assert.NoError(err, "Manifest should be written")
+	})
+
+	t.Run("skip unchanged notes", func(t *testing.T) {
+		var fetched []string
+		updated["N3"] = 2
+		n, err := ExportAll(store, newNS(&fetched), dir, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(1, n, "Only the updated note should be exported")
+		assert.Equal([]string{"N3"}, fetched, "Only the updated note should be fetched")
+	})
+
+	t.Run("export deleted files again", func(t *testing.T) {
+		var fetched []string
+		assert.NoError(os.Remove(filepath.Join(dir, "Personal-Home", "Shopping.md")))
+		n, err := ExportAll(store, newNS(&fetched), dir, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(1, n, "The missing file should be exported")
+	})
+
+	t.Run("continue after failed note", func(t *testing.T) {
+		var fetched []string
+		rawDir := filepath.Join(dir, "raw")
+		ns := newNS(&fetched)
+		ns.getNoteContent = func(guid string) (string, error) {
+			if guid == "N1" {
+				return "", errors.New("expected error")
+			}
+			return "<en-note><p>Content</p></en-note>", nil
+		}
+		n, err := ExportAll(store, ns, rawDir, RawNote)
+		assert.Error(err, "Should return an error")
+		assert.Contains(err.Error(), "Meeting: notes", "Failed note should be in the error")
+		assert.Equal(2, n, "The other notes should be exported")
+		_, err = os.Stat(filepath.Join(rawDir, "Personal-Home", "Shopping.xml"))
+		assert.NoError(err, "Raw note should be exported")
+	})
+
+	t.Run("keep colliding notes apart between exports", func(t *testing.T) {
+		todoDir := filepath.Join(dir, "todo")
+		notes := []*Note{
+			&Note{Title: "todo", GUID: "NA", Updated: 1},
+			&Note{Title: "todo", GUID: "NB", Updated: 1},
+		}
+		ns := new(mockNS)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return books[:1], nil }
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.findNotes = func(f *NoteFilter, o, max int) ([]*Note, error) {
+			listed := make([]*Note, len(notes))
+			for i, n := range notes {
+				c := *n
+				listed[i] = &c
+			}
+			return listed, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) {
+			return "<en-note><p>Content " + guid + "</p></en-note>", nil
+		}
+		content := func(name string) string {
+			data, _ := ioutil.ReadFile(filepath.Join(todoDir, "Work", name))
+			return string(data)
+		}
+
+		_, err := ExportAll(store, ns, todoDir, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(content("todo.md"), "Content NA")
+		assert.Contains(content("todo-NB.md"), "Content NB")
+
+		// The notes are listed in the other order and the note with the
+		// path is renamed, so the other note takes over the path.
+		notes = []*Note{
+			&Note{Title: "todo", GUID: "NB", Updated: 2},
+			&Note{Title: "done", GUID: "NA", Updated: 2},
+		}
+		_, err = ExportAll(store, ns, todoDir, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(content("todo.md"), "Content NB", "The file taken over should not be removed")
+		assert.Contains(content("done.md"), "Content NA")
+		_, err = os.Stat(filepath.Join(todoDir, "Work", "todo-NB.md"))
+		assert.True(os.IsNotExist(err), "The old file should be removed")
+
+		// A new colliding note listed first doesn't take the path.
+		notes = []*Note{
+			&Note{Title: "todo", GUID: "N0", Updated: 1},
+			&Note{Title: "todo", GUID: "NB", Updated: 2},
+			&Note{Title: "done", GUID: "NA", Updated: 2},
+		}
+		_, err = ExportAll(store, ns, todoDir, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(content("todo.md"), "Content NB", "The note should keep its path")
+		assert.Contains(content("todo-N0.md"), "Content N0")
+	})
+}
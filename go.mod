@@ -4,12 +4,11 @@ go 1.13
 
 require (
 	github.com/TcM1911/evernote-sdk-golang v0.0.0-20180506223349-0986bec6d284
-	github.com/apache/thrift v0.0.0-20161210005454-c3a3f653b66b // indirect
+	github.com/apache/thrift v0.0.0-20161210005454-c3a3f653b66b
 	github.com/boltdb/bolt v1.3.2-0.20180302180052-fd01fc79c553
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/mattn/go-runewidth v0.0.3 // indirect
-	github.com/mattn/godown v0.0.0-20180312012330-2e9e17e0ea51
 	github.com/mrjones/oauth v0.0.0-20161024000904-88427e754deb
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	github.com/olekukonko/tablewriter v0.0.0-20180506121414-d4647c9c7a84
@@ -20,7 +19,7 @@ require (
 	github.com/spf13/cobra v0.0.0-20161116132053-9495bc009a56
 	github.com/spf13/pflag v0.0.0-20161024131444-5ccb023bc27d // indirect
 	github.com/stretchr/testify v1.1.4-0.20160305165446-6fe211e49392
-	golang.org/x/net v0.0.0-20180511174649-2491c5de3490 // indirect
+	golang.org/x/net v0.0.0-20180511174649-2491c5de3490
 	golang.org/x/sys v0.0.0-20200321134203-328b4cd54aae // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 )
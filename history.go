@@ -0,0 +1,67 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import "errors"
+
+// ErrVersionsUnsupported is returned if the account doesn't keep versions
+// of notes, for example because of the account's tier.
+var ErrVersionsUnsupported = errors.New("the account doesn't support note versions")
+
+// NoteVersion is a version of a note saved by the notestore.
+type NoteVersion struct {
+	// USN is the update sequence number of the version.
+	USN int32
+	// Title is the note title in the version.
+	Title string
+	// Updated is when the note was modified in the version, in
+	// milliseconds since the epoch.
+	Updated int64
+	// Saved is when the version was saved by the notestore, in
+	// milliseconds since the epoch.
+	Saved int64
+}
+
+// ListNoteVersions returns the saved versions of the note with the title.
+func ListNoteVersions(db Storager, ns NotestoreClient, title string) ([]NoteVersion, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	return ns.ListNoteVersions(n.GUID)
+}
+
+// RestoreNoteVersion replaces the title and content of the note with the
+// ones in the version with the update sequence number. The notebook, tags
+// and attributes of the note are left as they are. The note as it was
+// before is kept as a version by the notestore.
+func RestoreNoteVersion(db Storager, ns NotestoreClient, title string, usn int32) (*Note, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	v, err := ns.GetNoteVersion(n.GUID, usn)
+	if err != nil {
+		return nil, err
+	}
+	restored := &Note{GUID: n.GUID, Title: v.Title, Body: v.Body}
+	if err = ns.UpdateNote(restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
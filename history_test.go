@@ -0,0 +1,88 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteVersions(t *testing.T) {
+	assert := assert.New(t)
+	note := &Note{Title: "Note", GUID: "Note GUID", Notebook: &Notebook{GUID: "Notebook GUID"}, Tags: []string{"work"}}
+	store := new(mockStore)
+
+	t.Run("list versions", func(t *testing.T) {
+		expected := []NoteVersion{{USN: 12, Title: "Note", Updated: 1000, Saved: 2000}}
+		ns := nsWithNote(note)
+		ns.listVersions = func(guid string) ([]NoteVersion, error) {
+			assert.Equal(note.GUID, guid, "Wrong note GUID")
+			return expected, nil
+		}
+		versions, err := ListNoteVersions(store, ns, "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, versions, "Wrong versions returned")
+	})
+
+	t.Run("versions unsupported", func(t *testing.T) {
+		ns := nsWithNote(note)
+		ns.listVersions = func(string) ([]NoteVersion, error) { return nil, ErrVersionsUnsupported }
+		_, err := ListNoteVersions(store, ns, "Note")
+		assert.Equal(ErrVersionsUnsupported, err, "Wrong error returned")
+	})
+
+	t.Run("restore version", func(t *testing.T) {
+		ns := nsWithNote(note)
+		ns.getVersion = func(guid string, usn int32) (*Note, error) {
+			assert.Equal(note.GUID, guid, "Wrong note GUID")
+			assert.Equal(int32(12), usn, "Wrong USN")
+			return &Note{Title: "Old title", Body: "<en-note>Old</en-note>"}, nil
+		}
+		var updated *Note
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		n, err := RestoreNoteVersion(store, ns, "Note", 12)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(updated, n, "Should return the saved note")
+		if assert.NotNil(updated, "Note should be updated") {
+			assert.Equal(note.GUID, updated.GUID, "Wrong note updated")
+			assert.Equal("Old title", updated.Title, "Title should be restored")
+			assert.Equal("<en-note>Old</en-note>", updated.Body, "Content should be restored")
+			assert.Nil(updated.Notebook, "Notebook should be left as is")
+			assert.Nil(updated.Tags, "Tags should be left as is")
+		}
+	})
+
+	t.Run("return error from GetNoteVersion", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := nsWithNote(note)
+		ns.getVersion = func(string, int32) (*Note, error) { return nil, expectedErr }
+		ns.updateNote = func(*Note) error {
+			t.Error("Should not update the note")
+			return nil
+		}
+		_, err := RestoreNoteVersion(store, ns, "Note", 12)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+
+	t.Run("return error for missing note", func(t *testing.T) {
+		_, err := ListNoteVersions(store, nsWithNote(note), "Missing")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+}
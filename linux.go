@@ -20,10 +20,16 @@
 package clinote
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 )
 
+// defaultOpener is the command used to open links.
+const defaultOpener = "xdg-open"
+
 var (
 	// configDir is the folder used to store the configuration files.
 	configDir string
@@ -54,3 +60,39 @@ func init() {
 		panic("can't locate user's xdg cache folder.")
 	}
 }
+
+// hasLinkHandler returns false if xdg-mime reports that no application
+// handles links with the scheme. If it can't be asked, true is returned
+// and the opener is left to fail.
+func hasLinkHandler(scheme string) bool {
+	path, err := exec.LookPath("xdg-mime")
+	if err != nil {
+		return true
+	}
+	out, err := exec.Command(path, "query", "default", "x-scheme-handler/"+scheme).Output()
+	return err != nil || len(bytes.TrimSpace(out)) > 0
+}
+
+// ReadImage reads the PNG image on the clipboard with wl-paste on Wayland
+// and xclip otherwise.
+func (SystemClipboard) ReadImage() ([]byte, error) {
+	args := []string{"xclip", "-selection", "clipboard", "-target", "image/png", "-out"}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		args = []string{"wl-paste", "--no-newline", "--type", "image/png"}
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is needed to read the clipboard", ErrClipboardNotSupported, args[0])
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoClipboardImage, msg)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoClipboardImage, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ConvertEmoji replaces the known :shortcode: sequences in the text of the
+// ENML body with their emoji. Text in code spans and code blocks is left
+// as is. Unknown shortcodes aren't changed.
+func ConvertEmoji(body []byte) []byte {
+	var out bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(body))
+	// code counts the open code and pre elements and codeDivs the open
+	// divs of the code block the text is in.
+	code, codeDivs := 0, 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		switch tt {
+		case html.TextToken:
+			if code == 0 && codeDivs == 0 {
+				out.WriteString(replaceShortcodes(string(raw)))
+				continue
+			}
+		case html.StartTagToken:
+			name, hasAttr := z.TagName()
+			switch {
+			case string(name) == "code" || string(name) == "pre":
+				code++
+			case string(name) != "div":
+			case codeDivs > 0:
+				codeDivs++
+			case hasAttr && isCodeBlockStyle(z):
+				codeDivs = 1
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "code", "pre":
+				if code > 0 {
+					code--
+				}
+			case "div":
+				if codeDivs > 0 {
+					codeDivs--
+				}
+			}
+		}
+		out.Write(raw)
+	}
+	return out.Bytes()
+}
+
+// isCodeBlockStyle returns true if the style attribute of the current tag
+// marks it as a code block.
+func isCodeBlockStyle(z *html.Tokenizer) bool {
+	for {
+		key, val, more := z.TagAttr()
+		if string(key) == "style" {
+			for _, prop := range strings.Split(string(val), ";") {
+				kv := strings.SplitN(prop, ":", 2)
+				if len(kv) == 2 && strings.TrimSpace(kv[0]) == codeBlockProperty {
+					return strings.EqualFold(strings.TrimSpace(kv[1]), "true")
+				}
+			}
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// replaceShortcodes replaces the known shortcodes in the text. A colon that
+// doesn't start a known shortcode can still end one, so "a:b:smile:" has
+// the smile replaced.
+func replaceShortcodes(text string) string {
+	if strings.Count(text, ":") < 2 {
+		return text
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(text, ':')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start+1:], ':')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		emoji, ok := emojiCodes[text[start+1:end]]
+		if !ok {
+			b.WriteString(text[:end])
+			text = text[end:]
+			continue
+		}
+		b.WriteString(text[:start])
+		b.WriteString(emoji)
+		text = text[end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
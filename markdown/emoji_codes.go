@@ -0,0 +1,594 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+// emojiCodes maps the shortcodes to their emoji. The names follow the ones
+// used by GitHub.
+var emojiCodes = map[string]string{
+	"+1":                           "\U0001F44D",
+	"-1":                           "\U0001F44E",
+	"100":                          "\U0001F4AF",
+	"1st_place_medal":              "\U0001F947",
+	"2nd_place_medal":              "\U0001F948",
+	"3rd_place_medal":              "\U0001F949",
+	"airplane":                     "\u2708\uFE0F",
+	"alarm_clock":                  "\u23F0",
+	"alien":                        "\U0001F47D",
+	"anchor":                       "\u2693",
+	"angel":                        "\U0001F47C",
+	"anger":                        "\U0001F4A2",
+	"angry":                        "\U0001F620",
+	"anguished":                    "\U0001F627",
+	"ant":                          "\U0001F41C",
+	"apple":                        "\U0001F34E",
+	"arrow_down":                   "\u2B07\uFE0F",
+	"arrow_left":                   "\u2B05\uFE0F",
+	"arrow_right":                  "\u27A1\uFE0F",
+	"arrow_up":                     "\u2B06\uFE0F",
+	"arrows_clockwise":             "\U0001F501",
+	"arrows_counterclockwise":      "\U0001F504",
+	"art":                          "\U0001F3A8",
+	"astonished":                   "\U0001F632",
+	"athletic_shoe":                "\U0001F45F",
+	"avocado":                      "\U0001F951",
+	"baby":                         "\U0001F476",
+	"baby_chick":                   "\U0001F424",
+	"back":                         "\U0001F519",
+	"bacon":                        "\U0001F953",
+	"balloon":                      "\U0001F388",
+	"ballot_box_with_check":        "\u2611\uFE0F",
+	"banana":                       "\U0001F34C",
+	"bangbang":                     "\u203C\uFE0F",
+	"bar_chart":                    "\U0001F4CA",
+	"baseball":                     "\u26BE",
+	"basketball":                   "\U0001F3C0",
+	"bathtub":                      "\U0001F6C1",
+	"battery":                      "\U0001F50B",
+	"beach_umbrella":               "\u26F1\uFE0F",
+	"bear":                         "\U0001F43B",
+	"bed":                          "\U0001F6CF\uFE0F",
+	"bee":                          "\U0001F41D",
+	"beer":                         "\U0001F37A",
+	"beers":                        "\U0001F37B",
+	"beetle":                       "\U0001F41E",
+	"bell":                         "\U0001F514",
+	"bike":                         "\U0001F6B2",
+	"bird":                         "\U0001F426",
+	"birthday":                     "\U0001F382",
+	"black_circle":                 "\u26AB",
+	"black_flag":                   "\U0001F3F4",
+	"black_heart":                  "\U0001F5A4",
+	"black_large_square":           "\u2B1B",
+	"blue_book":                    "\U0001F4D8",
+	"blue_heart":                   "\U0001F499",
+	"blush":                        "\U0001F60A",
+	"boat":                         "\u26F5",
+	"bomb":                         "\U0001F4A3",
+	"book":                         "\U0001F4D6",
+	"bookmark":                     "\U0001F516",
+	"bookmark_tabs":                "\U0001F4D1",
+	"books":                        "\U0001F4DA",
+	"boom":                         "\U0001F4A5",
+	"bouquet":                      "\U0001F490",
+	"bow":                          "\U0001F647",
+	"bowling":                      "\U0001F3B3",
+	"boy":                          "\U0001F466",
+	"brain":                        "\U0001F9E0",
+	"bread":                        "\U0001F35E",
+	"briefcase":                    "\U0001F4BC",
+	"broken_heart":                 "\U0001F494",
+	"bug":                          "\U0001F41B",
+	"bulb":                         "\U0001F4A1",
+	"burrito":                      "\U0001F32F",
+	"bus":                          "\U0001F68C",
+	"butterfly":                    "\U0001F98B",
+	"cactus":                       "\U0001F335",
+	"cake":                         "\U0001F370",
+	"calendar":                     "\U0001F4C6",
+	"call_me_hand":                 "\U0001F919",
+	"calling":                      "\U0001F4F2",
+	"camel":                        "\U0001F42B",
+	"camera":                       "\U0001F4F7",
+	"candle":                       "\U0001F56F\uFE0F",
+	"candy":                        "\U0001F36C",
+	"car":                          "\U0001F697",
+	"card_index":                   "\U0001F4C7",
+	"carrot":                       "\U0001F955",
+	"cat":                          "\U0001F431",
+	"cd":                           "\U0001F4BF",
+	"champagne":                    "\U0001F37E",
+	"chart_with_downwards_trend":   "\U0001F4C9",
+	"chart_with_upwards_trend":     "\U0001F4C8",
+	"checkered":                    "\U0001F3C1",
+	"checkered_flag":               "\U0001F3C1",
+	"cheese":                       "\U0001F9C0",
+	"cherries":                     "\U0001F352",
+	"cherry_blossom":               "\U0001F338",
+	"chicken":                      "\U0001F414",
+	"chocolate_bar":                "\U0001F36B",
+	"christmas_tree":               "\U0001F384",
+	"clap":                         "\U0001F44F",
+	"clapper":                      "\U0001F3AC",
+	"clipboard":                    "\U0001F4CB",
+	"clock1":                       "\U0001F550",
+	"clock12":                      "\U0001F55B",
+	"closed_book":                  "\U0001F4D5",
+	"cloud":                        "\u2601\uFE0F",
+	"clown_face":                   "\U0001F921",
+	"cocktail":                     "\U0001F378",
+	"coffee":                       "\u2615",
+	"cold_sweat":                   "\U0001F630",
+	"collision":                    "\U0001F4A5",
+	"computer":                     "\U0001F4BB",
+	"computer_mouse":               "\U0001F5B1\uFE0F",
+	"confetti_ball":                "\U0001F38A",
+	"confounded":                   "\U0001F616",
+	"confused":                     "\U0001F615",
+	"construction":                 "\U0001F6A7",
+	"construction_site":            "\U0001F3D7\uFE0F",
+	"construction_worker":          "\U0001F477",
+	"cookie":                       "\U0001F36A",
+	"cool":                         "\U0001F192",
+	"cop":                          "\U0001F46E",
+	"copyright":                    "\u00A9\uFE0F",
+	"corn":                         "\U0001F33D",
+	"couple":                       "\U0001F46B",
+	"cow":                          "\U0001F42E",
+	"cowboy_hat_face":              "\U0001F920",
+	"crab":                         "\U0001F980",
+	"credit_card":                  "\U0001F4B3",
+	"crescent_moon":                "\U0001F319",
+	"crossed_fingers":              "\U0001F91E",
+	"crown":                        "\U0001F451",
+	"cry":                          "\U0001F622",
+	"crying_cat_face":              "\U0001F63F",
+	"crystal_ball":                 "\U0001F52E",
+	"cupid":                        "\U0001F498",
+	"dancer":                       "\U0001F483",
+	"dart":                         "\U0001F3AF",
+	"dash":                         "\U0001F4A8",
+	"date":                         "\U0001F4C5",
+	"deciduous_tree":               "\U0001F333",
+	"desktop_computer":             "\U0001F5A5\uFE0F",
+	"disappointed":                 "\U0001F61E",
+	"dizzy":                        "\U0001F4AB",
+	"dizzy_face":                   "\U0001F635",
+	"dog":                          "\U0001F436",
+	"dollar":                       "\U0001F4B5",
+	"dolphin":                      "\U0001F42C",
+	"door":                         "\U0001F6AA",
+	"doughnut":                     "\U0001F369",
+	"dragon":                       "\U0001F409",
+	"dress":                        "\U0001F457",
+	"drooling_face":                "\U0001F924",
+	"droplet":                      "\U0001F4A7",
+	"duck":                         "\U0001F986",
+	"dvd":                          "\U0001F4C0",
+	"eagle":                        "\U0001F985",
+	"earth_africa":                 "\U0001F30D",
+	"earth_americas":               "\U0001F30E",
+	"earth_asia":                   "\U0001F30F",
+	"egg":                          "\U0001F95A",
+	"eggplant":                     "\U0001F346",
+	"eight":                        "8\uFE0F\u20E3",
+	"electric_plug":                "\U0001F50C",
+	"elephant":                     "\U0001F418",
+	"email":                        "\u2709\uFE0F",
+	"end":                          "\U0001F51A",
+	"envelope":                     "\u2709\uFE0F",
+	"euro":                         "\U0001F4B6",
+	"evergreen_tree":               "\U0001F332",
+	"exclamation":                  "\u2757",
+	"expressionless":               "\U0001F611",
+	"eye":                          "\U0001F441\uFE0F",
+	"eyeglasses":                   "\U0001F453",
+	"eyes":                         "\U0001F440",
+	"face_with_head_bandage":       "\U0001F915",
+	"face_with_thermometer":        "\U0001F912",
+	"facepalm":                     "\U0001F926",
+	"facepunch":                    "\U0001F44A",
+	"fallen_leaf":                  "\U0001F342",
+	"family":                       "\U0001F46A",
+	"fearful":                      "\U0001F628",
+	"file_folder":                  "\U0001F4C1",
+	"fire":                         "\U0001F525",
+	"fireworks":                    "\U0001F386",
+	"fish":                         "\U0001F41F",
+	"fist":                         "\u270A",
+	"five":                         "5\uFE0F\u20E3",
+	"flashlight":                   "\U0001F526",
+	"floppy_disk":                  "\U0001F4BE",
+	"flushed":                      "\U0001F633",
+	"football":                     "\U0001F3C8",
+	"footprints":                   "\U0001F463",
+	"fork_and_knife":               "\U0001F374",
+	"four":                         "4\uFE0F\u20E3",
+	"four_leaf_clover":             "\U0001F340",
+	"fox_face":                     "\U0001F98A",
+	"free":                         "\U0001F193",
+	"fries":                        "\U0001F35F",
+	"frog":                         "\U0001F438",
+	"frowning":                     "\U0001F626",
+	"frowning_face":                "\u2639\uFE0F",
+	"fuelpump":                     "\u26FD",
+	"full_moon":                    "\U0001F315",
+	"game_die":                     "\U0001F3B2",
+	"gear":                         "\u2699\uFE0F",
+	"gem":                          "\U0001F48E",
+	"ghost":                        "\U0001F47B",
+	"gift":                         "\U0001F381",
+	"gift_heart":                   "\U0001F49D",
+	"girl":                         "\U0001F467",
+	"globe_with_meridians":         "\U0001F310",
+	"golf":                         "\u26F3",
+	"grapes":                       "\U0001F347",
+	"green_apple":                  "\U0001F34F",
+	"green_book":                   "\U0001F4D7",
+	"green_heart":                  "\U0001F49A",
+	"grey_exclamation":             "\u2755",
+	"grey_question":                "\u2754",
+	"grimacing":                    "\U0001F62C",
+	"grin":                         "\U0001F601",
+	"grinning":                     "\U0001F600",
+	"guitar":                       "\U0001F3B8",
+	"hamburger":                    "\U0001F354",
+	"hammer":                       "\U0001F528",
+	"hammer_and_wrench":            "\U0001F6E0\uFE0F",
+	"hamster":                      "\U0001F439",
+	"hand":                         "\u270B",
+	"handbag":                      "\U0001F45C",
+	"handshake":                    "\U0001F91D",
+	"hankey":                       "\U0001F4A9",
+	"hash":                         "#\uFE0F\u20E3",
+	"headphones":                   "\U0001F3A7",
+	"hear_no_evil":                 "\U0001F649",
+	"heart":                        "\u2764\uFE0F",
+	"heart_eyes":                   "\U0001F60D",
+	"heart_eyes_cat":               "\U0001F63B",
+	"heartbeat":                    "\U0001F493",
+	"heartpulse":                   "\U0001F497",
+	"heavy_check_mark":             "\u2714\uFE0F",
+	"heavy_division_sign":          "\u2797",
+	"heavy_exclamation_mark":       "\u2757",
+	"heavy_minus_sign":             "\u2796",
+	"heavy_multiplication_x":       "\u2716\uFE0F",
+	"heavy_plus_sign":              "\u2795",
+	"helicopter":                   "\U0001F681",
+	"hibiscus":                     "\U0001F33A",
+	"honeybee":                     "\U0001F41D",
+	"horse":                        "\U0001F434",
+	"hospital":                     "\U0001F3E5",
+	"hot_pepper":                   "\U0001F336",
+	"hotdog":                       "\U0001F32D",
+	"hotel":                        "\U0001F3E8",
+	"hourglass":                    "\u231B",
+	"hourglass_flowing_sand":       "\u23F3",
+	"house":                        "\U0001F3E0",
+	"hugs":                         "\U0001F917",
+	"hushed":                       "\U0001F62F",
+	"icecream":                     "\U0001F366",
+	"imp":                          "\U0001F47F",
+	"inbox_tray":                   "\U0001F4E5",
+	"incoming_envelope":            "\U0001F4E8",
+	"information_source":           "\u2139\uFE0F",
+	"innocent":                     "\U0001F607",
+	"interrobang":                  "\u2049\uFE0F",
+	"iphone":                       "\U0001F4F1",
+	"jack_o_lantern":               "\U0001F383",
+	"jeans":                        "\U0001F456",
+	"jigsaw":                       "\U0001F9E9",
+	"joy":                          "\U0001F602",
+	"joy_cat":                      "\U0001F639",
+	"key":                          "\U0001F511",
+	"keyboard":                     "\u2328\uFE0F",
+	"keycap_ten":                   "\U0001F51F",
+	"kiss":                         "\U0001F48B",
+	"kissing":                      "\U0001F617",
+	"kissing_closed_eyes":          "\U0001F61A",
+	"kissing_heart":                "\U0001F618",
+	"kissing_smiling_eyes":         "\U0001F619",
+	"knife":                        "\U0001F52A",
+	"koala":                        "\U0001F428",
+	"label":                        "\U0001F3F7\uFE0F",
+	"large_blue_circle":            "\U0001F535",
+	"large_blue_diamond":           "\U0001F537",
+	"large_orange_diamond":         "\U0001F536",
+	"laughing":                     "\U0001F606",
+	"ledger":                       "\U0001F4D2",
+	"lemon":                        "\U0001F34B",
+	"link":                         "\U0001F517",
+	"link_chain":                   "\U0001F517",
+	"lion":                         "\U0001F981",
+	"lips":                         "\U0001F444",
+	"lipstick":                     "\U0001F484",
+	"lock":                         "\U0001F512",
+	"loud_sound":                   "\U0001F50A",
+	"loudspeaker":                  "\U0001F4E2",
+	"lying_face":                   "\U0001F925",
+	"mag":                          "\U0001F50D",
+	"mag_right":                    "\U0001F50E",
+	"mailbox":                      "\U0001F4EB",
+	"man":                          "\U0001F468",
+	"maple_leaf":                   "\U0001F341",
+	"mask":                         "\U0001F637",
+	"medal_sports":                 "\U0001F3C5",
+	"mega":                         "\U0001F4E3",
+	"memo":                         "\U0001F4DD",
+	"metal":                        "\U0001F918",
+	"microphone":                   "\U0001F3A4",
+	"microscope":                   "\U0001F52C",
+	"milk_glass":                   "\U0001F95B",
+	"money_mouth_face":             "\U0001F911",
+	"money_with_wings":             "\U0001F4B8",
+	"moneybag":                     "\U0001F4B0",
+	"monkey":                       "\U0001F412",
+	"monkey_face":                  "\U0001F435",
+	"mortar_board":                 "\U0001F393",
+	"motorcycle":                   "\U0001F3CD",
+	"mountain":                     "\u26F0\uFE0F",
+	"mouse":                        "\U0001F42D",
+	"movie_camera":                 "\U0001F3A5",
+	"muscle":                       "\U0001F4AA",
+	"mushroom":                     "\U0001F344",
+	"musical_note":                 "\U0001F3B5",
+	"mute":                         "\U0001F507",
+	"nauseated_face":               "\U0001F922",
+	"necktie":                      "\U0001F454",
+	"negative_squared_cross_mark":  "\u274E",
+	"nerd_face":                    "\U0001F913",
+	"neutral_face":                 "\U0001F610",
+	"new":                          "\U0001F195",
+	"new_moon":                     "\U0001F311",
+	"newspaper":                    "\U0001F4F0",
+	"ng":                           "\U0001F196",
+	"nine":                         "9\uFE0F\u20E3",
+	"no_bell":                      "\U0001F515",
+	"no_entry":                     "\u26D4",
+	"no_entry_sign":                "\U0001F6AB",
+	"no_good":                      "\U0001F645",
+	"no_mouth":                     "\U0001F636",
+	"notebook":                     "\U0001F4D3",
+	"notes":                        "\U0001F3B6",
+	"nut_and_bolt":                 "\U0001F529",
+	"ocean":                        "\U0001F30A",
+	"octopus":                      "\U0001F419",
+	"office":                       "\U0001F3E2",
+	"ok":                           "\U0001F197",
+	"ok_hand":                      "\U0001F44C",
+	"ok_woman":                     "\U0001F646",
+	"older_man":                    "\U0001F474",
+	"older_woman":                  "\U0001F475",
+	"on":                           "\U0001F51B",
+	"one":                          "1\uFE0F\u20E3",
+	"open_book":                    "\U0001F4D6",
+	"open_file_folder":             "\U0001F4C2",
+	"open_hands":                   "\U0001F450",
+	"open_mouth":                   "\U0001F62E",
+	"orange_book":                  "\U0001F4D9",
+	"orange_heart":                 "\U0001F9E1",
+	"outbox_tray":                  "\U0001F4E4",
+	"owl":                          "\U0001F989",
+	"package":                      "\U0001F4E6",
+	"page_facing_up":               "\U0001F4C4",
+	"page_with_curl":               "\U0001F4C3",
+	"palm_tree":                    "\U0001F334",
+	"panda_face":                   "\U0001F43C",
+	"paperclip":                    "\U0001F4CE",
+	"partly_sunny":                 "\u26C5",
+	"paw_prints":                   "\U0001F43E",
+	"peach":                        "\U0001F351",
+	"pear":                         "\U0001F350",
+	"pen":                          "\U0001F58A\uFE0F",
+	"pencil":                       "\U0001F4DD",
+	"pencil2":                      "\u270F\uFE0F",
+	"penguin":                      "\U0001F427",
+	"pensive":                      "\U0001F614",
+	"performing_arts":              "\U0001F3AD",
+	"persevere":                    "\U0001F623",
+	"phone":                        "\u260E\uFE0F",
+	"pig":                          "\U0001F437",
+	"pill":                         "\U0001F48A",
+	"pineapple":                    "\U0001F34D",
+	"pizza":                        "\U0001F355",
+	"point_down":                   "\U0001F447",
+	"point_left":                   "\U0001F448",
+	"point_right":                  "\U0001F449",
+	"point_up":                     "\u261D\uFE0F",
+	"point_up_2":                   "\U0001F446",
+	"poop":                         "\U0001F4A9",
+	"popcorn":                      "\U0001F37F",
+	"potato":                       "\U0001F954",
+	"pout":                         "\U0001F621",
+	"pray":                         "\U0001F64F",
+	"princess":                     "\U0001F478",
+	"printer":                      "\U0001F5A8\uFE0F",
+	"punch":                        "\U0001F44A",
+	"purple_heart":                 "\U0001F49C",
+	"pushpin":                      "\U0001F4CC",
+	"question":                     "\u2753",
+	"rabbit":                       "\U0001F430",
+	"radio":                        "\U0001F4FB",
+	"rage":                         "\U0001F621",
+	"rainbow":                      "\U0001F308",
+	"raised_hand":                  "\u270B",
+	"raised_hands":                 "\U0001F64C",
+	"raising_hand":                 "\U0001F64B",
+	"ramen":                        "\U0001F35C",
+	"recycle":                      "\u267B\uFE0F",
+	"red_car":                      "\U0001F697",
+	"red_circle":                   "\U0001F534",
+	"registered":                   "\u00AE\uFE0F",
+	"relaxed":                      "\u263A\uFE0F",
+	"relieved":                     "\U0001F60C",
+	"repeat":                       "\U0001F500",
+	"revolving_hearts":             "\U0001F49E",
+	"ribbon":                       "\U0001F380",
+	"rice":                         "\U0001F35A",
+	"ring":                         "\U0001F48D",
+	"robot":                        "\U0001F916",
+	"rocket":                       "\U0001F680",
+	"rofl":                         "\U0001F923",
+	"roll_eyes":                    "\U0001F644",
+	"rose":                         "\U0001F339",
+	"round_pushpin":                "\U0001F4CD",
+	"runner":                       "\U0001F3C3",
+	"running":                      "\U0001F3C3",
+	"sailboat":                     "\u26F5",
+	"santa":                        "\U0001F385",
+	"satisfied":                    "\U0001F606",
+	"school":                       "\U0001F3EB",
+	"school_satchel":               "\U0001F392",
+	"scissors":                     "\u2702\uFE0F",
+	"scream":                       "\U0001F631",
+	"scream_cat":                   "\U0001F640",
+	"see_no_evil":                  "\U0001F648",
+	"seedling":                     "\U0001F331",
+	"seven":                        "7\uFE0F\u20E3",
+	"shark":                        "\U0001F988",
+	"shield":                       "\U0001F6E1\uFE0F",
+	"ship":                         "\U0001F6A2",
+	"shirt":                        "\U0001F455",
+	"shopping_cart":                "\U0001F6D2",
+	"shower":                       "\U0001F6BF",
+	"shrug":                        "\U0001F937",
+	"six":                          "6\uFE0F\u20E3",
+	"skull":                        "\U0001F480",
+	"sleeping":                     "\U0001F634",
+	"sleepy":                       "\U0001F62A",
+	"slightly_frowning_face":       "\U0001F641",
+	"slightly_smiling_face":        "\U0001F642",
+	"small_red_triangle":           "\U0001F53A",
+	"small_red_triangle_down":      "\U0001F53B",
+	"smile":                        "\U0001F604",
+	"smile_cat":                    "\U0001F638",
+	"smiley":                       "\U0001F603",
+	"smiley_cat":                   "\U0001F63A",
+	"smiling_imp":                  "\U0001F608",
+	"smirk":                        "\U0001F60F",
+	"snail":                        "\U0001F40C",
+	"snake":                        "\U0001F40D",
+	"sneezing_face":                "\U0001F927",
+	"snowflake":                    "\u2744\uFE0F",
+	"snowman":                      "\u26C4",
+	"sob":                          "\U0001F62D",
+	"soccer":                       "\u26BD",
+	"soon":                         "\U0001F51C",
+	"sos":                          "\U0001F198",
+	"sound":                        "\U0001F509",
+	"spaghetti":                    "\U0001F35D",
+	"sparkles":                     "\u2728",
+	"sparkling_heart":              "\U0001F496",
+	"speak_no_evil":                "\U0001F64A",
+	"speech_balloon":               "\U0001F4AC",
+	"spider":                       "\U0001F577",
+	"spiral_calendar":              "\U0001F5D3\uFE0F",
+	"star":                         "\u2B50",
+	"star2":                        "\U0001F31F",
+	"statue_of_liberty":            "\U0001F5FD",
+	"steam_locomotive":             "\U0001F682",
+	"stop_sign":                    "\U0001F6D1",
+	"stopwatch":                    "\u23F1\uFE0F",
+	"straight_ruler":               "\U0001F4CF",
+	"strawberry":                   "\U0001F353",
+	"stuck_out_tongue":             "\U0001F61B",
+	"stuck_out_tongue_closed_eyes": "\U0001F61D",
+	"stuck_out_tongue_winking_eye": "\U0001F61C",
+	"sunflower":                    "\U0001F33B",
+	"sunglasses":                   "\U0001F60E",
+	"sunny":                        "\u2600\uFE0F",
+	"sushi":                        "\U0001F363",
+	"sweat":                        "\U0001F613",
+	"sweat_drops":                  "\U0001F4A6",
+	"sweat_smile":                  "\U0001F605",
+	"syringe":                      "\U0001F489",
+	"taco":                         "\U0001F32E",
+	"tada":                         "\U0001F389",
+	"tangerine":                    "\U0001F34A",
+	"taxi":                         "\U0001F695",
+	"tea":                          "\U0001F375",
+	"telephone":                    "\u260E\uFE0F",
+	"telescope":                    "\U0001F52D",
+	"tennis":                       "\U0001F3BE",
+	"tent":                         "\u26FA",
+	"thinking":                     "\U0001F914",
+	"thought_balloon":              "\U0001F4AD",
+	"three":                        "3\uFE0F\u20E3",
+	"thumbsdown":                   "\U0001F44E",
+	"thumbsup":                     "\U0001F44D",
+	"ticket":                       "\U0001F3AB",
+	"tiger":                        "\U0001F42F",
+	"timer_clock":                  "\u23F2\uFE0F",
+	"tired_face":                   "\U0001F62B",
+	"tm":                           "\u2122\uFE0F",
+	"toilet":                       "\U0001F6BD",
+	"tomato":                       "\U0001F345",
+	"tongue":                       "\U0001F445",
+	"top":                          "\U0001F51D",
+	"tophat":                       "\U0001F3A9",
+	"train":                        "\U0001F68B",
+	"triangular_flag_on_post":      "\U0001F6A9",
+	"triangular_ruler":             "\U0001F4D0",
+	"triumph":                      "\U0001F624",
+	"trophy":                       "\U0001F3C6",
+	"tropical_fish":                "\U0001F420",
+	"truck":                        "\U0001F69A",
+	"tulip":                        "\U0001F337",
+	"turtle":                       "\U0001F422",
+	"tv":                           "\U0001F4FA",
+	"two":                          "2\uFE0F\u20E3",
+	"two_hearts":                   "\U0001F495",
+	"umbrella":                     "\u2614",
+	"unamused":                     "\U0001F612",
+	"unicorn":                      "\U0001F984",
+	"unlock":                       "\U0001F513",
+	"up":                           "\U0001F199",
+	"upside_down_face":             "\U0001F643",
+	"v":                            "\u270C\uFE0F",
+	"vertical_traffic_light":       "\U0001F6A6",
+	"video_game":                   "\U0001F3AE",
+	"volcano":                      "\U0001F30B",
+	"vulcan_salute":                "\U0001F596",
+	"walking":                      "\U0001F6B6",
+	"warning":                      "\u26A0\uFE0F",
+	"warning_sign":                 "\u26A0\uFE0F",
+	"wastebasket":                  "\U0001F5D1\uFE0F",
+	"watch":                        "\u231A",
+	"watermelon":                   "\U0001F349",
+	"wave":                         "\U0001F44B",
+	"weary":                        "\U0001F629",
+	"whale":                        "\U0001F433",
+	"white_check_mark":             "\u2705",
+	"white_circle":                 "\u26AA",
+	"white_flag":                   "\U0001F3F3\uFE0F",
+	"white_large_square":           "\u2B1C",
+	"wine_glass":                   "\U0001F377",
+	"wink":                         "\U0001F609",
+	"wolf":                         "\U0001F43A",
+	"woman":                        "\U0001F469",
+	"world_map":                    "\U0001F5FA\uFE0F",
+	"worried":                      "\U0001F61F",
+	"wrench":                       "\U0001F527",
+	"writing_hand":                 "\u270D\uFE0F",
+	"x":                            "\u274C",
+	"yellow_heart":                 "\U0001F49B",
+	"yum":                          "\U0001F60B",
+	"zap":                          "\u26A1",
+	"zero":                         "0\uFE0F\u20E3",
+	"zipper_mouth_face":            "\U0001F910",
+	"zzz":                          "\U0001F4A4",
+}
@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertEmoji(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{"shortcode", "Done :tada:", "<p>Done \U0001F389</p>\n"},
+		{"several shortcodes", ":+1::smile: and :heart:", "<p>\U0001F44D\U0001F604 and ❤️</p>\n"},
+		{"unknown shortcode", "Time 10:30:45 :not_an_emoji:", "<p>Time 10:30:45 :not_an_emoji:</p>\n"},
+		{"shortcode after unknown", "a:b:smile:", "<p>a:b\U0001F604</p>\n"},
+		{"emphasis", "**:fire:** _:rocket:_", "<p><strong>\U0001F525</strong> <em>\U0001F680</em></p>\n"},
+		{"link text", "[:book:](http://example.com/:book:)", "<p><a href=\"http://example.com/:book:\">\U0001F4D6</a></p>\n"},
+		{"code span", "Use `:smile:` for :smile:", "<p>Use <code>:smile:</code> for \U0001F604</p>\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(test.expected, string(ConvertEmoji(ToXML(test.md))))
+		})
+	}
+
+	t.Run("code block", func(t *testing.T) {
+		md := ":bug: fixed\n\n```go\n// :bug:\nx := 1\n```\n\nAfter :sparkles:\n"
+		actual := string(ConvertEmoji(ToXML(md)))
+		assert.Contains(actual, "<p>\U0001F41B fixed</p>")
+		assert.Contains(actual, "// :bug:", "Should not convert in the code block")
+		assert.Contains(actual, "<p>After ✨</p>")
+	})
+
+	t.Run("leave the markup", func(t *testing.T) {
+		body := []byte(`<div title=":smile:"><en-media hash="abc" type="image/png"/><br/>:smile:</div>`)
+		assert.Equal(`<div title=":smile:"><en-media hash="abc" type="image/png"/><br/>`+"\U0001F604</div>", string(ConvertEmoji(body)))
+	})
+
+	t.Run("FromHTML leaves emoji", func(t *testing.T) {
+		md, err := FromHTML(string(ConvertEmoji(ToXML("Done :tada:"))))
+		assert.NoError(err)
+		assert.Equal("Done \U0001F389", strings.TrimSpace(md))
+	})
+
+	t.Run("all codes are emoji", func(t *testing.T) {
+		for code, emoji := range emojiCodes {
+			assert.NotEmpty(emoji, "Should have an emoji for %s", code)
+			assert.NotContains(code, ":", "Shortcode %s should not contain a colon", code)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/russross/blackfriday"
+	nethtml "golang.org/x/net/html"
+)
+
+// ENML doesn't allow the id and class attributes the HTML renderer uses for
+// footnotes, so the footnotes are marked with the title attribute instead.
+const (
+	// footnotesTitle is the title of the div holding the footnotes at the
+	// bottom of the note.
+	footnotesTitle = "footnotes"
+	// footnoteTitle is the prefix of the title of the footnote references
+	// and the footnotes. It's followed by the footnote's label.
+	footnoteTitle = "footnote:"
+)
+
+// footnoteDefinition matches the start of a footnote definition.
+var footnoteDefinition = regexp.MustCompile(`^ {0,3}\[\^([^\]]+)\]:`)
+
+// escapeUnusedFootnotes escapes the footnote definitions that aren't
+// referenced in the note. The markdown parser only renders the referenced
+// footnotes, so the text of the others would otherwise be dropped. The
+// escaped definitions are rendered as text.
+func escapeUnusedFootnotes(md string) string {
+	lines := strings.Split(md, "\n")
+	var fenceMarker string
+	changed := false
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if m := footnoteDefinition.FindStringSubmatch(line); fenceMarker == "" && m != nil {
+			ref := "[^" + m[1] + "]"
+			if strings.Count(md, ref) == strings.Count(md, ref+":") {
+				lines[i] = strings.Replace(line, "[^", `\[^`, 1)
+				changed = true
+			}
+		}
+		fenceMarker = updateFence(fenceMarker, trimmed)
+	}
+	if !changed {
+		return md
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FootnoteRef renders the footnote reference as a superscript link with the
+// footnote's number.
+func (r *enmlRenderer) FootnoteRef(out *bytes.Buffer, ref []byte, id int) {
+	n := strconv.Itoa(id)
+	out.WriteString(`<sup title="` + footnoteTitle + html.EscapeString(string(ref)) + `"><a href="#fn-` + n + `">` + n + `</a></sup>`)
+}
+
+// Footnotes renders the footnotes as a numbered list after a horizontal rule
+// at the bottom of the note.
+func (r *enmlRenderer) Footnotes(out *bytes.Buffer, text func() bool) {
+	out.WriteString(`<div title="` + footnotesTitle + `">` + "\n")
+	r.HRule(out)
+	r.Renderer.List(out, text, blackfriday.LIST_TYPE_ORDERED)
+	out.WriteString("</div>\n")
+}
+
+// FootnoteItem renders a footnote as a list item.
+func (r *enmlRenderer) FootnoteItem(out *bytes.Buffer, name, text []byte, flags int) {
+	out.WriteString(`<li title="` + footnoteTitle + html.EscapeString(string(name)) + `">`)
+	out.Write(bytes.TrimSpace(text))
+	out.WriteString("</li>\n")
+}
+
+// footnoteLabel returns the label of a footnote reference or a footnote.
+func footnoteLabel(node *nethtml.Node) (string, bool) {
+	title := attr(node, "title")
+	if !strings.HasPrefix(title, footnoteTitle) {
+		return "", false
+	}
+	return strings.TrimPrefix(title, footnoteTitle), true
+}
+
+// isFootnotes returns true if the node is the div holding the footnotes.
+func isFootnotes(node *nethtml.Node) bool {
+	return tagName(node) == "div" && attr(node, "title") == footnotesTitle
+}
+
+// convertFootnotes converts the footnotes back to footnote definitions. The
+// lines after the first are indented so they stay part of the footnote.
+func convertFootnotes(node *nethtml.Node) string {
+	var defs []string
+	var walk func(*nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			label, ok := footnoteLabel(c)
+			if !ok || tagName(c) != "li" {
+				walk(c)
+				continue
+			}
+			text := convertBlocks(c)
+			defs = append(defs, "[^"+label+"]: "+indentLines(text, "    "))
+		}
+	}
+	walk(node)
+	return strings.Join(defs, "\n")
+}
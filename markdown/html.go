@@ -19,16 +19,556 @@ package markdown
 
 import (
 	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
 	"strings"
 
-	"github.com/mattn/godown"
+	"golang.org/x/net/html"
 )
 
+var whitespace = regexp.MustCompile(`[ \t\r\n]+`)
+
+// FromHTML converts the HTML body of a note to markdown. Named and numeric
+// character references are decoded into UTF-8 and non-breaking spaces are
+// written as regular spaces.
 func FromHTML(body string) (string, error) {
-	buf := new(bytes.Buffer)
-	err := godown.Convert(buf, strings.NewReader(body), new(godown.Option))
+	doc, err := html.Parse(strings.NewReader(body))
 	if err != nil {
 		return "", err
 	}
-	return strings.Trim(buf.String(), "\n"), nil
+	liftVoidChildren(doc)
+	return strings.Trim(convertBlocks(doc), "\n"), nil
+}
+
+// voidElements are the ENML elements that never have content. The HTML
+// parser doesn't know them, so the nodes after such an element end up as
+// its children.
+var voidElements = map[string]bool{"en-media": true, "en-todo": true}
+
+// liftVoidChildren moves the children of the void elements up to be their
+// following siblings, where they are in the note.
+func liftVoidChildren(node *html.Node) {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if voidElements[tagName(c)] {
+			for gc := c.LastChild; gc != nil; gc = c.LastChild {
+				c.RemoveChild(gc)
+				node.InsertBefore(gc, c.NextSibling)
+			}
+		}
+		liftVoidChildren(c)
+	}
+}
+
+// block is a rendered block of markdown. Tight blocks, like the lines of a
+// note written as one div per line, are only separated by a new line. Other
+// blocks are separated by an empty line.
+type block struct {
+	text  string
+	tight bool
+}
+
+// convertBlocks converts the children of the node to markdown blocks.
+func convertBlocks(node *html.Node) string {
+	var blocks []block
+	inline := new(bytes.Buffer)
+	flush := func() {
+		text := trimLines(inline.String())
+		if text != "" {
+			blocks = append(blocks, block{text: text, tight: true})
+		}
+		inline.Reset()
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if !isBlock(c) {
+			convertInline(c, inline)
+			continue
+		}
+		flush()
+		if b, ok := convertBlock(c); ok {
+			blocks = append(blocks, b)
+		}
+	}
+	flush()
+	return joinBlocks(blocks)
+}
+
+func joinBlocks(blocks []block) string {
+	buf := new(bytes.Buffer)
+	for i, b := range blocks {
+		if i > 0 {
+			if blocks[i-1].tight && b.tight {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString("\n\n")
+			}
+		}
+		buf.WriteString(b.text)
+	}
+	return buf.String()
+}
+
+func convertBlock(node *html.Node) (block, bool) {
+	switch tagName(node) {
+	case "html", "body", "en-note":
+		return block{text: convertBlocks(node)}, true
+	case "head", "style", "script":
+		return block{}, false
+	case "div":
+		if isFootnotes(node) {
+			return block{text: convertFootnotes(node)}, true
+		}
+		if isCodeBlock(node) {
+			return block{text: fence(textContent(node), styleProperty(node, codeBlockLangProperty))}, true
+		}
+		return block{text: todoListItem(node, convertBlocks(node)), tight: true}, true
+	case "p":
+		text := convertBlocks(node)
+		return block{text: text}, text != ""
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(node.Data[1] - '0')
+		return block{text: strings.Repeat("#", level) + " " + inlineText(node)}, true
+	case "pre":
+		return block{text: fence(textContent(node), codeLanguage(node))}, true
+	case "blockquote":
+		return block{text: prefixLines(convertBlocks(node), "> ", ">")}, true
+	case "hr":
+		return block{text: "---"}, true
+	case "ul", "ol":
+		return block{text: convertList(node)}, true
+	case "table":
+		text := convertTable(node)
+		return block{text: text}, text != ""
+	}
+	return block{text: convertBlocks(node)}, true
+}
+
+func isBlock(node *html.Node) bool {
+	if node.Type == html.DocumentNode {
+		return true
+	}
+	if node.Type != html.ElementNode {
+		return false
+	}
+	switch tagName(node) {
+	case "html", "head", "body", "en-note", "style", "script", "div", "p", "h1", "h2", "h3", "h4", "h5", "h6",
+		"pre", "blockquote", "hr", "ul", "ol", "table":
+		return true
+	}
+	return false
+}
+
+func convertInline(node *html.Node, w *bytes.Buffer) {
+	switch node.Type {
+	case html.TextNode:
+		// The line breaks in quotes are kept, so the lines of a quote
+		// written in markdown stay lines.
+		lines := []string{replaceNbsp(node.Data)}
+		if inBlockquote(node) {
+			lines = strings.Split(lines[0], "\n")
+		}
+		for i, line := range lines {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			w.WriteString(escapeText(whitespace.ReplaceAllString(line, " "), atLineStart(w)))
+		}
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if markers := emphasisMarkers(node); len(markers) > 0 {
+		wrapInline(node, w, markers)
+		return
+	}
+	switch tagName(node) {
+	case "br":
+		w.WriteString("\n")
+	case "a":
+		w.WriteString(markdownLink(inlineText(node), attr(node, "href"), attr(node, "title")))
+	case "code":
+		text := textContent(node)
+		ticks := "`"
+		if strings.Contains(text, "`") {
+			ticks = "``"
+		}
+		w.WriteString(ticks + text + ticks)
+	case "img":
+		fmt.Fprintf(w, "![%s](%s)", attr(node, "alt"), attr(node, "src"))
+	case "sup":
+		if label, ok := footnoteLabel(node); ok {
+			w.WriteString("[^" + label + "]")
+			return
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			convertInline(c, w)
+		}
+	case "en-media":
+		w.WriteString(mediaPlaceholder(attr(node, "type"), attr(node, "hash")))
+	case "en-crypt":
+		w.WriteString(cryptPlaceholder(node))
+	case "en-todo":
+		if strings.EqualFold(attr(node, "checked"), "true") {
+			w.WriteString("[x] ")
+		} else {
+			w.WriteString("[ ] ")
+		}
+	default:
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			convertInline(c, w)
+		}
+	}
+}
+
+// orderedListMarker matches text that would start an ordered list item.
+var orderedListMarker = regexp.MustCompile(`^(\d+)([.)])( |$)`)
+
+// entityStart matches an ampersand that would start a character reference.
+var entityStart = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// escapeText escapes the characters in the text of the note that markdown
+// would otherwise read as formatting or HTML. If the text starts a line,
+// markers that would make the line a block, like a heading or a list item,
+// are escaped too.
+func escapeText(s string, lineStart bool) string {
+	buf := new(bytes.Buffer)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\', '`', '*':
+			buf.WriteByte('\\')
+		case '_':
+			// Underscores inside words don't start emphasis.
+			if i == 0 || i == len(s)-1 || !isAlnum(s[i-1]) || !isAlnum(s[i+1]) {
+				buf.WriteByte('\\')
+			}
+		case '<':
+			if i+1 < len(s) && (isAlnum(s[i+1]) || strings.IndexByte("/!?", s[i+1]) >= 0) {
+				buf.WriteByte('\\')
+			}
+		case '&':
+			if entityStart.MatchString(s[i:]) {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteByte(c)
+	}
+	text := buf.String()
+	if !lineStart {
+		return text
+	}
+	trimmed := strings.TrimLeft(text, " ")
+	indent := text[:len(text)-len(trimmed)]
+	if trimmed != "" && strings.IndexByte("#>-+", trimmed[0]) >= 0 {
+		return indent + "\\" + trimmed
+	}
+	return indent + orderedListMarker.ReplaceAllString(trimmed, "$1\\$2$3")
+}
+
+// atLineStart returns true if nothing but spaces has been written to the
+// current line.
+func atLineStart(w *bytes.Buffer) bool {
+	b := w.Bytes()
+	return len(bytes.TrimSpace(b[bytes.LastIndexByte(b, '\n')+1:])) == 0
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// markdownLink returns the markdown for a link. Anchors without a link are
+// written as their text, and links without text use the URL as the text.
+func markdownLink(text, href, title string) string {
+	if href == "" {
+		return text
+	}
+	if text == "" || text == href {
+		return "<" + href + ">"
+	}
+	dest := strings.Replace(href, " ", "%20", -1)
+	if title == "" {
+		return fmt.Sprintf("[%s](%s)", text, dest)
+	}
+	return fmt.Sprintf("[%s](%s \"%s\")", text, dest, title)
+}
+
+// mediaPlaceholder returns the markdown image used in place of an en-media
+// tag. ToXMLWithMedia converts it back to the tag.
+func mediaPlaceholder(mimeType, hash string) string {
+	alt := "attachment"
+	if strings.HasPrefix(mimeType, "image/") {
+		alt = "image"
+	}
+	return fmt.Sprintf("![%s](%s%s)", alt, mediaScheme, hash)
+}
+
+// cryptPlaceholder returns the markdown image used in place of an en-crypt
+// tag. The encrypted text isn't touched, the whole element is stored in the
+// link so ToXML can restore it as is.
+func cryptPlaceholder(node *html.Node) string {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, node); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("![encrypted](%s%s)", cryptScheme, base64.RawURLEncoding.EncodeToString(buf.Bytes()))
+}
+
+const (
+	boldMarker   = "**"
+	italicMarker = "_"
+	strikeMarker = "~~"
+)
+
+// emphasisMarkers returns the markdown markers for the emphasis of the node.
+// Both the emphasis tags and the inline styles used by Evernote's editor
+// are recognized. Emphasis already given by a parent is left out, so nested
+// tags of the same kind don't repeat the marker.
+func emphasisMarkers(node *html.Node) []string {
+	var markers []string
+	for _, m := range nodeEmphasis(node) {
+		if !hasEmphasis(node.Parent, m) {
+			markers = append(markers, m)
+		}
+	}
+	return markers
+}
+
+func hasEmphasis(node *html.Node, marker string) bool {
+	for n := node; n != nil; n = n.Parent {
+		for _, m := range nodeEmphasis(n) {
+			if m == marker {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeEmphasis returns the markers for the emphasis set by the node itself.
+func nodeEmphasis(node *html.Node) []string {
+	if node.Type != html.ElementNode {
+		return nil
+	}
+	switch tagName(node) {
+	case "b", "strong":
+		return []string{boldMarker}
+	case "i", "em":
+		return []string{italicMarker}
+	case "del", "s", "strike":
+		return []string{strikeMarker}
+	case "span":
+	default:
+		return nil
+	}
+	var markers []string
+	switch strings.ToLower(styleProperty(node, "font-weight")) {
+	case "bold", "bolder", "700", "800", "900":
+		markers = append(markers, boldMarker)
+	}
+	switch strings.ToLower(styleProperty(node, "font-style")) {
+	case "italic", "oblique":
+		markers = append(markers, italicMarker)
+	}
+	decoration := styleProperty(node, "text-decoration") + " " + styleProperty(node, "text-decoration-line")
+	if strings.Contains(strings.ToLower(decoration), "line-through") {
+		markers = append(markers, strikeMarker)
+	}
+	return markers
+}
+
+// wrapInline wraps the inline content of the node with the markers. The
+// markers are closed in the reverse order. Spaces at the edges are moved
+// outside the markers, since markdown doesn't allow them inside.
+func wrapInline(node *html.Node, w *bytes.Buffer, markers []string) {
+	buf := new(bytes.Buffer)
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		convertInline(c, buf)
+	}
+	text := buf.String()
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		w.WriteString(text)
+		return
+	}
+	if strings.HasPrefix(text, " ") {
+		w.WriteString(" ")
+	}
+	closing := make([]string, len(markers))
+	for i, m := range markers {
+		closing[len(markers)-1-i] = m
+	}
+	w.WriteString(strings.Join(markers, "") + trimmed + strings.Join(closing, ""))
+	if strings.HasSuffix(text, " ") {
+		w.WriteString(" ")
+	}
+}
+
+// inlineText returns the inline content of the node on a single line.
+func inlineText(node *html.Node) string {
+	buf := new(bytes.Buffer)
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		convertInline(c, buf)
+	}
+	return strings.TrimSpace(whitespace.ReplaceAllString(buf.String(), " "))
+}
+
+// inBlockquote returns true if the node is inside a blockquote.
+func inBlockquote(node *html.Node) bool {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if tagName(p) == "blockquote" {
+			return true
+		}
+	}
+	return false
+}
+
+// todoListItem makes a line starting with a checkbox a task list item,
+// unless it already is in a list.
+func todoListItem(node *html.Node, text string) string {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if tagName(p) == "li" {
+			return text
+		}
+	}
+	first := node.FirstChild
+	for first != nil && first.Type == html.TextNode && strings.TrimSpace(first.Data) == "" {
+		first = first.NextSibling
+	}
+	if first != nil && tagName(first) == "en-todo" {
+		return "- " + text
+	}
+	return text
+}
+
+func convertList(node *html.Node) string {
+	var items []string
+	n := 0
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if tagName(c) != "li" {
+			continue
+		}
+		n++
+		marker := "- "
+		if tagName(node) == "ol" {
+			marker = fmt.Sprintf("%d. ", n)
+		}
+		text := strings.Replace(convertBlocks(c), "\n\n", "\n", -1)
+		// The lines after the first are indented by the marker width, so
+		// sublists and continued lines stay part of the item.
+		items = append(items, marker+indentLines(text, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// isCodeBlock returns true if the node is an Evernote code block.
+func isCodeBlock(node *html.Node) bool {
+	return strings.EqualFold(styleProperty(node, codeBlockProperty), "true")
+}
+
+// styleProperty returns the value of the CSS property in the node's style attribute.
+func styleProperty(node *html.Node, name string) string {
+	for _, decl := range strings.Split(attr(node, "style"), ";") {
+		kv := strings.SplitN(decl, ":", 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == name {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return ""
+}
+
+// fence returns the code as a fenced code block. The fence is made longer
+// than any run of backticks in the code.
+func fence(code, lang string) string {
+	ticks := "```"
+	for strings.Contains(code, ticks) {
+		ticks += "`"
+	}
+	return ticks + lang + "\n" + strings.TrimSuffix(code, "\n") + "\n" + ticks
+}
+
+func codeLanguage(node *html.Node) string {
+	for n := node; n != nil; n = n.FirstChild {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			if strings.HasPrefix(class, "language-") {
+				return class[len("language-"):]
+			}
+		}
+		if n.Type != html.ElementNode {
+			break
+		}
+	}
+	return ""
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return replaceNbsp(node.Data)
+	}
+	buf := new(bytes.Buffer)
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		switch tagName(c) {
+		case "br":
+			buf.WriteString("\n")
+		case "div", "p":
+			// Each div is a line, a div with only a line break is an empty line.
+			buf.WriteString(strings.TrimSuffix(textContent(c), "\n") + "\n")
+		default:
+			buf.WriteString(textContent(c))
+		}
+	}
+	return buf.String()
+}
+
+// replaceNbsp replaces non-breaking spaces with regular spaces. The HTML
+// parser has already decoded &nbsp; into the rune.
+func replaceNbsp(s string) string {
+	return strings.Replace(s, "\u00a0", " ", -1)
+}
+
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// indentLines indents all lines except the first one.
+func indentLines(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func prefixLines(s, prefix, empty string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = empty
+		} else {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tagName(node *html.Node) string {
+	if node.Type != html.ElementNode {
+		return ""
+	}
+	return strings.ToLower(node.Data)
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
 }
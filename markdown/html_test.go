@@ -34,3 +34,323 @@ func TestFromHTML(t *testing.T) {
 	assert.NoError(err, "Should parse the doc without an error")
 	assert.Equal(expected, actual, "Not converted")
 }
+
+func TestFromHTMLEntities(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		Name     string
+		HTML     string
+		Expected string
+	}{
+		{"ampersand", "<div>Tom &amp; Jerry</div>", "Tom & Jerry"},
+		{"numeric", "<div>caf&#233; cr&#xE8;me</div>", "café crème"},
+		{"named", "<div>caf&eacute; &lt;tag&gt;</div>", "café \\<tag>"},
+		{"nbsp", "<div>a&nbsp;b&nbsp;&nbsp;c</div>", "a b c"},
+		{"emoji", "<div>&#128512;</div>", "\U0001F600"},
+		{"code block", "<pre>if a &amp;&amp; b {\n&nbsp;&nbsp;return\n}</pre>", "```\nif a && b {\n  return\n}\n```"},
+		{"en-note root", "<en-note><div>caf&eacute;</div><pre>x</pre></en-note>", "café\n\n```\nx\n```"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := FromHTML(test.HTML)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.Expected, actual, "Entities not decoded")
+		})
+	}
+}
+
+func TestFromHTMLEscaping(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{"tag", "<div>&lt;b&gt;not bold&lt;/b&gt;</div>", `\<b>not bold\</b>`},
+		{"comparison", "<div>a &lt; b &amp; c R&amp;D</div>", "a < b & c R&D"},
+		{"entity", "<div>&amp;lt;</div>", `\&lt;`},
+		{"emphasis", "<div>*word* and _word_</div>", `\*word\* and \_word\_`},
+		{"intra word underscore", "<div>snake_case</div>", "snake_case"},
+		{"code", "<div>`code`</div>", "\\`code\\`"},
+		{"backslash", `<div>a\b</div>`, `a\\b`},
+		{"heading", "<div># not a heading</div>", `\# not a heading`},
+		{"list", "<div>1. one</div><div>- two</div>", "1\\. one\n\\- two"},
+		{"quote", "<p>&gt; not a quote</p>", `\> not a quote`},
+		{"not at line start", "<div>a # b - c 1. d</div>", "a # b - c 1. d"},
+		{"after line break", "<div>a<br/>2. b</div>", "a\n2\\. b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.html)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.expected, actual)
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		doc := "<p>&lt;b&gt;text&lt;/b&gt;</p>\n<p>1. *word* `x` &amp;lt;</p>"
+		md, err := FromHTML(doc)
+		assert.NoError(err, "Should parse the doc without an error")
+		xml := string(ToXML(md))
+		assert.Contains(xml, "&lt;b&gt;text&lt;/b&gt;", "Text should not become a tag")
+		assert.NotContains(xml, "<ol>", "Text should not become a list")
+		actual, err := FromHTML(xml)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Text not preserved")
+	})
+}
+
+func TestFromHTMLRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		md   string
+	}{
+		{"paragraphs", "First paragraph.\n\nSecond paragraph."},
+		{"headings", "# Title\n\n## Section\n\nText"},
+		{"emphasis", "**bold** _italic_ ~~strike~~ and **_both_**"},
+		{"inline code", "Run `go test` now"},
+		{"link", "See [the docs](https://example.com/docs \"Docs\") and <https://example.com>"},
+		{"unordered list", "- one\n- two\n- three"},
+		{"ordered list", "1. one\n2. two"},
+		{"nested list", "- one\n  - two\n- three"},
+		{"code block", "```\nfunc a() {}\n```"},
+		{"horizontal rule", "Above\n\n---\n\nBelow"},
+		{"image", "![alt](https://example.com/a.png)"},
+		{"multi-line blockquote", "> one\n> two\n>\n> three"},
+		{"escaped text", "\\# not a heading \\*word\\* \\<b>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(string(ToXML(test.md)))
+			assert.NoError(err, "Should parse the doc without an error")
+			assert.Equal(test.md, actual, "Markdown not preserved")
+		})
+	}
+}
+
+func TestFromHTMLBlocks(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{"div per line", "<div>one</div><div>two</div><div><br/></div><div>three</div>", "one\ntwo\n\nthree"},
+		{"paragraphs", "<p>one</p>\n<p>two</p>", "one\n\ntwo"},
+		{"heading", "<h2>Title <i>here</i></h2><p>text</p>", "## Title _here_\n\ntext"},
+		{"inline spacing", "<p>a <b>bold </b>word</p>", "a **bold** word"},
+		{"link", `<p>see <a href="http://example.com">example</a></p>`, "see [example](http://example.com)"},
+		{"list", "<ul><li>one</li><li>two</li></ul><ol><li>a</li><li>b</li></ol>", "- one\n- two\n\n1. a\n2. b"},
+		{"code", "<pre><code>a\n\n  b\n</code></pre>", "```\na\n\n  b\n```"},
+		{"blockquote", "<blockquote><p>a</p><p>b</p></blockquote>", "> a\n>\n> b"},
+		{"blockquote lines", "<blockquote><div>a</div><div>b</div><div><br/></div><div>c<br/>d</div></blockquote>", "> a\n> b\n>\n> c\n> d"},
+		{"blockquote soft line break", "<blockquote>\n<p>a\n# b</p>\n</blockquote>", "> a\n> \\# b"},
+		{"horizontal rule", "<div>a</div><hr/><div>b</div>", "a\n\n---\n\nb"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.doc)
+			assert.NoError(err, "Should parse the doc without an error")
+			assert.Equal(test.expected, actual, "Not converted")
+		})
+	}
+}
+
+func TestFromHTMLEmphasis(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{"tags", "<div><b>b</b> <i>i</i> <strike>s</strike> <s>s</s></div>", "**b** _i_ ~~s~~ ~~s~~"},
+		{"bold style", `<div><span style="font-weight: bold;">bold</span></div>`, "**bold**"},
+		{"numeric bold style", `<div><span style="font-weight:700">bold</span></div>`, "**bold**"},
+		{"italic style", `<div><span style="font-style: italic;">italic</span></div>`, "_italic_"},
+		{"strikethrough style", `<div><span style="text-decoration: line-through;">gone</span></div>`, "~~gone~~"},
+		{"combined styles", `<div><span style="font-weight: bold; font-style: italic;">both</span></div>`, "**_both_**"},
+		{"plain span", `<div><span style="color: red;">text</span></div>`, "text"},
+		{"nested same emphasis", "<div><i>a <em>b</em></i></div>", "_a b_"},
+		{"styled span in bold", `<div><b>a <span style="font-weight: bold; font-style: italic;">b</span></b></div>`, "**a _b_**"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.html)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestFromHTMLLinks(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{"anchor without link", `<div><a name="top"></a>Text</div>`, "Text"},
+		{"link without text", `<div><a href="https://example.com"></a></div>`, "<https://example.com>"},
+		{"space in link", `<div><a href="https://example.com/a b">link</a></div>`, "[link](https://example.com/a%20b)"},
+		{"formatted text", `<div><a href="https://example.com" title="Title"><b>bold</b> link</a></div>`, `[**bold** link](https://example.com "Title")`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.html)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestFromHTMLCodeBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("evernote code block", func(t *testing.T) {
+		doc := `<div style="box-sizing: border-box; -en-codeblock: true;"><div>func main() {</div><div>    fmt.Println("&lt;a&gt;")</div><div><br/></div><div>}</div></div>`
+		actual, err := FromHTML(doc)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal("```\nfunc main() {\n    fmt.Println(\"<a>\")\n\n}\n```", actual)
+	})
+
+	t.Run("keep language", func(t *testing.T) {
+		doc := `<div style="-en-codeblock:true;-en-codeblock-language:go;"><div>a := 1</div></div>`
+		actual, err := FromHTML(doc)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal("```go\na := 1\n```", actual)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		md := "Text\n\n```go\nfunc a() {\n\n\treturn\n}\n```\n\nMore text"
+		actual, err := FromHTML(string(ToXML(md)))
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Code block not preserved")
+	})
+
+	t.Run("fence longer than backticks in code", func(t *testing.T) {
+		actual, err := FromHTML("<pre><code>```\nx\n```</code></pre>")
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal("````\n```\nx\n```\n````", actual)
+	})
+}
+
+func TestFromHTMLMedia(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{"image", `<div><en-media type="image/png" hash="abc123"/></div>`, "![image](en-media:abc123)"},
+		{"attachment", `<div><en-media type="application/pdf" hash="abc123"/></div>`, "![attachment](en-media:abc123)"},
+		{"inline", `<p>a <en-media type="image/png" hash="abc123"/> b</p>`, "a ![image](en-media:abc123) b"},
+		{"blocks after", `<en-media type="image/png" hash="abc123"/><div>line a</div><div>line b</div>`, "![image](en-media:abc123)\nline a\nline b"},
+		{"closed element", `<div><en-media type="image/png" hash="abc123"></en-media>a</div><div>b</div>`, "![image](en-media:abc123)a\nb"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.doc)
+			assert.NoError(err, "Should parse the doc without an error")
+			assert.Equal(test.expected, actual, "Not converted")
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		media := map[string]string{"abc123": "image/png"}
+		md := "Text\n\n![image](en-media:abc123)"
+		xml := string(ToXMLWithMedia(md, media))
+		assert.Contains(xml, `<en-media type="image/png" hash="abc123"/>`, "Media tag not restored")
+		actual, err := FromHTML(xml)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Image placeholder not preserved")
+	})
+}
+
+func TestFromHTMLTodo(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{"list", `<ul><li><en-todo checked="true"/>done</li><li><en-todo checked="false"/>todo</li></ul>`, "- [x] done\n- [ ] todo"},
+		{"missing checked", `<ul><li><en-todo/>todo</li></ul>`, "- [ ] todo"},
+		{"closed element", `<ul><li><en-todo checked="true"></en-todo>done</li></ul>`, "- [x] done"},
+		{"div per line", `<div><en-todo checked="true"/>done</div><div><en-todo/>todo</div>`, "- [x] done\n- [ ] todo"},
+		{"inline", `<p>a <en-todo/>b</p>`, "a [ ] b"},
+		{"blocks after", `<en-todo checked="true"/>done<div>line a</div><div>line b</div>`, "[x] done\nline a\nline b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.doc)
+			assert.NoError(err, "Should parse the doc without an error")
+			assert.Equal(test.expected, actual, "Not converted")
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		md := "- [ ] one\n- [x] two\n- three"
+		actual, err := FromHTML(string(ToXML(md)))
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Task list not preserved")
+	})
+}
+
+func TestFromHTMLCrypt(t *testing.T) {
+	assert := assert.New(t)
+	tag := `<en-crypt hint="My &amp; hint" cipher="AES" length="128">RU5DMI1mnQ7fKjBk9f0a57gSc9Nfbuw3uuwMKs32Y+wJGLZa0N8PcTzf7pu3/2VOBqZMvfkKGh4mnJuGy45ZT2TwOfqt+ey8Tic7BmhGg7b4n+SpJFHntkeLglxFWJt6oIG14i7IpamIuYyE5XcBRkOQs2cr7rg730d1hxx6sW/KqIfdr+0rF4k+rqP7tpI5ha/ALkhaZAuDbIVic39aCRcu6uve6mHHHrA=</en-crypt>`
+
+	t.Run("placeholder", func(t *testing.T) {
+		actual, err := FromHTML(`<div>Secret: ` + tag + `</div>`)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Contains(actual, "Secret: ![encrypted](en-crypt:", "Not converted to a placeholder")
+		assert.NotContains(actual, "RU5DMI1mnQ7f", "Ciphertext should not be in the markdown as is")
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		md, err := FromHTML(`<p>Secret: ` + tag + `</p><p>After</p>`)
+		assert.NoError(err, "Should parse the doc without an error")
+		xml := string(ToXML(md))
+		assert.Contains(xml, tag, "en-crypt tag not restored")
+		actual, err := FromHTML(xml)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Placeholder not preserved")
+	})
+
+	t.Run("invalid placeholder", func(t *testing.T) {
+		xml := string(ToXML("![encrypted](en-crypt:PGI-eDwvYj4)\n"))
+		assert.NotContains(xml, "<b>", "Only en-crypt tags should be restored")
+	})
+}
+
+func TestFromHTMLFootnotes(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("round trip", func(t *testing.T) {
+		md := "Text with a note[^1] and another[^2].\n\n[^1]: First footnote.\n[^2]: Second footnote."
+		actual, err := FromHTML(string(ToXML(md)))
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Footnotes not preserved")
+	})
+
+	t.Run("multiple paragraphs", func(t *testing.T) {
+		md := "Text[^a].\n\n[^a]: First paragraph.\n\n    Second paragraph."
+		actual, err := FromHTML(string(ToXML(md)))
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Footnote paragraphs not preserved")
+	})
+
+	t.Run("unreferenced footnote", func(t *testing.T) {
+		md := "Text\n\n[^1]: Not referenced."
+		actual, err := FromHTML(string(ToXML(md)))
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Footnote text not preserved")
+	})
+
+	t.Run("regular superscript", func(t *testing.T) {
+		actual, err := FromHTML("<p>x<sup>2</sup></p>")
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal("x2", actual, "Superscript text not kept")
+	})
+}
@@ -0,0 +1,109 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// listItemMarker matches the marker at the start of a list item.
+var listItemMarker = regexp.MustCompile(`^([-*+]|\d+[.)]) +`)
+
+// nestedListIndent is the indentation per nesting level that the markdown
+// parser needs to see a list item as a part of a nested list.
+const nestedListIndent = "    "
+
+// listItem is an open list item while normalizing the list indentation.
+type listItem struct {
+	// indent is the number of spaces before the marker.
+	indent int
+	// width is the width of the marker, including the spaces after it.
+	width int
+}
+
+// normalizeListIndent re-indents nested lists with four spaces per level.
+// Sublists indented with fewer spaces, for example by the marker width as
+// FromHTML does, are otherwise merged with their parent list when parsed. An item
+// is nested if it is indented more than the item before it. The lines that
+// continue an item are indented to the item's level, keeping any extra
+// indentation, for example in a fenced code block.
+func normalizeListIndent(md string) string {
+	lines := strings.Split(md, "\n")
+	var items []listItem
+	var fenceMarker string
+	prevBlank := false
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if trimmed == "" {
+			prevBlank = true
+			continue
+		}
+		marker := listItemMarker.FindString(trimmed)
+		if fenceMarker == "" && marker != "" && !ruleLine.MatchString(trimmed) && (len(items) > 0 || indent < 4) {
+			for len(items) > 0 && items[len(items)-1].indent >= indent {
+				items = items[:len(items)-1]
+			}
+			items = append(items, listItem{indent: indent, width: len(marker)})
+			lines[i] = strings.Repeat(nestedListIndent, len(items)-1) + trimmed
+			prevBlank = false
+			continue
+		}
+		// An unindented line after an empty line ends the list.
+		if fenceMarker == "" && indent == 0 && prevBlank {
+			items = nil
+		}
+		if len(items) > 0 {
+			lines[i] = continueListItem(items, indent, trimmed)
+		}
+		fenceMarker = updateFence(fenceMarker, trimmed)
+		prevBlank = false
+	}
+	return strings.Join(lines, "\n")
+}
+
+// continueListItem indents the line continuing a list item. The line belongs
+// to the deepest item indented less than the line, or to the last item if
+// the line isn't indented enough.
+func continueListItem(items []listItem, indent int, trimmed string) string {
+	level := len(items) - 1
+	for level > 0 && items[level].indent >= indent {
+		level--
+	}
+	extra := indent - items[level].indent - items[level].width
+	if extra < 0 {
+		extra = 0
+	}
+	return strings.Repeat(nestedListIndent, level+1) + strings.Repeat(" ", extra) + trimmed
+}
+
+// updateFence returns the marker of the fenced code block that is open after
+// the line, or an empty string if no block is open.
+func updateFence(fenceMarker, trimmed string) string {
+	if fenceMarker != "" {
+		if strings.HasPrefix(trimmed, fenceMarker) {
+			return ""
+		}
+		return fenceMarker
+	}
+	if m := fenceLine.FindStringSubmatch(trimmed); m != nil {
+		return m[1]
+	}
+	return ""
+}
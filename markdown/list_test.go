@@ -0,0 +1,71 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeListIndent(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{"flat list", "- a\n- b", "- a\n- b"},
+		{"two spaces", "- a\n  - b\n    - c\n- d", "- a\n    - b\n        - c\n- d"},
+		{"ordered", "1. a\n   1. b\n2. c", "1. a\n    1. b\n2. c"},
+		{"back to parent level", "- a\n  - b\n    - c\n  - d", "- a\n    - b\n        - c\n    - d"},
+		{"continuation", "- a\n  more\n  - b\n    more", "- a\n    more\n    - b\n        more"},
+		{"code in item", "- a\n  ```\n    - code\n  ```", "- a\n    ```\n      - code\n    ```"},
+		{"end of list", "- a\n  - b\n\nText", "- a\n    - b\n\nText"},
+		{"fenced code", "```\n- a\n  - b\n```", "```\n- a\n  - b\n```"},
+		{"indented code", "Text\n\n    - a\n      - b", "Text\n\n    - a\n      - b"},
+		{"rule", "* * *\n  text", "* * *\n  text"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(test.expected, normalizeListIndent(test.md))
+		})
+	}
+}
+
+func TestNestedListRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		md   string
+	}{
+		{"unordered", "- one\n  - two\n    - three\n- four"},
+		{"ordered", "1. one\n   1. two\n      1. three\n2. four"},
+		{"mixed", "1. one\n   - two\n     1. three\n   - four\n2. five"},
+		{"wide marker", "1. a\n2. b\n3. c\n4. d\n5. e\n6. f\n7. g\n8. h\n9. i\n10. one\n    - two\n      - three"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			xml := string(ToXML(test.md))
+			assert.Contains(xml, "<li>two\n\n<", "Sublist should be nested")
+			actual, err := FromHTML(xml)
+			assert.NoError(err, "Should parse the doc without an error")
+			assert.Equal(test.md, actual, "Nested list not preserved")
+		})
+	}
+}
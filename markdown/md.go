@@ -15,11 +15,168 @@
  * Copyright (C) Joakim Kennedy, 2016
  */
 
+// Package markdown converts between markdown and the body of Evernote notes.
+// The functions work on the body inside the en-note element and don't
+// depend on a store or a notestore.
 package markdown
 
-import "github.com/russross/blackfriday"
+import (
+	"bytes"
+	"encoding/base64"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/russross/blackfriday"
+)
+
+const (
+	htmlFlags = blackfriday.HTML_USE_XHTML |
+		blackfriday.HTML_USE_SMARTYPANTS |
+		blackfriday.HTML_SMARTYPANTS_FRACTIONS |
+		blackfriday.HTML_SMARTYPANTS_DASHES |
+		blackfriday.HTML_SMARTYPANTS_LATEX_DASHES
+
+	extensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+		blackfriday.EXTENSION_TABLES |
+		blackfriday.EXTENSION_FENCED_CODE |
+		blackfriday.EXTENSION_AUTOLINK |
+		blackfriday.EXTENSION_STRIKETHROUGH |
+		blackfriday.EXTENSION_SPACE_HEADERS |
+		blackfriday.EXTENSION_HEADER_IDS |
+		blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+		blackfriday.EXTENSION_DEFINITION_LISTS |
+		blackfriday.EXTENSION_FOOTNOTES
+)
+
+const (
+	// codeBlockProperty is the style property Evernote uses to mark a div as a code block.
+	codeBlockProperty = "-en-codeblock"
+	// codeBlockLangProperty is the style property that holds the language of the code block.
+	codeBlockLangProperty = "-en-codeblock-language"
+	codeBlockFormat       = "box-sizing: border-box; padding: 8px; font-family: Monaco, Menlo, Consolas, 'Courier New', monospace; font-size: 12px; color: rgb(51, 51, 51); border-radius: 4px; background-color: rgb(251, 250, 248); border: 1px solid rgba(0, 0, 0, 0.15); white-space: pre-wrap;"
+)
+
+// mediaScheme is the link scheme of the images that reference an attachment.
+const mediaScheme = "en-media:"
+
+// cryptScheme is the link scheme of the images that hold an encrypted
+// section. The link holds the base64 encoded en-crypt element.
+const cryptScheme = "en-crypt:"
 
 // ToXML converts the markdown body to Evernote's xml body style.
 func ToXML(mdBody string) []byte {
-	return blackfriday.MarkdownCommon([]byte(mdBody))
+	return ToXMLWithMedia(mdBody, nil)
+}
+
+// ToXMLWithMedia converts the markdown body to Evernote's xml body style.
+// Images linking to en-media:HASH are converted to en-media tags. The media
+// map holds the MIME type of the attachments by their hex encoded hash.
+// References to attachments that aren't in the map are removed.
+func ToXMLWithMedia(mdBody string, media map[string]string) []byte {
+	md := normalizeListIndent(escapeUnusedFootnotes(mdBody))
+	return blackfriday.Markdown([]byte(md), newENMLRenderer(media), extensions)
+}
+
+// enmlRenderer renders markdown as ENML. Elements that need to be rendered
+// differently from HTML are overridden, the rest is handled by the HTML
+// renderer.
+type enmlRenderer struct {
+	blackfriday.Renderer
+	media map[string]string
+	// unsupported holds the constructs that couldn't be represented in
+	// ENML. They are only reported by ToXMLStrict.
+	unsupported []string
+}
+
+func newENMLRenderer(media map[string]string) *enmlRenderer {
+	return &enmlRenderer{Renderer: blackfriday.HtmlRenderer(htmlFlags, "", ""), media: media}
+}
+
+// Image renders images referencing an attachment as an en-media tag and
+// encrypted sections as the original en-crypt tag.
+func (r *enmlRenderer) Image(out *bytes.Buffer, link []byte, title []byte, alt []byte) {
+	if bytes.HasPrefix(link, []byte(cryptScheme)) {
+		tag, err := base64.RawURLEncoding.DecodeString(string(link[len(cryptScheme):]))
+		if err != nil || !bytes.HasPrefix(tag, []byte("<en-crypt")) {
+			r.unsupported = append(r.unsupported, "invalid encrypted section")
+			return
+		}
+		out.Write(tag)
+		return
+	}
+	if !bytes.HasPrefix(link, []byte(mediaScheme)) {
+		r.Renderer.Image(out, link, title, alt)
+		return
+	}
+	hash := strings.ToLower(string(link[len(mediaScheme):]))
+	mimeType, ok := r.media[hash]
+	if !ok {
+		r.unsupported = append(r.unsupported, "reference to a missing attachment "+strconv.Quote(hash))
+		return
+	}
+	out.WriteString(`<en-media type="` + html.EscapeString(mimeType) + `" hash="` + html.EscapeString(hash) + `"/>`)
+}
+
+// BlockCode renders the code block as an Evernote code block, one div per line.
+func (r *enmlRenderer) BlockCode(out *bytes.Buffer, text []byte, lang string) {
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+	out.WriteString(`<div style="` + codeBlockFormat + codeBlockProperty + ":true;")
+	if fields := strings.Fields(lang); len(fields) > 0 {
+		out.WriteString(codeBlockLangProperty + ":" + html.EscapeString(strings.TrimPrefix(fields[0], ".")) + ";")
+	}
+	out.WriteString(`">`)
+	for _, line := range strings.Split(strings.TrimSuffix(string(text), "\n"), "\n") {
+		if line == "" {
+			out.WriteString("<div><br/></div>")
+			continue
+		}
+		out.WriteString("<div>" + html.EscapeString(line) + "</div>")
+	}
+	out.WriteString("</div>\n")
+}
+
+// HRule renders a thematic break as an ENML horizontal rule.
+func (r *enmlRenderer) HRule(out *bytes.Buffer) {
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+	out.WriteString("<hr/>\n")
+}
+
+var (
+	todoUnchecked = []byte("[ ] ")
+	todoChecked   = [][]byte{[]byte("[x] "), []byte("[X] ")}
+)
+
+// ListItem renders task list items with an en-todo checkbox.
+func (r *enmlRenderer) ListItem(out *bytes.Buffer, text []byte, flags int) {
+	r.Renderer.ListItem(out, renderTodo(text), flags)
+}
+
+func renderTodo(text []byte) []byte {
+	prefix := []byte{}
+	// Items in a loose list are wrapped in a paragraph.
+	if bytes.HasPrefix(text, []byte("<p>")) {
+		prefix = []byte("<p>")
+	}
+	rest := text[len(prefix):]
+	if bytes.HasPrefix(rest, todoUnchecked) {
+		return todoItem(prefix, `<en-todo checked="false"/>`, rest[len(todoUnchecked):])
+	}
+	for _, checked := range todoChecked {
+		if bytes.HasPrefix(rest, checked) {
+			return todoItem(prefix, `<en-todo checked="true"/>`, rest[len(checked):])
+		}
+	}
+	return text
+}
+
+func todoItem(prefix []byte, todo string, text []byte) []byte {
+	buf := bytes.NewBuffer(append([]byte{}, prefix...))
+	buf.WriteString(todo)
+	buf.Write(text)
+	return buf.Bytes()
 }
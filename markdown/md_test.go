@@ -0,0 +1,180 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToXMLCodeBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("fenced code block", func(t *testing.T) {
+		actual := string(ToXML("```go\nif a < b {\n\n}\n```\n"))
+		assert.Contains(actual, codeBlockProperty+":true;", "Should be marked as a code block")
+		assert.Contains(actual, codeBlockLangProperty+":go;", "Should keep the language")
+		assert.Contains(actual, "<div>if a &lt; b {</div><div><br/></div><div>}</div>", "Should have a div per line")
+		assert.NotContains(actual, "<pre>", "Should not use pre")
+		assert.NotContains(actual, "class=", "Class attributes are not allowed in ENML")
+	})
+
+	t.Run("without language", func(t *testing.T) {
+		actual := string(ToXML("```\ncode\n```\n"))
+		assert.Contains(actual, codeBlockProperty+":true;", "Should be marked as a code block")
+		assert.NotContains(actual, codeBlockLangProperty, "Should not have a language")
+	})
+
+	t.Run("indented code block", func(t *testing.T) {
+		actual := string(ToXML("Text\n\n    code\n"))
+		assert.Contains(actual, "<div>code</div>", "Should have a div per line")
+	})
+}
+
+func TestToXMLBlocks(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("horizontal rule", func(t *testing.T) {
+		actual := string(ToXML("Above\n\n---\n\nBelow\n\n***\n"))
+		assert.Equal("<p>Above</p>\n\n<hr/>\n\n<p>Below</p>\n\n<hr/>\n", actual)
+	})
+
+	t.Run("blockquote", func(t *testing.T) {
+		actual := string(ToXML("> quoted\n> line\n>\n> second\n"))
+		assert.Equal("<blockquote>\n<p>quoted\nline</p>\n\n<p>second</p>\n</blockquote>\n", actual)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		md := "Above\n\n---\n\n> quoted\n>\n> second\n\nBelow"
+		actual, err := FromHTML(string(ToXML(md)))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(md, actual, "Rule and blockquote not preserved")
+	})
+}
+
+func TestInlineFormatting(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		md   string
+		xml  string
+		back string
+	}{
+		{"bold", "**bold**", "<p><strong>bold</strong></p>\n", "**bold**"},
+		{"italic", "*italic*", "<p><em>italic</em></p>\n", "_italic_"},
+		{"strikethrough", "~~strike~~", "<p><del>strike</del></p>\n", "~~strike~~"},
+		{"bold with italic", "**bold _and italic_**", "<p><strong>bold <em>and italic</em></strong></p>\n", "**bold _and italic_**"},
+		{"italic with bold", "_italic **and bold**_", "<p><em>italic <strong>and bold</strong></em></p>\n", "_italic **and bold**_"},
+		{"bold and italic", "***both***", "<p><strong><em>both</em></strong></p>\n", "**_both_**"},
+		{"strikethrough with bold", "~~gone **for good**~~", "<p><del>gone <strong>for good</strong></del></p>\n", "~~gone **for good**~~"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			xml := string(ToXML(test.md))
+			assert.Equal(test.xml, xml, "Wrong XML")
+			back, err := FromHTML(xml)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.back, back, "Wrong markdown")
+			assert.Equal(xml, string(ToXML(back)), "Formatting not preserved")
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name string
+		md   string
+		xml  string
+		back string
+	}{
+		{"with title", `[text](https://example.com "The title")`, `<p><a href="https://example.com" title="The title">text</a></p>` + "\n", `[text](https://example.com "The title")`},
+		{"without title", "[text](https://example.com)", `<p><a href="https://example.com">text</a></p>` + "\n", "[text](https://example.com)"},
+		{"reference", "[text][ref]\n\n[ref]: https://example.com \"Ref\"\n", `<p><a href="https://example.com" title="Ref">text</a></p>` + "\n", `[text](https://example.com "Ref")`},
+		{"autolink", "<https://example.com>", `<p><a href="https://example.com">https://example.com</a></p>` + "\n", "<https://example.com>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			xml := string(ToXML(test.md))
+			assert.Equal(test.xml, xml, "Wrong XML")
+			back, err := FromHTML(xml)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.back, back, "Wrong markdown")
+		})
+	}
+}
+
+func TestToXMLMedia(t *testing.T) {
+	assert := assert.New(t)
+	media := map[string]string{"abc123": "image/png"}
+
+	t.Run("known attachment", func(t *testing.T) {
+		actual := string(ToXMLWithMedia("![image](en-media:ABC123)\n", media))
+		assert.Equal("<p><en-media type=\"image/png\" hash=\"abc123\"/></p>\n", actual)
+	})
+
+	t.Run("unknown attachment", func(t *testing.T) {
+		actual := string(ToXMLWithMedia("![image](en-media:def456)\n", media))
+		assert.NotContains(actual, "en-media", "Unknown attachment should be removed")
+		assert.NotContains(actual, "<img", "Unknown attachment should be removed")
+	})
+
+	t.Run("regular image", func(t *testing.T) {
+		actual := string(ToXMLWithMedia("![alt](http://example.com/a.png)\n", media))
+		assert.Contains(actual, `<img src="http://example.com/a.png" alt="alt"`, "Regular image not rendered")
+	})
+}
+
+func TestToXMLTodo(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{"unchecked", "- [ ] todo\n", `<li><en-todo checked="false"/>todo</li>`},
+		{"checked", "- [x] done\n", `<li><en-todo checked="true"/>done</li>`},
+		{"checked upper case", "- [X] done\n", `<li><en-todo checked="true"/>done</li>`},
+		{"loose list", "- [ ] one\n\n- [x] two\n", `<li><p><en-todo checked="false"/>one</p>`},
+		{"plain item", "- item\n", "<li>item</li>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Contains(string(ToXML(test.md)), test.expected)
+		})
+	}
+}
+
+func TestToXMLFootnotes(t *testing.T) {
+	assert := assert.New(t)
+	md := "Text[^1] and more[^note].\n\n[^1]: First.\n[^note]: Second.\n"
+	actual := string(ToXML(md))
+	assert.Contains(actual, `<sup title="footnote:1"><a href="#fn-1">1</a></sup>`, "Reference not rendered")
+	assert.Contains(actual, `<sup title="footnote:note"><a href="#fn-2">2</a></sup>`, "Reference not rendered")
+	assert.Contains(actual, `<div title="footnotes">`, "Footnote section not rendered")
+	assert.Contains(actual, `<li title="footnote:1">First.</li>`, "Footnote not rendered")
+	assert.Contains(actual, `<li title="footnote:note">Second.</li>`, "Footnote not rendered")
+	assert.NotContains(actual, "id=", "ENML doesn't allow id attributes")
+	assert.NotContains(actual, "class=", "ENML doesn't allow class attributes")
+
+	t.Run("unreferenced footnote is kept", func(t *testing.T) {
+		actual := string(ToXML("Text\n\n[^1]: Not referenced.\n"))
+		assert.Contains(actual, "[^1]: Not referenced.", "Footnote text should not be dropped")
+	})
+}
@@ -0,0 +1,142 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	fenceLine    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	listMarker   = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])( \[[ xX]\])? `)
+	quoteMarker  = regexp.MustCompile(`^(\s*>\s?)+`)
+	headingLine  = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+	ruleLine     = regexp.MustCompile(`^ {0,3}([-*_] *){3,}$`)
+	linkRefLine  = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+	indentedLine = regexp.MustCompile(`^( {4}|\t)`)
+	setextLine   = regexp.MustCompile(`^=+$`)
+)
+
+// ReflowMarkdown wraps the lines of prose in the markdown document so that
+// they are at most width characters long. Fenced code blocks, tables and
+// headings are not changed. Words longer than width are put on a line of
+// their own. If width is less than one, the document is returned unchanged.
+func ReflowMarkdown(md string, width int) string {
+	if width < 1 {
+		return md
+	}
+	lines := strings.Split(md, "\n")
+	out := make([]string, 0, len(lines))
+	var fenceMarker string
+	for _, line := range lines {
+		if fenceMarker != "" {
+			out = append(out, line)
+			if strings.HasPrefix(strings.TrimSpace(line), fenceMarker) {
+				fenceMarker = ""
+			}
+			continue
+		}
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			fenceMarker = m[1]
+			out = append(out, line)
+			continue
+		}
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// wrapLine wraps a single line. List items and block quotes keep their
+// marker on the first line and the continuation lines are indented to
+// match. A continuation line never starts with a word that would make it a
+// block of its own, like a list item, so the line is broken a word earlier
+// or not at all.
+func wrapLine(line string, width int) []string {
+	if utf8.RuneCountInString(line) <= width || !isProse(line) {
+		return []string{line}
+	}
+	prefix, indent := "", ""
+	if m := quoteMarker.FindString(line); m != "" {
+		prefix, indent = m, m
+	}
+	rest := line[len(prefix):]
+	if m := listMarker.FindString(rest); m != "" {
+		prefix += m
+		indent += strings.Repeat(" ", utf8.RuneCountInString(m))
+	} else if prefix == "" && indentedLine.MatchString(rest) {
+		// Indented code blocks and list continuations are left as they are.
+		return []string{line}
+	}
+	content := line[len(prefix):]
+	// Two trailing spaces is a hard line break and has to be kept.
+	hardBreak := strings.HasSuffix(content, "  ")
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return []string{line}
+	}
+
+	var wrapped []string
+	current := prefix + words[0]
+	// start is where the words of the current line start.
+	start := len(prefix)
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width {
+			current += " " + word
+			continue
+		}
+		if !startsBlock(word) {
+			wrapped = append(wrapped, current)
+			current, start = indent+word, len(indent)
+			continue
+		}
+		// Move the last word of the line to the next line with the
+		// word, if it can start the line.
+		i := strings.LastIndex(current, " ")
+		if i < start || startsBlock(current[i+1:]) {
+			current += " " + word
+			continue
+		}
+		wrapped = append(wrapped, current[:i])
+		current, start = indent+current[i+1:]+" "+word, len(indent)
+	}
+	if hardBreak {
+		current += "  "
+	}
+	return append(wrapped, current)
+}
+
+// startsBlock returns true if a line starting with the word would be read
+// as a block, like a list item, a heading or a block quote, instead of as a
+// continuation of the paragraph.
+func startsBlock(word string) bool {
+	return listMarker.MatchString(word+" ") || headingLine.MatchString(word) ||
+		strings.HasPrefix(word, ">") || fenceLine.MatchString(word) ||
+		ruleLine.MatchString(word) || setextLine.MatchString(word)
+}
+
+// isProse returns false for the lines that can't be wrapped without
+// changing the meaning of the document.
+func isProse(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "|") {
+		return false
+	}
+	return !headingLine.MatchString(line) && !ruleLine.MatchString(line) && !linkRefLine.MatchString(line)
+}
@@ -0,0 +1,81 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReflowMarkdown(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		Name     string
+		MD       string
+		Width    int
+		Expected string
+	}{
+		{"short line", "Short line", 20, "Short line"},
+		{"paragraph", "The quick brown fox jumps over the lazy dog", 16, "The quick brown\nfox jumps over\nthe lazy dog"},
+		{"long word", "a verylongwordthatdoesnotfit b", 10, "a\nverylongwordthatdoesnotfit\nb"},
+		{"list item", "- one two three four five", 12, "- one two\n  three four\n  five"},
+		{"numbered todo", "1. [ ] one two three", 12, "1. [ ] one\n       two\n       three"},
+		{"block quote", "> one two three four", 10, "> one two\n> three\n> four"},
+		{"heading", "# A very long heading that is not wrapped", 10, "# A very long heading that is not wrapped"},
+		{"table", "| a very long cell | another long cell |", 10, "| a very long cell | another long cell |"},
+		{"hard break", "one two three  ", 10, "one two\nthree  "},
+		{"nested list continuation", "    indented line that is long", 10, "    indented line that is long"},
+		{"zero width", "The quick brown fox", 0, "The quick brown fox"},
+		{"lines kept", "one two three\n\nfour five six", 9, "one two\nthree\n\nfour five\nsix"},
+		{"no list item", "prices up 5% - a lot", 12, "prices up\n5% - a lot"},
+		{"no numbered item", "see chapter 1. for more", 12, "see\nchapter 1.\nfor more"},
+		{"no plus item", "one two + three", 8, "one\ntwo +\nthree"},
+		{"no heading", "issue number # 12", 13, "issue\nnumber # 12"},
+		{"no block quote", "if a >b then", 4, "if\na >b\nthen"},
+		{"no setext heading", "one two =", 7, "one\ntwo ="},
+		{"no rule", "one two ***", 7, "one\ntwo ***"},
+		{"no block in list item", "- one two - three", 8, "- one\n  two -\n  three"},
+		{"no earlier word to move", "one - two", 3, "one -\ntwo"},
+		{
+			"code block",
+			"```go\nfunc main() { fmt.Println(\"a very long line\") }\n```\nafter the code block",
+			10,
+			"```go\nfunc main() { fmt.Println(\"a very long line\") }\n```\nafter the\ncode block",
+		},
+		{
+			"code block with longer fence",
+			"````\n```\nnot the end of the code block\n````\nwrapped text",
+			8,
+			"````\n```\nnot the end of the code block\n````\nwrapped\ntext",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(test.Expected, ReflowMarkdown(test.MD, test.Width), "Wrong reflow")
+		})
+	}
+
+	t.Run("wrapped lines fit", func(t *testing.T) {
+		md := strings.Repeat("word ", 50)
+		for _, line := range strings.Split(ReflowMarkdown(md, 20), "\n") {
+			assert.True(len(line) <= 20, "Line too long: %q", line)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/russross/blackfriday"
+)
+
+// ErrUnsupportedMarkdown is returned by ToXMLStrict if the markdown has
+// constructs that can't be represented in ENML.
+var ErrUnsupportedMarkdown = errors.New("unsupported markdown")
+
+// maxSnippetLength is the length the raw HTML is cut to in the error.
+const maxSnippetLength = 40
+
+// ToXMLStrict converts the markdown body like ToXMLWithMedia, but instead of
+// dropping the constructs that can't be represented in ENML, an
+// ErrUnsupportedMarkdown listing them is returned. Raw HTML, header IDs,
+// table rows with more cells than the header, references to missing
+// attachments and invalid encrypted sections are reported.
+func ToXMLStrict(mdBody string, media map[string]string) ([]byte, error) {
+	md := normalizeListIndent(escapeUnusedFootnotes(mdBody))
+	r := newENMLRenderer(media)
+	body := blackfriday.Markdown([]byte(md), r, extensions)
+	unsupported := append(tableOverflow(md), r.unsupported...)
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMarkdown, strings.Join(unsupported, "; "))
+	}
+	return body, nil
+}
+
+// BlockHtml records the raw HTML block and renders it as is.
+func (r *enmlRenderer) BlockHtml(out *bytes.Buffer, text []byte) {
+	r.unsupported = append(r.unsupported, "raw HTML "+snippet(text))
+	r.Renderer.BlockHtml(out, text)
+}
+
+// RawHtmlTag records the inline HTML tag and renders it as is.
+func (r *enmlRenderer) RawHtmlTag(out *bytes.Buffer, tag []byte) {
+	r.unsupported = append(r.unsupported, "raw HTML "+snippet(tag))
+	r.Renderer.RawHtmlTag(out, tag)
+}
+
+// Header records the header ID, since ENML doesn't allow id attributes.
+func (r *enmlRenderer) Header(out *bytes.Buffer, text func() bool, level int, id string) {
+	if id != "" {
+		r.unsupported = append(r.unsupported, "header ID "+strconv.Quote(id))
+	}
+	r.Renderer.Header(out, text, level, id)
+}
+
+// snippet returns the first line of the text, quoted and cut to
+// maxSnippetLength.
+func snippet(text []byte) string {
+	s := strings.TrimSpace(string(text))
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + "..."
+	}
+	if len(s) > maxSnippetLength {
+		s = s[:maxSnippetLength] + "..."
+	}
+	return strconv.Quote(s)
+}
+
+var tableDelimiter = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+
+// tableOverflow returns the table rows that have more cells than the
+// header. The extra cells are dropped when the table is converted.
+func tableOverflow(md string) []string {
+	var found []string
+	lines := strings.Split(md, "\n")
+	fenceMarker := ""
+	columns := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		inFence := fenceMarker != ""
+		fenceMarker = updateFence(fenceMarker, trimmed)
+		if inFence || fenceMarker != "" {
+			columns = 0
+			continue
+		}
+		if columns > 0 {
+			if !strings.Contains(trimmed, "|") {
+				columns = 0
+				continue
+			}
+			if cells := tableCells(trimmed); cells > columns {
+				found = append(found, fmt.Sprintf("table row on line %d has %d cells but the table has %d columns", i+1, cells, columns))
+			}
+			continue
+		}
+		if i > 0 && tableDelimiter.MatchString(trimmed) {
+			header := tableCells(strings.TrimSpace(lines[i-1]))
+			if header > 1 && header == tableCells(trimmed) {
+				columns = header
+			}
+		}
+	}
+	return found
+}
+
+// tableCells counts the cells in the table row the same way as blackfriday.
+func tableCells(row string) int {
+	cells := 1
+	for i := 0; i < len(row); i++ {
+		if row[i] == '|' && !escaped(row, i) {
+			cells++
+		}
+	}
+	if strings.HasPrefix(row, "|") {
+		cells--
+	}
+	if len(row) > 1 && strings.HasSuffix(row, "|") && !escaped(row, len(row)-1) {
+		cells--
+	}
+	return cells
+}
+
+// escaped reports if the character at i is preceded by an odd number of
+// backslashes.
+func escaped(s string, i int) bool {
+	n := 0
+	for i-n-1 >= 0 && s[i-n-1] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
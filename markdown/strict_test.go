@@ -0,0 +1,68 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToXMLStrict(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("supported markdown", func(t *testing.T) {
+		md := "# Title\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n![image](en-media:ABC)\n\n```\n<div>code</div>\n```\n"
+		actual, err := ToXMLStrict(md, map[string]string{"abc": "image/png"})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(string(ToXMLWithMedia(md, map[string]string{"abc": "image/png"})), string(actual), "Should convert like ToXMLWithMedia")
+	})
+
+	tests := []struct {
+		name    string
+		md      string
+		problem string
+	}{
+		{"raw HTML block", "Text\n\n<div class=\"x\">\nblock\n</div>\n", `raw HTML "<div class=\"x\">..."`},
+		{"inline HTML", "Some <span style=\"color:red\">red</span> text", `raw HTML "<span style=\"color:red\">"`},
+		{"header ID", "# Title {#title}\n", `header ID "title"`},
+		{"table row with extra cells", "| a | b |\n|---|---|\n| 1 | 2 | 3 |\n", "table row on line 3 has 3 cells but the table has 2 columns"},
+		{"missing attachment", "![image](en-media:ABC)", `reference to a missing attachment "abc"`},
+		{"invalid encrypted section", "![encrypted](en-crypt:invalid)", "invalid encrypted section"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ToXMLStrict(test.md, nil)
+			assert.True(errors.Is(err, ErrUnsupportedMarkdown), "Should return ErrUnsupportedMarkdown")
+			if assert.Error(err) {
+				assert.Contains(err.Error(), test.problem, "Should list the construct")
+			}
+		})
+	}
+
+	t.Run("list all the constructs", func(t *testing.T) {
+		_, err := ToXMLStrict("# Title {#id}\n\n<div>html</div>\n", nil)
+		assert.EqualError(err, `unsupported markdown: header ID "id"; raw HTML "<div>html</div>"`)
+	})
+
+	t.Run("ignore tables in code blocks", func(t *testing.T) {
+		_, err := ToXMLStrict("```\n| a | b |\n|---|---|\n| 1 | 2 | 3 |\n```\n", nil)
+		assert.NoError(err, "Should not return an error")
+	})
+}
@@ -0,0 +1,115 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// convertTable converts the table to a pipe table. The first row is the
+// header, unless it has no header cells. The column alignment is kept in
+// the separator row.
+func convertTable(node *html.Node) string {
+	var rows [][]string
+	var aligns []string
+	header := false
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch tagName(c) {
+			case "thead", "tbody", "tfoot":
+				collect(c)
+			case "tr":
+				var row []string
+				allHeaders := true
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					name := tagName(cell)
+					if name != "th" && name != "td" {
+						continue
+					}
+					if name != "th" {
+						allHeaders = false
+					}
+					if len(rows) == 0 {
+						aligns = append(aligns, cellAlignment(cell))
+					}
+					row = append(row, strings.Replace(inlineText(cell), "|", `\|`, -1))
+				}
+				if len(rows) == 0 && len(row) > 0 && (allHeaders || tagName(c.Parent) == "thead") {
+					header = true
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	collect(node)
+	if len(rows) == 0 {
+		return ""
+	}
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	for len(aligns) < cols {
+		aligns = append(aligns, "")
+	}
+	if !header {
+		// Markdown tables need a header row, use an empty one.
+		rows = append([][]string{make([]string, cols)}, rows...)
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			seps := make([]string, cols)
+			for j, a := range aligns {
+				seps[j] = alignmentMarker(a)
+			}
+			lines = append(lines, "| "+strings.Join(seps, " | ")+" |")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func cellAlignment(cell *html.Node) string {
+	if a := attr(cell, "align"); a != "" {
+		return strings.ToLower(a)
+	}
+	return strings.ToLower(styleProperty(cell, "text-align"))
+}
+
+func alignmentMarker(align string) string {
+	switch align {
+	case "left":
+		return ":---"
+	case "right":
+		return "---:"
+	case "center":
+		return ":---:"
+	}
+	return "---"
+}
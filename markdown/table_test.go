@@ -0,0 +1,76 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromHTMLTable(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{
+			"header in thead",
+			"<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody><tr><td>a</td><td>1</td></tr></tbody></table>",
+			"| Name | Value |\n| --- | --- |\n| a | 1 |",
+		},
+		{
+			"header without thead",
+			"<table><tr><th>Name</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>",
+			"| Name | Value |\n| --- | --- |\n| a | 1 |",
+		},
+		{
+			"no header",
+			"<table><tr><td>a</td><td>1</td></tr><tr><td>b</td></tr></table>",
+			"|  |  |\n| --- | --- |\n| a | 1 |\n| b |  |",
+		},
+		{
+			"alignment",
+			`<table><tr><th align="left">a</th><th style="text-align: right;">b</th><th align="center">c</th></tr><tr><td>1</td><td>2</td><td>3</td></tr></table>`,
+			"| a | b | c |\n| :--- | ---: | :---: |\n| 1 | 2 | 3 |",
+		},
+		{
+			"escape pipes and inline formatting",
+			"<table><tr><th>a|b</th></tr><tr><td><b>bold</b>\n text</td></tr></table>",
+			"| a\\|b |\n| --- |\n| **bold** text |",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromHTML(test.doc)
+			assert.NoError(err, "Should parse the doc without an error")
+			assert.Equal(test.expected, actual, "Table not converted")
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		md := "Text\n\n| a | b |\n| :--- | ---: |\n| 1 | 2 |"
+		xml := string(ToXML(md))
+		assert.Contains(xml, "<thead>\n<tr>\n<th align=\"left\">a</th>", "Header should be the first row")
+		assert.NotContains(xml, ":---", "Alignment markers should not be in the output")
+		actual, err := FromHTML(xml)
+		assert.NoError(err, "Should parse the doc without an error")
+		assert.Equal(md, actual, "Table not preserved")
+	})
+}
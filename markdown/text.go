@@ -0,0 +1,86 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// TextFromHTML converts the HTML body of a note to plain text without any
+// markdown. Each div is a line and line breaks are kept.
+func TextFromHTML(body string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	writeText(doc, buf)
+	return strings.Trim(buf.String(), "\n"), nil
+}
+
+// writeText writes the text of the node. Blocks are written on their own
+// lines.
+func writeText(node *html.Node, buf *bytes.Buffer) {
+	if node.Type == html.TextNode {
+		buf.WriteString(replaceNbsp(node.Data))
+		return
+	}
+	if tagName(node) == "br" {
+		buf.WriteString("\n")
+		return
+	}
+	block := node.Type == html.ElementNode && isBlock(node)
+	if block {
+		endLine(buf)
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		writeText(c, buf)
+	}
+	if block {
+		endLine(buf)
+	}
+}
+
+// endLine ends the current line unless it is already ended.
+func endLine(buf *bytes.Buffer) {
+	if b := buf.Bytes(); len(b) > 0 && b[len(b)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+}
+
+// TextToXML converts plain text to ENML without interpreting it as markdown.
+// Each line is written in its own div and empty lines as a div with only a
+// line break.
+func TextToXML(text string) []byte {
+	buf := new(bytes.Buffer)
+	text = strings.Trim(text, "\n")
+	if text == "" {
+		return buf.Bytes()
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			buf.WriteString("<div><br/></div>")
+			continue
+		}
+		buf.WriteString("<div>" + html.EscapeString(line) + "</div>")
+	}
+	return buf.Bytes()
+}
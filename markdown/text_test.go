@@ -0,0 +1,47 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainText(t *testing.T) {
+	assert := assert.New(t)
+	text := "# Not a heading\n- not a list\n\n<b>tags</b> & *stars*"
+	xml := "<div># Not a heading</div><div>- not a list</div><div><br/></div>" +
+		"<div>&lt;b&gt;tags&lt;/b&gt; &amp; *stars*</div>"
+
+	t.Run("to xml", func(t *testing.T) {
+		assert.Equal(xml, string(TextToXML(text)), "Each line should be a div")
+	})
+
+	t.Run("from html", func(t *testing.T) {
+		actual, err := TextFromHTML("<en-note>" + xml + "</en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(text, actual, "Text should survive the round trip")
+	})
+
+	t.Run("line breaks", func(t *testing.T) {
+		actual, err := TextFromHTML("<en-note>One<br/>Two<div><b>Three</b></div></en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("One\nTwo\nThree", actual, "Line breaks should be kept")
+	})
+}
@@ -28,14 +28,25 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/TcM1911/clinote/markdown"
 	uuid "github.com/satori/go.uuid"
 )
 
 const (
+	// DefaultNoteTitle is the title used for new notes without a title.
+	DefaultNoteTitle = "Untitled note"
+	// DefaultSourceApplication is the source application of new notes
+	// that don't have one set.
+	DefaultSourceApplication = "clinote"
 	// XMLHeader is the header that needs to added to the note content.
 	XMLHeader = `<?xml version="1.0" encoding="UTF-8"?><!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">`
 	// headSep indicates the start and end of the note header
@@ -43,6 +54,13 @@ const (
 	headSep               = "---"
 	headTitleField        = "title:"
 	headNotebookNameField = "notebook:"
+	headTagsField         = "tags:"
+	headReminderField     = "reminder:"
+	headSourceField       = "source:"
+	headSourceURLField    = "source-url:"
+	headCreatedField      = "created:"
+	headUpdatedField      = "updated:"
+	headTagsSep           = ","
 	newNotePrependString  = "new_note_"
 )
 
@@ -62,6 +80,34 @@ var (
 var (
 	// ErrNoNoteFound is returned if search resulted in no notes found.
 	ErrNoNoteFound = errors.New("no note found")
+	// ErrNoteLimitReached is returned when a search for all the matching
+	// notes is stopped after MaxFindNotes notes.
+	ErrNoteLimitReached = errors.New("the maximum number of notes was reached")
+	// ErrMultipleNotesFound is returned if more than one note partially
+	// matches the title. The error is wrapped with the matching titles.
+	ErrMultipleNotesFound = errors.New("multiple notes found")
+	// ErrNoteConflict is returned if the note has been changed on the server
+	// since it was fetched.
+	ErrNoteConflict = errors.New("the note has been changed on the server")
+	// ErrNoteTooLarge is returned by the notestore if the ENML of the note
+	// is larger than its limit. The error is wrapped with the size of the
+	// content.
+	ErrNoteTooLarge = errors.New("the note is too large")
+	// ErrNoAttachmentData is returned if the data of an attachment isn't
+	// returned by the server. The error is wrapped with the filename.
+	ErrNoAttachmentData = errors.New("the data of the attachment is missing")
+	// ErrDuplicateNote is returned if a note with the same title already
+	// exists in the notebook.
+	ErrDuplicateNote = errors.New("a note with the title already exists in the notebook")
+	// ErrExportFileExists is returned if the default export file already
+	// exists and the ForceNote option isn't given.
+	ErrExportFileExists = errors.New("the export file already exists")
+	// ErrInvalidNoteTime is returned if the created or updated time in the
+	// note header can't be parsed.
+	ErrInvalidNoteTime = errors.New("invalid note time")
+	// ErrFutureCreatedTime is returned if the created time in the note
+	// header is in the future.
+	ErrFutureCreatedTime = errors.New("the created time is in the future")
 )
 
 // NoteOption are used for options around notes.
@@ -77,6 +123,28 @@ const (
 	UseRecoveryPointNote
 	// StdinNote will read note contents from stdin
 	StdinNote
+	// ForceNote saves the note even if it has been changed on the server.
+	ForceNote
+	// DryRunNote prints the note content that would be uploaded instead of
+	// saving it to the server.
+	DryRunNote
+	// NoHeaderNote writes the note without the header.
+	NoHeaderNote
+	// PlainTextNote will display or edit the note as plain text, without
+	// interpreting it as markdown.
+	PlainTextNote
+	// ShowDiffNote prints a diff of the changes before an edited note is
+	// saved.
+	ShowDiffNote
+	// UniqueTitleNote refuses to create a new note if a note with the same
+	// title already exists in the notebook.
+	UniqueTitleNote
+	// StrictMarkdownNote refuses to save the note if the markdown has
+	// constructs that can't be represented in ENML.
+	StrictMarkdownNote
+	// EmojiNote converts the :shortcode: sequences in the markdown to
+	// their emoji when the note is saved.
+	EmojiNote
 )
 
 // Note is the structure of an Evernote note.
@@ -93,6 +161,32 @@ type Note struct {
 	Deleted bool
 	// Notebook the note belongs to.
 	Notebook *Notebook
+	// Tags is a list of the tag names the note is tagged with.
+	Tags []string
+	// TagGUIDs is a list of the GUIDs for the tags the note is tagged with.
+	// Notes returned by a search only have the GUIDs set.
+	TagGUIDs []string
+	// Resources are the files attached to the note.
+	Resources []*Attachment
+	// Reminder is the note's reminder. A nil reminder leaves the note's
+	// reminder untouched when the note is saved while an empty reminder
+	// removes it.
+	Reminder *Reminder
+	// SourceApplication is the application that created the note. An
+	// empty value leaves the note's source untouched when it is saved.
+	SourceApplication string
+	// SourceURL is the URL the note's content came from, for example for
+	// a web clip. An empty value leaves the URL untouched when the note
+	// is saved.
+	SourceURL string
+	// ExtraHeaders are the fields in the note header that aren't known,
+	// like user annotations, by their name. They are written back to the
+	// header so they survive being edited, exported and imported, but
+	// they aren't saved to the notestore.
+	ExtraHeaders map[string]string
+	// BodyAttrs are the attributes of the en-note element, like the font
+	// and background of the note.
+	BodyAttrs string `xml:"-"`
 	// Created
 	Created int64
 	// Updated
@@ -112,25 +206,167 @@ func (n *Note) Hash(raw bool) []byte {
 	return hasher.Sum(nil)
 }
 
+var xmlTags = regexp.MustCompile(`<[^>]*>`)
+
+// Stats returns the number of words and characters in the note. The
+// markdown content is counted if it exists, otherwise the body is used
+// without the tags.
+func (n *Note) Stats() (words int, chars int) {
+	content := n.MD
+	if content == "" {
+		content = html.UnescapeString(xmlTags.ReplaceAllString(n.Body, " "))
+	}
+	words = len(strings.Fields(content))
+	chars = utf8.RuneCountInString(strings.TrimSpace(content))
+	return words, chars
+}
+
 // NoteFilter is the search filter for notes.
 type NoteFilter struct {
 	// NotebookGUID is the GUID for the notebook to limit the search to.
 	NotebookGUID string
 	// Words can be a search string or note title.
 	Words string
+	// TagGUIDs restricts the search to notes tagged with all the tags.
+	TagGUIDs []string
+	// Stack restricts the search to notebooks in the stack.
+	Stack string
+	// CreatedAfter restricts the search to notes created at or after the
+	// time, in milliseconds since the epoch.
+	CreatedAfter int64
+	// CreatedBefore restricts the search to notes created before the time,
+	// in milliseconds since the epoch.
+	CreatedBefore int64
+	// UpdatedAfter restricts the search to notes updated at or after the
+	// time, in milliseconds since the epoch.
+	UpdatedAfter int64
+	// UpdatedBefore restricts the search to notes updated before the time,
+	// in milliseconds since the epoch.
+	UpdatedBefore int64
 	// Order
 	Order int32
+	// Reverse reverses the sort order. Titles are sorted from A to Z and
+	// the other orders list the largest first, unless Reverse is set.
+	Reverse bool
 }
 
-// FindNotes searches for notes.
+// inRange returns true if the note is within the filter's time range.
+func (f *NoteFilter) inRange(n *Note) bool {
+	if f.CreatedAfter != 0 && n.Created < f.CreatedAfter {
+		return false
+	}
+	if f.CreatedBefore != 0 && n.Created >= f.CreatedBefore {
+		return false
+	}
+	if f.UpdatedAfter != 0 && n.Updated < f.UpdatedAfter {
+		return false
+	}
+	if f.UpdatedBefore != 0 && n.Updated >= f.UpdatedBefore {
+		return false
+	}
+	return true
+}
+
+// FindNotes searches for notes. Notes outside of the filter's time range are
+// never returned, even if the notestore includes them.
 func FindNotes(ns NotestoreClient, filter *NoteFilter, offset int, count int) ([]*Note, error) {
-	return ns.FindNotes(filter, offset, count)
+	notes, err := ns.FindNotes(filter, offset, count)
+	if err != nil {
+		return nil, err
+	}
+	list := notes[:0]
+	for _, n := range notes {
+		if filter.inRange(n) {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+// MaxFindNotes is the largest number of notes fetched by a search for all
+// the matching notes.
+const MaxFindNotes = 10000
+
+// DefaultMaxNoteSize is the largest ENML content, in bytes, Evernote
+// accepts for a note.
+const DefaultMaxNoteSize = 5242880
+
+// FindNotesStream searches for notes like FindNotes but sends the notes on
+// the returned channel as they are fetched. The notes are fetched in batches,
+// so a large count gives results before the whole search is done. A count of
+// zero fetches all the matching notes. To guard against a search that never
+// ends, it is stopped after MaxFindNotes notes and ErrNoteLimitReached is
+// returned. Both channels are closed when the search is done. If the search
+// fails, the error is sent on the error channel before the channels are
+// closed. The notestore is used until the channels are closed, so it
+// shouldn't be used for anything else before that.
+func FindNotesStream(ns NotestoreClient, filter *NoteFilter, offset, count int) (<-chan *Note, <-chan error) {
+	notes := make(chan *Note)
+	errs := make(chan error, 1)
+	unlimited := count == 0
+	if unlimited {
+		count = MaxFindNotes
+	}
+	go func() {
+		defer close(errs)
+		defer close(notes)
+		for fetched := 0; fetched < count; {
+			size := findNotesBatchSize
+			if count-fetched < size {
+				size = count - fetched
+			}
+			batch, err := ns.FindNotes(filter, offset+fetched, size)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for _, n := range batch {
+				if filter.inRange(n) {
+					notes <- n
+				}
+			}
+			fetched += len(batch)
+			if len(batch) < size {
+				return
+			}
+		}
+		if unlimited {
+			errs <- ErrNoteLimitReached
+		}
+	}()
+	return notes, errs
+}
+
+// guidPattern matches an Evernote GUID in the UUID form.
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// NoteLink returns the link that opens the note in the Evernote app.
+func NoteLink(n *Note, shardID, userID string) string {
+	return fmt.Sprintf("evernote:///view/%s/%s/%s/%s/", userID, shardID, n.GUID, n.GUID)
+}
+
+// NoteWebLink returns the link that opens the note in the Evernote web
+// client.
+func NoteWebLink(n *Note, shardID, userID string) string {
+	return fmt.Sprintf("https://www.evernote.com/shard/%s/nl/%s/%s/", shardID, userID, n.GUID)
 }
 
 // GetNote gets the note metadata in the notebook from the server.
 // If the notebook is an empty string, the first matching note will
-// be returned.
+// be returned. If the title is a note GUID, the note is fetched
+// directly without a search. If the title is an index in the last
+// search, the note's current metadata is fetched since the search
+// has the metadata from when it was made. In offline mode, the note
+// is looked up in the last search instead.
 func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, error) {
+	if guid := strings.TrimSpace(title); guidPattern.MatchString(guid) {
+		n, err := ns.GetNote(guid)
+		if errors.Is(err, ErrOffline) {
+			return findCachedNote(db, guid)
+		}
+		return n, err
+	}
+
 	// Check if the title is a number. If it is
 	// assume that the user wants to get the note
 	// from a saved search.
@@ -142,7 +378,11 @@ func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, er
 			return nil, err
 		}
 		if index <= len(notes) {
-			return notes[index-1], nil
+			n, err := ns.GetNote(notes[index-1].GUID)
+			if errors.Is(err, ErrOffline) {
+				return notes[index-1], nil
+			}
+			return n, err
 		}
 	}
 
@@ -154,146 +394,926 @@ func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, er
 		}
 		filter.NotebookGUID = nb.GUID
 	}
+	title = strings.TrimSpace(title)
 	filter.Words = title
 	notes, err := ns.FindNotes(filter, 0, 20)
+	if errors.Is(err, ErrOffline) {
+		n, err := findCachedNote(db, title)
+		if err == nil && filter.NotebookGUID != "" && (n.Notebook == nil || n.Notebook.GUID != filter.NotebookGUID) {
+			return nil, ErrNoNoteFound
+		}
+		return n, err
+	}
 	if err != nil {
 		return nil, err
 	}
-	var note *Note
+	return matchNoteTitle(notes, title)
+}
+
+// matchNoteTitle returns the note with the title. Titles are matched case
+// insensitive and without surrounding whitespace. If no title matches, the
+// only note with a title containing the search term is returned.
+func matchNoteTitle(notes []*Note, title string) (*Note, error) {
 	for _, n := range notes {
-		if n.Title == title {
-			note = n
-			break
+		if strings.EqualFold(strings.TrimSpace(n.Title), title) {
+			return n, nil
 		}
 	}
-	if note == nil {
+	term := strings.ToLower(title)
+	if term == "" {
 		return nil, ErrNoNoteFound
 	}
-	return note, nil
+	var matches []*Note
+	for _, n := range notes {
+		if strings.Contains(strings.ToLower(n.Title), term) {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, ErrNoNoteFound
+	case 1:
+		return matches[0], nil
+	}
+	titles := make([]string, len(matches))
+	for i, n := range matches {
+		titles[i] = strconv.Quote(n.Title)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMultipleNotesFound, strings.Join(titles, ", "))
 }
 
 // GetNoteWithContent returns the note with content from the user's notestore.
+// If the content has been cached since the note was last updated, the cached
+// content is used. The content is converted with the MarkdownConverter.
 func GetNoteWithContent(db Storager, ns NotestoreClient, title string) (*Note, error) {
+	return getNoteWithContent(MarkdownConverter{}, db, ns, title, DefaultNoteOption)
+}
+
+// GetNoteWithContent returns the note with content like GetNoteWithContent,
+// but converts the content with the client's Converter.
+func (c *Client) GetNoteWithContent(title string) (*Note, error) {
+	return getNoteWithContent(c.converter(), c.Store, c.NoteStore, title, DefaultNoteOption)
+}
+
+func getNoteWithContent(conv Converter, db Storager, ns NotestoreClient, title string, opts NoteOption) (*Note, error) {
 	n, err := GetNote(db, ns, title, "")
-	content, err := ns.GetNoteContent(n.GUID)
 	if err != nil {
 		return nil, err
 	}
-	err = decodeXML(content, n)
+	if err = loadNoteContent(conv, db, ns, n, opts); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// StreamNoteContent writes the ENML content of the note with the GUID to
+// the writer as it is returned by the notestore. The content isn't parsed,
+// converted to markdown or cached, so only one copy of it is kept in
+// memory. This makes it suitable for dumping many large notes.
+func StreamNoteContent(ns NotestoreClient, guid string, w io.Writer) error {
+	content, err := ns.GetNoteContent(guid)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, strings.NewReader(content))
+	return err
+}
+
+// loadNoteContent gets the note's content and sets the body and markdown.
+// With the PlainTextNote option, the content is set as plain text instead
+// of markdown.
+func loadNoteContent(conv Converter, db Storager, ns NotestoreClient, n *Note, opts NoteOption) error {
+	content, err := getNoteContent(db, ns, n)
+	if err != nil {
+		return err
+	}
+	return setNoteContent(conv, n, content, opts)
+}
+
+// setNoteContent sets the body and markdown of the note from the ENML
+// content. With the PlainTextNote option, the content is set as plain text
+// instead of markdown, otherwise the converter is used.
+func setNoteContent(conv Converter, n *Note, content string, opts NoteOption) error {
+	content, sanitized := sanitizeXML(content)
+	if sanitized {
+		fmt.Fprintf(warningOutput, "Warning: the content of the note %q is malformed and has been repaired.\n", n.Title)
+	}
+	err := decodeXML(content, n)
+	if err != nil {
+		return err
+	}
+	n.BodyAttrs = bodyAttrs(content)
+	if opts&PlainTextNote != 0 {
+		n.MD, err = markdown.TextFromHTML(n.Body)
+		return err
+	}
+	n.MD, err = conv.FromENML(n.Body)
+	return err
+}
+
+func getNoteContent(db Storager, ns NotestoreClient, n *Note) (string, error) {
+	// Without an updated time, it's not possible to know if the cache is valid.
+	if n.Updated == 0 {
+		return ns.GetNoteContent(n.GUID)
+	}
+	content, err := db.GetCachedContent(n.GUID, n.Updated)
+	if err != nil || content != "" {
+		return content, err
+	}
+	content, err = ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return "", err
+	}
+	return content, db.SaveCachedContent(n.GUID, n.Updated, content)
+}
+
+// SaveChanges updates the changes to the note on the server. If the note has
+// been changed on the server since it was fetched, ErrNoteConflict is returned
+// unless the ForceNote option is given. With the DryRunNote option, the
+// content is printed instead of saved. The content is converted with the
+// MarkdownConverter.
+func SaveChanges(ns NotestoreClient, n *Note, opts NoteOption) error {
+	return saveNoteChanges(MarkdownConverter{}, ns, n, opts)
+}
+
+// SaveChanges updates the changes to the note on the server like
+// SaveChanges, but converts the content with the client's Converter.
+func (c *Client) SaveChanges(n *Note, opts NoteOption) error {
+	return saveNoteChanges(c.converter(), c.NoteStore, n, opts)
+}
+
+func saveNoteChanges(conv Converter, ns NotestoreClient, n *Note, opts NoteOption) error {
+	body, err := noteXML(conv, n, opts)
+	if err != nil {
+		return err
+	}
+	if opts&DryRunNote != 0 {
+		return printDryRun(body)
+	}
+	if opts&ForceNote == 0 {
+		if err := checkForConflict(ns, n); err != nil {
+			return err
+		}
+	}
+	n.Body = body
+	return ns.UpdateNote(n)
+}
+
+// checkForConflict compares the note's updated time with the time on the server.
+func checkForConflict(ns NotestoreClient, n *Note) error {
+	// New notes can't have a conflict.
+	if n.GUID == "" {
+		return nil
+	}
+	current, err := ns.GetNote(n.GUID)
+	if err != nil {
+		return err
+	}
+	if current.Updated != n.Updated {
+		return ErrNoteConflict
+	}
+	return nil
+}
+
+// ChangeTitle changes the note's title. The note can be given by its title,
+// its index in the last search or its GUID. ErrNoNoteFound is returned if
+// the note doesn't exist.
+func ChangeTitle(db Storager, ns NotestoreClient, old, new string) error {
+	n, err := GetNote(db, ns, old, "")
+	if err != nil {
+		return err
+	}
+	n.Title = new
+	return ns.UpdateNote(n)
+}
+
+// MoveNote moves the note to a new notebook.
+func MoveNote(db Storager, ns NotestoreClient, noteTitle, notebookName string) error {
+	n, err := GetNote(db, ns, noteTitle, "")
+	if err != nil {
+		return err
+	}
+	b, err := FindNotebook(db, ns, notebookName)
+	if err != nil {
+		return err
+	}
+	n.Notebook = b
+	return ns.UpdateNote(n)
+}
+
+// MoveNotesMatching moves all the notes matching the filter into the target
+// notebook. Notes already in the notebook are left as is. A note that fails
+// to be moved doesn't stop the rest from being moved, the failures are
+// returned as one error together with the number of moved notes.
+func MoveNotesMatching(db Storager, ns NotestoreClient, filter *NoteFilter, targetNotebook string) (int, error) {
+	b, err := FindNotebook(db, ns, targetNotebook)
+	if err != nil {
+		return 0, err
+	}
+	notes, err := findAllNotes(ns, filter, 0)
+	if err != nil {
+		return 0, err
+	}
+	moved := 0
+	var failed []string
+	for _, n := range notes {
+		if n.Notebook != nil && n.Notebook.GUID == b.GUID {
+			continue
+		}
+		n.Notebook = b
+		if err := ns.UpdateNote(n); err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %s", n.Title, err))
+			continue
+		}
+		moved++
+	}
+	if len(failed) > 0 {
+		return moved, fmt.Errorf("failed to move %d of %d notes: %s", len(failed), len(notes), strings.Join(failed, "; "))
+	}
+	return moved, nil
+}
+
+// AppendNote adds the content to the end of the note. The content is added
+// as markdown unless the RawNote option is given.
+func AppendNote(db Storager, ns NotestoreClient, title, content string, opts NoteOption) error {
+	return addToNote(db, ns, title, content, false, opts)
+}
+
+// PrependNote adds the content to the beginning of the note. The content is
+// added as markdown unless the RawNote option is given.
+func PrependNote(db Storager, ns NotestoreClient, title, content string, opts NoteOption) error {
+	return addToNote(db, ns, title, content, true, opts)
+}
+
+func addToNote(db Storager, ns NotestoreClient, title, content string, prepend bool, opts NoteOption) error {
+	n, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return err
+	}
+	content = strings.Trim(content, "\n")
+	if content == "" {
+		return nil
+	}
+	join := func(old string) string {
+		old = strings.Trim(old, "\n")
+		if old == "" {
+			return content
+		}
+		if prepend {
+			return content + "\n\n" + old
+		}
+		return old + "\n\n" + content
+	}
+	if opts&RawNote != 0 {
+		n.Body = join(n.Body)
+	} else {
+		n.MD = join(n.MD)
+	}
+	return SaveChanges(ns, n, opts)
+}
+
+// DeleteNote moves a note from the notebook to the trash can.
+func DeleteNote(db Storager, ns NotestoreClient, title, notebook string) error {
+	n, err := GetNote(db, ns, title, notebook)
+	if err != nil {
+		return err
+	}
+	err = ns.DeleteNote(n.GUID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ExpungeNote permanently removes the note from the server. Unlike
+// DeleteNote, the note isn't moved to the trash and can't be restored.
+func ExpungeNote(db Storager, ns NotestoreClient, title, notebook string) error {
+	n, err := GetNote(db, ns, title, notebook)
+	if err != nil {
+		return err
+	}
+	return ns.ExpungeNote(n.GUID)
+}
+
+// ListTrash returns the notes in the trash.
+func ListTrash(ns NotestoreClient) ([]*Note, error) {
+	return ns.ListTrash()
+}
+
+// RestoreNote moves the note with the title out of the trash. If the note's
+// notebook has been deleted, the note is restored to the default notebook.
+func RestoreNote(db Storager, ns NotestoreClient, title string) error {
+	notes, err := ns.ListTrash()
+	if err != nil {
+		return err
+	}
+	n, err := matchNoteTitle(notes, strings.TrimSpace(title))
+	if err != nil {
+		return err
+	}
+	exists := false
+	if n.Notebook != nil {
+		exists, err = notebookExists(db, ns, n.Notebook.GUID)
+		if err != nil {
+			return err
+		}
+	}
+	if !exists {
+		nb, err := ns.GetDefaultNotebook()
+		if err != nil {
+			return err
+		}
+		n.Notebook = nb
+		if err = ns.UpdateNote(n); err != nil {
+			return err
+		}
+	}
+	return ns.RestoreNote(n.GUID)
+}
+
+// notebookExists checks against the notebooks on the server if the
+// notebook exists.
+func notebookExists(db Storager, ns NotestoreClient, guid string) (bool, error) {
+	bs, err := GetNotebooks(db, ns, true)
+	if err != nil {
+		return false, err
+	}
+	for _, b := range bs {
+		if b.GUID == guid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findNotesBatchSize is the number of notes requested at a time when
+// searching for more notes than a single request returns.
+const findNotesBatchSize = 100
+
+// DeleteNotesMatching moves the notes matching the filter to the trash.
+// At most limit notes are deleted, a limit of zero or less deletes all the
+// matching notes. A note that fails to be deleted doesn't stop the rest
+// from being deleted, the failures are returned as one error together with
+// the number of deleted notes.
+func DeleteNotesMatching(db Storager, ns NotestoreClient, filter *NoteFilter, limit int) (int, error) {
+	notes, err := findAllNotes(ns, filter, limit)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	var failed []string
+	for _, n := range notes {
+		if err := ns.DeleteNote(n.GUID); err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %s", n.Title, err))
+			continue
+		}
+		deleted++
+	}
+	if len(failed) > 0 {
+		return deleted, fmt.Errorf("failed to delete %d of %d notes: %s", len(failed), len(notes), strings.Join(failed, "; "))
+	}
+	return deleted, nil
+}
+
+// findAllNotes returns up to limit notes matching the filter. If limit is
+// zero or less, all the matching notes are returned.
+func findAllNotes(ns NotestoreClient, filter *NoteFilter, limit int) ([]*Note, error) {
+	var notes []*Note
+	for limit <= 0 || len(notes) < limit {
+		count := findNotesBatchSize
+		if limit > 0 && limit-len(notes) < count {
+			count = limit - len(notes)
+		}
+		batch, err := ns.FindNotes(filter, len(notes), count)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, batch...)
+		if len(batch) < count {
+			break
+		}
+	}
+	return notes, nil
+}
+
+// CopyNote creates a new note with the content of the note matching title.
+// The copy is saved to notebook, or to the same notebook as the original if
+// notebook is empty. The data of the attachments is fetched and they are
+// copied with the same hash, so the media references in the content are
+// kept. If the data of an attachment can't be fetched, ErrNoAttachmentData
+// is returned instead of creating a copy without it.
+func CopyNote(db Storager, ns NotestoreClient, title, newTitle, notebook string) (*Note, error) {
+	src, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return nil, err
+	}
+	n := &Note{
+		Title:             newTitle,
+		MD:                src.MD,
+		Notebook:          src.Notebook,
+		SourceApplication: src.SourceApplication,
+		SourceURL:         src.SourceURL,
+	}
+	if n.Title == "" {
+		n.Title = src.Title
+	}
+	if len(src.Tags) > 0 {
+		n.Tags = append([]string{}, src.Tags...)
+	}
+	if len(src.Resources) > 0 {
+		// The note's metadata doesn't have the data of the attachments.
+		full, err := ns.GetNoteWithResources(src.GUID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range full.Resources {
+			if len(a.Data) == 0 {
+				return nil, fmt.Errorf("%w: %q", ErrNoAttachmentData, a.Filename)
+			}
+			cp := *a
+			n.Resources = append(n.Resources, &cp)
+		}
+	}
+	if notebook != "" {
+		nb, err := FindNotebook(db, ns, notebook)
+		if err != nil {
+			return nil, err
+		}
+		n.Notebook = nb
+	}
+	if err = SaveNewNote(ns, n, DefaultNoteOption); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// ExportNote writes the note, including the header, to the file at path. The
+// content is written as markdown unless the RawNote option is given. An
+// existing file is overwritten.
+//
+// If path is empty, the note is written to a file in the current directory
+// named after the note title. In this case, an existing file is only
+// overwritten if the ForceNote option is given.
+func ExportNote(db Storager, ns NotestoreClient, title, path string, opts NoteOption) error {
+	return ExportNoteWrapped(db, ns, title, path, opts, 0)
+}
+
+// ExportNoteWrapped exports the note like ExportNote, with the markdown
+// wrapped at the wrap column. The markdown isn't wrapped if wrap is less
+// than one.
+func ExportNoteWrapped(db Storager, ns NotestoreClient, title, path string, opts NoteOption, wrap int) error {
+	n, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return err
+	}
+	if err = LoadTagNames(ns, n); err != nil {
+		return err
+	}
+	n.MD = markdown.ReflowMarkdown(n.MD, wrap)
+	if n.Notebook != nil && n.Notebook.GUID != "" && n.Notebook.Name == "" {
+		nb, err := GetNotebook(ns, n.Notebook.GUID)
+		if err != nil {
+			return err
+		}
+		n.Notebook = nb
+	}
+	f, err := createExportFile(path, n, noteFileExtension(opts), opts)
+	if err != nil {
+		return err
+	}
+	if err = WriteNote(f, n, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// createExportFile creates the file the note is exported to. If path is
+// empty, the file is named after the note title with the extension and
+// ErrExportFileExists is returned if it already exists, unless the ForceNote
+// option is given.
+func createExportFile(path string, n *Note, ext string, opts NoteOption) (*os.File, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if path == "" {
+		path = exportFilename(n, ext)
+		if opts&ForceNote == 0 {
+			flag |= os.O_EXCL
+		}
+	}
+	f, err := os.OpenFile(path, flag, 0600)
+	if os.IsExist(err) {
+		return nil, ErrExportFileExists
+	}
+	return f, err
+}
+
+// ImportNote creates a new note from the file at path. The title, notebook
+// and tags are read from the header. If the file has no header, the
+// filename without the extension is used as the title.
+func ImportNote(ns NotestoreClient, path string, opts NoteOption) (*Note, error) {
+	return importNote(ns, path, nil, opts)
+}
+
+// importNote imports the note from the file at path. If nb isn't nil, the
+// note is saved to it instead of the notebook in the header.
+func importNote(ns NotestoreClient, path string, nb *Notebook, opts NoteOption) (*Note, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n := new(Note)
+	if hasNoteHeader(data) {
+		err = parseNote(bytes.NewReader(data), n, opts)
+	} else {
+		err = parseContent(bufio.NewScanner(bytes.NewReader(data)), n, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
-	n.MD, err = markdown.FromHTML(n.Body)
+	if n.Title == "" {
+		base := filepath.Base(path)
+		n.Title = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if nb != nil {
+		n.Notebook = nb
+	} else if n.Notebook != nil && n.Notebook.Name != "" {
+		nb, err := findNotebookByName(ns, n.Notebook.Name)
+		if err != nil {
+			return nil, err
+		}
+		n.Notebook = nb
+	}
+	if err = SaveNewNote(ns, n, opts); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// ImportDirectory imports the markdown files in the directory and its
+// subdirectories as new notes, like ImportNote. If notebook is given, all
+// the notes are saved to it. Otherwise the notebook in each file's header
+// is used. A file that fails to be imported doesn't stop the rest from
+// being imported, the failures are returned as one error together with the
+// imported notes.
+func ImportDirectory(ns NotestoreClient, dir string, notebook string, opts NoteOption) ([]*Note, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	var nb *Notebook
+	if notebook != "" {
+		var err error
+		if nb, err = findNotebookByName(ns, notebook); err != nil {
+			return nil, err
+		}
+	}
+	var notes []*Note
+	var failed []string
+	files := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			files++
+			failed = append(failed, fmt.Sprintf("%q: %s", path, err))
+			return nil
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		files++
+		n, err := importNote(ns, path, nb, opts)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %s", path, err))
+			return nil
+		}
+		notes = append(notes, n)
+		return nil
+	})
+	if err != nil {
+		return notes, err
+	}
+	if len(failed) > 0 {
+		return notes, fmt.Errorf("failed to import %d of %d files: %s", len(failed), files, strings.Join(failed, "; "))
+	}
+	return notes, nil
+}
+
+// NewNoteFromTemplate creates a new note from the template file at path.
+// The {{date}} and {{title}} placeholders are replaced before the header and
+// content are parsed. A title given as an argument takes precedence over the
+// title in the template. The notebook is only set by name and needs to be
+// resolved before the note is saved.
+func NewNoteFromTemplate(path, title string, opts NoteOption) (*Note, error) {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	data = []byte(expandTemplate(string(data), title, time.Now()))
+	n := new(Note)
+	if hasNoteHeader(data) {
+		err = parseNote(bytes.NewReader(data), n, opts)
+	} else {
+		err = parseContent(bufio.NewScanner(bytes.NewReader(data)), n, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if title != "" {
+		n.Title = title
+	} else if n.Title == "" {
+		n.Title = DefaultNoteTitle
+	}
 	return n, nil
 }
 
-// SaveChanges updates the changes to the note on the server.
-func SaveChanges(ns NotestoreClient, n *Note, opts NoteOption) error {
-	return saveChanges(ns, n, true, opts&RawNote != 0)
+// expandTemplate replaces the placeholders in the template.
+func expandTemplate(template, title string, now time.Time) string {
+	return strings.NewReplacer(
+		"{{date}}", now.Format(timeFormat),
+		"{{title}}", title,
+	).Replace(template)
+}
+
+// hasNoteHeader returns true if the content starts with a note header. A
+// header only has the header fields between the separators, so content that
+// starts with a horizontal rule isn't mistaken for a header.
+func hasNoteHeader(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(normalizeLineEndings(data)))
+	if !scanner.Scan() || scanner.Text() != headSep {
+		return false
+	}
+	// Custom fields are allowed, but at least one known field has to be
+	// present so a body starting with a rule isn't taken for a header.
+	known := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == headSep {
+			return known
+		}
+		if isHeaderField(line) {
+			known = true
+		} else if !customHeaderField.MatchString(line) {
+			return false
+		}
+	}
+	return false
+}
+
+// customHeaderField matches a header line with a field that isn't known.
+var customHeaderField = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):(.*)$`)
+
+func isHeaderField(line string) bool {
+	for _, field := range []string{headTitleField, headNotebookNameField, headTagsField, headReminderField,
+		headSourceField, headSourceURLField, headCreatedField, headUpdatedField} {
+		if strings.HasPrefix(line, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func findNotebookByName(ns NotestoreClient, name string) (*Notebook, error) {
+	bs, err := ns.GetAllNotebooks()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bs {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+	return nil, ErrNoNotebookFound
+}
+
+// noteXML returns the ENML body for an existing note.
+func noteXML(conv Converter, n *Note, opts NoteOption) (string, error) {
+	if opts&RawNote != 0 {
+		return rawNoteXML(n), nil
+	}
+	var body string
+	if opts&PlainTextNote != 0 {
+		body = addMediaTags(toPlainXML(n.MD), n.Resources)
+	} else {
+		var err error
+		if body, err = toXML(conv, n.MD, n.Resources, opts); err != nil {
+			return "", err
+		}
+	}
+	return setBodyAttrs(body, n.BodyAttrs), nil
+}
+
+// rawNoteXML returns the ENML body for the note from its body, without
+// converting the markdown.
+func rawNoteXML(n *Note) string {
+	return setBodyAttrs(fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body), n.BodyAttrs)
+}
+
+// stdinInput is where the content is read from with the StdinNote option.
+var stdinInput io.Reader = os.Stdin
+
+// dryRunOutput is where the content is written in dry-run mode.
+var dryRunOutput io.Writer = os.Stdout
+
+// warningOutput is where the warnings are written.
+var warningOutput io.Writer = os.Stderr
+
+// diffOutput is where the diff of the changes is written with the
+// ShowDiffNote option.
+var diffOutput io.Writer = os.Stdout
+
+func printDryRun(body string) error {
+	_, err := fmt.Fprintln(dryRunOutput, body)
+	return err
 }
 
-// ChangeTitle changes the note's title.
-func ChangeTitle(db Storager, ns NotestoreClient, old, new string) error {
-	n, err := GetNote(db, ns, old, "")
-	if err != nil {
-		return err
-	}
-	n.Title = new
-	return saveChanges(ns, n, false, false)
+// SaveNewNote pushes the new note to the server. With the DryRunNote
+// option, the content is printed instead of saved. With the UniqueTitleNote
+// option, ErrDuplicateNote is returned if the notebook already has a note
+// with the title. The content is converted with the MarkdownConverter.
+func SaveNewNote(ns NotestoreClient, n *Note, opts NoteOption) error {
+	return saveNewNote(MarkdownConverter{}, ns, n, opts)
 }
 
-// MoveNote moves the note to a new notebook.
-func MoveNote(db Storager, ns NotestoreClient, noteTitle, notebookName string) error {
-	n, err := GetNote(db, ns, noteTitle, "")
-	if err != nil {
+// SaveNewNote pushes the new note to the server like SaveNewNote, but
+// converts the content with the client's Converter.
+func (c *Client) SaveNewNote(n *Note, opts NoteOption) error {
+	return saveNewNote(c.converter(), c.NoteStore, n, opts)
+}
+
+func saveNewNote(conv Converter, ns NotestoreClient, n *Note, opts NoteOption) error {
+	if err := checkNewNoteTitle(ns, n, opts); err != nil {
 		return err
 	}
-	b, err := FindNotebook(db, ns, notebookName)
-	if err != nil {
-		return err
+	raw := opts&RawNote != 0
+	var body string
+	if !raw && opts&PlainTextNote == 0 && n.MD != "" {
+		var err error
+		if body, err = toXML(conv, n.MD, n.Resources, opts); err != nil {
+			return err
+		}
+	} else if raw {
+		body = fmt.Sprintf("%s<en-note><pre><code>%s</code></pre></en-note>", XMLHeader, html.EscapeString(n.Body))
+	} else if opts&PlainTextNote != 0 {
+		body = toPlainXML(n.MD)
+	} else {
+		body = XMLHeader + "<en-note></en-note>"
 	}
-	n.Notebook = b
-	return saveChanges(ns, n, false, false)
+	return saveNewNoteBody(ns, n, body, opts)
 }
 
-// DeleteNote moves a note from the notebook to the trash can.
-func DeleteNote(db Storager, ns NotestoreClient, title, notebook string) error {
-	n, err := GetNote(db, ns, title, notebook)
-	if err != nil {
-		return err
+// checkNewNoteTitle checks that the title of the new note is unique with
+// the UniqueTitleNote option.
+func checkNewNoteTitle(ns NotestoreClient, n *Note, opts NoteOption) error {
+	if opts&UniqueTitleNote != 0 && opts&DryRunNote == 0 {
+		return checkDuplicateTitle(ns, n)
 	}
-	err = ns.DeleteNote(n.GUID)
-	if err != nil {
+	return nil
+}
+
+// saveNewNoteBody pushes the new note with the ENML body to the server, see
+// SaveNewNote. The title has to have been checked.
+func saveNewNoteBody(ns NotestoreClient, n *Note, body string, opts NoteOption) error {
+	body = setBodyAttrs(addMediaTags(body, n.Resources), n.BodyAttrs)
+	if opts&DryRunNote != 0 {
+		return printDryRun(body)
+	}
+	n.Body = body
+	if n.SourceApplication == "" {
+		n.SourceApplication = DefaultSourceApplication
+	}
+	if err := ns.CreateNote(n); err != nil {
 		return err
 	}
 	return nil
 }
 
-func saveChanges(ns NotestoreClient, n *Note, updateContent, useRawContent bool) error {
-	if updateContent {
-		body := toXML(n.MD)
-		if useRawContent {
-			body = fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body)
+// FindDuplicateNote returns the note in the notebook of n that has the same
+// title as n. Titles are matched like GetNote does, case insensitive and
+// without surrounding whitespace. If the note doesn't have a notebook, the
+// default notebook is searched. If no note has the title, nil is returned.
+func FindDuplicateNote(ns NotestoreClient, n *Note) (*Note, error) {
+	title := strings.TrimSpace(n.Title)
+	if title == "" {
+		return nil, nil
+	}
+	nb := n.Notebook
+	if nb == nil || nb.GUID == "" {
+		var err error
+		if nb, err = ns.GetDefaultNotebook(); err != nil {
+			return nil, err
 		}
-		n.Body = body
 	}
-	err := ns.UpdateNote(n)
+	notes, err := ns.FindNotes(&NoteFilter{Words: title, NotebookGUID: nb.GUID}, 0, 20)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	for _, note := range notes {
+		if strings.EqualFold(strings.TrimSpace(note.Title), title) {
+			return note, nil
+		}
+	}
+	return nil, nil
 }
 
-// SaveNewNote pushes the new note to the server.
-func SaveNewNote(ns NotestoreClient, n *Note, raw bool) error {
-	var body string
-	if !raw && n.MD != "" {
-		body = toXML(n.MD)
-	} else if raw {
-		body = fmt.Sprintf("%s<en-note><pre><code>%s</code></pre></en-note>", XMLHeader, html.EscapeString(n.Body))
-	} else {
-		body = XMLHeader + "<en-note></en-note>"
-	}
-	n.Body = body
-	if err := ns.CreateNote(n); err != nil {
+// checkDuplicateTitle returns ErrDuplicateNote if the notebook of n already
+// has a note with the title.
+func checkDuplicateTitle(ns NotestoreClient, n *Note) error {
+	dup, err := FindDuplicateNote(ns, n)
+	if err != nil {
 		return err
 	}
+	if dup != nil {
+		return fmt.Errorf("%w: %q", ErrDuplicateNote, n.Title)
+	}
 	return nil
 }
 
 // EditNote opens the editor so the user can edit the note. Once the user closes the
-// editor, the note is saved to the notestore.
+// editor, the note is saved to the notestore. If the editor exits with an error,
+// ErrEditorAborted is returned and nothing is saved.
 func EditNote(client *Client, title string, opts NoteOption) error {
 	db, ns := client.Store, client.NoteStore
 	var note *Note
 	var err error
 	if opts&UseRecoveryPointNote != 0 {
 		note, err = db.GetNoteRecoveryPoint()
-		if note.GUID == "" {
+		if err == nil && note.GUID == "" {
 			return ErrNoNoteFound
 		}
 	} else {
-		note, err = GetNoteWithContent(db, ns, title)
+		note, err = getNoteWithContent(client.converter(), db, ns, title, opts)
+		if err == nil {
+			err = LoadTagNames(ns, note)
+		}
 	}
 	if err != nil {
 		return err
 	}
-	oldHash := note.Hash(opts&RawNote != 0)
-	nb, err := GetNotebook(client.NoteStore, note.Notebook.GUID)
+	return editAndSaveNote(client, note, opts)
+}
+
+// ListRecoveryPoints returns the notes that failed to be saved.
+func ListRecoveryPoints(db Storager) ([]*Note, error) {
+	return db.ListRecoveryPoints()
+}
+
+// EditRecoveryPoint opens the recovery point for the note with the GUID in
+// the editor and tries to save it again. The recovery point is removed once
+// the note has been saved.
+func EditRecoveryPoint(client *Client, guid string, opts NoteOption) error {
+	notes, err := client.Store.ListRecoveryPoints()
 	if err != nil {
 		return err
 	}
-	note.Notebook = nb
+	for _, n := range notes {
+		if n.GUID == guid {
+			return editAndSaveNote(client, n, opts|UseRecoveryPointNote)
+		}
+	}
+	return ErrNoNoteFound
+}
+
+// sameTags returns true if both lists have the same tag names in the same
+// order.
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// editAndSaveNote opens the note in the editor and saves the changes. If the
+// save fails, a recovery point is created. Recovered notes are always saved
+// since they haven't been saved before, and the recovery point is removed
+// once they have been.
+func editAndSaveNote(client *Client, note *Note, opts NoteOption) error {
+	db := client.Store
+	recovered := opts&UseRecoveryPointNote != 0
+	oldHash := note.Hash(opts&RawNote != 0)
+	oldReminder := note.Reminder
+	oldSource, oldSourceURL := note.SourceApplication, note.SourceURL
+	oldTags := append([]string(nil), note.Tags...)
+	oldCreated := note.Created
+	oldTitle, oldContent := note.Title, diffContent(note, opts)
+	// Some notes, like certain search results, don't have a notebook. For
+	// them, a notebook is only resolved if one is set in the header.
+	if note.Notebook != nil {
+		nb, err := GetNotebook(client.NoteStore, note.Notebook.GUID)
+		if err != nil {
+			return err
+		}
+		note.Notebook = nb
+	}
 	initialNotebook := getNotebookName(note)
-	cacheFile, err := editNote(client, note, opts)
+	cacheFile, content, err := editNote(client, note, opts)
 	if err != nil {
 		return err
 	}
 	defer cacheFile.CloseAndRemove()
-	err = parseNote(cacheFile, note, opts)
+	err = parseNote(content, note, opts)
 	if err != nil {
 		return err
 	}
@@ -301,45 +1321,150 @@ func EditNote(client *Client, title string, opts NoteOption) error {
 	if err != nil {
 		return err
 	}
-	if bytes.Equal(oldHash, note.Hash(opts&RawNote != 0)) && initialNotebook == note.Notebook.Name {
+	if !recovered && bytes.Equal(oldHash, note.Hash(opts&RawNote != 0)) && initialNotebook == getNotebookName(note) &&
+		sameReminder(oldReminder, note.Reminder) && oldSource == note.SourceApplication && oldSourceURL == note.SourceURL &&
+		sameTags(oldTags, note.Tags) && oldCreated == note.Created {
 		return nil
 	}
-	err = SaveChanges(ns, note, opts)
+	if opts&ShowDiffNote != 0 {
+		if err = writeDiff(diffOutput, oldTitle, note.Title, oldContent, diffContent(note, opts)); err != nil {
+			return err
+		}
+		if client.ConfirmSave != nil && !client.ConfirmSave() {
+			return ErrSaveCancelled
+		}
+	}
+	err = client.SaveChanges(note, opts)
 	if err != nil {
-		saveErr := db.SaveNoteRecoveryPoint(note)
+		saveErr := saveRecoveryPoint(db, note, opts&RawNote != 0)
 		if saveErr != nil {
 			err = errors.New("Error when saving note: " + err.Error() + "\nFailed to create recovery point: " + saveErr.Error())
 		}
+		return err
 	}
-	return err
+	if recovered && opts&DryRunNote == 0 {
+		return db.RemoveRecoveryPoint(note.GUID)
+	}
+	return nil
+}
+
+// diffContent returns the content of the note that is compared when the
+// diff of the changes is shown. It's the same content as the hash is
+// calculated from.
+func diffContent(n *Note, opts NoteOption) string {
+	if opts&RawNote != 0 {
+		return n.Body
+	}
+	return n.MD
+}
+
+// saveRecoveryPoint saves the note as a recovery point. If the most recent
+// recovery point is for the same note and has the same content, it is kept
+// as is so repeated failures don't rewrite the draft. Otherwise the note is
+// saved and becomes the most recent recovery point.
+func saveRecoveryPoint(db Storager, note *Note, raw bool) error {
+	last, err := db.GetNoteRecoveryPoint()
+	if err != nil {
+		return err
+	}
+	if last.GUID == note.GUID && bytes.Equal(last.Hash(raw), note.Hash(raw)) {
+		return nil
+	}
+	return db.SaveNoteRecoveryPoint(note)
 }
 
 // CreateAndEditNewNote creates a new note and opens it in the client's editor.
-// Once the editor has been closed, the note is saved to the notestore.
+// Once the editor has been closed, the note is saved to the notestore. If the
+// editor exits with an error, ErrEditorAborted is returned and the note is not
+// created. With the UniqueTitleNote option, the title is checked before the
+// editor is opened. If the title or notebook is changed to match an existing
+// note in the editor, a warning is printed but the note is still saved so
+// the edit isn't lost.
 func CreateAndEditNewNote(client *Client, note *Note, opts NoteOption) error {
+	unique := opts&UniqueTitleNote != 0 && opts&DryRunNote == 0
+	if unique && note.Title != DefaultNoteTitle {
+		if err := checkDuplicateTitle(client.NoteStore, note); err != nil {
+			return err
+		}
+	}
 	initialNotebook := getNotebookName(note)
-	cacheFile, err := editNote(client, note, opts)
+	cacheFile, content, err := editNote(client, note, opts)
 	if err != nil {
 		return err
 	}
 
 	defer cacheFile.CloseAndRemove()
-	err = parseNote(cacheFile, note, opts)
+	err = parseNote(content, note, opts)
 	if err != nil {
 		return err
 	}
+	if note.Title == DefaultNoteTitle && opts&(RawNote|PlainTextNote) == 0 {
+		titleFromHeading(note)
+	}
 	err = checkForNotebookAndUpdate(client, note, initialNotebook)
 	if err != nil {
 		return err
 	}
-	return SaveNewNote(client.NoteStore, note, opts&RawNote != 0)
+	if unique {
+		opts &^= UniqueTitleNote
+		if dup, err := FindDuplicateNote(client.NoteStore, note); err == nil && dup != nil {
+			fmt.Fprintf(warningOutput, "Warning: a note with the title %q already exists in the notebook.\n", note.Title)
+		}
+	}
+	return client.SaveNewNote(note, opts)
+}
+
+// titleFromHeading uses the first line of the note as the title, if it is
+// a markdown heading. The heading is removed from the note's content.
+func titleFromHeading(note *Note) {
+	lines := strings.SplitN(note.MD, "\n", 2)
+	if !strings.HasPrefix(lines[0], "# ") {
+		return
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(lines[0][2:]), "#"))
+	if title == "" {
+		return
+	}
+	note.Title = title
+	note.MD = ""
+	if len(lines) == 2 {
+		note.MD = strings.TrimLeft(lines[1], "\n")
+	}
+}
+
+// ReadNewNoteFromStdin reads the content of a new note from stdin, like
+// CreateAndEditNewNote does with the StdinNote option. The content can start
+// with a note header. If the note has the default title and its first line
+// is a markdown heading, the heading is used as the title.
+func ReadNewNoteFromStdin(note *Note, opts NoteOption) error {
+	input, err := ioutil.ReadAll(stdinInput)
+	if err != nil {
+		return err
+	}
+	if !hasNoteHeader(input) {
+		note.MD = string(input)
+		note.Body = string(input)
+		buf := new(bytes.Buffer)
+		if err := WriteNote(buf, note, opts); err != nil {
+			return err
+		}
+		input = buf.Bytes()
+	}
+	if err := parseNote(bytes.NewReader(input), note, opts); err != nil {
+		return err
+	}
+	if note.Title == DefaultNoteTitle && opts&(RawNote|PlainTextNote) == 0 {
+		titleFromHeading(note)
+	}
+	return nil
 }
 
 func checkForNotebookAndUpdate(client *Client, note *Note, initialNotebook string) error {
-	if note.Notebook == nil || initialNotebook == note.Notebook.Name {
+	name := getNotebookName(note)
+	if name == "" || initialNotebook == name {
 		return nil
 	}
-	b, err := FindNotebook(client.Store, client.NoteStore, note.Notebook.Name)
+	b, err := FindNotebook(client.Store, client.NoteStore, name)
 	if err != nil {
 		return err
 	}
@@ -363,7 +1488,58 @@ func randomFilename(prepend string) (string, error) {
 	return prepend + id.String(), nil
 }
 
-func editNote(client *Client, note *Note, opts NoteOption) (CacheFile, error) {
+// noteFileExtension returns the file extension for the note content format.
+func noteFileExtension(opts NoteOption) string {
+	if opts&RawNote != 0 {
+		return ".xml"
+	} else if opts&PlainTextNote != 0 {
+		return ".txt"
+	}
+	return ".md"
+}
+
+// exportFilename returns the default filename with the extension used when
+// exporting the note.
+func exportFilename(note *Note, ext string) string {
+	name := slugifyTitle(note.Title)
+	if name == "" {
+		name = note.GUID
+	}
+	if name == "" {
+		name = "untitled"
+	}
+	return name + ext
+}
+
+// slugifyTitle converts the title into a string that is safe to use as a
+// filename. Path separators, characters reserved on Windows, control
+// characters and whitespace are replaced with a dash. Repeated dashes are
+// collapsed and leading or trailing dashes and dots are removed. The
+// returned string is empty if nothing is left of the title.
+func slugifyTitle(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range title {
+		if unicode.IsControl(r) || unicode.IsSpace(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			r = '-'
+		}
+		if r == '-' {
+			if dash {
+				continue
+			}
+			dash = true
+		} else {
+			dash = false
+		}
+		b.WriteRune(r)
+	}
+	return strings.Trim(b.String(), "-.")
+}
+
+// editNote writes the note to a cache file and opens it in the editor. The
+// cache file is returned together with the edited content from the client's
+// ContentSource. The caller has to remove the cache file.
+func editNote(client *Client, note *Note, opts NoteOption) (CacheFile, io.Reader, error) {
 	filename := ""
 
 	// If the note has a GUID == "", it is a new note.
@@ -371,62 +1547,98 @@ func editNote(client *Client, note *Note, opts NoteOption) (CacheFile, error) {
 	if note.GUID == "" {
 		randName, err := randomFilename(newNotePrependString)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		filename += randName
 	}
 
-	if opts&RawNote != 0 {
-		// Since the GUID is an empty string for new notes, we can allow a append of it.
-		filename += note.GUID + ".xml"
-	} else {
-		// body = note.MD
-		filename += note.GUID + ".md"
-	}
+	// Since the GUID is an empty string for new notes, we can allow a append of it.
+	filename += note.GUID + noteFileExtension(opts)
 	cacheFile, err := client.NewCacheFile(filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	var input []byte
 	if opts&StdinNote != 0 {
-		bytes, _ := ioutil.ReadAll(os.Stdin)
-		note.MD = string(bytes)
-		note.Body = string(bytes)
+		input, err = ioutil.ReadAll(stdinInput)
+		if err != nil {
+			return nil, nil, err
+		}
+		note.MD = string(input)
+		note.Body = string(input)
 	}
 
-	err = WriteNote(cacheFile, note, opts)
+	// Input with a header is written as is, so the changes in its header
+	// are parsed when the cache file is read back.
+	if hasNoteHeader(input) {
+		_, err = cacheFile.Write(input)
+	} else {
+		err = WriteNote(cacheFile, note, opts)
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// XXX: We need to close the file handler to the file
 	// before it is handed over to the editor. Otherwise,
 	// Go doesn't detect the changes.
 	err = cacheFile.Close()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if opts&StdinNote == 0 {
 		err = client.Edit(cacheFile)
+		if errors.Is(err, ErrEditorAborted) {
+			// Remove the aborted edit so it isn't mistaken for changes.
+			if reopenErr := cacheFile.ReOpen(); reopenErr == nil {
+				cacheFile.CloseAndRemove()
+			}
+			return nil, nil, ErrEditorAborted
+		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	err = cacheFile.ReOpen()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	// Content read from stdin isn't edited, so it's in the cache file.
+	if opts&StdinNote != 0 {
+		return cacheFile, cacheFile, nil
+	}
+	content, err := client.editedContent(cacheFile)
+	if err != nil {
+		cacheFile.CloseAndRemove()
+		return nil, nil, err
 	}
-	return cacheFile, nil
+	return cacheFile, content, nil
 }
 
 func parseNote(r io.Reader, n *Note, opts NoteOption) error {
-	scanner := bufio.NewScanner(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(normalizeLineEndings(data)))
 	if err := parseHeader(scanner, n); err != nil {
 		return err
 	}
 	return parseContent(scanner, n, opts)
 }
 
+// utf8BOM is the byte order mark some editors put at the start of a file.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// normalizeLineEndings strips a leading UTF-8 BOM and converts CRLF and lone
+// CR line endings to LF so no stray carriage returns end up in the note.
+func normalizeLineEndings(data []byte) []byte {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(data, []byte("\r"), []byte("\n"), -1)
+}
+
 func parseHeader(scanner *bufio.Scanner, n *Note) error {
 	// Find beginning of the header.
 	for scanner.Scan() {
@@ -455,12 +1667,140 @@ func parseHeader(scanner *bufio.Scanner, n *Note) error {
 			if n.Notebook == nil {
 				n.Notebook = new(Notebook)
 			}
-			n.Notebook.Name = strings.TrimSpace(line[len(headNotebookNameField):])
+			n.Notebook.Name = unquoteHeaderValue(line[len(headNotebookNameField):])
+			continue
+		}
+
+		if strings.Index(line, headTagsField) == 0 {
+			n.Tags = parseTags(line[len(headTagsField):])
+			continue
+		}
+
+		if strings.Index(line, headReminderField) == 0 {
+			if err := parseReminder(line[len(headReminderField):], n); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if strings.Index(line, headSourceField) == 0 {
+			n.SourceApplication = unquoteHeaderValue(line[len(headSourceField):])
+			continue
+		}
+
+		if strings.Index(line, headSourceURLField) == 0 {
+			n.SourceURL = unquoteHeaderValue(line[len(headSourceURLField):])
+			continue
+		}
+
+		if strings.Index(line, headCreatedField) == 0 {
+			t, err := parseNoteTime(line[len(headCreatedField):])
+			if err != nil {
+				return err
+			}
+			if t > toMillis(time.Now()) {
+				return ErrFutureCreatedTime
+			}
+			n.Created = t
+			continue
+		}
+
+		// The updated time of an existing note is used to detect conflicts,
+		// so it can only be set for new notes.
+		if strings.Index(line, headUpdatedField) == 0 {
+			t, err := parseNoteTime(line[len(headUpdatedField):])
+			if err != nil {
+				return err
+			}
+			if n.GUID == "" {
+				n.Updated = t
+			}
+			continue
+		}
+
+		if m := customHeaderField.FindStringSubmatch(line); m != nil {
+			if n.ExtraHeaders == nil {
+				n.ExtraHeaders = make(map[string]string)
+			}
+			n.ExtraHeaders[m[1]] = unquoteHeaderValue(m[2])
 		}
 	}
 	return scanner.Err()
 }
 
+// parseReminder sets the note's reminder from the header value. An empty
+// value clears the reminder on save.
+func parseReminder(value string, n *Note) error {
+	if strings.TrimSpace(value) == "" {
+		n.Reminder = new(Reminder)
+		return nil
+	}
+	t, err := ParseReminderTime(value)
+	if err != nil {
+		return err
+	}
+	setReminderTime(n, t)
+	return nil
+}
+
+// headTimeFormat is the format of the created and updated times in the
+// header. It's RFC3339 with the milliseconds, so the times are parsed back
+// unchanged.
+const headTimeFormat = "2006-01-02T15:04:05.999Z07:00"
+
+// parseNoteTime parses the created or updated time in the note header and
+// returns it in milliseconds. The time is given in milliseconds since the
+// epoch, in RFC 3339 format, or in one of the formats accepted for reminder
+// times.
+func parseNoteTime(value string) (int64, error) {
+	value = unquoteHeaderValue(value)
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return ms, nil
+	}
+	t, err := ParseReminderTime(value)
+	if err != nil {
+		return 0, ErrInvalidNoteTime
+	}
+	return toMillis(t), nil
+}
+
+// unquoteHeaderValue trims the header value and removes surrounding quotes
+// if present, keeping any whitespace inside the quotes.
+func unquoteHeaderValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	s, err := strconv.Unquote(value)
+	if err != nil {
+		return value
+	}
+	return s
+}
+
+// quoteHeaderValue quotes the value if it would not survive being parsed
+// as is, either because of leading or trailing whitespace, a line break
+// or because it looks like a quoted value.
+func quoteHeaderValue(value string) string {
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) || strings.ContainsAny(value, "\r\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// parseTags splits a comma-separated tag line into the tag names. An empty
+// line returns an empty, non-nil slice so the tags are cleared on save.
+func parseTags(line string) []string {
+	tags := make([]string, 0)
+	for _, t := range strings.Split(line, headTagsSep) {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
 func parseContent(scanner *bufio.Scanner, n *Note, opts NoteOption) error {
 	buf := new(bytes.Buffer)
 	for scanner.Scan() {
@@ -470,21 +1810,75 @@ func parseContent(scanner *bufio.Scanner, n *Note, opts NoteOption) error {
 		return err
 	}
 	if opts&RawNote != 0 {
-		//n.Body = strings.Trim(buf.String(), "\n")
-		n.Body = buf.String()
+		// WriteNote adds a new line to the end of the body, remove it so
+		// an unchanged note hashes the same.
+		n.Body = strings.TrimSuffix(buf.String(), "\n")
 	} else {
 		n.MD = strings.Trim(buf.String(), "\n")
 	}
 	return nil
 }
 
-func writeNoteHeader(w io.Writer, n *Note) error {
-	a := []string{
-		headSep,
-		headTitleField + " " + n.Title,
+// HeaderFieldOrder is the order the fields are written in the note header,
+// given by the field names. Fields that aren't in the list, like custom
+// fields, are written after the listed ones sorted by their name.
+var HeaderFieldOrder = []string{"title", "notebook", "tags", "reminder", "source", "source-url", "created", "updated"}
+
+// headerFields returns the header fields of the note as the header lines,
+// by the field name. Fields without a value are left out, except for the
+// title.
+func headerFields(n *Note) map[string]string {
+	fields := make(map[string]string, len(n.ExtraHeaders)+len(HeaderFieldOrder))
+	for name, value := range n.ExtraHeaders {
+		// A custom field can't replace a known field.
+		if !isHeaderField(name+":") && customHeaderField.MatchString(name+":") {
+			fields[name] = name + ":" + headSpace + quoteHeaderValue(value)
+		}
+	}
+	set := func(field, value string) {
+		fields[strings.TrimSuffix(field, ":")] = field + headSpace + value
 	}
+	set(headTitleField, n.Title)
 	if n.Notebook != nil && n.Notebook.Name != "" {
-		a = append(a, headNotebookNameField+headSpace+n.Notebook.Name)
+		set(headNotebookNameField, quoteHeaderValue(n.Notebook.Name))
+	}
+	if len(n.Tags) > 0 {
+		set(headTagsField, strings.Join(n.Tags, headTagsSep+headSpace))
+	}
+	if n.Reminder != nil && n.Reminder.Time != 0 {
+		set(headReminderField, noteTime(n.Reminder.Time).Format(time.RFC3339))
+	}
+	if n.SourceApplication != "" {
+		set(headSourceField, quoteHeaderValue(n.SourceApplication))
+	}
+	if n.SourceURL != "" {
+		set(headSourceURLField, quoteHeaderValue(n.SourceURL))
+	}
+	if n.Created != 0 {
+		set(headCreatedField, noteTime(n.Created).Format(headTimeFormat))
+	}
+	if n.Updated != 0 {
+		set(headUpdatedField, noteTime(n.Updated).Format(headTimeFormat))
+	}
+	return fields
+}
+
+func writeNoteHeader(w io.Writer, n *Note) error {
+	fields := headerFields(n)
+	a := []string{headSep}
+	for _, name := range HeaderFieldOrder {
+		if line, ok := fields[name]; ok {
+			a = append(a, line)
+			delete(fields, name)
+		}
+	}
+	rest := make([]string, 0, len(fields))
+	for name := range fields {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		a = append(a, fields[name])
 	}
 	a = append(a, headSep)
 	for _, line := range a {
@@ -496,10 +1890,13 @@ func writeNoteHeader(w io.Writer, n *Note) error {
 	return nil
 }
 
-// WriteNote writes the note using the provided writer.
+// WriteNote writes the note using the provided writer. The header is
+// omitted if the NoHeaderNote option is given.
 func WriteNote(w io.Writer, n *Note, opts NoteOption) error {
-	if err := writeNoteHeader(w, n); err != nil {
-		return err
+	if opts&NoHeaderNote == 0 {
+		if err := writeNoteHeader(w, n); err != nil {
+			return err
+		}
 	}
 	var err error
 	if opts&RawNote != 0 {
@@ -518,14 +1915,62 @@ func WriteNote(w io.Writer, n *Note, opts NoteOption) error {
 	return err
 }
 
-func toXML(mdBody string) string {
-	b := []byte("")
-	content := bytes.NewBuffer(b)
-	content.WriteString(XMLHeader)
-	content.WriteString("<en-note>")
-	content.Write(markdown.ToXML(mdBody))
-	content.WriteString("</en-note>")
-	return content.String()
+// toXML converts the markdown to ENML with the converter. Image references
+// to the attachments are converted to en-media tags if the converter is a
+// MediaConverter, otherwise the attachments are added to the end of the
+// note. With the StrictMarkdownNote option, the converter has to be a
+// StrictConverter. With the EmojiNote option, the shortcodes in the
+// converted body are replaced with their emoji.
+func toXML(conv Converter, mdBody string, resources []*Attachment, opts NoteOption) (string, error) {
+	var body string
+	var err error
+	if opts&StrictMarkdownNote != 0 {
+		sc, ok := conv.(StrictConverter)
+		if !ok {
+			return "", ErrStrictNotSupported
+		}
+		body, err = sc.ToENMLStrict(mdBody, mediaTypes(resources))
+	} else if mc, ok := conv.(MediaConverter); ok {
+		body, err = mc.ToENMLWithMedia(mdBody, mediaTypes(resources))
+	} else {
+		body, err = conv.ToENML(mdBody)
+	}
+	if err != nil {
+		return "", err
+	}
+	if opts&EmojiNote != 0 {
+		body = string(markdown.ConvertEmoji([]byte(body)))
+	}
+	content := XMLHeader + "<en-note>" + body + "</en-note>"
+	if _, ok := conv.(MediaConverter); !ok {
+		content = addMediaTags(content, resources)
+	}
+	return content, nil
+}
+
+// toPlainXML converts the plain text to ENML without interpreting it as
+// markdown.
+func toPlainXML(text string) string {
+	return XMLHeader + "<en-note>" + string(markdown.TextToXML(text)) + "</en-note>"
+}
+
+var enNoteTag = regexp.MustCompile(`<en-note\b([^>]*)>`)
+
+// bodyAttrs returns the attributes of the en-note element in the content.
+func bodyAttrs(content string) string {
+	m := enNoteTag.FindStringSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimSuffix(m[1], "/"))
+}
+
+// setBodyAttrs adds the attributes to the en-note element of the body.
+func setBodyAttrs(body, attrs string) string {
+	if attrs == "" {
+		return body
+	}
+	return strings.Replace(body, "<en-note>", "<en-note "+attrs+">", 1)
 }
 
 func decodeXML(content string, v interface{}) error {
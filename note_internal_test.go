@@ -19,6 +19,7 @@ package clinote
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,7 @@ func TestNoteParsing(t *testing.T) {
 		{"simple", []byte(testContent)},
 		{"compact", []byte(compactContent)},
 		{"with_white_space", []byte(contentWithWhiteSpace)},
+		{"crlf_with_bom", append([]byte("\xef\xbb\xbf"), strings.Replace(testContent, "\n", "\r\n", -1)...)},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -45,6 +47,13 @@ func TestNoteParsing(t *testing.T) {
 			assert.Equal(notebookName, n.Notebook.Name, "Wrong notebook parsed")
 		})
 	}
+	t.Run("raw_crlf", func(t *testing.T) {
+		n := new(Note)
+		err := parseNote(strings.NewReader("\xef\xbb\xbf---\r\ntitle: Raw\r\n---\r\n<div>One</div>\r\n<div>Two</div>\r\n"), n, RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Raw", n.Title, "Wrong title parsed")
+		assert.Equal("<div>One</div>\n<div>Two</div>", n.Body, "Carriage returns should be removed")
+	})
 }
 
 func TestNoteWriting(t *testing.T) {
@@ -62,6 +71,196 @@ func TestNoteWriting(t *testing.T) {
 	err := WriteNote(w, n, DefaultNoteOption)
 	assert.NoError(err, "Should not fail")
 	assert.Equal(testContent, string(w.Bytes()), "Wrong content written")
+
+	t.Run("without header", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		err := WriteNote(w, n, NoHeaderNote)
+		assert.NoError(err, "Should not fail")
+		assert.Equal(noteContent+"\n", w.String(), "Wrong content written")
+	})
+}
+
+func TestNoteStats(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name  string
+		note  *Note
+		words int
+		chars int
+	}{
+		{"markdown", &Note{MD: "# Title\n\nSome **bold** words."}, 5, 29},
+		{"unicode", &Note{MD: "Grüße från Göteborg"}, 3, 19},
+		{"body without markdown", &Note{Body: "<div>One two</div><div>three&amp;four</div>"}, 3, 19},
+		{"empty", &Note{}, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			words, chars := test.note.Stats()
+			assert.Equal(test.words, words, "Wrong word count")
+			assert.Equal(test.chars, chars, "Wrong character count")
+		})
+	}
+}
+
+func TestNotebookNameQuoting(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"My Books", "notebook: My Books"},
+		{"  spaced  ", `notebook: "  spaced  "`},
+		{`"quoted"`, `notebook: "\"quoted\""`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			n := &Note{Title: noteTitle, Notebook: &Notebook{Name: test.name}}
+			assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+			assert.Contains(w.String(), test.header+"\n", "Wrong notebook header written")
+
+			parsed := new(Note)
+			assert.NoError(parseNote(w, parsed, DefaultNoteOption), "Should not return an error")
+			assert.Equal(test.name, parsed.Notebook.Name, "Notebook name should survive the round trip")
+		})
+	}
+	t.Run("quoted_in_header", func(t *testing.T) {
+		n := new(Note)
+		err := parseNote(strings.NewReader("---\ntitle: T\nnotebook: \"My Books\"\n---\n"), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("My Books", n.Notebook.Name, "Quotes should be removed")
+	})
+}
+
+func TestHasNoteHeader(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"header", testContent, true},
+		{"header with tags", contentWithTags, true},
+		{"crlf header", "---\r\ntitle: T\r\n---\r\nBody", true},
+		{"no header", "Body", false},
+		{"horizontal rule", "---\n\nBody\n\n---\nMore", false},
+		{"unclosed", "---\ntitle: T\n", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(test.expected, hasNoteHeader([]byte(test.content)))
+		})
+	}
+	t.Run("body starting with a rule", func(t *testing.T) {
+		n := &Note{Title: noteTitle, MD: "---\n\nBody\n\n---"}
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+		parsed := new(Note)
+		assert.NoError(parseNote(w, parsed, DefaultNoteOption), "Should not return an error")
+		assert.Equal(noteTitle, parsed.Title, "Wrong title parsed")
+		assert.Equal(n.MD, parsed.MD, "Rules in the body should be kept")
+	})
+}
+
+func TestNoteTags(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("parse", func(t *testing.T) {
+		n := new(Note)
+		err := parseNote(bytes.NewReader([]byte(contentWithTags)), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"work", "urgent"}, n.Tags, "Wrong tags parsed")
+		assert.Equal(noteContent, n.MD, "Wrong content parsed")
+	})
+	t.Run("parse_empty", func(t *testing.T) {
+		n := &Note{Tags: []string{"old"}}
+		err := parseNote(bytes.NewReader([]byte("---\ntitle: Note title\ntags:\n---\n")), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.NotNil(n.Tags, "Tags should be cleared, not unset")
+		assert.Len(n.Tags, 0, "Tags should be cleared")
+	})
+	t.Run("write", func(t *testing.T) {
+		n := &Note{
+			Title:    noteTitle,
+			MD:       noteContent,
+			Notebook: &Notebook{Name: notebookName},
+			Tags:     []string{"work", "urgent"},
+		}
+		w := new(bytes.Buffer)
+		err := WriteNote(w, n, DefaultNoteOption)
+		assert.NoError(err, "Should not fail")
+		assert.Equal(contentWithTags, w.String(), "Wrong content written")
+	})
+}
+
+func TestNoteSource(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("round_trip", func(t *testing.T) {
+		n := &Note{Title: noteTitle, MD: noteContent, SourceApplication: "my-script", SourceURL: "https://example.com/page"}
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+		assert.Contains(w.String(), "\nsource: my-script\nsource-url: https://example.com/page\n---\n", "Wrong source header written")
+		assert.True(hasNoteHeader(w.Bytes()), "Should be detected as a header")
+		parsed := new(Note)
+		assert.NoError(parseNote(w, parsed, DefaultNoteOption), "Should not return an error")
+		assert.Equal(n.SourceApplication, parsed.SourceApplication, "Wrong source application parsed")
+		assert.Equal(n.SourceURL, parsed.SourceURL, "Wrong source URL parsed")
+		assert.Equal(noteContent, parsed.MD, "Wrong content parsed")
+	})
+	t.Run("not_written_if_empty", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, &Note{Title: noteTitle}, DefaultNoteOption), "Should not fail")
+		assert.NotContains(w.String(), "source", "Source should not be written")
+	})
+}
+
+func TestExtraHeaders(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("parse", func(t *testing.T) {
+		n := new(Note)
+		err := parseNote(strings.NewReader("---\ntitle: T\nreviewed-by: Anna\nstatus: \"  draft \"\n---\nBody\n"), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("T", n.Title, "Wrong title")
+		assert.Equal(map[string]string{"reviewed-by": "Anna", "status": "  draft "}, n.ExtraHeaders, "Wrong extra headers")
+		assert.Equal("Body", n.MD, "Wrong content")
+	})
+	t.Run("round_trip", func(t *testing.T) {
+		n := &Note{
+			Title:        noteTitle,
+			MD:           noteContent,
+			Tags:         []string{"work"},
+			ExtraHeaders: map[string]string{"zeta": "last", "alpha": "first", "multi": "two\nlines"},
+		}
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+		assert.True(strings.HasPrefix(w.String(), "---\ntitle: Note title\ntags: work\nalpha: first\nmulti: \"two\\nlines\"\nzeta: last\n---\n"),
+			"Wrong header written: %s", w.String())
+		assert.True(hasNoteHeader(w.Bytes()), "Should be detected as a header")
+		parsed := new(Note)
+		assert.NoError(parseNote(w, parsed, DefaultNoteOption), "Should not return an error")
+		assert.Equal(n.ExtraHeaders, parsed.ExtraHeaders, "Extra headers should survive the round trip")
+		assert.Equal(noteContent, parsed.MD, "Wrong content parsed")
+	})
+	t.Run("known fields take precedence", func(t *testing.T) {
+		n := &Note{Title: noteTitle, ExtraHeaders: map[string]string{"title": "Other", "notebook": "Other"}}
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+		assert.Equal("---\ntitle: Note title\n---\n\n", w.String(), "Custom fields should not replace known fields")
+	})
+	t.Run("configured order", func(t *testing.T) {
+		order := HeaderFieldOrder
+		defer func() { HeaderFieldOrder = order }()
+		HeaderFieldOrder = []string{"status", "tags", "title"}
+		n := &Note{Title: noteTitle, Tags: []string{"work"}, Notebook: &Notebook{Name: notebookName}, ExtraHeaders: map[string]string{"status": "draft"}}
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+		assert.True(strings.HasPrefix(w.String(), "---\nstatus: draft\ntags: work\ntitle: Note title\nnotebook: Notebook name\n---\n"),
+			"Wrong header order: %s", w.String())
+	})
+	t.Run("header detection", func(t *testing.T) {
+		assert.True(hasNoteHeader([]byte("---\ncustom: value\ntitle: T\n---\n")), "Custom fields should be allowed")
+		assert.False(hasNoteHeader([]byte("---\nNote: value\n---\n")), "A known field should be required")
+		assert.False(hasNoteHeader([]byte("---\ntitle: T\nnot a field\n---\n")), "Other lines should not be allowed")
+	})
 }
 
 const (
@@ -103,3 +302,14 @@ note
 
 
 `
+
+const contentWithTags = `---
+title: Note title
+notebook: Notebook name
+tags: work, urgent
+---
+Body
+of
+the
+note
+`
@@ -20,12 +20,117 @@ package clinote
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestFindNotes(t *testing.T) {
+	assert := assert.New(t)
+	old := &Note{Title: "Old", Created: int64(1000), Updated: int64(5000)}
+	inRange := &Note{Title: "In range", Created: int64(2000), Updated: int64(2000)}
+	newer := &Note{Title: "New", Created: int64(3000), Updated: int64(3000)}
+	ns := new(mockNS)
+	ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{old, inRange, newer}, nil }
+
+	t.Run("filter by created time", func(t *testing.T) {
+		notes, err := FindNotes(ns, &NoteFilter{CreatedAfter: int64(2000), CreatedBefore: int64(3000)}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Note{inRange}, notes, "Wrong notes returned")
+	})
+
+	t.Run("filter by updated time", func(t *testing.T) {
+		notes, err := FindNotes(ns, &NoteFilter{UpdatedAfter: int64(3000)}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Note{old, newer}, notes, "Wrong notes returned")
+	})
+
+	t.Run("return error", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := &mockNS{findNotes: func(*NoteFilter, int, int) ([]*Note, error) { return nil, expectedErr }}
+		_, err := FindNotes(ns, &NoteFilter{}, 0, 20)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestFindNotesStream(t *testing.T) {
+	assert := assert.New(t)
+	createNS := func(total int) (*mockNS, *[]int) {
+		var counts []int
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, offset, count int) ([]*Note, error) {
+			counts = append(counts, count)
+			var notes []*Note
+			for i := offset; i < total && i < offset+count; i++ {
+				notes = append(notes, &Note{GUID: "GUID" + strconv.Itoa(i), Created: int64(i)})
+			}
+			return notes, nil
+		}
+		return ns, &counts
+	}
+	collect := func(notes <-chan *Note, errs <-chan error) ([]*Note, error) {
+		var list []*Note
+		for n := range notes {
+			list = append(list, n)
+		}
+		return list, <-errs
+	}
+
+	t.Run("fetch in batches", func(t *testing.T) {
+		ns, counts := createNS(findNotesBatchSize + 10)
+		list, err := collect(FindNotesStream(ns, &NoteFilter{}, 0, findNotesBatchSize+50))
+		assert.NoError(err, "Should not return an error")
+		assert.Len(list, findNotesBatchSize+10, "Wrong number of notes")
+		assert.Equal([]int{findNotesBatchSize, 50}, *counts, "Wrong batch sizes requested")
+	})
+
+	t.Run("offset and filter", func(t *testing.T) {
+		ns, _ := createNS(10)
+		list, err := collect(FindNotesStream(ns, &NoteFilter{CreatedBefore: 5}, 2, 5))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Note{{GUID: "GUID2", Created: 2}, {GUID: "GUID3", Created: 3}, {GUID: "GUID4", Created: 4}}, list)
+	})
+
+	t.Run("fetch all notes", func(t *testing.T) {
+		ns, counts := createNS(findNotesBatchSize*2 + 5)
+		list, err := collect(FindNotesStream(ns, &NoteFilter{}, 0, 0))
+		assert.NoError(err, "Should not return an error")
+		assert.Len(list, findNotesBatchSize*2+5, "Wrong number of notes")
+		assert.Equal([]int{findNotesBatchSize, findNotesBatchSize, findNotesBatchSize}, *counts, "Wrong batch sizes requested")
+	})
+
+	t.Run("stop fetching all notes at the limit", func(t *testing.T) {
+		ns, _ := createNS(MaxFindNotes + 50)
+		list, err := collect(FindNotesStream(ns, &NoteFilter{}, 0, 0))
+		assert.Equal(ErrNoteLimitReached, err, "Wrong error returned")
+		assert.Len(list, MaxFindNotes, "Notes up to the limit should be sent")
+	})
+
+	t.Run("close channels on error", func(t *testing.T) {
+		expectedError := errors.New("expected error")
+		ns, _ := createNS(findNotesBatchSize * 2)
+		calls := 0
+		findNotes := ns.findNotes
+		ns.findNotes = func(filter *NoteFilter, offset, count int) ([]*Note, error) {
+			calls++
+			if calls > 1 {
+				return nil, expectedError
+			}
+			return findNotes(filter, offset, count)
+		}
+		list, err := collect(FindNotesStream(ns, &NoteFilter{}, 0, findNotesBatchSize*2))
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Len(list, findNotesBatchSize, "Notes before the error should be sent")
+	})
+}
+
 func TestGetNote(t *testing.T) {
 	assert := assert.New(t)
 	store := &mockStore{
@@ -50,6 +155,41 @@ func TestGetNote(t *testing.T) {
 		assert.NoError(err)
 		assert.Equal(expectedNote, note)
 	})
+	t.Run("get current metadata of note from search", func(t *testing.T) {
+		store.getSearch = func() ([]*Note, error) {
+			return []*Note{{Title: "Note", GUID: "GUID", Updated: int64(1000)}}, nil
+		}
+		ns := new(mockNS)
+		ns.getNote = func(guid string) (*Note, error) { return &Note{Title: "Note", GUID: guid, Updated: int64(2000)}, nil }
+		note, err := GetNote(store, ns, "1", "")
+		assert.NoError(err)
+		assert.Equal(int64(2000), note.Updated, "Should have the updated time from the server")
+	})
+	t.Run("get note from search offline", func(t *testing.T) {
+		cached := &Note{Title: "Note", GUID: "GUID"}
+		store.getSearch = func() ([]*Note, error) { return []*Note{cached}, nil }
+		ns := new(mockNS)
+		ns.getNote = func(guid string) (*Note, error) { return nil, ErrOffline }
+		note, err := GetNote(store, ns, "1", "")
+		assert.NoError(err)
+		assert.Equal(cached, note, "Should use the note in the search")
+	})
+	t.Run("get note by GUID", func(t *testing.T) {
+		guid := "1b4cb6f4-5d49-4c7c-9f6e-2a0c3b7d8e9f"
+		expectedNote := &Note{Title: "Note", GUID: guid}
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) {
+			t.Fatal("Should not search for the note")
+			return nil, nil
+		}
+		ns.getNote = func(g string) (*Note, error) {
+			assert.Equal(guid, g, "Wrong GUID")
+			return expectedNote, nil
+		}
+		note, err := GetNote(store, ns, guid, "")
+		assert.NoError(err)
+		assert.Equal(expectedNote, note)
+	})
 	t.Run("handle cache note index overflow", func(t *testing.T) {
 		store.getSearch = func() ([]*Note, error) {
 			return []*Note{new(Note), new(Note), new(Note)}, nil
@@ -79,6 +219,37 @@ func TestGetNote(t *testing.T) {
 		_, err := GetNote(store, ns, title, "")
 		assert.EqualError(err, ErrNoNoteFound.Error())
 	})
+	t.Run("match title case insensitive", func(t *testing.T) {
+		expectedNote := &Note{Title: "Expected Note "}
+		ns := nsWithNote(expectedNote)
+		note, err := GetNote(store, ns, "  expected note", "")
+		assert.NoError(err)
+		assert.Equal(expectedNote, note)
+	})
+	t.Run("prefer exact match over partial match", func(t *testing.T) {
+		expectedNote := &Note{Title: "Meeting"}
+		notes := []*Note{&Note{Title: "Meeting notes"}, expectedNote}
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+		note, err := GetNote(store, ns, "meeting", "")
+		assert.NoError(err)
+		assert.Equal(expectedNote, note)
+	})
+	t.Run("single partial match", func(t *testing.T) {
+		expectedNote := &Note{Title: "Shopping list for the weekend"}
+		ns := nsWithNote(expectedNote)
+		note, err := GetNote(store, ns, "Shopping List", "")
+		assert.NoError(err)
+		assert.Equal(expectedNote, note)
+	})
+	t.Run("multiple partial matches", func(t *testing.T) {
+		notes := []*Note{&Note{Title: "Meeting 1"}, &Note{Title: "Other"}, &Note{Title: "Meeting 2"}}
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+		_, err := GetNote(store, ns, "meeting", "")
+		assert.True(errors.Is(err, ErrMultipleNotesFound), "Wrong error returned")
+		assert.Contains(err.Error(), `"Meeting 1", "Meeting 2"`, "Candidate titles should be listed")
+	})
 	t.Run("restrict notes by notebook", func(t *testing.T) {
 		title := "Expected Note"
 		notebook := "Expected Notebook"
@@ -107,6 +278,31 @@ func TestGetNote(t *testing.T) {
 	})
 }
 
+func TestStreamNoteContent(t *testing.T) {
+	assert := assert.New(t)
+	content := XMLHeader + "<en-note><div>Body & more</div></en-note>"
+
+	t.Run("write content as is", func(t *testing.T) {
+		ns := &mockNS{getNoteContent: func(guid string) (string, error) {
+			assert.Equal("GUID", guid, "Wrong GUID")
+			return content, nil
+		}}
+		buf := new(bytes.Buffer)
+		err := StreamNoteContent(ns, "GUID", buf)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(content, buf.String(), "Content should not be changed")
+	})
+
+	t.Run("return error from GetNoteContent", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := &mockNS{getNoteContent: func(string) (string, error) { return "", expectedErr }}
+		buf := new(bytes.Buffer)
+		err := StreamNoteContent(ns, "GUID", buf)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+		assert.Empty(buf.String(), "Nothing should be written")
+	})
+}
+
 func TestGetNoteContent(t *testing.T) {
 	assert := assert.New(t)
 	store := &mockStore{
@@ -142,6 +338,745 @@ func TestGetNoteContent(t *testing.T) {
 		_, err := GetNoteWithContent(store, ns, title)
 		assert.Error(err, "Expected an error")
 	})
+	t.Run("repair malformed content", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		warningOutput = buf
+		defer func() { warningOutput = os.Stderr }()
+		title := "Note title"
+		note := &Note{Title: title}
+		ns := nsWithNote(note)
+		ns.getNoteContent = func(string) (string, error) {
+			return "<en-note><div>a < b & c<br></div><hr><div>After</div></en-note>", nil
+		}
+		n, err := GetNoteWithContent(store, ns, title)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("<div>a &lt; b &amp; c<br/></div><hr/><div>After</div>", n.Body, "Wrong body")
+		assert.Contains(n.MD, "After", "Content after the void element should be kept")
+		assert.Contains(buf.String(), title, "Should warn about the note")
+	})
+	t.Run("no warning for valid content", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		warningOutput = buf
+		defer func() { warningOutput = os.Stderr }()
+		title := "Note title"
+		note := &Note{Title: title}
+		ns := nsWithNote(note)
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><div>a &amp; b<br/></div></en-note>", nil }
+		_, err := GetNoteWithContent(store, ns, title)
+		assert.NoError(err, "Should not return an error")
+		assert.Empty(buf.String(), "Should not warn")
+	})
+	t.Run("use cached content", func(t *testing.T) {
+		title := "Note title"
+		store := &mockStore{
+			getCachedContent: func(guid string, updated int64) (string, error) {
+				assert.Equal("GUID", guid, "Wrong GUID")
+				assert.Equal(int64(1000), updated, "Wrong updated time")
+				return "<en-note><p>Cached</p></en-note>", nil
+			},
+		}
+		ns := nsWithNote(&Note{Title: title, GUID: "GUID", Updated: int64(1000)})
+		ns.getNoteContent = func(string) (string, error) { t.Fatal("Content should not be fetched"); return "", nil }
+		n, err := GetNoteWithContent(store, ns, title)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("<p>Cached</p>", n.Body, "Wrong content")
+	})
+	t.Run("cache fetched content", func(t *testing.T) {
+		title := "Note title"
+		content := "<en-note><p>Fetched</p></en-note>"
+		var cached string
+		store := &mockStore{
+			getCachedContent:  func(string, int64) (string, error) { return "", nil },
+			saveCachedContent: func(guid string, updated int64, c string) error { cached = c; return nil },
+		}
+		ns := nsWithNote(&Note{Title: title, GUID: "GUID", Updated: int64(2000)})
+		ns.getNoteContent = func(string) (string, error) { return content, nil }
+		_, err := GetNoteWithContent(store, ns, title)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(content, cached, "Content not cached")
+	})
+	t.Run("cache key of a note from the saved search", func(t *testing.T) {
+		// The search was made before the note was edited, so only the
+		// server has the current updated time.
+		store := &mockStore{
+			getSearch: func() ([]*Note, error) { return []*Note{{Title: "Note", GUID: "GUID", Updated: int64(1000)}}, nil },
+			getCachedContent: func(guid string, updated int64) (string, error) {
+				if updated == int64(1000) {
+					return "<en-note><p>Before the edit</p></en-note>", nil
+				}
+				return "", nil
+			},
+			saveCachedContent: func(guid string, updated int64, c string) error { return nil },
+		}
+		ns := new(mockNS)
+		ns.getNote = func(guid string) (*Note, error) { return &Note{Title: "Note", GUID: guid, Updated: int64(2000)}, nil }
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><p>After the edit</p></en-note>", nil }
+		n, err := GetNoteWithContent(store, ns, "1")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("<p>After the edit</p>", n.Body, "Should not use the content cached before the edit")
+	})
+	t.Run("plain text content", func(t *testing.T) {
+		title := "Note title"
+		ns := nsWithNote(&Note{Title: title})
+		ns.getNoteContent = func(string) (string, error) {
+			return "<en-note><div># Not a heading</div><div>- item</div></en-note>", nil
+		}
+		n, err := getNoteWithContent(MarkdownConverter{}, store, ns, title, PlainTextNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("# Not a heading\n- item", n.MD, "Content should not be converted to markdown")
+	})
+	t.Run("en-note attributes", func(t *testing.T) {
+		title := "Note title"
+		ns := nsWithNote(&Note{Title: title})
+		ns.getNoteContent = func(string) (string, error) {
+			return XMLHeader + `<en-note style="font-family: Georgia;"><div>Styled</div></en-note>`, nil
+		}
+		n, err := GetNoteWithContent(store, ns, title)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(`style="font-family: Georgia;"`, n.BodyAttrs, "Wrong attributes captured")
+		assert.Equal("Styled", n.MD, "Wrong content")
+	})
+	t.Run("bare en-note", func(t *testing.T) {
+		title := "Note title"
+		ns := nsWithNote(&Note{Title: title})
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><div>Plain</div></en-note>", nil }
+		n, err := GetNoteWithContent(store, ns, title)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("", n.BodyAttrs, "No attributes should be captured")
+	})
+}
+
+func TestDeleteNotesMatching(t *testing.T) {
+	assert := assert.New(t)
+	store := new(mockStore)
+	createNotes := func(n int) []*Note {
+		notes := make([]*Note, n)
+		for i := range notes {
+			notes[i] = &Note{Title: "Note " + strconv.Itoa(i), GUID: "GUID" + strconv.Itoa(i)}
+		}
+		return notes
+	}
+	createNS := func(notes []*Note) (*mockNS, *[]string) {
+		var deleted []string
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, offset, count int) ([]*Note, error) {
+			if offset >= len(notes) {
+				return []*Note{}, nil
+			}
+			end := offset + count
+			if end > len(notes) {
+				end = len(notes)
+			}
+			return notes[offset:end], nil
+		}
+		ns.deleteNote = func(guid string) error { deleted = append(deleted, guid); return nil }
+		return ns, &deleted
+	}
+
+	t.Run("delete all matching notes", func(t *testing.T) {
+		notes := createNotes(findNotesBatchSize + 5)
+		ns, deleted := createNS(notes)
+		n, err := DeleteNotesMatching(store, ns, &NoteFilter{Words: "old draft"}, 0)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(len(notes), n, "Wrong number of deleted notes")
+		assert.Len(*deleted, len(notes), "All notes should be deleted")
+	})
+
+	t.Run("respect the limit", func(t *testing.T) {
+		ns, deleted := createNS(createNotes(10))
+		n, err := DeleteNotesMatching(store, ns, &NoteFilter{}, 3)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(3, n, "Wrong number of deleted notes")
+		assert.Equal([]string{"GUID0", "GUID1", "GUID2"}, *deleted, "Wrong notes deleted")
+	})
+
+	t.Run("continue after failed delete", func(t *testing.T) {
+		ns, _ := createNS(createNotes(3))
+		var deleted []string
+		ns.deleteNote = func(guid string) error {
+			if guid == "GUID1" {
+				return errors.New("expected error")
+			}
+			deleted = append(deleted, guid)
+			return nil
+		}
+		n, err := DeleteNotesMatching(store, ns, &NoteFilter{}, 0)
+		assert.Error(err, "Should return an error")
+		assert.Contains(err.Error(), `"Note 1": expected error`, "Failed note should be reported")
+		assert.Equal(2, n, "Wrong number of deleted notes")
+		assert.Equal([]string{"GUID0", "GUID2"}, deleted, "Other notes should be deleted")
+	})
+
+	t.Run("return error from FindNotes", func(t *testing.T) {
+		expectedError := errors.New("expected error")
+		ns := new(mockNS)
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return nil, expectedError }
+		n, err := DeleteNotesMatching(store, ns, &NoteFilter{}, 0)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Equal(0, n, "No notes should be deleted")
+	})
+}
+
+func TestRestoreNote(t *testing.T) {
+	assert := assert.New(t)
+	expectedError := errors.New("expected error")
+	nb := &Notebook{GUID: "NB GUID", Name: "Notebook"}
+	defaultNB := &Notebook{GUID: "Default GUID", Name: "Default"}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{}, nil },
+		storeNotebookList: func(*NotebookCacheList) error { return nil },
+	}
+	createNS := func(noteBook *Notebook) (*mockNS, *Note, *string, **Note) {
+		note := &Note{Title: "Deleted note", GUID: "GUID", Notebook: &Notebook{GUID: noteBook.GUID}}
+		var restored string
+		var updated *Note
+		ns := new(mockNS)
+		ns.listTrash = func() ([]*Note, error) { return []*Note{&Note{Title: "Other"}, note}, nil }
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{nb, defaultNB}, nil }
+		ns.getDefaultNB = func() (*Notebook, error) { return defaultNB, nil }
+		ns.restoreNote = func(guid string) error { restored = guid; return nil }
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		return ns, note, &restored, &updated
+	}
+
+	t.Run("restore note", func(t *testing.T) {
+		ns, note, restored, updated := createNS(nb)
+		err := RestoreNote(store, ns, "deleted note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(note.GUID, *restored, "Wrong note restored")
+		assert.Nil(*updated, "Note should not be moved")
+	})
+
+	t.Run("restore to default notebook if notebook deleted", func(t *testing.T) {
+		ns, note, restored, updated := createNS(&Notebook{GUID: "Deleted notebook"})
+		err := RestoreNote(store, ns, "Deleted note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(note.GUID, *restored, "Wrong note restored")
+		assert.Equal(defaultNB, (*updated).Notebook, "Note should be moved to the default notebook")
+	})
+
+	t.Run("return error if not in trash", func(t *testing.T) {
+		ns, _, restored, _ := createNS(nb)
+		err := RestoreNote(store, ns, "Missing")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+		assert.Equal("", *restored, "No note should be restored")
+	})
+
+	t.Run("return error from ListTrash", func(t *testing.T) {
+		ns, _, _, _ := createNS(nb)
+		ns.listTrash = func() ([]*Note, error) { return nil, expectedError }
+		err := RestoreNote(store, ns, "Deleted note")
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+
+	t.Run("return error from RestoreNote", func(t *testing.T) {
+		ns, _, _, _ := createNS(nb)
+		ns.restoreNote = func(string) error { return expectedError }
+		err := RestoreNote(store, ns, "Deleted note")
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+}
+
+func TestAppendNote(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	title := "Log"
+	newNS := func() (*mockNS, *string) {
+		body := "<en-note><div>Original</div></en-note>"
+		ns := nsWithNote(&Note{Title: title, GUID: "GUID"})
+		ns.getNoteContent = func(string) (string, error) { return body, nil }
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid}, nil }
+		ns.updateNote = func(n *Note) error { body = n.Body; return nil }
+		return ns, &body
+	}
+
+	t.Run("append", func(t *testing.T) {
+		ns, body := newNS()
+		err := AppendNote(store, ns, title, "Entry\n", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(testXML("Original\n\nEntry"), *body, "Content not appended")
+	})
+
+	t.Run("prepend twice", func(t *testing.T) {
+		ns, body := newNS()
+		assert.NoError(PrependNote(store, ns, title, "First entry", DefaultNoteOption))
+		assert.NoError(PrependNote(store, ns, title, "Second entry", DefaultNoteOption))
+		assert.Equal(testXML("Second entry\n\nFirst entry\n\nOriginal"), *body, "Entries in the wrong order")
+	})
+
+	t.Run("prepend raw", func(t *testing.T) {
+		ns, body := newNS()
+		err := PrependNote(store, ns, title, "<div>Entry</div>", RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div>Entry</div>\n\n<div>Original</div></en-note>", *body, "Content not prepended")
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		ns, _ := newNS()
+		ns.updateNote = func(*Note) error { t.Fatal("Note should not be updated"); return nil }
+		assert.NoError(AppendNote(store, ns, title, "\n", DefaultNoteOption))
+	})
+}
+
+func TestCopyNote(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	title := "Template"
+	srcBook := &Notebook{GUID: "Source GUID"}
+	newNS := func(created **Note) *mockNS {
+		ns := nsWithNote(&Note{Title: title, GUID: "Note GUID", Notebook: srcBook, Tags: []string{"work"}})
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><p>Note content</p></en-note>", nil }
+		ns.createNote = func(n *Note) error { *created = n; return nil }
+		return ns
+	}
+
+	t.Run("copy to the same notebook", func(t *testing.T) {
+		var created *Note
+		n, err := CopyNote(store, newNS(&created), title, "Copy", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(created, n, "Should return the saved note")
+		assert.Equal("Copy", n.Title, "Wrong title")
+		assert.Equal("", n.GUID, "GUID should be cleared")
+		assert.Equal(srcBook, n.Notebook, "Wrong notebook")
+		assert.Equal([]string{"work"}, n.Tags, "Tags should be copied")
+		assert.Equal(testXML("Note content"), n.Body, "Wrong content")
+	})
+
+	t.Run("copy to another notebook", func(t *testing.T) {
+		var created *Note
+		target := &Notebook{GUID: "Target GUID", Name: "Target"}
+		ns := newNS(&created)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{target}, nil }
+		n, err := CopyNote(store, ns, title, "Copy", "Target")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(target, n.Notebook, "Wrong notebook")
+	})
+
+	t.Run("copy attachments with their data", func(t *testing.T) {
+		var created *Note
+		ns := newNS(&created)
+		hash := []byte{0xab, 0xcd}
+		ns.getNoteWithResources = func(guid string) (*Note, error) {
+			assert.Equal("Note GUID", guid, "Wrong GUID")
+			return &Note{GUID: guid, Resources: []*Attachment{{Filename: "a.png", MIMEType: "image/png", Hash: hash, Data: []byte("data")}}}, nil
+		}
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) {
+			return []*Note{{Title: title, GUID: "Note GUID", Resources: []*Attachment{{Filename: "a.png", MIMEType: "image/png", Hash: hash}}}}, nil
+		}
+		n, err := CopyNote(store, ns, title, "Copy", "")
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(n.Resources, 1, "Attachment should be copied") {
+			assert.Equal([]byte("data"), n.Resources[0].Data, "Data should be copied")
+			assert.Equal(hash, n.Resources[0].Hash, "Hash should be kept")
+		}
+	})
+
+	t.Run("return error if attachment data is missing", func(t *testing.T) {
+		var created *Note
+		ns := newNS(&created)
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) {
+			return []*Note{{Title: title, GUID: "Note GUID", Resources: []*Attachment{{Filename: "a.png"}}}}, nil
+		}
+		ns.getNoteWithResources = func(guid string) (*Note, error) {
+			return &Note{GUID: guid, Resources: []*Attachment{{Filename: "a.png"}}}, nil
+		}
+		_, err := CopyNote(store, ns, title, "Copy", "")
+		assert.True(errors.Is(err, ErrNoAttachmentData), "Should return ErrNoAttachmentData")
+		assert.Nil(created, "Note should not be created")
+	})
+
+	t.Run("return error if notebook not found", func(t *testing.T) {
+		var created *Note
+		ns := newNS(&created)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{}, nil }
+		_, err := CopyNote(store, ns, title, "Copy", "Missing")
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+		assert.Nil(created, "Note should not be created")
+	})
+}
+
+func TestExportNote(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	dir, err := ioutil.TempDir("", "clinote-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	title := "Note title"
+	nb := &Notebook{GUID: "Notebook GUID", Name: "Notebook"}
+	newNS := func() *mockNS {
+		ns := nsWithNote(&Note{Title: title, Notebook: &Notebook{GUID: nb.GUID}})
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><p>Note content</p></en-note>", nil }
+		ns.getNotebook = func(string) (*Notebook, error) { return nb, nil }
+		return ns
+	}
+
+	t.Run("export markdown", func(t *testing.T) {
+		path := filepath.Join(dir, "note.md")
+		err := ExportNote(store, newNS(), title, path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(err, "Should be able to read the exported file")
+		assert.Equal("---\ntitle: Note title\nnotebook: Notebook\n---\nNote content\n", string(data))
+	})
+
+	t.Run("export wrapped markdown", func(t *testing.T) {
+		path := filepath.Join(dir, "wrapped.md")
+		err := ExportNoteWrapped(store, newNS(), title, path, DefaultNoteOption, 5)
+		assert.NoError(err, "Should not return an error")
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(err, "Should be able to read the exported file")
+		assert.Equal("---\ntitle: Note title\nnotebook: Notebook\n---\nNote\ncontent\n", string(data))
+	})
+
+	t.Run("export tags", func(t *testing.T) {
+		path := filepath.Join(dir, "tags.md")
+		ns := nsWithNote(&Note{Title: title, TagGUIDs: []string{"TAG1"}})
+		ns.getNoteContent = func(string) (string, error) { return "<en-note><p>Note content</p></en-note>", nil }
+		ns.listTags = func() ([]*Tag, error) { return []*Tag{{Name: "Work", GUID: "TAG1"}}, nil }
+		err := ExportNote(store, ns, title, path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(err, "Should be able to read the exported file")
+		assert.Equal("---\ntitle: Note title\ntags: Work\n---\nNote content\n", string(data))
+	})
+
+	t.Run("export raw", func(t *testing.T) {
+		path := filepath.Join(dir, "note.xml")
+		err := ExportNote(store, newNS(), title, path, RawNote)
+		assert.NoError(err, "Should not return an error")
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(err, "Should be able to read the exported file")
+		assert.Equal("---\ntitle: Note title\nnotebook: Notebook\n---\n<p>Note content</p>\n", string(data))
+	})
+
+	t.Run("overwrite existing file", func(t *testing.T) {
+		path := filepath.Join(dir, "existing.md")
+		assert.NoError(ioutil.WriteFile(path, []byte("old content that is longer than the note"), 0600))
+		err := ExportNote(store, newNS(), title, path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		data, _ := ioutil.ReadFile(path)
+		assert.NotContains(string(data), "old content", "Old content should be replaced")
+	})
+
+	t.Run("return error if note not found", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.md")
+		err := ExportNote(store, newNS(), "Missing note", path, DefaultNoteOption)
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+		_, err = os.Stat(path)
+		assert.True(os.IsNotExist(err), "No file should be created")
+	})
+
+	t.Run("return error from GetNotebook", func(t *testing.T) {
+		expectedError := errors.New("expected error")
+		ns := newNS()
+		ns.getNotebook = func(string) (*Notebook, error) { return nil, expectedError }
+		err := ExportNote(store, ns, title, filepath.Join(dir, "nb.md"), DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+
+	t.Run("export to default filename", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chdir(wd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+		err = ExportNote(store, newNS(), title, "", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		_, err = os.Stat(filepath.Join(dir, "Note-title.md"))
+		assert.NoError(err, "File should be named after the title")
+
+		err = ExportNote(store, newNS(), title, "", DefaultNoteOption)
+		assert.Equal(ErrExportFileExists, err, "Existing file should not be overwritten")
+		err = ExportNote(store, newNS(), title, "", ForceNote)
+		assert.NoError(err, "Existing file should be overwritten with force")
+	})
+}
+
+func TestSlugifyTitle(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Note title", "Note-title"},
+		{"2018/01/02: Meeting", "2018-01-02-Meeting"},
+		{"What?  <b>*bold*</b>", "What-b-bold-b"},
+		{"Tab\tand\nnew line\x01", "Tab-and-new-line"},
+		{"..hidden.", "hidden"},
+		{"Ünïcödé 日本", "Ünïcödé-日本"},
+		{"//::", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.expected, slugifyTitle(test.title))
+		})
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("Note.md", exportFilename(&Note{Title: "Note", GUID: "GUID"}, ".md"))
+	assert.Equal("Note.xml", exportFilename(&Note{Title: "Note"}, noteFileExtension(RawNote)))
+	assert.Equal("GUID.md", exportFilename(&Note{Title: "/:/", GUID: "GUID"}, ".md"))
+	assert.Equal("untitled.enex", exportFilename(&Note{Title: "?"}, ".enex"))
+}
+
+func TestNewNoteFromTemplate(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "meeting.md")
+	template := "---\ntitle: Meeting {{date}}\nnotebook: Work\ntags: meeting\n---\n# {{title}}\n\nNotes\n"
+	if err := ioutil.WriteFile(path, []byte(template), 0600); err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().Format(timeFormat)
+
+	t.Run("use the template header", func(t *testing.T) {
+		n, err := NewNoteFromTemplate(path, "", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Meeting "+today, n.Title, "Wrong title")
+		assert.Equal("Work", n.Notebook.Name, "Wrong notebook")
+		assert.Equal([]string{"meeting"}, n.Tags, "Wrong tags")
+		assert.Equal("# \n\nNotes", n.MD, "Wrong content")
+	})
+
+	t.Run("title takes precedence", func(t *testing.T) {
+		n, err := NewNoteFromTemplate(path, "Planning", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Planning", n.Title, "Wrong title")
+		assert.Equal("# Planning\n\nNotes", n.MD, "Title placeholder not replaced")
+	})
+
+	t.Run("template without header", func(t *testing.T) {
+		plain := filepath.Join(dir, "plain.md")
+		if err := ioutil.WriteFile(plain, []byte("Date: {{date}}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		n, err := NewNoteFromTemplate(plain, "", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(DefaultNoteTitle, n.Title, "Should use the default title")
+		assert.Equal("Date: "+today, n.MD, "Date placeholder not replaced")
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		_, err := NewNoteFromTemplate(filepath.Join(dir, "missing.md"), "", DefaultNoteOption)
+		assert.Error(err, "Should return an error")
+	})
+}
+
+func TestParseHeaderTimes(t *testing.T) {
+	assert := assert.New(t)
+	header := "---\ntitle: Note\ncreated: 1420070400000\nupdated: 1436000000000\n---\nContent\n"
+
+	n := &Note{GUID: "GUID", Updated: 5}
+	assert.NoError(parseNote(strings.NewReader(header), n, DefaultNoteOption), "Should not return an error")
+	assert.Equal(int64(1420070400000), n.Created, "Created time should be set")
+	assert.Equal(int64(5), n.Updated, "Updated time of an existing note should be kept")
+
+	n = new(Note)
+	assert.NoError(parseNote(strings.NewReader(header), n, DefaultNoteOption), "Should not return an error")
+	assert.Equal(int64(1436000000000), n.Updated, "Updated time of a new note should be set")
+
+	t.Run("write and parse back", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(WriteNote(buf, &Note{Title: "Note", Created: 1420070400123, Updated: 1436000000456}, DefaultNoteOption))
+		assert.Contains(buf.String(), "created: "+noteTime(1420070400123).Format(headTimeFormat)+"\n", "Created time should be written")
+		assert.Contains(buf.String(), "updated: "+noteTime(1436000000456).Format(headTimeFormat)+"\n", "Updated time should be written")
+		n := new(Note)
+		assert.NoError(parseNote(buf, n, DefaultNoteOption), "Should not return an error")
+		assert.Equal(int64(1420070400123), n.Created, "Created time should be unchanged")
+		assert.Equal(int64(1436000000456), n.Updated, "Updated time should be unchanged")
+	})
+}
+
+func TestImportNote(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	nb := &Notebook{GUID: "Notebook GUID", Name: "Notebook"}
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	newNS := func(created **Note) *mockNS {
+		ns := new(mockNS)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{nb}, nil }
+		ns.createNote = func(n *Note) error { *created = n; return nil }
+		return ns
+	}
+
+	t.Run("import note with header", func(t *testing.T) {
+		var created *Note
+		path := writeFile("header.md", "---\ntitle: Imported\nnotebook: Notebook\ntags: a, b\n---\n# Heading\n\nContent\n")
+		n, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(n, created, "Should save the returned note")
+		assert.Equal("Imported", n.Title, "Wrong title")
+		assert.Equal(nb, n.Notebook, "Wrong notebook")
+		assert.Equal([]string{"a", "b"}, n.Tags, "Wrong tags")
+		assert.Equal("# Heading\n\nContent", n.MD, "Wrong content")
+		assert.Contains(n.Body, "<h1>Heading</h1>", "Content should be converted")
+	})
+
+	t.Run("keep created and updated time", func(t *testing.T) {
+		var created *Note
+		path := writeFile("dates.md", "---\ntitle: Old note\ncreated: 2015-03-04T10:00:00Z\nupdated: 1436000000000\n---\nContent\n")
+		n, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(toMillis(time.Date(2015, 3, 4, 10, 0, 0, 0, time.UTC)), created.Created, "Created time should be kept")
+		assert.Equal(int64(1436000000000), created.Updated, "Updated time should be kept")
+		assert.Empty(n.ExtraHeaders, "Times should not be kept as custom fields")
+	})
+
+	t.Run("return error for created time in the future", func(t *testing.T) {
+		var created *Note
+		future := time.Now().Add(48 * time.Hour).Format(time.RFC3339)
+		path := writeFile("future.md", "---\ntitle: Future note\ncreated: "+future+"\n---\nContent\n")
+		_, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.Equal(ErrFutureCreatedTime, err, "Wrong error returned")
+		assert.Nil(created, "Note should not be created")
+	})
+
+	t.Run("return error for invalid time", func(t *testing.T) {
+		var created *Note
+		path := writeFile("invalid.md", "---\ntitle: Note\nupdated: last week\n---\nContent\n")
+		_, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.Equal(ErrInvalidNoteTime, err, "Wrong error returned")
+	})
+
+	t.Run("use filename without header", func(t *testing.T) {
+		var created *Note
+		path := writeFile("My note.md", "Content\n")
+		n, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("My note", n.Title, "Title should be the filename")
+		assert.Equal("Content", n.MD, "Wrong content")
+		assert.Nil(n.Notebook, "Notebook should not be set")
+	})
+
+	t.Run("use filename if header has no title", func(t *testing.T) {
+		var created *Note
+		path := writeFile("untitled.md", "---\nnotebook: Notebook\n---\nContent\n")
+		n, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("untitled", n.Title, "Title should be the filename")
+	})
+
+	t.Run("return error for unknown notebook", func(t *testing.T) {
+		var created *Note
+		path := writeFile("unknown.md", "---\ntitle: Note\nnotebook: Unknown\n---\nContent\n")
+		_, err := ImportNote(newNS(&created), path, DefaultNoteOption)
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+		assert.Nil(created, "Note should not be created")
+	})
+
+	t.Run("return error for missing file", func(t *testing.T) {
+		var created *Note
+		_, err := ImportNote(newNS(&created), filepath.Join(dir, "missing.md"), DefaultNoteOption)
+		assert.Error(err, "Should return an error")
+	})
+
+	t.Run("return error from CreateNote", func(t *testing.T) {
+		expectedError := errors.New("expected error")
+		var created *Note
+		ns := newNS(&created)
+		ns.createNote = func(*Note) error { return expectedError }
+		_, err := ImportNote(ns, writeFile("err.md", "Content\n"), DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+}
+
+func TestImportDirectory(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-import-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a.md":     "First\n",
+		"b.txt":    "Not markdown\n",
+		"c.md":     "---\ntitle: Broken\nnotebook: Unknown\n---\nContent\n",
+		"sub/d.md": "---\ntitle: Nested\nnotebook: Other\n---\nContent\n",
+		"sub/e.MD": "Upper\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nb := &Notebook{GUID: "Notebook GUID", Name: "Imported"}
+	other := &Notebook{GUID: "Other GUID", Name: "Other"}
+	newNS := func(created *[]*Note) *mockNS {
+		ns := new(mockNS)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{nb, other}, nil }
+		ns.createNote = func(n *Note) error { *created = append(*created, n); return nil }
+		return ns
+	}
+
+	t.Run("import all files to the notebook", func(t *testing.T) {
+		var created []*Note
+		notes, err := ImportDirectory(newNS(&created), dir, "Imported", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(created, notes, "Should return the saved notes")
+		var titles []string
+		for _, n := range notes {
+			titles = append(titles, n.Title)
+			assert.Equal(nb, n.Notebook, "Wrong notebook for %s", n.Title)
+		}
+		assert.Equal([]string{"a", "Broken", "Nested", "e"}, titles, "Wrong notes imported")
+	})
+
+	t.Run("continue past failed files", func(t *testing.T) {
+		var created []*Note
+		notes, err := ImportDirectory(newNS(&created), dir, "", DefaultNoteOption)
+		assert.Error(err, "Should return an error")
+		assert.Contains(err.Error(), "failed to import 1 of 4 files", "Wrong error message")
+		assert.Contains(err.Error(), "c.md", "Error should name the failed file")
+		assert.Len(notes, 3, "Should import the other files")
+		assert.Equal(other, notes[1].Notebook, "Should use the notebook in the header")
+	})
+
+	t.Run("return error for unknown notebook", func(t *testing.T) {
+		var created []*Note
+		notes, err := ImportDirectory(newNS(&created), dir, "Unknown", DefaultNoteOption)
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+		assert.Nil(notes, "Should not import any notes")
+		assert.Nil(created, "Should not create any notes")
+	})
+
+	t.Run("return error for missing directory", func(t *testing.T) {
+		var created []*Note
+		_, err := ImportDirectory(newNS(&created), filepath.Join(dir, "missing"), "", DefaultNoteOption)
+		assert.Error(err, "Should return an error")
+	})
 }
 
 func TestSaveChanges(t *testing.T) {
@@ -183,6 +1118,76 @@ func TestSaveChanges(t *testing.T) {
 		assert.NoError(err, "Should not return an error")
 		assert.Equal(expectedRawContent, note.Body, "Note content doesn't match")
 	})
+	t.Run("keep en-note attributes", func(t *testing.T) {
+		ns := new(mockNS)
+		note := &Note{MD: body, BodyAttrs: `style="background:#fff;"`}
+		ns.updateNote = func(n *Note) error { return nil }
+		err := SaveChanges(ns, note, opts)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+`<en-note style="background:#fff;"><p>`+body+"</p>\n</en-note>", note.Body, "Attributes should be kept")
+	})
+	t.Run("save if not changed on the server", func(t *testing.T) {
+		ns := new(mockNS)
+		note := &Note{GUID: "GUID", Updated: int64(1000)}
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, Updated: int64(1000)}, nil }
+		updated := false
+		ns.updateNote = func(n *Note) error { updated = true; return nil }
+		err := SaveChanges(ns, note, opts)
+		assert.NoError(err, "Should not return an error")
+		assert.True(updated, "Note should be updated")
+	})
+	t.Run("return conflict if changed on the server", func(t *testing.T) {
+		ns := new(mockNS)
+		note := &Note{GUID: "GUID", Updated: int64(1000)}
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, Updated: int64(2000)}, nil }
+		ns.updateNote = func(n *Note) error { t.Fatal("Note should not be updated"); return nil }
+		err := SaveChanges(ns, note, opts)
+		assert.Equal(ErrNoteConflict, err, "Wrong error returned")
+	})
+	t.Run("save note from the saved search twice", func(t *testing.T) {
+		// The saved search has the updated time from before the first
+		// save, so the second save must compare the server's own time.
+		server := &Note{Title: "Note", GUID: "GUID", Updated: int64(1000)}
+		store := &mockStore{getSearch: func() ([]*Note, error) { return []*Note{{Title: "Note", GUID: "GUID", Updated: int64(1000)}}, nil }}
+		ns := new(mockNS)
+		ns.getNote = func(guid string) (*Note, error) { n := *server; return &n, nil }
+		ns.updateNote = func(n *Note) error { server.Updated += 1000; n.Updated = server.Updated; return nil }
+		for i := 0; i < 2; i++ {
+			note, err := GetNote(store, ns, "1", "")
+			if !assert.NoError(err) {
+				return
+			}
+			note.MD = body
+			assert.NoError(SaveChanges(ns, note, opts), "Should not return a conflict")
+		}
+		assert.Equal(int64(3000), server.Updated, "Both saves should be made")
+	})
+	t.Run("force save if changed on the server", func(t *testing.T) {
+		ns := new(mockNS)
+		note := &Note{GUID: "GUID", Updated: int64(1000)}
+		updated := false
+		ns.updateNote = func(n *Note) error { updated = true; return nil }
+		err := SaveChanges(ns, note, ForceNote)
+		assert.NoError(err, "Should not return an error")
+		assert.True(updated, "Note should be updated")
+	})
+	t.Run("return error from GetNote", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.getNote = func(string) (*Note, error) { return nil, expectedError }
+		err := SaveChanges(ns, &Note{GUID: "GUID"}, opts)
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+	t.Run("dry run prints content", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		dryRunOutput = buf
+		defer func() { dryRunOutput = os.Stdout }()
+		ns := new(mockNS)
+		ns.getNote = func(string) (*Note, error) { t.Fatal("Server should not be called"); return nil, nil }
+		ns.updateNote = func(n *Note) error { t.Fatal("Note should not be updated"); return nil }
+		err := SaveChanges(ns, &Note{GUID: "GUID", MD: body}, DryRunNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expectedMDContent+"\n", buf.String(), "Wrong content printed")
+	})
 }
 
 func TestChangeTitle(t *testing.T) {
@@ -201,9 +1206,32 @@ func TestChangeTitle(t *testing.T) {
 
 		err := ChangeTitle(store, ns, "Old", "New")
 		assert.NoError(err, "Should not return an error")
-		assert.Equal(note, savedNote, "Same note should be saved")
+		assert.Equal(note, savedNote, "Same note should be saved")
+		assert.Equal("New", savedNote.Title, "Title should be New")
+	})
+	t.Run("should change title by GUID", func(t *testing.T) {
+		guid := "6a5ba1b2-1c2d-4e5f-8a9b-0c1d2e3f4a5b"
+		ns := new(mockNS)
+		var savedNote *Note
+		ns.getNote = func(g string) (*Note, error) { return &Note{GUID: g, Title: "Old"}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return nil, errors.New("should not search") }
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+
+		err := ChangeTitle(store, ns, guid, "New")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(guid, savedNote.GUID, "Wrong note saved")
 		assert.Equal("New", savedNote.Title, "Title should be New")
 	})
+	t.Run("should return ErrNoNoteFound", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{&Note{Title: "Other"}}, nil }
+		ns.getNote = func(string) (*Note, error) { return nil, ErrNoNoteFound }
+
+		err := ChangeTitle(store, ns, "Old", "New")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+		err = ChangeTitle(store, ns, "6a5ba1b2-1c2d-4e5f-8a9b-0c1d2e3f4a5b", "New")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
 	t.Run("should handle error from saveChanges", func(t *testing.T) {
 		ns := new(mockNS)
 		note := &Note{Title: "Old"}
@@ -285,6 +1313,80 @@ func TestMoveNote(t *testing.T) {
 	})
 }
 
+func TestMoveNotesMatching(t *testing.T) {
+	assert := assert.New(t)
+	target := &Notebook{Name: "Archive", GUID: "Archive GUID"}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	createNS := func() (*mockNS, *[]string) {
+		old := &Notebook{Name: "Old", GUID: "Old GUID"}
+		notes := []*Note{
+			{Title: "Note 0", GUID: "GUID0", Notebook: old},
+			{Title: "Note 1", GUID: "GUID1", Notebook: target},
+			{Title: "Note 2", GUID: "GUID2", Notebook: old},
+		}
+		var moved []string
+		ns := new(mockNS)
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{old, target}, nil }
+		ns.findNotes = func(filter *NoteFilter, offset, count int) ([]*Note, error) {
+			if offset >= len(notes) {
+				return []*Note{}, nil
+			}
+			return notes[offset:], nil
+		}
+		ns.updateNote = func(n *Note) error {
+			assert.Equal(target.GUID, n.Notebook.GUID, "Note should be moved to the target notebook")
+			moved = append(moved, n.GUID)
+			return nil
+		}
+		return ns, &moved
+	}
+
+	t.Run("move matching notes", func(t *testing.T) {
+		ns, moved := createNS()
+		n, err := MoveNotesMatching(store, ns, &NoteFilter{Words: "archive:"}, "Archive")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(2, n, "Wrong number of moved notes")
+		assert.Equal([]string{"GUID0", "GUID2"}, *moved, "Notes already in the notebook should be skipped")
+	})
+
+	t.Run("continue after failed move", func(t *testing.T) {
+		ns, _ := createNS()
+		var moved []string
+		ns.updateNote = func(n *Note) error {
+			if n.GUID == "GUID0" {
+				return errors.New("expected error")
+			}
+			moved = append(moved, n.GUID)
+			return nil
+		}
+		n, err := MoveNotesMatching(store, ns, &NoteFilter{}, "Archive")
+		assert.Error(err, "Should return an error")
+		assert.Contains(err.Error(), `"Note 0": expected error`, "Failed note should be reported")
+		assert.Equal(1, n, "Wrong number of moved notes")
+		assert.Equal([]string{"GUID2"}, moved, "Other notes should be moved")
+	})
+
+	t.Run("unknown notebook", func(t *testing.T) {
+		ns, moved := createNS()
+		n, err := MoveNotesMatching(store, ns, &NoteFilter{}, "Missing")
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+		assert.Equal(0, n, "No notes should be moved")
+		assert.Len(*moved, 0, "No notes should be moved")
+	})
+}
+
+func TestNoteLinks(t *testing.T) {
+	assert := assert.New(t)
+	n := &Note{GUID: "d8f0c5ea-1a3c-4b2e-9f00-2b7a1c9e5e11"}
+	assert.Equal("evernote:///view/123456/s42/d8f0c5ea-1a3c-4b2e-9f00-2b7a1c9e5e11/d8f0c5ea-1a3c-4b2e-9f00-2b7a1c9e5e11/",
+		NoteLink(n, "s42", "123456"), "Wrong app link")
+	assert.Equal("https://www.evernote.com/shard/s42/nl/123456/d8f0c5ea-1a3c-4b2e-9f00-2b7a1c9e5e11/",
+		NoteWebLink(n, "s42", "123456"), "Wrong web link")
+}
+
 func TestDeleteNote(t *testing.T) {
 	assert := assert.New(t)
 	noteGUID := "Note GUID"
@@ -319,35 +1421,144 @@ func TestDeleteNote(t *testing.T) {
 
 }
 
+func TestExpungeNote(t *testing.T) {
+	assert := assert.New(t)
+	noteGUID := "Note GUID"
+	noteTitle := "Note title"
+	expectedError := errors.New("expected error")
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	t.Run("should expunge note", func(t *testing.T) {
+		note := &Note{Title: noteTitle, GUID: noteGUID}
+		ns := nsWithNote(note)
+		var expunged string
+		ns.expungeNote = func(g string) error { expunged = g; return nil }
+		ns.deleteNote = func(string) error { return errors.New("note should not be moved to the trash") }
+		err := ExpungeNote(store, ns, noteTitle, "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(noteGUID, expunged, "Wrong note expunged")
+	})
+	t.Run("should return error for unknown note", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{}, nil }
+		err := ExpungeNote(store, ns, noteTitle, "")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+	t.Run("should return error from ExpungeNote", func(t *testing.T) {
+		ns := nsWithNote(&Note{Title: noteTitle, GUID: noteGUID})
+		ns.expungeNote = func(string) error { return expectedError }
+		err := ExpungeNote(store, ns, noteTitle, "")
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+}
+
 func TestSaveNewNote(t *testing.T) {
 	assert := assert.New(t)
 	expectedError := errors.New("expected error")
 	cases := []struct {
 		Name string
 		N    *Note
-		Raw  bool
+		Opts NoteOption
 	}{
-		{"empty note", &Note{}, false},
-		{"with MD", &Note{MD: "content"}, false},
-		{"raw content", &Note{Body: "<p>content</p>"}, true},
+		{"empty note", &Note{}, DefaultNoteOption},
+		{"with MD", &Note{MD: "content"}, DefaultNoteOption},
+		{"raw content", &Note{Body: "<p>content</p>"}, RawNote},
+		{"plain text", &Note{MD: "# content"}, PlainTextNote},
 	}
 	for _, test := range cases {
 		t.Run(test.Name, func(t *testing.T) {
 			ns := new(mockNS)
 			var createdNote *Note
 			ns.createNote = func(n *Note) error { createdNote = n; return nil }
-			err := SaveNewNote(ns, test.N, test.Raw)
+			err := SaveNewNote(ns, test.N, test.Opts)
 			assert.NoError(err, "Should not return an error")
 			assert.Equal(test.N, createdNote, "Should save the correct note")
 		})
 	}
+	t.Run("default source application", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.createNote = func(*Note) error { return nil }
+		n := &Note{MD: "content"}
+		assert.NoError(SaveNewNote(ns, n, DefaultNoteOption), "Should not return an error")
+		assert.Equal(DefaultSourceApplication, n.SourceApplication, "Wrong source application")
+
+		n = &Note{MD: "content", SourceApplication: "my-script"}
+		assert.NoError(SaveNewNote(ns, n, DefaultNoteOption), "Should not return an error")
+		assert.Equal("my-script", n.SourceApplication, "Source application should be kept")
+	})
 	t.Run("return error from CreateNote", func(t *testing.T) {
 		ns := new(mockNS)
 		ns.createNote = func(*Note) error { return expectedError }
-		err := SaveNewNote(ns, &Note{}, false)
+		err := SaveNewNote(ns, &Note{}, DefaultNoteOption)
 		assert.Error(err, "should return an error")
 		assert.Equal(expectedError, err, "Wrong error returned")
 	})
+	t.Run("dry run", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		dryRunOutput = buf
+		defer func() { dryRunOutput = os.Stdout }()
+		ns := new(mockNS)
+		n := &Note{MD: "content"}
+		err := SaveNewNote(ns, n, DryRunNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(testXML("content")+"\n", buf.String(), "Wrong content printed")
+		assert.Equal("", n.Body, "Note should not be changed")
+	})
+	t.Run("plain text is not converted", func(t *testing.T) {
+		ns := new(mockNS)
+		n := &Note{MD: "# Not a heading\n* not a list"}
+		ns.createNote = func(*Note) error { return nil }
+		err := SaveNewNote(ns, n, PlainTextNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div># Not a heading</div><div>* not a list</div></en-note>", n.Body, "Wrong body saved")
+	})
+	t.Run("unique title", func(t *testing.T) {
+		nb := &Notebook{GUID: "NBGUID"}
+		existing := &Note{Title: "Existing Note", GUID: "GUID1", Notebook: nb}
+		ns := nsWithNote(existing)
+		var filter *NoteFilter
+		ns.findNotes = func(f *NoteFilter, o, max int) ([]*Note, error) {
+			filter = f
+			return []*Note{&Note{Title: "Existing Note 2"}, existing}, nil
+		}
+		created := false
+		ns.createNote = func(*Note) error { created = true; return nil }
+
+		err := SaveNewNote(ns, &Note{Title: " existing note ", Notebook: nb}, UniqueTitleNote)
+		assert.True(errors.Is(err, ErrDuplicateNote), "Should return ErrDuplicateNote")
+		assert.False(created, "Duplicate note should not be created")
+		assert.Equal("existing note", filter.Words, "Wrong search term")
+		assert.Equal("NBGUID", filter.NotebookGUID, "Should only search the notebook")
+
+		err = SaveNewNote(ns, &Note{Title: "Existing", Notebook: nb}, UniqueTitleNote)
+		assert.NoError(err, "Only exact title matches are duplicates")
+		assert.True(created, "Note should be created")
+
+		created = false
+		err = SaveNewNote(ns, &Note{Title: "Existing Note", Notebook: nb}, DefaultNoteOption)
+		assert.NoError(err, "Duplicates are allowed without the option")
+		assert.True(created, "Note should be created")
+	})
+	t.Run("unique title in default notebook", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.getDefaultNB = func() (*Notebook, error) { return &Notebook{GUID: "DEFAULT"}, nil }
+		var filter *NoteFilter
+		ns.findNotes = func(f *NoteFilter, o, max int) ([]*Note, error) {
+			filter = f
+			return []*Note{&Note{Title: "Title"}}, nil
+		}
+		err := SaveNewNote(ns, &Note{Title: "Title"}, UniqueTitleNote)
+		assert.True(errors.Is(err, ErrDuplicateNote), "Should return ErrDuplicateNote")
+		assert.Equal("DEFAULT", filter.NotebookGUID, "Should search the default notebook")
+	})
+	t.Run("unique title search error", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return nil, expectedError }
+		err := SaveNewNote(ns, &Note{Title: "Title", Notebook: &Notebook{GUID: "NBGUID"}}, UniqueTitleNote)
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
 }
 
 func TestEditNote(t *testing.T) {
@@ -379,6 +1590,7 @@ func TestEditNote(t *testing.T) {
 		}
 		ns := nsWithNote(expectedNote)
 		ns.getNoteContent = func(guid string) (string, error) { return expectedNote.Body, nil }
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, Updated: expectedNote.Updated}, nil }
 		ns.getNotebook = func(guid string) (*Notebook, error) {
 			if guid != expectedNotebook.GUID {
 				return nil, nil
@@ -424,6 +1636,182 @@ func TestEditNote(t *testing.T) {
 		return a, b, c, d, e
 	}
 
+	t.Run("save_reminder_change", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		c.Editor = &mockEditor{edit: func(file CacheFile) error {
+			cache := file.(*mockCacheFile)
+			content := strings.Replace(cache.buffer.String(), "\n---\n", "\nreminder: 2024-06-01T09:00:00Z\n---\n", 1)
+			cache.buffer.Reset()
+			_, err := cache.buffer.WriteString(content)
+			return err
+		}}
+		var saved *Note
+		ns.updateNote = func(n *Note) error {
+			saved = n
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(saved, "Note should be saved when only the reminder changed") && assert.NotNil(saved.Reminder, "Reminder should be set") {
+			assert.Equal(toMillis(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)), saved.Reminder.Time, "Wrong reminder time")
+		}
+	})
+
+	t.Run("save_source_change", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		c.Editor = &mockEditor{edit: func(file CacheFile) error {
+			cache := file.(*mockCacheFile)
+			content := strings.Replace(cache.buffer.String(), "\n---\n", "\nsource-url: https://example.com\n---\n", 1)
+			cache.buffer.Reset()
+			_, err := cache.buffer.WriteString(content)
+			return err
+		}}
+		var saved *Note
+		ns.updateNote = func(n *Note) error {
+			saved = n
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(saved, "Note should be saved when only the source changed") {
+			assert.Equal("https://example.com", saved.SourceURL, "Wrong source URL")
+		}
+	})
+
+	t.Run("save_created_change", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		expectedNote.Created = toMillis(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+		c.Editor = &mockEditor{edit: func(file CacheFile) error {
+			cache := file.(*mockCacheFile)
+			content := strings.Replace(cache.buffer.String(), "created: "+noteTime(expectedNote.Created).Format(headTimeFormat),
+				"created: 2015-03-04T10:00:00Z", 1)
+			cache.buffer.Reset()
+			_, err := cache.buffer.WriteString(content)
+			return err
+		}}
+		var saved *Note
+		ns.updateNote = func(n *Note) error {
+			saved = n
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(saved, "Note should be saved when only the created time changed") {
+			assert.Equal(toMillis(time.Date(2015, 3, 4, 10, 0, 0, 0, time.UTC)), saved.Created, "Wrong created time")
+		}
+	})
+
+	t.Run("save_tag_change", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		expectedNote.TagGUIDs = []string{"TAG1"}
+		ns.listTags = func() ([]*Tag, error) {
+			return []*Tag{{Name: "Work", GUID: "TAG1"}, {Name: "Home", GUID: "TAG2"}}, nil
+		}
+		var header string
+		c.Editor = &mockEditor{edit: func(file CacheFile) error {
+			cache := file.(*mockCacheFile)
+			header = cache.buffer.String()
+			content := strings.Replace(header, "tags: Work\n", "tags: Work, Home\n", 1)
+			cache.buffer.Reset()
+			_, err := cache.buffer.WriteString(content)
+			return err
+		}}
+		var saved *Note
+		ns.updateNote = func(n *Note) error {
+			saved = n
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(header, "tags: Work\n", "Header should show the note's tags")
+		if assert.NotNil(saved, "Note should be saved when only the tags changed") {
+			assert.Equal([]string{"Work", "Home"}, saved.Tags, "Wrong tags")
+		}
+	})
+
+	t.Run("show_diff", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("New content added")
+		buf := new(bytes.Buffer)
+		diffOutput = buf
+		defer func() { diffOutput = os.Stdout }()
+		confirmed := false
+		c.ConfirmSave = func() bool {
+			confirmed = true
+			assert.Contains(buf.String(), "+New content added", "Diff should be shown before confirming")
+			return true
+		}
+		saveNoteCalled := false
+		ns.updateNote = func(*Note) error {
+			saveNoteCalled = true
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, ShowDiffNote)
+		assert.NoError(err, "Should not return an error")
+		assert.True(confirmed, "Should ask for confirmation")
+		assert.True(saveNoteCalled, "Should call SaveNote")
+		assert.Contains(buf.String(), "--- Note Title\n+++ Note Title\n", "Diff should have a header")
+		assert.Contains(buf.String(), " Body content\n", "Diff should include context")
+	})
+
+	t.Run("show_diff_cancelled", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("New content added")
+		diffOutput = new(bytes.Buffer)
+		defer func() { diffOutput = os.Stdout }()
+		c.ConfirmSave = func() bool { return false }
+		ns.updateNote = func(*Note) error {
+			t.Error("Should not save the note")
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, ShowDiffNote)
+		assert.Equal(ErrSaveCancelled, err, "Wrong error returned")
+	})
+
+	t.Run("show_diff_no_change", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		buf := new(bytes.Buffer)
+		diffOutput = buf
+		defer func() { diffOutput = os.Stdout }()
+		c.ConfirmSave = func() bool {
+			t.Error("Should not ask for confirmation")
+			return false
+		}
+		ns.updateNote = func(*Note) error { return nil }
+		err := EditNote(c, expectedNote.Title, ShowDiffNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Empty(buf.String(), "Should not write a diff")
+	})
+
+	t.Run("no_notebook", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("New content added")
+		expectedNote.Notebook = nil
+		ns.getNotebook = func(string) (*Notebook, error) {
+			t.Error("Should not get the notebook")
+			return nil, nil
+		}
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error {
+			savedNote = n
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(savedNote, "Should save the note") {
+			assert.Nil(savedNote.Notebook, "Notebook should not be set")
+			assert.Contains(savedNote.Body, "New content added", "Saved note should include added data")
+		}
+	})
+
+	t.Run("no_notebook_no_change", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		expectedNote.Notebook = nil
+		ns.updateNote = func(*Note) error {
+			t.Error("Should not save the note")
+			return nil
+		}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+	})
+
 	// No edit
 	t.Run("no_change_md", func(t *testing.T) {
 		c, ns, writtenData, expectedNote, originalContent := setupClient("")
@@ -579,6 +1967,16 @@ func TestEditNote(t *testing.T) {
 		assert.Equal(expectedError, err, "Wrong error returned")
 	})
 
+	t.Run("editor_aborted", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("")
+		c.Editor = &mockEditor{edit: func(CacheFile) error { return fmt.Errorf("%w: exit status 1", ErrEditorAborted) }}
+		ns.updateNote = func(*Note) error { t.Fatal("Note should not be saved"); return nil }
+		store.saveNoteRecoveryPoint = func(*Note) error { t.Fatal("Recovery point should not be saved"); return nil }
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(ErrEditorAborted, err, "Wrong error returned")
+	})
+
 	t.Run("save_recovery_point_if_saves_fails", func(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
 		ns.updateNote = func(*Note) error { return expectedError }
@@ -595,6 +1993,67 @@ func TestEditNote(t *testing.T) {
 		assert.Equal(expectedNote, savedNote, "Note not saved")
 	})
 
+	t.Run("keep_recovery_point_on_repeated_failures", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
+		ns.updateNote = func(*Note) error { return expectedError }
+		var last *Note
+		saves := 0
+		store.getNoteRecoveryPoint = func() (*Note, error) {
+			if last == nil {
+				return new(Note), nil
+			}
+			return last, nil
+		}
+		store.saveNoteRecoveryPoint = func(n *Note) error {
+			saves++
+			saved := *n
+			last = &saved
+			return nil
+		}
+
+		store.listRecoveryPoints = func() ([]*Note, error) {
+			recovered := *last
+			return []*Note{&recovered}, nil
+		}
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Equal(1, saves, "Recovery point should be saved")
+		draft := last.MD
+
+		// Reopen the recovery point without changing it.
+		c.Editor = &mockEditor{edit: func(CacheFile) error { return nil }}
+		err = EditRecoveryPoint(c, expectedNote.GUID, DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Equal(1, saves, "Unchanged recovery point should not be saved again")
+		assert.Equal(draft, last.MD, "Recovery point should be kept")
+
+		// Change the recovery point before the save fails again.
+		c.Editor = &mockEditor{edit: func(file CacheFile) error {
+			_, err := file.(*mockCacheFile).buffer.WriteString("\nmore text\n")
+			return err
+		}}
+		err = EditRecoveryPoint(c, expectedNote.GUID, DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Equal(2, saves, "Changed recovery point should be saved")
+		assert.Equal(draft+"\n\nmore text", last.MD, "Most recent recovery point should be kept")
+	})
+
+	t.Run("save_recovery_point_on_conflict", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, Updated: expectedNote.Updated + 1}, nil }
+		ns.updateNote = func(*Note) error { t.Fatal("Note should not be updated"); return nil }
+		var savedNote *Note
+		store.saveNoteRecoveryPoint = func(n *Note) error {
+			savedNote = n
+			return nil
+		}
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(ErrNoteConflict, err, "Wrong error returned")
+		assert.Equal(expectedNote, savedNote, "Note not saved")
+	})
+
 	t.Run("warn_if_recovery_fails", func(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
 		ns.updateNote = func(*Note) error { return expectedError }
@@ -632,6 +2091,41 @@ func TestEditNote(t *testing.T) {
 		assert.Equal(expectedNote, savedNote, "Wrong note saved")
 	})
 
+	t.Run("recover_note_by_guid", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("")
+		other := &Note{Title: "Other", GUID: "OTHERGUID"}
+		store.listRecoveryPoints = func() ([]*Note, error) { return []*Note{other, expectedNote}, nil }
+		var removed string
+		store.removeRecoveryPoint = func(guid string) error { removed = guid; return nil }
+		ns.getNoteContent = func(string) (string, error) { return "", errors.New("should not be called") }
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+
+		err := EditRecoveryPoint(c, expectedNote.GUID, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expectedNote, savedNote, "Unchanged recovery point should be saved")
+		assert.Equal(expectedNote.GUID, removed, "Recovery point should be removed")
+	})
+
+	t.Run("keep_recovery_point_if_save_fails", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("")
+		store.listRecoveryPoints = func() ([]*Note, error) { return []*Note{expectedNote}, nil }
+		store.removeRecoveryPoint = func(string) error { t.Fatal("Recovery point should not be removed"); return nil }
+		store.saveNoteRecoveryPoint = func(*Note) error { return nil }
+		ns.updateNote = func(*Note) error { return expectedError }
+
+		err := EditRecoveryPoint(c, expectedNote.GUID, DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+
+	t.Run("error_recover_unknown_guid", func(t *testing.T) {
+		c, _, _, _, _, store := setupClientAndStore("")
+		store.listRecoveryPoints = func() ([]*Note, error) { return []*Note{}, nil }
+
+		err := EditRecoveryPoint(c, "UNKNOWN", DefaultNoteOption)
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+
 	t.Run("error_recover_note_if_empty", func(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
 		expectedNote.GUID = ""
@@ -680,6 +2174,72 @@ notebook: New name of the notebook
 		assert.Error(err, "Should return an error")
 
 	})
+
+	t.Run("content_from_stdin", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		c.Editor = &mockEditor{edit: func(CacheFile) error { t.Fatal("Editor should not be opened"); return nil }}
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+		defer func() { stdinInput = os.Stdin }()
+
+		stdinInput = strings.NewReader("Content from stdin\n")
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption|StdinNote)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(savedNote, "Should save the note") {
+			assert.Equal(expectedNote.Title, savedNote.Title, "Title should not change")
+			assert.Equal("Content from stdin", savedNote.MD, "Content should be replaced")
+		}
+	})
+
+	t.Run("header_from_stdin", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		c.Editor = &mockEditor{edit: func(CacheFile) error { t.Fatal("Editor should not be opened"); return nil }}
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+		defer func() { stdinInput = os.Stdin }()
+
+		stdinInput = strings.NewReader("---\ntitle: Title from stdin\n---\nContent from stdin\n")
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption|StdinNote)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(savedNote, "Should save the note") {
+			assert.Equal("Title from stdin", savedNote.Title, "Title should be read from the header")
+			assert.Equal("Content from stdin", savedNote.MD, "Content should be replaced")
+		}
+	})
+}
+
+func TestReadNewNoteFromStdin(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { stdinInput = os.Stdin }()
+	tests := []struct {
+		name          string
+		title         string
+		input         string
+		expectedTitle string
+		expectedMD    string
+	}{
+		{"title_from_heading", DefaultNoteTitle, "# My heading\n\nNote body\n", "My heading", "Note body"},
+		{"no_heading", DefaultNoteTitle, "Note body\n", DefaultNoteTitle, "Note body"},
+		{"keep_given_title", "Given title", "# My heading\n", "Given title", "# My heading"},
+		{"header", DefaultNoteTitle, "---\ntitle: From header\n---\nNote body\n", "From header", "Note body"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdinInput = strings.NewReader(test.input)
+			note := &Note{Title: test.title}
+			assert.NoError(ReadNewNoteFromStdin(note, DefaultNoteOption|StdinNote), "Should not return an error")
+			assert.Equal(test.expectedTitle, note.Title, "Wrong title")
+			assert.Equal(test.expectedMD, note.MD, "Wrong content")
+		})
+	}
+
+	t.Run("notebook_from_header", func(t *testing.T) {
+		stdinInput = strings.NewReader("---\ntitle: Title\nnotebook: Notebook\n---\nNote body\n")
+		note := &Note{Title: DefaultNoteTitle}
+		assert.NoError(ReadNewNoteFromStdin(note, DefaultNoteOption|StdinNote), "Should not return an error")
+		assert.NotNil(note.Notebook, "Notebook should be set")
+		assert.Equal("Notebook", note.Notebook.Name, "Wrong notebook")
+	})
 }
 
 func TestCreateAndEditNewNote(t *testing.T) {
@@ -720,6 +2280,37 @@ func TestCreateAndEditNewNote(t *testing.T) {
 		assert.Len(actualFilename, 36+len(newNotePrependString)+3)
 	})
 
+	t.Run("editor_aborted", func(t *testing.T) {
+		editor := client.Editor
+		defer func() { client.Editor = editor }()
+		client.Editor = &mockEditor{edit: func(CacheFile) error { return ErrEditorAborted }}
+		savedNote = nil
+		err := CreateAndEditNewNote(client, &Note{Title: "Aborted"}, DefaultNoteOption)
+		assert.Equal(ErrEditorAborted, err, "Wrong error returned")
+		assert.Nil(savedNote, "Note should not be created")
+	})
+
+	t.Run("content_from_content_source", func(t *testing.T) {
+		defer func() { client.ContentSource = nil }()
+		client.ContentSource = &ReaderContentSource{Reader: strings.NewReader("---\ntitle: From pipe\nnotebook: \n---\nPiped content\n")}
+		savedNote = nil
+		err := CreateAndEditNewNote(client, &Note{Title: "New"}, DefaultNoteOption)
+		assert.NoError(err)
+		if assert.NotNil(savedNote, "Note should be created") {
+			assert.Equal("From pipe", savedNote.Title, "Title should be read from the content source")
+			assert.Equal("Piped content", savedNote.MD, "Content should be read from the content source")
+		}
+	})
+
+	t.Run("error_from_content_source", func(t *testing.T) {
+		defer func() { client.ContentSource = nil }()
+		client.ContentSource = &PipeContentSource{Path: filepath.Join(os.TempDir(), "clinote-missing-pipe")}
+		savedNote = nil
+		err := CreateAndEditNewNote(client, &Note{Title: "New"}, DefaultNoteOption)
+		assert.True(os.IsNotExist(err), "Should return the error from opening the pipe")
+		assert.Nil(savedNote, "Note should not be created")
+	})
+
 	t.Run("handle_error_from_parsing", func(t *testing.T) {
 		client.newCacheFile = func(_ *Client, _ string) (CacheFile, error) {
 			return &mockCacheFile{
@@ -772,11 +2363,96 @@ notebook: Name of the notebook
 		assert.NoError(err)
 		assert.Equal("Name of the notebook", savedNote.Notebook.Name)
 	})
+
+	titleTests := []struct {
+		name          string
+		content       string
+		expectedTitle string
+		expectedMD    string
+	}{
+		{"title_from_heading", "# My heading\n\nNote body", "My heading", "Note body"},
+		{"title_from_closed_heading", "# My heading #\nNote body", "My heading", "Note body"},
+		{"only_heading", "# My heading", "My heading", ""},
+		{"no_heading", "Note body\n# Heading", DefaultNoteTitle, "Note body\n# Heading"},
+		{"sub_heading", "## Sub heading\nNote body", DefaultNoteTitle, "## Sub heading\nNote body"},
+	}
+	for _, test := range titleTests {
+		t.Run(test.name, func(t *testing.T) {
+			content := test.content
+			client.Editor = &mockEditor{
+				edit: func(file CacheFile) error {
+					cache := file.(*mockCacheFile)
+					cache.buffer.Reset()
+					_, err := cache.buffer.WriteString("---\ntitle: " + DefaultNoteTitle + "\n---\n" + content + "\n")
+					return err
+				},
+			}
+			err := CreateAndEditNewNote(client, &Note{Title: DefaultNoteTitle}, DefaultNoteOption)
+			assert.NoError(err)
+			assert.Equal(test.expectedTitle, savedNote.Title, "Wrong title")
+			assert.Equal(test.expectedMD, savedNote.MD, "Wrong content")
+		})
+	}
+
+	t.Run("keep_given_title", func(t *testing.T) {
+		client.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				cache := file.(*mockCacheFile)
+				cache.buffer.Reset()
+				_, err := cache.buffer.WriteString("---\ntitle: Given title\n---\n# My heading\n")
+				return err
+			},
+		}
+		err := CreateAndEditNewNote(client, &Note{Title: "Given title"}, DefaultNoteOption)
+		assert.NoError(err)
+		assert.Equal("Given title", savedNote.Title, "Title should not change")
+		assert.Equal("# My heading", savedNote.MD, "Heading should be kept")
+	})
+
+	t.Run("unique_title", func(t *testing.T) {
+		findNotes := ns.findNotes
+		defer func() { ns.findNotes = findNotes }()
+		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) {
+			return []*Note{&Note{Title: "Existing"}}, nil
+		}
+		edited := false
+		client.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				edited = true
+				cache := file.(*mockCacheFile)
+				cache.buffer.Reset()
+				_, err := cache.buffer.WriteString("---\ntitle: Existing\n---\nBody\n")
+				return err
+			},
+		}
+		nb := &Notebook{GUID: "NBGUID", Name: "Name of the notebook"}
+
+		savedNote = nil
+		err := CreateAndEditNewNote(client, &Note{Title: "Existing", Notebook: nb}, UniqueTitleNote)
+		assert.True(errors.Is(err, ErrDuplicateNote), "Should return ErrDuplicateNote")
+		assert.False(edited, "Editor should not be opened")
+		assert.Nil(savedNote, "Note should not be created")
+
+		buf := new(bytes.Buffer)
+		warningOutput = buf
+		defer func() { warningOutput = os.Stderr }()
+		err = CreateAndEditNewNote(client, &Note{Title: "New", Notebook: nb}, UniqueTitleNote)
+		assert.NoError(err, "Edited note should be saved")
+		assert.True(edited, "Editor should be opened")
+		assert.Equal("Existing", savedNote.Title, "Note should be created")
+		assert.Contains(buf.String(), "already exists", "Should warn about the duplicate")
+	})
 }
 
 func nsWithNote(note *Note) *mockNS {
 	notes := []*Note{&Note{Title: "Other note"}, note}
 	ns := new(mockNS)
 	ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+	ns.getNote = func(guid string) (*Note, error) {
+		if guid != note.GUID {
+			return nil, ErrNoNoteFound
+		}
+		return note, nil
+	}
 	return ns
 }
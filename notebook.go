@@ -17,7 +17,10 @@
 
 package clinote
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	// ErrNoNotebookFound is returned if no matching notebook was found.
@@ -25,6 +28,11 @@ var (
 	// ErrNoNotebookCached is returned when trying to update a notebook
 	// that hasn't been pulled from the server.
 	ErrNoNotebookCached = errors.New("no notebook found")
+	// ErrNotebookExists is returned if a notebook with the name already exists.
+	ErrNotebookExists = errors.New("a notebook with the name already exists")
+	// ErrNotebookNotEmpty is returned when trying to delete a notebook
+	// that still has notes in it.
+	ErrNotebookNotEmpty = errors.New("the notebook is not empty")
 )
 
 // Notebook is a struct for the notebook.
@@ -49,49 +57,275 @@ func UpdateNotebook(db Storager, ns NotestoreClient, name string, notebook *Note
 	if notebook.Stack != "" {
 		b.Stack = notebook.Stack
 	}
-	return ns.UpdateNotebook(b)
+	if err = ns.UpdateNotebook(b); err != nil {
+		return err
+	}
+	return updateCachedNotebook(db, b)
+}
+
+// RenameNotebook changes the name of the notebook. ErrNotebookExists is
+// returned if another notebook already has the new name. Notebook names are
+// compared case insensitive, like Evernote does.
+func RenameNotebook(db Storager, ns NotestoreClient, oldName, newName string) error {
+	bs, err := GetNotebooks(db, ns, false)
+	if err != nil {
+		return err
+	}
+	var b *Notebook
+	for _, nb := range bs {
+		if nb.Name == oldName {
+			b = nb
+			break
+		}
+	}
+	if b == nil {
+		return ErrNoNotebookFound
+	}
+	for _, nb := range bs {
+		if nb != b && strings.EqualFold(nb.Name, newName) {
+			return ErrNotebookExists
+		}
+	}
+	renamed := *b
+	renamed.Name = newName
+	if err = ns.UpdateNotebook(&renamed); err != nil {
+		return err
+	}
+	return updateCachedNotebook(db, &renamed)
+}
+
+// DeleteNotebook permanently removes the notebook. ErrNotebookNotEmpty is
+// returned if the notebook still has notes in it. Use ForceDeleteNotebook
+// to delete the notebook together with its notes.
+func DeleteNotebook(db Storager, ns NotestoreClient, name string) error {
+	return deleteNotebook(db, ns, name, false)
+}
+
+// ForceDeleteNotebook permanently removes the notebook and all the notes
+// in it.
+func ForceDeleteNotebook(db Storager, ns NotestoreClient, name string) error {
+	return deleteNotebook(db, ns, name, true)
+}
+
+func deleteNotebook(db Storager, ns NotestoreClient, name string, force bool) error {
+	b, err := findNotebook(db, ns, name)
+	if err != nil {
+		return err
+	}
+	if !force {
+		count, err := ns.NoteCount(b.GUID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrNotebookNotEmpty
+		}
+	}
+	if err = ns.ExpungeNotebook(b.GUID); err != nil {
+		return err
+	}
+	return removeCachedNotebook(db, b.GUID)
+}
+
+// removeCachedNotebook removes the notebook from the notebook cache, so a
+// deleted notebook isn't listed until the cache expires.
+func removeCachedNotebook(db Storager, guid string) error {
+	return db.UpdateNotebookCache(func(list *NotebookCacheList) (*NotebookCacheList, error) {
+		for i, nb := range list.Notebooks {
+			if nb.GUID == guid {
+				list.Notebooks = append(list.Notebooks[:i], list.Notebooks[i+1:]...)
+				return list, nil
+			}
+		}
+		return nil, nil
+	})
 }
 
-// FindNotebook gets the notebook matching with the name.
-// If no notebook is found, nil is returned.
+// updateCachedNotebook replaces the notebook in the notebook cache, so the
+// changes are seen without waiting for the cache to expire.
+func updateCachedNotebook(db Storager, b *Notebook) error {
+	return db.UpdateNotebookCache(func(list *NotebookCacheList) (*NotebookCacheList, error) {
+		for i, nb := range list.Notebooks {
+			if nb.GUID == b.GUID {
+				list.Notebooks[i] = b
+				return list, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+// FindNotebook gets the notebook matching with the name. If the name is
+// a notebook GUID, the notebook is fetched directly instead. If no notebook
+// is found, ErrNoNotebookFound is returned.
 func FindNotebook(db Storager, ns NotestoreClient, name string) (*Notebook, error) {
 	return findNotebook(db, ns, name)
 }
 
 func findNotebook(db Storager, ns NotestoreClient, name string) (*Notebook, error) {
+	guid := strings.TrimSpace(name)
+	isGUID := guidPattern.MatchString(guid)
+	if isGUID {
+		b, err := ns.GetNotebook(guid)
+		if !errors.Is(err, ErrOffline) {
+			return b, err
+		}
+	}
 	bs, err := GetNotebooks(db, ns, false)
 	if err != nil {
 		return nil, err
 	}
 	for _, b := range bs {
-		if b.Name == name {
+		if b.Name == name || isGUID && b.GUID == guid {
 			return b, nil
 		}
 	}
 	return nil, ErrNoNotebookFound
 }
 
-// GetNotebooks returns all the user's notebooks.
+// GetNotebooks returns all the user's notebooks. The notebooks are cached
+// for DefaultNotebookCacheTime. Notebooks renamed, updated or deleted with
+// clinote are changed in the cache right away, but notebooks created or
+// changed by other clients aren't seen until the cache has expired. If
+// forceSync is true, the notebooks are fetched from the notestore and the
+// cache is replaced. The notebooks are fetched before the cache is updated
+// with UpdateNotebookCache, so the database isn't locked while waiting for
+// the notestore. If another call has refreshed the cache in the meantime,
+// its notebooks are kept. In offline mode, an outdated cache is used unless
+// forceSync is true.
 func GetNotebooks(db Storager, ns NotestoreClient, forceSync bool) ([]*Notebook, error) {
 	list, err := db.GetNotebookCache()
 	if err != nil {
 		return nil, err
 	}
-	if !list.IsOutdated() && len(list.Notebooks) > 0 && !forceSync {
+	if !forceSync && !list.IsOutdated() && len(list.Notebooks) > 0 {
 		return list.Notebooks, nil
 	}
 	bs, err := ns.GetAllNotebooks()
+	if errors.Is(err, ErrOffline) && len(list.Notebooks) > 0 && !forceSync {
+		return list.Notebooks, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	list = NewNotebookCacheList(bs)
-	err = db.StoreNotebookList(list)
+	err = db.UpdateNotebookCache(func(list *NotebookCacheList) (*NotebookCacheList, error) {
+		if !forceSync && !list.IsOutdated() && len(list.Notebooks) > 0 {
+			bs = list.Notebooks
+			return nil, nil
+		}
+		return NewNotebookCacheList(bs), nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	return bs, nil
 }
 
+// NoteCounts returns the number of notes in each of the notebooks, keyed by
+// the notebook GUID. The counts are cached for a short time, so repeated
+// listings don't query the notestore again. Only the notebooks without a
+// cached count are looked up, unless the cache is outdated or forceSync is
+// true.
+func NoteCounts(db Storager, ns NotestoreClient, nbs []*Notebook, forceSync bool) (map[string]int32, error) {
+	list, err := db.GetNoteCountCache()
+	if err != nil {
+		return nil, err
+	}
+	if list.IsOutdated() || forceSync || list.Counts == nil {
+		list = NewNoteCountCacheList(make(map[string]int32))
+	}
+	counts := make(map[string]int32, len(nbs))
+	updated := false
+	for _, nb := range nbs {
+		if count, ok := list.Counts[nb.GUID]; ok {
+			counts[nb.GUID] = count
+			continue
+		}
+		count, err := ns.NoteCount(nb.GUID)
+		if err != nil {
+			return nil, err
+		}
+		counts[nb.GUID] = count
+		list.Counts[nb.GUID] = count
+		updated = true
+	}
+	if updated {
+		if err = db.StoreNoteCountList(list); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// maxNotebookLookups is the largest number of notebooks that are fetched one
+// by one. If more notebooks are needed, it's cheaper to fetch all of them.
+const maxNotebookLookups = 5
+
+// NotebooksForNotes returns the notebooks of the notes, keyed by GUID. Only
+// the notebooks that the notes belong to are resolved. They are taken from
+// the notebook cache if it is up to date. Otherwise, they are fetched one by
+// one from the notestore, unless there are so many that fetching the whole
+// list is cheaper.
+func NotebooksForNotes(db Storager, ns NotestoreClient, notes []*Note) (map[string]*Notebook, error) {
+	nbs := make(map[string]*Notebook)
+	var guids []string
+	for _, n := range notes {
+		if n.Notebook == nil || n.Notebook.GUID == "" {
+			continue
+		}
+		if _, ok := nbs[n.Notebook.GUID]; !ok {
+			nbs[n.Notebook.GUID] = nil
+			guids = append(guids, n.Notebook.GUID)
+		}
+	}
+	if len(guids) == 0 {
+		return nbs, nil
+	}
+	list, err := db.GetNotebookCache()
+	if err != nil {
+		return nil, err
+	}
+	if list.IsOutdated() && len(guids) > maxNotebookLookups {
+		bs, err := GetNotebooks(db, ns, true)
+		if err != nil {
+			return nil, err
+		}
+		list = &NotebookCacheList{Notebooks: bs}
+	} else if list.IsOutdated() {
+		list = &NotebookCacheList{}
+	}
+	for _, nb := range list.Notebooks {
+		if _, ok := nbs[nb.GUID]; ok {
+			nbs[nb.GUID] = nb
+		}
+	}
+	// The notestore client can't be used concurrently, so the notebooks
+	// missing from the cache are fetched one at a time.
+	for _, guid := range guids {
+		if nbs[guid] != nil {
+			continue
+		}
+		nb, err := GetNotebook(ns, guid)
+		if err != nil {
+			return nil, err
+		}
+		nbs[guid] = nb
+	}
+	return nbs, nil
+}
+
+// NotebooksInStack returns the notebooks that belong to the stack.
+// The stack name is matched case-insensitively.
+func NotebooksInStack(nbs []*Notebook, stack string) []*Notebook {
+	var list []*Notebook
+	for _, nb := range nbs {
+		if strings.EqualFold(nb.Stack, stack) {
+			list = append(list, nb)
+		}
+	}
+	return list
+}
+
 // GetNotebook returns a notebook from the user's notestore.
 func GetNotebook(ns NotestoreClient, guid string) (*Notebook, error) {
 	return ns.GetNotebook(guid)
@@ -19,6 +19,8 @@ package clinote
 
 import (
 	"errors"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -45,6 +47,28 @@ func TestFindNotebook(t *testing.T) {
 		assert.Error(err, "Should return an error")
 		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
 	})
+	guid := "4d1b3c2a-9f8e-4a7b-b6c5-d4e3f2a1b0c9"
+	t.Run("return notebook by GUID", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.getNotebook = func(g string) (*Notebook, error) { return &Notebook{Name: "Book", GUID: g}, nil }
+		b, err := FindNotebook(store, ns, guid)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(guid, b.GUID, "Wrong notebook returned")
+	})
+	t.Run("return error if no notebook with the GUID", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.getNotebook = func(string) (*Notebook, error) { return nil, ErrNoNotebookFound }
+		_, err := FindNotebook(store, ns, guid)
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+	})
+	t.Run("look up GUID in the cache when offline", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.getNotebook = func(string) (*Notebook, error) { return nil, ErrOffline }
+		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{&Notebook{Name: "Book", GUID: guid}}, nil }
+		b, err := FindNotebook(store, ns, guid)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Book", b.Name, "Wrong notebook returned")
+	})
 }
 
 func TestGetNotebooks(t *testing.T) {
@@ -91,6 +115,88 @@ func TestGetNotebooks(t *testing.T) {
 		assert.Len(bs, 2, "Incorrect number of notebooks returned")
 		assert.Equal(expectedCache.Notebooks, bs, "Wrong books returned")
 	})
+	t.Run("forced refresh picks up new notebook", func(t *testing.T) {
+		server := []*Notebook{&Notebook{Name: "Old", GUID: "GUID1"}}
+		cache := NewNotebookCacheList(server)
+		ns := &mockNS{
+			getAllNotebooks: func() ([]*Notebook, error) { return server, nil },
+			createNotebook: func(b *Notebook, _ bool) error {
+				server = append(server, b)
+				return nil
+			},
+		}
+		store := &mockStore{
+			getNotebookCache:  func() (*NotebookCacheList, error) { return cache, nil },
+			storeNotebookList: func(list *NotebookCacheList) error { cache = list; return nil },
+		}
+		assert.NoError(CreateNotebook(ns, &Notebook{Name: "New", GUID: "GUID2"}, false), "Should not return an error")
+
+		bs, err := GetNotebooks(store, ns, false)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(bs, 1, "The cached notebooks should be returned")
+
+		bs, err = GetNotebooks(store, ns, true)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(bs, 2, "The new notebook should be returned")
+		assert.Equal("New", bs[1].Name, "Wrong notebook returned")
+		assert.Len(cache.Notebooks, 2, "The cache should be replaced")
+	})
+	t.Run("fetch outside of the cache update", func(t *testing.T) {
+		var mu sync.Mutex
+		cache := &NotebookCacheList{}
+		updating := false
+		ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.False(updating, "The notebooks should not be fetched while the cache is updated")
+			return []*Notebook{&Notebook{Name: "Notebook"}}, nil
+		}}
+		store := &mockStore{
+			getNotebookCache: func() (*NotebookCacheList, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return cache, nil
+			},
+			storeNotebookList: func(list *NotebookCacheList) error {
+				mu.Lock()
+				defer mu.Unlock()
+				cache = list
+				return nil
+			},
+		}
+		// The store serializes the updates of the cache, like the database
+		// does with its transactions.
+		var updateMu sync.Mutex
+		store.updateNotebookCache = func(fn func(*NotebookCacheList) (*NotebookCacheList, error)) error {
+			updateMu.Lock()
+			defer updateMu.Unlock()
+			setUpdating := func(b bool) {
+				mu.Lock()
+				updating = b
+				mu.Unlock()
+			}
+			setUpdating(true)
+			defer setUpdating(false)
+			list, _ := store.GetNotebookCache()
+			updated, err := fn(list)
+			if err != nil || updated == nil {
+				return err
+			}
+			return store.StoreNotebookList(updated)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bs, err := GetNotebooks(store, ns, false)
+				assert.NoError(err, "Should not return an error")
+				assert.Len(bs, 1, "Wrong number of notebooks returned")
+			}()
+		}
+		wg.Wait()
+		assert.Len(cache.Notebooks, 1, "The notebooks should be cached")
+	})
 }
 
 func TestUpdateNotebook(t *testing.T) {
@@ -138,3 +244,295 @@ func TestUpdateNotebook(t *testing.T) {
 		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
 	})
 }
+
+func TestRenameNotebook(t *testing.T) {
+	assert := assert.New(t)
+	setup := func() (*mockStore, *mockNS, **NotebookCacheList, **Notebook) {
+		var stored *NotebookCacheList
+		var saved *Notebook
+		cache := NewNotebookCacheList([]*Notebook{
+			&Notebook{GUID: "GUID1", Name: "Old Name"},
+			&Notebook{GUID: "GUID2", Name: "Other"},
+		})
+		store := &mockStore{
+			getNotebookCache:  func() (*NotebookCacheList, error) { return cache, nil },
+			storeNotebookList: func(list *NotebookCacheList) error { stored = list; return nil },
+		}
+		ns := &mockNS{updateNotebook: func(b *Notebook) error { saved = b; return nil }}
+		return store, ns, &stored, &saved
+	}
+
+	t.Run("rename and update the cache", func(t *testing.T) {
+		store, ns, stored, saved := setup()
+		err := RenameNotebook(store, ns, "Old Name", "New Name")
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(*saved, "Notebook should be updated") {
+			assert.Equal("GUID1", (*saved).GUID, "Wrong notebook updated")
+			assert.Equal("New Name", (*saved).Name, "Wrong name")
+		}
+		if assert.NotNil(*stored, "Cache should be updated") {
+			nb, err := FindNotebook(&mockStore{getNotebookCache: func() (*NotebookCacheList, error) { return *stored, nil }}, ns, "New Name")
+			assert.NoError(err, "Should find the notebook by the new name")
+			assert.Equal("GUID1", nb.GUID, "Wrong notebook found")
+		}
+	})
+
+	t.Run("error if the name is taken", func(t *testing.T) {
+		store, ns, _, saved := setup()
+		err := RenameNotebook(store, ns, "Old Name", "other")
+		assert.Equal(ErrNotebookExists, err, "Wrong error returned")
+		assert.Nil(*saved, "Notebook should not be updated")
+	})
+
+	t.Run("error if the notebook doesn't exist", func(t *testing.T) {
+		store, ns, _, _ := setup()
+		err := RenameNotebook(store, ns, "Missing", "New Name")
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+	})
+
+	t.Run("return error from UpdateNotebook", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		store, ns, stored, _ := setup()
+		ns.updateNotebook = func(*Notebook) error { return expectedErr }
+		err := RenameNotebook(store, ns, "Old Name", "New Name")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+		assert.Nil(*stored, "Cache should not be updated")
+	})
+}
+
+func TestDeleteNotebook(t *testing.T) {
+	assert := assert.New(t)
+	setup := func(count int32) (*mockStore, *mockNS, **NotebookCacheList, *string) {
+		var stored *NotebookCacheList
+		var expunged string
+		cache := NewNotebookCacheList([]*Notebook{
+			&Notebook{GUID: "GUID1", Name: "Old"},
+			&Notebook{GUID: "GUID2", Name: "Other"},
+		})
+		store := &mockStore{
+			getNotebookCache:  func() (*NotebookCacheList, error) { return cache, nil },
+			storeNotebookList: func(list *NotebookCacheList) error { stored = list; return nil },
+		}
+		ns := &mockNS{
+			noteCount:       func(string) (int32, error) { return count, nil },
+			expungeNotebook: func(guid string) error { expunged = guid; return nil },
+		}
+		return store, ns, &stored, &expunged
+	}
+
+	t.Run("delete empty notebook", func(t *testing.T) {
+		store, ns, stored, expunged := setup(0)
+		err := DeleteNotebook(store, ns, "Old")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("GUID1", *expunged, "Wrong notebook expunged")
+		if assert.NotNil(*stored, "Cache should be updated") {
+			assert.Len((*stored).Notebooks, 1, "Notebook should be removed from the cache")
+			assert.Equal("GUID2", (*stored).Notebooks[0].GUID, "Wrong notebook left in the cache")
+		}
+	})
+
+	t.Run("error if the notebook is not empty", func(t *testing.T) {
+		store, ns, stored, expunged := setup(3)
+		err := DeleteNotebook(store, ns, "Old")
+		assert.Equal(ErrNotebookNotEmpty, err, "Wrong error returned")
+		assert.Empty(*expunged, "Notebook should not be expunged")
+		assert.Nil(*stored, "Cache should not be updated")
+	})
+
+	t.Run("force delete notebook with notes", func(t *testing.T) {
+		store, ns, stored, expunged := setup(3)
+		err := ForceDeleteNotebook(store, ns, "Old")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("GUID1", *expunged, "Wrong notebook expunged")
+		assert.NotNil(*stored, "Cache should be updated")
+	})
+
+	t.Run("error if the notebook doesn't exist", func(t *testing.T) {
+		store, ns, _, expunged := setup(0)
+		err := DeleteNotebook(store, ns, "Missing")
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+		assert.Empty(*expunged, "Notebook should not be expunged")
+	})
+
+	t.Run("return error from ExpungeNotebook", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		store, ns, stored, _ := setup(0)
+		ns.expungeNotebook = func(string) error { return expectedErr }
+		err := DeleteNotebook(store, ns, "Old")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+		assert.Nil(*stored, "Cache should not be updated")
+	})
+}
+
+func TestNotebooksInStack(t *testing.T) {
+	assert := assert.New(t)
+	work := &Notebook{Name: "Projects", Stack: "Work"}
+	nbs := []*Notebook{work, &Notebook{Name: "Recipes", Stack: "Home"}, &Notebook{Name: "Inbox"}}
+	assert.Equal([]*Notebook{work}, NotebooksInStack(nbs, "work"), "Wrong notebooks returned")
+	assert.Empty(NotebooksInStack(nbs, "Travel"), "Should not return any notebooks")
+}
+
+func TestNotebooksForNotes(t *testing.T) {
+	assert := assert.New(t)
+	work := &Notebook{GUID: "GUID1", Name: "Work"}
+	home := &Notebook{GUID: "GUID2", Name: "Home"}
+	notes := []*Note{
+		&Note{Title: "Note1", Notebook: &Notebook{GUID: work.GUID}},
+		&Note{Title: "Note2", Notebook: &Notebook{GUID: home.GUID}},
+		&Note{Title: "Note3", Notebook: &Notebook{GUID: work.GUID}},
+		&Note{Title: "Note4"},
+	}
+	expected := map[string]*Notebook{work.GUID: work, home.GUID: home}
+
+	t.Run("use the cache", func(t *testing.T) {
+		store := &mockStore{getNotebookCache: func() (*NotebookCacheList, error) {
+			return NewNotebookCacheList([]*Notebook{work, home, &Notebook{GUID: "GUID3"}}), nil
+		}}
+		ns := &mockNS{getNotebook: func(string) (*Notebook, error) { t.Fatal("Notebook should not be fetched"); return nil, nil }}
+		nbs, err := NotebooksForNotes(store, ns, notes)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, nbs, "Wrong notebooks returned")
+	})
+
+	t.Run("fetch the notebooks in the notes", func(t *testing.T) {
+		store := &mockStore{getNotebookCache: func() (*NotebookCacheList, error) { return &NotebookCacheList{}, nil }}
+		var fetched []string
+		ns := &mockNS{getNotebook: func(guid string) (*Notebook, error) {
+			fetched = append(fetched, guid)
+			return expected[guid], nil
+		}}
+		ns.getAllNotebooks = func() ([]*Notebook, error) { t.Fatal("All notebooks should not be fetched"); return nil, nil }
+		nbs, err := NotebooksForNotes(store, ns, notes)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, nbs, "Wrong notebooks returned")
+		assert.Equal([]string{work.GUID, home.GUID}, fetched, "Each notebook should only be fetched once")
+	})
+
+	t.Run("fetch all notebooks for many notebooks", func(t *testing.T) {
+		var many []*Note
+		var all []*Notebook
+		for i := 0; i <= maxNotebookLookups; i++ {
+			nb := &Notebook{GUID: "GUID" + strconv.Itoa(i)}
+			all = append(all, nb)
+			many = append(many, &Note{Notebook: &Notebook{GUID: nb.GUID}})
+		}
+		store := &mockStore{
+			getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{}, nil },
+			storeNotebookList: func(*NotebookCacheList) error { return nil },
+		}
+		ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) { return all, nil }}
+		ns.getNotebook = func(string) (*Notebook, error) { t.Fatal("Notebook should not be fetched"); return nil, nil }
+		nbs, err := NotebooksForNotes(store, ns, many)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(nbs, len(all), "Wrong number of notebooks")
+	})
+
+	t.Run("return error from GetNotebook", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		store := &mockStore{getNotebookCache: func() (*NotebookCacheList, error) { return &NotebookCacheList{}, nil }}
+		ns := &mockNS{getNotebook: func(string) (*Notebook, error) { return nil, expectedErr }}
+		_, err := NotebooksForNotes(store, ns, notes)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestNoteCounts(t *testing.T) {
+	assert := assert.New(t)
+	nbs := []*Notebook{&Notebook{GUID: "GUID1"}, &Notebook{GUID: "GUID2"}}
+
+	t.Run("fetch and cache the counts", func(t *testing.T) {
+		var stored *NoteCountCacheList
+		store := &mockStore{storeNoteCountList: func(list *NoteCountCacheList) error { stored = list; return nil }}
+		ns := &mockNS{noteCount: func(guid string) (int32, error) {
+			if guid == "GUID1" {
+				return 3, nil
+			}
+			return 0, nil
+		}}
+		counts, err := NoteCounts(store, ns, nbs, false)
+		assert.NoError(err, "Should not return an error")
+		expected := map[string]int32{"GUID1": 3, "GUID2": 0}
+		assert.Equal(expected, counts, "Wrong counts")
+		if assert.NotNil(stored, "Counts should be cached") {
+			assert.Equal(expected, stored.Counts, "Wrong counts cached")
+		}
+	})
+
+	t.Run("use the cache", func(t *testing.T) {
+		store := &mockStore{
+			getNoteCountCache: func() (*NoteCountCacheList, error) {
+				return NewNoteCountCacheList(map[string]int32{"GUID1": 3, "GUID2": 0}), nil
+			},
+			storeNoteCountList: func(*NoteCountCacheList) error { t.Fatal("Cache should not be updated"); return nil },
+		}
+		ns := &mockNS{noteCount: func(string) (int32, error) { t.Fatal("Count should not be fetched"); return 0, nil }}
+		counts, err := NoteCounts(store, ns, nbs, false)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(map[string]int32{"GUID1": 3, "GUID2": 0}, counts, "Wrong counts")
+	})
+
+	t.Run("refetch outdated counts", func(t *testing.T) {
+		store := &mockStore{getNoteCountCache: func() (*NoteCountCacheList, error) {
+			list := NewNoteCountCacheList(map[string]int32{"GUID1": 3, "GUID2": 0})
+			list.Timestamp = time.Now().Add(-2 * DefaultNoteCountCacheTime)
+			return list, nil
+		}}
+		ns := &mockNS{noteCount: func(string) (int32, error) { return 5, nil }}
+		counts, err := NoteCounts(store, ns, nbs, false)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(map[string]int32{"GUID1": 5, "GUID2": 5}, counts, "Counts should be refetched")
+	})
+
+	t.Run("return error from NoteCount", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := &mockNS{noteCount: func(string) (int32, error) { return 0, expectedErr }}
+		_, err := NoteCounts(new(mockStore), ns, nbs, false)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+// BenchmarkNotebookResolution compares resolving the notebook names of a
+// search result by fetching all notebooks with only fetching the notebooks
+// of the notes, for an account with many notebooks.
+func BenchmarkNotebookResolution(b *testing.B) {
+	const accountNotebooks = 1000
+	notes := make([]*Note, 20)
+	for i := range notes {
+		notes[i] = &Note{Notebook: &Notebook{GUID: "GUID" + strconv.Itoa(i%3)}}
+	}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{}, nil },
+		storeNotebookList: func(*NotebookCacheList) error { return nil },
+	}
+	ns := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) {
+			nbs := make([]*Notebook, accountNotebooks)
+			for i := range nbs {
+				nbs[i] = &Notebook{GUID: "GUID" + strconv.Itoa(i), Name: "Notebook " + strconv.Itoa(i)}
+			}
+			return nbs, nil
+		},
+		getNotebook: func(guid string) (*Notebook, error) { return &Notebook{GUID: guid, Name: "Notebook"}, nil },
+	}
+
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bs, err := GetNotebooks(store, ns, false)
+			if err != nil {
+				b.Fatal(err)
+			}
+			nbs := make(map[string]*Notebook, len(bs))
+			for _, nb := range bs {
+				nbs[nb.GUID] = nb
+			}
+		}
+	})
+
+	b.Run("targeted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NotebooksForNotes(store, ns, notes); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -16,8 +16,37 @@ type NotestoreClient interface {
 	UpdateNote(note *Note) error
 	// DeleteNote removes a note from the user's notebook.
 	DeleteNote(guid string) error
+	// ExpungeNote permanently removes the note from the server.
+	ExpungeNote(guid string) error
 	// CreateNote creates a new note on the server.
 	CreateNote(note *Note) error
 	// UpdateNotebook updates the notebook on the server.
 	UpdateNotebook(book *Notebook) error
+	// ListTags returns all the user's tags.
+	ListTags() ([]*Tag, error)
+	// GetNote returns the note's metadata, without the content.
+	GetNote(guid string) (*Note, error)
+	// GetNoteWithResources returns the note's metadata, without the
+	// content, together with the data of its attachments.
+	GetNoteWithResources(guid string) (*Note, error)
+	// ListTrash returns the notes in the trash.
+	ListTrash() ([]*Note, error)
+	// RestoreNote moves the note out of the trash.
+	RestoreNote(guid string) error
+	// GetDefaultNotebook returns the user's default notebook.
+	GetDefaultNotebook() (*Notebook, error)
+	// NoteCount returns the number of notes in the notebook.
+	NoteCount(notebookGUID string) (int32, error)
+	// ExpungeNotebook permanently removes the notebook from the server.
+	ExpungeNotebook(guid string) error
+	// ListNoteVersions returns the saved versions of the note.
+	// ErrVersionsUnsupported is returned if the account doesn't keep
+	// versions of notes.
+	ListNoteVersions(guid string) ([]NoteVersion, error)
+	// GetNoteVersion returns the note, with the content, as it was in the
+	// version with the update sequence number.
+	GetNoteVersion(guid string, usn int32) (*Note, error)
+	// GetSyncChunk returns the notes changed after the update sequence
+	// number. At most maxEntries changes are returned.
+	GetSyncChunk(afterUSN, maxEntries int32) (*SyncChunk, error)
 }
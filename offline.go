@@ -0,0 +1,150 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import "errors"
+
+// ErrOffline is returned by the offline notestore for all the calls that
+// need the network.
+var ErrOffline = errors.New("clinote is in offline mode and the network can't be used")
+
+// OfflineNotestore is a notestore used in offline mode. All calls fail
+// with ErrOffline without trying to reach the server, so only the data
+// in the local cache can be used.
+type OfflineNotestore struct{}
+
+// FindNotes returns ErrOffline.
+func (OfflineNotestore) FindNotes(filter *NoteFilter, offset, count int) ([]*Note, error) {
+	return nil, ErrOffline
+}
+
+// GetAllNotebooks returns ErrOffline.
+func (OfflineNotestore) GetAllNotebooks() ([]*Notebook, error) {
+	return nil, ErrOffline
+}
+
+// GetNotebook returns ErrOffline.
+func (OfflineNotestore) GetNotebook(guid string) (*Notebook, error) {
+	return nil, ErrOffline
+}
+
+// CreateNotebook returns ErrOffline.
+func (OfflineNotestore) CreateNotebook(b *Notebook, defaultNotebook bool) error {
+	return ErrOffline
+}
+
+// GetNoteContent returns ErrOffline.
+func (OfflineNotestore) GetNoteContent(guid string) (string, error) {
+	return "", ErrOffline
+}
+
+// UpdateNote returns ErrOffline.
+func (OfflineNotestore) UpdateNote(note *Note) error {
+	return ErrOffline
+}
+
+// DeleteNote returns ErrOffline.
+func (OfflineNotestore) DeleteNote(guid string) error {
+	return ErrOffline
+}
+
+// ExpungeNote returns ErrOffline.
+func (OfflineNotestore) ExpungeNote(guid string) error {
+	return ErrOffline
+}
+
+// CreateNote returns ErrOffline.
+func (OfflineNotestore) CreateNote(note *Note) error {
+	return ErrOffline
+}
+
+// UpdateNotebook returns ErrOffline.
+func (OfflineNotestore) UpdateNotebook(book *Notebook) error {
+	return ErrOffline
+}
+
+// ListTags returns ErrOffline.
+func (OfflineNotestore) ListTags() ([]*Tag, error) {
+	return nil, ErrOffline
+}
+
+// GetNote returns ErrOffline.
+func (OfflineNotestore) GetNote(guid string) (*Note, error) {
+	return nil, ErrOffline
+}
+
+// GetNoteWithResources returns ErrOffline.
+func (OfflineNotestore) GetNoteWithResources(guid string) (*Note, error) {
+	return nil, ErrOffline
+}
+
+// ListTrash returns ErrOffline.
+func (OfflineNotestore) ListTrash() ([]*Note, error) {
+	return nil, ErrOffline
+}
+
+// RestoreNote returns ErrOffline.
+func (OfflineNotestore) RestoreNote(guid string) error {
+	return ErrOffline
+}
+
+// GetDefaultNotebook returns ErrOffline.
+func (OfflineNotestore) GetDefaultNotebook() (*Notebook, error) {
+	return nil, ErrOffline
+}
+
+// NoteCount returns ErrOffline.
+func (OfflineNotestore) NoteCount(notebookGUID string) (int32, error) {
+	return 0, ErrOffline
+}
+
+// ExpungeNotebook returns ErrOffline.
+func (OfflineNotestore) ExpungeNotebook(guid string) error {
+	return ErrOffline
+}
+
+// ListNoteVersions returns ErrOffline.
+func (OfflineNotestore) ListNoteVersions(guid string) ([]NoteVersion, error) {
+	return nil, ErrOffline
+}
+
+// GetNoteVersion returns ErrOffline.
+func (OfflineNotestore) GetNoteVersion(guid string, usn int32) (*Note, error) {
+	return nil, ErrOffline
+}
+
+// GetSyncChunk returns ErrOffline.
+func (OfflineNotestore) GetSyncChunk(afterUSN, maxEntries int32) (*SyncChunk, error) {
+	return nil, ErrOffline
+}
+
+// findCachedNote returns the note from the last search matching the title
+// or the GUID. It's used to find notes without the notestore in offline
+// mode.
+func findCachedNote(db Storager, title string) (*Note, error) {
+	notes, err := db.GetSearch()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		if n.GUID == title {
+			return n, nil
+		}
+	}
+	return matchNoteTitle(notes, title)
+}
@@ -0,0 +1,99 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfflineMode(t *testing.T) {
+	assert := assert.New(t)
+	ns := OfflineNotestore{}
+	guid := "6a5ba1b2-1c2d-4e5f-8a9b-0c1d2e3f4a5b"
+	cached := &Note{Title: "Cached note", GUID: guid, Updated: 1000, Notebook: &Notebook{GUID: "NB1"}}
+	store := &mockStore{
+		getSearch: func() ([]*Note, error) { return []*Note{&Note{Title: "Other"}, cached}, nil },
+		getCachedContent: func(g string, updated int64) (string, error) {
+			if g == guid && updated == cached.Updated {
+				return XMLHeader + "<en-note><p>Cached content</p></en-note>", nil
+			}
+			return "", nil
+		},
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return NewNotebookCacheListWithLimit([]*Notebook{&Notebook{Name: "Notebook", GUID: "NB1"}}, time.Nanosecond), nil
+		},
+		getTagCache: func() (*TagCacheList, error) {
+			return &TagCacheList{Tags: []*Tag{&Tag{Name: "old"}}, Timestamp: time.Now().Add(-2 * DefaultTagCacheTime), Limit: DefaultTagCacheTime}, nil
+		},
+	}
+
+	t.Run("client option", func(t *testing.T) {
+		c := NewClient(&DefaultConfig{}, store, new(mockNS), OfflineMode)
+		assert.Equal(OfflineNotestore{}, c.NoteStore, "Offline notestore should be used")
+	})
+
+	t.Run("notestore calls fail", func(t *testing.T) {
+		_, err := ns.FindNotes(&NoteFilter{}, 0, 1)
+		assert.Equal(ErrOffline, err, "Wrong error returned")
+		assert.Equal(ErrOffline, ns.CreateNote(&Note{}), "Wrong error returned")
+		assert.Equal(ErrOffline, SaveNewNote(ns, &Note{Title: "New"}, DefaultNoteOption), "Wrong error returned")
+	})
+
+	t.Run("note from the last search", func(t *testing.T) {
+		n, err := GetNoteWithContent(store, ns, "cached note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(guid, n.GUID, "Wrong note returned")
+		assert.Equal("Cached content", n.MD, "Cached content should be used")
+
+		n, err = GetNote(store, ns, guid, "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(cached, n, "Note should be found by GUID")
+
+		n, err = GetNote(store, ns, "Cached note", "Notebook")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(cached, n, "Note should be found in the notebook")
+
+		_, err = GetNote(store, ns, "Unknown", "")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+
+	t.Run("content not cached", func(t *testing.T) {
+		s := *store
+		s.getCachedContent = func(string, int64) (string, error) { return "", nil }
+		_, err := GetNoteWithContent(&s, ns, "Cached note")
+		assert.Equal(ErrOffline, err, "Wrong error returned")
+	})
+
+	t.Run("outdated notebook cache", func(t *testing.T) {
+		bs, err := GetNotebooks(store, ns, false)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(bs, 1, "Cached notebooks should be returned")
+
+		_, err = GetNotebooks(store, ns, true)
+		assert.Equal(ErrOffline, err, "A forced refresh needs the network")
+	})
+
+	t.Run("outdated tag cache", func(t *testing.T) {
+		ts, err := GetTags(store, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("old", ts[0].Name, "Cached tags should be returned")
+	})
+}
@@ -0,0 +1,78 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNoOpener is returned if the program used to open the note can't
+	// be found.
+	ErrNoOpener = errors.New("no program found to open the note with")
+	// ErrOpenFailed is returned if the note couldn't be opened in the app
+	// or in the web client.
+	ErrOpenFailed = errors.New("failed to open the note")
+)
+
+// OpenNote opens the note in the Evernote app with the client's opener. If
+// no app is registered for Evernote links or the app link can't be opened,
+// the note is opened in the web client instead. The opened link is
+// returned.
+func OpenNote(client *Client, title, shardID, userID string) (string, error) {
+	n, err := GetNote(client.Store, client.NoteStore, title, "")
+	if err != nil {
+		return "", err
+	}
+	opener := client.OpenerCommand()
+	if len(opener) == 0 {
+		return "", ErrNoOpener
+	}
+	path, err := exec.LookPath(opener[0])
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNoOpener, err)
+	}
+	// The handler check only makes sense for the platform's opener, a
+	// configured one is trusted to know what it can open.
+	if strings.TrimSpace(client.Opener) != "" || hasLinkHandler("evernote") {
+		link := NoteLink(n, shardID, userID)
+		if openLink(path, opener[1:], link) == nil {
+			return link, nil
+		}
+	}
+	link := NoteWebLink(n, shardID, userID)
+	if err = openLink(path, opener[1:], link); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrOpenFailed, err)
+	}
+	return link, nil
+}
+
+// openLink runs the opener with the arguments followed by the link. The
+// output of the opener is included in the error if it fails.
+func openLink(path string, args []string, link string) error {
+	cmd := exec.Command(path, append(append([]string{}, args...), link)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(bytes.TrimSpace(out)) > 0 {
+		return fmt.Errorf("%s: %s", err, bytes.TrimSpace(out))
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenNote(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-open")
+	if !assert.NoError(err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// The opener logs the links it's given and fails for app links if the
+	// first argument is "noapp".
+	openerPath := filepath.Join(dir, "opener")
+	logPath := filepath.Join(dir, "opened")
+	script := `#!/bin/sh
+if [ "$1" = "noapp" ]; then
+	shift
+	case "$1" in evernote:*) echo "no handler"; exit 1;; esac
+fi
+if [ "$1" = "fail" ]; then
+	echo "broken"
+	exit 2
+fi
+echo "$1" >> ` + logPath + "\n"
+	if !assert.NoError(ioutil.WriteFile(openerPath, []byte(script), 0700)) {
+		return
+	}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	createClient := func(opener string) *Client {
+		os.Remove(logPath)
+		ns := nsWithNote(&Note{Title: "Note", GUID: "NOTEGUID", Notebook: &Notebook{GUID: "NBGUID"}})
+		c := NewClient(nil, store, ns, DefaultClientOptions)
+		c.Opener = opener
+		return c
+	}
+	opened := func() []string {
+		data, _ := ioutil.ReadFile(logPath)
+		return strings.Fields(string(data))
+	}
+	appLink := "evernote:///view/123/s1/NOTEGUID/NOTEGUID/"
+	webLink := "https://www.evernote.com/shard/s1/nl/123/NOTEGUID/"
+
+	t.Run("open in the app", func(t *testing.T) {
+		link, err := OpenNote(createClient(openerPath), "Note", "s1", "123")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(appLink, link, "Should open the app link")
+		assert.Equal([]string{appLink}, opened())
+	})
+	t.Run("fall back to the web client", func(t *testing.T) {
+		link, err := OpenNote(createClient(openerPath+" noapp"), "Note", "s1", "123")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(webLink, link, "Should open the web link")
+		assert.Equal([]string{webLink}, opened())
+	})
+	t.Run("opener fails", func(t *testing.T) {
+		_, err := OpenNote(createClient(openerPath+" fail"), "Note", "s1", "123")
+		assert.True(errors.Is(err, ErrOpenFailed), "Should return ErrOpenFailed")
+		if assert.Error(err) {
+			assert.Contains(err.Error(), "broken", "Should include the output of the opener")
+		}
+	})
+	t.Run("missing opener", func(t *testing.T) {
+		_, err := OpenNote(createClient(filepath.Join(dir, "missing")), "Note", "s1", "123")
+		assert.True(errors.Is(err, ErrNoOpener), "Should return ErrNoOpener")
+	})
+	t.Run("missing note", func(t *testing.T) {
+		_, err := OpenNote(createClient(openerPath), "Missing", "s1", "123")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+	t.Run("default opener", func(t *testing.T) {
+		assert.Equal(strings.Fields(defaultOpener), NewClient(nil, store, nil, DefaultClientOptions).OpenerCommand())
+		assert.Equal([]string{"firefox", "--new-tab"}, createClient("  firefox  --new-tab ").OpenerCommand())
+	})
+}
@@ -0,0 +1,119 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidReminderTime is returned if the reminder time can't be parsed.
+var ErrInvalidReminderTime = errors.New("invalid reminder time")
+
+// reminderTimeFormats are the formats accepted for reminder times, besides
+// RFC 3339. Times without a timezone are in the local time.
+var reminderTimeFormats = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Reminder is the reminder of a note. The times are in milliseconds.
+type Reminder struct {
+	// Order is used by Evernote to sort the reminders. It's set to the
+	// time the reminder was added.
+	Order int64
+	// Time is when the reminder is due. It's zero for reminders without
+	// a time.
+	Time int64
+	// DoneTime is when the reminder was marked as done.
+	DoneTime int64
+}
+
+// IsEmpty returns true if the reminder doesn't have any of its values set.
+// Saving a note with an empty reminder clears the note's reminder.
+func (r *Reminder) IsEmpty() bool {
+	return r.Order == 0 && r.Time == 0 && r.DoneTime == 0
+}
+
+// ParseReminderTime parses the time of a reminder. The time is given in
+// RFC 3339 format, or without the seconds or the timezone, in which case
+// the local time is used.
+func ParseReminderTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, format := range reminderTimeFormats {
+		if t, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidReminderTime
+}
+
+// SetNoteReminder sets the reminder of the note matching the title to the
+// time.
+func SetNoteReminder(db Storager, ns NotestoreClient, title string, at time.Time) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	setReminderTime(n, at)
+	return ns.UpdateNote(n)
+}
+
+// ClearNoteReminder removes the reminder from the note matching the title.
+func ClearNoteReminder(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	n.Reminder = new(Reminder)
+	return ns.UpdateNote(n)
+}
+
+// setReminderTime sets the note's reminder to the time. The order of an
+// existing reminder is kept, and so is its done time unless the time is
+// changed. Times are compared in seconds, since that's the precision of
+// the note header.
+func setReminderTime(n *Note, at time.Time) {
+	r := &Reminder{Time: toMillis(at)}
+	if old := n.Reminder; old != nil {
+		r.Order = old.Order
+		if old.Time/1000 == r.Time/1000 {
+			r.Time = old.Time
+			r.DoneTime = old.DoneTime
+		}
+	}
+	if r.Order == 0 {
+		r.Order = toMillis(time.Now())
+	}
+	n.Reminder = r
+}
+
+// sameReminder returns true if the reminders are equal. A nil reminder is
+// equal to an empty reminder, since neither sets a reminder.
+func sameReminder(a, b *Reminder) bool {
+	if a == nil || b == nil {
+		return (a == nil || a.IsEmpty()) && (b == nil || b.IsEmpty())
+	}
+	return *a == *b
+}
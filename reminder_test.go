@@ -0,0 +1,145 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReminderTime(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"2024-06-01T09:00:00Z", time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)},
+		{"2024-06-01T09:00:00+02:00", time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC)},
+		{"2024-06-01T09:00:30", time.Date(2024, 6, 1, 9, 0, 30, 0, time.Local)},
+		{"2024-06-01T09:00", time.Date(2024, 6, 1, 9, 0, 0, 0, time.Local)},
+		{" 2024-06-01 09:00 ", time.Date(2024, 6, 1, 9, 0, 0, 0, time.Local)},
+		{"2024-06-01", time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, test := range tests {
+		actual, err := ParseReminderTime(test.value)
+		assert.NoError(err, "Should not return an error for %q", test.value)
+		assert.True(test.expected.Equal(actual), "Wrong time for %q: %s", test.value, actual)
+	}
+	_, err := ParseReminderTime("tomorrow")
+	assert.Equal(ErrInvalidReminderTime, err, "Wrong error returned")
+}
+
+func TestNoteReminder(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	at := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	setup := func(r *Reminder) (*mockNS, **Note) {
+		var saved *Note
+		ns := nsWithNote(&Note{Title: "Title", GUID: "GUID", Reminder: r})
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		return ns, &saved
+	}
+
+	t.Run("set new reminder", func(t *testing.T) {
+		ns, saved := setup(nil)
+		err := SetNoteReminder(store, ns, "Title", at)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(*saved, "Note should be saved") && assert.NotNil((*saved).Reminder, "Reminder should be set") {
+			assert.Equal(toMillis(at), (*saved).Reminder.Time, "Wrong reminder time")
+			assert.NotZero((*saved).Reminder.Order, "Reminder order should be set")
+			assert.Empty((*saved).Body, "Content should not be updated")
+		}
+	})
+
+	t.Run("move existing reminder", func(t *testing.T) {
+		ns, saved := setup(&Reminder{Order: 42, Time: 1000, DoneTime: 2000})
+		err := SetNoteReminder(store, ns, "Title", at)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(*saved, "Note should be saved") {
+			assert.Equal(&Reminder{Order: 42, Time: toMillis(at)}, (*saved).Reminder, "Order should be kept and done time cleared")
+		}
+	})
+
+	t.Run("clear reminder", func(t *testing.T) {
+		ns, saved := setup(&Reminder{Order: 42, Time: 1000})
+		err := ClearNoteReminder(store, ns, "Title")
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(*saved, "Note should be saved") && assert.NotNil((*saved).Reminder, "Reminder should be empty, not nil") {
+			assert.True((*saved).Reminder.IsEmpty(), "Reminder should be cleared")
+		}
+	})
+
+	t.Run("return error for missing note", func(t *testing.T) {
+		ns, _ := setup(nil)
+		err := SetNoteReminder(store, ns, "Missing", at)
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+}
+
+func TestReminderHeader(t *testing.T) {
+	assert := assert.New(t)
+	at := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("write and parse", func(t *testing.T) {
+		n := &Note{Title: "Title", MD: "Body", Reminder: &Reminder{Order: 42, Time: toMillis(at), DoneTime: 5}}
+		w := new(bytes.Buffer)
+		assert.NoError(WriteNote(w, n, DefaultNoteOption), "Should not fail")
+		assert.Contains(w.String(), "reminder: "+at.Local().Format(time.RFC3339)+"\n", "Reminder should be in the header")
+		assert.True(hasNoteHeader(w.Bytes()), "Header should be recognized")
+
+		old := n.Reminder
+		assert.NoError(parseNote(bytes.NewReader(w.Bytes()), n, DefaultNoteOption), "Should not fail")
+		assert.True(sameReminder(old, n.Reminder), "Unchanged reminder should be kept as is")
+	})
+
+	t.Run("parse new time", func(t *testing.T) {
+		n := &Note{Reminder: &Reminder{Order: 42, Time: 1000, DoneTime: 2000}}
+		err := parseNote(strings.NewReader("---\ntitle: Title\nreminder: 2024-06-01T09:00:00Z\n---\nBody\n"), n, DefaultNoteOption)
+		assert.NoError(err, "Should not fail")
+		assert.Equal(&Reminder{Order: 42, Time: toMillis(at)}, n.Reminder, "Wrong reminder parsed")
+	})
+
+	t.Run("empty value clears the reminder", func(t *testing.T) {
+		n := &Note{Reminder: &Reminder{Order: 42, Time: 1000}}
+		err := parseNote(strings.NewReader("---\ntitle: Title\nreminder:\n---\nBody\n"), n, DefaultNoteOption)
+		assert.NoError(err, "Should not fail")
+		if assert.NotNil(n.Reminder, "Reminder should be empty, not nil") {
+			assert.True(n.Reminder.IsEmpty(), "Reminder should be cleared")
+		}
+	})
+
+	t.Run("missing line keeps the reminder", func(t *testing.T) {
+		r := &Reminder{Order: 42, Time: 1000}
+		n := &Note{Reminder: r}
+		err := parseNote(strings.NewReader("---\ntitle: Title\n---\nBody\n"), n, DefaultNoteOption)
+		assert.NoError(err, "Should not fail")
+		assert.Equal(r, n.Reminder, "Reminder should not be changed")
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		err := parseNote(strings.NewReader("---\ntitle: Title\nreminder: soon\n---\nBody\n"), new(Note), DefaultNoteOption)
+		assert.Equal(ErrInvalidReminderTime, err, "Wrong error returned")
+	})
+}
@@ -0,0 +1,95 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// entityRef matches a character or entity reference at the start of the
+	// string.
+	entityRef = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+	// voidStartTag matches the start tag of an element that can't have
+	// any content. The last group is empty if the tag isn't self-closed.
+	voidStartTag = regexp.MustCompile(`(?i)<(area|base|br|col|embed|hr|img|input|param|source|track|wbr|en-media|en-todo)((?:\s[^<>]*?)?)\s*(/?)>`)
+	// voidEndTag matches the end tag of an element that can't have any
+	// content.
+	voidEndTag = regexp.MustCompile(`(?i)</(area|base|br|col|embed|hr|img|input|param|source|track|wbr|en-media|en-todo)\s*>`)
+)
+
+// sanitizeXML repairs the common mistakes found in the ENML of old notes.
+// Bare ampersands and less-than signs that don't start a tag are escaped,
+// and the void elements are self-closed so they don't swallow the following
+// content. The second return value is true if the content was changed.
+func sanitizeXML(content string) (string, bool) {
+	s := escapeMarkup(content)
+	s = voidEndTag.ReplaceAllString(s, "")
+	s = voidStartTag.ReplaceAllStringFunc(s, func(tag string) string {
+		m := voidStartTag.FindStringSubmatch(tag)
+		if m[3] == "/" {
+			return tag
+		}
+		return "<" + m[1] + strings.TrimRight(m[2], " \t\r\n") + "/>"
+	})
+	return s, s != content
+}
+
+// escapeMarkup escapes the ampersands that are not part of a reference and
+// the less-than signs that don't start a tag. Comments and CDATA sections
+// are left untouched.
+func escapeMarkup(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '<' && strings.HasPrefix(s[i:], "<!--"):
+			i += copySection(&b, s[i:], "-->") - 1
+		case c == '<' && strings.HasPrefix(s[i:], "<![CDATA["):
+			i += copySection(&b, s[i:], "]]>") - 1
+		case c == '<' && (i+1 == len(s) || !isTagStart(s[i+1])):
+			b.WriteString("&lt;")
+		case c == '&' && !entityRef.MatchString(s[i:]):
+			b.WriteString("&amp;")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// copySection writes the section up to and including the end marker and
+// returns the number of bytes written. If the end marker is missing, the
+// rest of the string is written.
+func copySection(b *strings.Builder, s, end string) int {
+	n := strings.Index(s, end)
+	if n < 0 {
+		n = len(s)
+	} else {
+		n += len(end)
+	}
+	b.WriteString(s[:n])
+	return n
+}
+
+func isTagStart(c byte) bool {
+	return c == '/' || c == '!' || c == '?' || c == '_' || c == ':' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
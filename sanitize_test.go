@@ -0,0 +1,96 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeXML(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		changed  bool
+	}{
+		{
+			"valid",
+			`<en-note><div>Tom &amp; Jerry&nbsp;&#160;&#xA0;</div><br/><en-todo checked="true"/></en-note>`,
+			`<en-note><div>Tom &amp; Jerry&nbsp;&#160;&#xA0;</div><br/><en-todo checked="true"/></en-note>`,
+			false,
+		},
+		{
+			"bare_ampersand",
+			`<en-note><div>Research & Development, R&D</div></en-note>`,
+			`<en-note><div>Research &amp; Development, R&amp;D</div></en-note>`,
+			true,
+		},
+		{
+			"entity_without_semicolon",
+			`<en-note><div>a&nbsp b</div></en-note>`,
+			`<en-note><div>a&amp;nbsp b</div></en-note>`,
+			true,
+		},
+		{
+			"ampersand_in_link",
+			`<en-note><a href="http://example.com/?a=1&b=2">link</a></en-note>`,
+			`<en-note><a href="http://example.com/?a=1&amp;b=2">link</a></en-note>`,
+			true,
+		},
+		{
+			"bare_less_than",
+			`<en-note><div>if a < b && b > c</div></en-note>`,
+			`<en-note><div>if a &lt; b &amp;&amp; b > c</div></en-note>`,
+			true,
+		},
+		{
+			"unclosed_void_elements",
+			`<en-note><div>one<br>two</div><hr><img src="a.png" alt="A"><en-todo checked="false"></en-note>`,
+			`<en-note><div>one<br/>two</div><hr/><img src="a.png" alt="A"/><en-todo checked="false"/></en-note>`,
+			true,
+		},
+		{
+			"void_end_tags",
+			`<en-note><div>one<br></br>two</div><BR ></en-note>`,
+			`<en-note><div>one<br/>two</div><BR/></en-note>`,
+			true,
+		},
+		{
+			"comments_and_cdata",
+			`<en-note><!-- a & b --><![CDATA[x < y & z]]><div>&</div></en-note>`,
+			`<en-note><!-- a & b --><![CDATA[x < y & z]]><div>&amp;</div></en-note>`,
+			true,
+		},
+		{
+			"doctype",
+			`<?xml version="1.0" encoding="UTF-8"?><!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd"><en-note/>`,
+			`<?xml version="1.0" encoding="UTF-8"?><!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd"><en-note/>`,
+			false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, changed := sanitizeXML(test.content)
+			assert.Equal(test.expected, s, "Wrong content")
+			assert.Equal(test.changed, changed, "Wrong changed value")
+		})
+	}
+}
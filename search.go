@@ -0,0 +1,119 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoSavedSearch is returned if no search is saved with the name.
+var ErrNoSavedSearch = errors.New("no saved search found")
+
+// SearchResult is a note matching a search, with a snippet of its content
+// around the matched term.
+type SearchResult struct {
+	// Note is the matching note.
+	Note *Note
+	// Snippet is the part of the note's content around the match.
+	Snippet string
+}
+
+// SearchNotes searches for notes matching the query and returns a snippet
+// of each note's content around the match. The snippet has up to radius
+// characters on each side of the match.
+func SearchNotes(db Storager, ns NotestoreClient, query string, count, radius int) ([]*SearchResult, error) {
+	return SearchNotesWithFilter(db, ns, &NoteFilter{Words: query}, count, radius)
+}
+
+// SearchNotesWithFilter is like SearchNotes but returns the notes matching
+// the filter. The snippets are taken around the filter's search words.
+func SearchNotesWithFilter(db Storager, ns NotestoreClient, filter *NoteFilter, count, radius int) ([]*SearchResult, error) {
+	query := filter.Words
+	notes, err := FindNotes(ns, filter, 0, count)
+	if err != nil {
+		return nil, err
+	}
+	// The content is fetched one note at a time, since the notestore client
+	// can't be used concurrently. This also keeps the number of requests
+	// in flight to the API down to one.
+	results := make([]*SearchResult, 0, len(notes))
+	for _, n := range notes {
+		if err = loadNoteContent(MarkdownConverter{}, db, ns, n, DefaultNoteOption); err != nil {
+			return nil, err
+		}
+		results = append(results, &SearchResult{Note: n, Snippet: extractSnippet(n.MD, query, radius)})
+	}
+	return results, nil
+}
+
+// snippetEllipsis marks that the snippet has been cut.
+const snippetEllipsis = "..."
+
+// extractSnippet returns the text around the first match of the query in
+// the markdown, with up to radius characters on each side. The query is
+// matched case insensitive. If the whole query isn't found, the first of its
+// words that is found is used. Without a match, the beginning of the text is
+// returned.
+func extractSnippet(md, query string, radius int) string {
+	if radius < 0 {
+		radius = 0
+	}
+	text := strings.Join(strings.Fields(md), " ")
+	runes := []rune(text)
+	start, length := findMatch(text, query)
+	from := start - radius
+	if from < 0 {
+		from = 0
+	}
+	to := start + length + radius
+	if length == 0 {
+		to = from + 2*radius
+	}
+	if to > len(runes) {
+		to = len(runes)
+	}
+	snippet := string(runes[from:to])
+	if from > 0 {
+		snippet = snippetEllipsis + snippet
+	}
+	if to < len(runes) {
+		snippet += snippetEllipsis
+	}
+	return snippet
+}
+
+// findMatch returns the position and length, in runes, of the query in the
+// text. The length is zero if nothing matched.
+func findMatch(text, query string) (int, int) {
+	// Lower casing keeps the number of runes, so the positions are the
+	// same in the original text.
+	lower := strings.ToLower(text)
+	terms := append([]string{strings.Join(strings.Fields(query), " ")}, strings.Fields(query)...)
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		term = strings.ToLower(term)
+		if i := strings.Index(lower, term); i != -1 {
+			return utf8.RuneCountInString(lower[:i]), utf8.RuneCountInString(term)
+		}
+	}
+	return 0, 0
+}
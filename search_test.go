@@ -0,0 +1,105 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractSnippet(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		md       string
+		query    string
+		radius   int
+		expected string
+	}{
+		{"match in the middle", "The quick brown fox jumps over the lazy dog", "fox", 6, "...brown fox jumps..."},
+		{"match at the start", "The quick brown fox", "the", 6, "The quick..."},
+		{"match at the end", "The quick brown fox", "fox", 6, "...brown fox"},
+		{"case insensitive", "The quick brown fox", "FOX", 6, "...brown fox"},
+		{"whitespace collapsed", "# Heading\n\nThe   quick\nbrown fox", "quick", 4, "...The quick bro..."},
+		{"first word of query", "The quick brown fox", "cat fox", 6, "...brown fox"},
+		{"no match", "The quick brown fox", "cat", 3, "The qu..."},
+		{"unicode", "Smörgåsbord och kaffe", "KAFFE", 4, "...och kaffe"},
+		{"short text", "fox", "fox", 10, "fox"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(test.expected, extractSnippet(test.md, test.query, test.radius))
+		})
+	}
+}
+
+func TestSearchNotes(t *testing.T) {
+	assert := assert.New(t)
+	store := new(mockStore)
+
+	t.Run("return snippets", func(t *testing.T) {
+		notes := []*Note{&Note{Title: "Note1", GUID: "GUID1"}, &Note{Title: "Note2", GUID: "GUID2"}}
+		content := map[string]string{
+			"GUID1": "<en-note><p>Buy milk and bread</p></en-note>",
+			"GUID2": "<en-note><p>Milk is in the fridge</p></en-note>",
+		}
+		ns := &mockNS{
+			findNotes: func(filter *NoteFilter, offset, count int) ([]*Note, error) {
+				assert.Equal("milk", filter.Words, "Wrong search term")
+				assert.Equal(10, count, "Wrong count")
+				return notes, nil
+			},
+			getNoteContent: func(guid string) (string, error) { return content[guid], nil },
+		}
+		results, err := SearchNotes(store, ns, "milk", 10, 4)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(results, 2, "Wrong number of results") {
+			assert.Equal(notes[0], results[0].Note, "Wrong note")
+			assert.Equal("Buy milk and...", results[0].Snippet, "Wrong snippet")
+			assert.Equal("Milk is ...", results[1].Snippet, "Wrong snippet")
+		}
+	})
+
+	t.Run("return error from GetNoteContent", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := &mockNS{
+			findNotes:      func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{new(Note)}, nil },
+			getNoteContent: func(string) (string, error) { return "", expectedErr },
+		}
+		_, err := SearchNotes(store, ns, "milk", 10, 4)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+
+	t.Run("search with filter", func(t *testing.T) {
+		filter := &NoteFilter{Words: "milk", NotebookGUID: "Notebook GUID"}
+		ns := &mockNS{
+			findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+				assert.Equal(filter, f, "Wrong filter")
+				return []*Note{&Note{Title: "Note1", GUID: "GUID1"}}, nil
+			},
+			getNoteContent: func(string) (string, error) { return "<en-note><p>Buy milk</p></en-note>", nil },
+		}
+		results, err := SearchNotesWithFilter(store, ns, filter, 10, 4)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(results, 1, "Wrong number of results") {
+			assert.Equal("Buy milk", results[0].Snippet, "Wrong snippet")
+		}
+	})
+}
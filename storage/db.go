@@ -45,6 +45,9 @@ var (
 	dbBucket       = []byte("db_data")
 	settingsBucket = []byte("settings")
 	cacheBucket    = []byte("cache")
+	contentBucket  = []byte("note_content")
+	recoveryBucket = []byte("recovery_points")
+	searchBucket   = []byte("saved_searches")
 )
 
 // List of keys
@@ -52,8 +55,11 @@ var (
 	settingsKey         = []byte("user_settings")
 	credentialsKey      = []byte("user_credentials")
 	notebookCacheKey    = []byte("notebook_cache")
+	tagCacheKey         = []byte("tag_cache")
+	noteCountCacheKey   = []byte("note_count_cache")
 	searchCacheKey      = []byte("note_search_cache")
 	noteRecoverCacheKey = []byte("note_recover_cache")
+	syncUSNKey          = []byte("sync_usn")
 	dbVersionKey        = []byte("dbVersion")
 )
 
@@ -281,6 +287,77 @@ func (d *Database) StoreNotebookList(list *clinote.NotebookCacheList) error {
 	return d.storeData(cacheBucket, notebookCacheKey, data)
 }
 
+// UpdateNotebookCache calls fn with the stored NotebookCacheList and saves
+// the list it returns, unless it's nil. It's done in one transaction, and the
+// database file is locked while it's open, so neither other goroutines nor
+// other processes can change the cache in between.
+func (d *Database) UpdateNotebookCache(fn func(*clinote.NotebookCacheList) (*clinote.NotebookCacheList, error)) error {
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(t *bolt.Tx) error {
+		b, err := t.CreateBucketIfNotExists(cacheBucket)
+		if err != nil {
+			return err
+		}
+		var list clinote.NotebookCacheList
+		if data := b.Get(notebookCacheKey); data != nil {
+			if err = json.Unmarshal(data, &list); err != nil {
+				return err
+			}
+		}
+		updated, err := fn(&list)
+		if err != nil || updated == nil {
+			return err
+		}
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		return b.Put(notebookCacheKey, data)
+	})
+}
+
+// GetTagCache returns the stored TagCacheList.
+func (d *Database) GetTagCache() (*clinote.TagCacheList, error) {
+	var list clinote.TagCacheList
+	data, err := d.getData(cacheBucket, tagCacheKey)
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, &list)
+	}
+	return &list, err
+}
+
+// StoreTagList saves the tag list to the database.
+func (d *Database) StoreTagList(list *clinote.TagCacheList) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return d.storeData(cacheBucket, tagCacheKey, data)
+}
+
+// GetNoteCountCache returns the stored NoteCountCacheList.
+func (d *Database) GetNoteCountCache() (*clinote.NoteCountCacheList, error) {
+	var list clinote.NoteCountCacheList
+	data, err := d.getData(cacheBucket, noteCountCacheKey)
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, &list)
+	}
+	return &list, err
+}
+
+// StoreNoteCountList saves the note counts to the database.
+func (d *Database) StoreNoteCountList(list *clinote.NoteCountCacheList) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return d.storeData(cacheBucket, noteCountCacheKey, data)
+}
+
 // SaveSearch stores the search to the database.
 func (d *Database) SaveSearch(notes []*clinote.Note) error {
 	data, err := json.Marshal(notes)
@@ -300,14 +377,98 @@ func (d *Database) GetSearch() ([]*clinote.Note, error) {
 	return notes, err
 }
 
+// SaveNamedSearch stores the search filter with the name.
+func (d *Database) SaveNamedSearch(name string, filter *clinote.NoteFilter) error {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return err
+	}
+	return d.storeData(searchBucket, []byte(name), data)
+}
+
+// GetNamedSearch returns the search filter saved with the name. If no
+// search is saved with the name, clinote.ErrNoSavedSearch is returned.
+func (d *Database) GetNamedSearch(name string) (*clinote.NoteFilter, error) {
+	data, err := d.getData(searchBucket, []byte(name))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, clinote.ErrNoSavedSearch
+	}
+	var filter clinote.NoteFilter
+	if err = json.Unmarshal(data, &filter); err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
 // SaveNoteRecoveryPoint saves the note to the database so it can be
-// recovered in the case something fails.
+// recovered in the case something fails. A recovery point is kept for
+// each note with a GUID, and the last saved one is returned by
+// GetNoteRecoveryPoint.
 func (d *Database) SaveNoteRecoveryPoint(note *clinote.Note) error {
 	data, err := json.Marshal(note)
 	if err != nil {
 		return err
 	}
-	return d.storeData(cacheBucket, noteRecoverCacheKey, data)
+	if err = d.storeData(cacheBucket, noteRecoverCacheKey, data); err != nil || note.GUID == "" {
+		return err
+	}
+	return d.storeData(recoveryBucket, []byte(note.GUID), data)
+}
+
+// ListRecoveryPoints returns the recovery points for all notes.
+func (d *Database) ListRecoveryPoints() ([]*clinote.Note, error) {
+	var notes []*clinote.Note
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return nil, err
+	}
+	err = db.View(func(t *bolt.Tx) error {
+		b := t.Bucket(recoveryBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var note clinote.Note
+			if err := json.Unmarshal(v, &note); err != nil {
+				return err
+			}
+			notes = append(notes, &note)
+			return nil
+		})
+	})
+	return notes, err
+}
+
+// RemoveRecoveryPoint removes the recovery point for the note. If it is
+// the last saved recovery point, it is removed from there too.
+func (d *Database) RemoveRecoveryPoint(guid string) error {
+	last, err := d.GetNoteRecoveryPoint()
+	if err != nil {
+		return err
+	}
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(t *bolt.Tx) error {
+		if last.GUID == guid {
+			if c := t.Bucket(cacheBucket); c != nil {
+				if err := c.Delete(noteRecoverCacheKey); err != nil {
+					return err
+				}
+			}
+		}
+		b := t.Bucket(recoveryBucket)
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(guid))
+	})
 }
 
 // GetNoteRecoveryPoint returns the saved note that failed to save.
@@ -320,6 +481,61 @@ func (d *Database) GetNoteRecoveryPoint() (*clinote.Note, error) {
 	return &note, err
 }
 
+// cachedContent is the stored content of a note.
+type cachedContent struct {
+	Updated int64
+	Content string
+}
+
+// GetCachedContent returns the cached content for the note. If the content
+// isn't cached, or was cached for another updated time, an empty string is
+// returned.
+func (d *Database) GetCachedContent(guid string, updated int64) (string, error) {
+	data, err := d.getData(contentBucket, []byte(guid))
+	if err != nil || data == nil {
+		return "", err
+	}
+	var c cachedContent
+	if err = json.Unmarshal(data, &c); err != nil {
+		return "", err
+	}
+	if c.Updated != updated {
+		return "", nil
+	}
+	return c.Content, nil
+}
+
+// SaveCachedContent saves the content of the note at the updated time. Any
+// content previously cached for the note is replaced.
+func (d *Database) SaveCachedContent(guid string, updated int64, content string) error {
+	data, err := json.Marshal(&cachedContent{Updated: updated, Content: content})
+	if err != nil {
+		return err
+	}
+	return d.storeData(contentBucket, []byte(guid), data)
+}
+
+// GetSyncUSN returns the update sequence number of the last sync. If the
+// notes haven't been synced, zero is returned.
+func (d *Database) GetSyncUSN() (int32, error) {
+	data, err := d.getData(cacheBucket, syncUSNKey)
+	if err != nil || data == nil {
+		return 0, err
+	}
+	var usn int32
+	err = json.Unmarshal(data, &usn)
+	return usn, err
+}
+
+// SaveSyncUSN saves the update sequence number of the last sync.
+func (d *Database) SaveSyncUSN(usn int32) error {
+	data, err := json.Marshal(usn)
+	if err != nil {
+		return err
+	}
+	return d.storeData(cacheBucket, syncUSNKey, data)
+}
+
 // Close shuts down the connection to the database.
 func (d *Database) Close() error {
 	return d.closeDB()
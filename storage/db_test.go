@@ -20,6 +20,7 @@ package storage
 import (
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/TcM1911/clinote"
@@ -61,7 +62,10 @@ func TestNotebookCaching(t *testing.T) {
 	db, tmpDir := setupTestDB(t)
 	defer os.RemoveAll(tmpDir)
 	defer db.Close()
-	books := make([]*clinote.Notebook, 3)
+	books := []*clinote.Notebook{
+		&clinote.Notebook{Name: "Notebook1", GUID: "GUID1", Stack: "Work"},
+		&clinote.Notebook{Name: "Notebook2", GUID: "GUID2"},
+	}
 	expected := clinote.NewNotebookCacheList(books)
 
 	t.Run("Store", func(t *testing.T) {
@@ -74,6 +78,151 @@ func TestNotebookCaching(t *testing.T) {
 		assert.NoError(err, "Should not return an error")
 		compareCacheList(assert, expected, actual)
 	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := db.UpdateNotebookCache(func(list *clinote.NotebookCacheList) (*clinote.NotebookCacheList, error) {
+			compareCacheList(assert, expected, list)
+			list.Notebooks = list.Notebooks[:1]
+			return list, nil
+		})
+		assert.NoError(err, "Should not return an error")
+		actual, err := db.GetNotebookCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(actual.Notebooks, 1, "The updated list should be stored")
+	})
+
+	t.Run("Update without changes", func(t *testing.T) {
+		err := db.UpdateNotebookCache(func(list *clinote.NotebookCacheList) (*clinote.NotebookCacheList, error) {
+			return nil, nil
+		})
+		assert.NoError(err, "Should not return an error")
+		actual, err := db.GetNotebookCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(actual.Notebooks, 1, "The list should be kept")
+	})
+
+	t.Run("Concurrent updates", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := db.UpdateNotebookCache(func(list *clinote.NotebookCacheList) (*clinote.NotebookCacheList, error) {
+					list.Notebooks = append(list.Notebooks, &clinote.Notebook{Name: "Notebook"})
+					return list, nil
+				})
+				assert.NoError(err, "Should not return an error")
+			}()
+		}
+		wg.Wait()
+		actual, err := db.GetNotebookCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(actual.Notebooks, 11, "No update should be lost")
+	})
+}
+
+func TestContentCaching(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	guid, content := "Note GUID", "<en-note>Content</en-note>"
+
+	t.Run("Get not cached", func(t *testing.T) {
+		actual, err := db.GetCachedContent(guid, int64(1000))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("", actual, "Should not return any content")
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		err := db.SaveCachedContent(guid, int64(1000), content)
+		assert.NoError(err, "Should not fail when storing the content")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		actual, err := db.GetCachedContent(guid, int64(1000))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(content, actual, "Wrong content returned")
+	})
+
+	t.Run("Get outdated", func(t *testing.T) {
+		actual, err := db.GetCachedContent(guid, int64(2000))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("", actual, "Should not return outdated content")
+	})
+}
+
+func TestSyncUSN(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+
+	usn, err := db.GetSyncUSN()
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(int32(0), usn, "Should return zero if not synced")
+
+	assert.NoError(db.SaveSyncUSN(42), "Should not fail when storing the USN")
+	usn, err = db.GetSyncUSN()
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(int32(42), usn, "Wrong USN returned")
+}
+
+func TestTagCaching(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	expected := clinote.NewTagCacheList([]*clinote.Tag{
+		&clinote.Tag{Name: "Tag 1", GUID: "GUID1"},
+		&clinote.Tag{Name: "Tag 2", GUID: "GUID2", ParentGUID: "GUID1"},
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		actual, err := db.GetTagCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(actual.Tags, 0, "Cache should be empty")
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		err := db.StoreTagList(expected)
+		assert.NoError(err, "Should not fail when storing tag cache")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		actual, err := db.GetTagCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected.Limit, actual.Limit)
+		assert.Equal(expected.Tags, actual.Tags)
+		assert.True(expected.Timestamp.Equal(actual.Timestamp), "Wrong timestamp")
+	})
+}
+
+func TestNoteCountCaching(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	expected := clinote.NewNoteCountCacheList(map[string]int32{"GUID1": 3, "GUID2": 0})
+
+	t.Run("Empty", func(t *testing.T) {
+		actual, err := db.GetNoteCountCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(actual.Counts, 0, "Cache should be empty")
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		err := db.StoreNoteCountList(expected)
+		assert.NoError(err, "Should not fail when storing note count cache")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		actual, err := db.GetNoteCountCache()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected.Limit, actual.Limit)
+		assert.Equal(expected.Counts, actual.Counts)
+		assert.True(expected.Timestamp.Equal(actual.Timestamp), "Wrong timestamp")
+	})
 }
 
 func TestSearchCaching(t *testing.T) {
@@ -99,6 +248,38 @@ func TestSearchCaching(t *testing.T) {
 	})
 }
 
+func TestNamedSearch(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	expected := &clinote.NoteFilter{Words: "review", NotebookGUID: "Notebook GUID", TagGUIDs: []string{"Tag GUID"}}
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := db.GetNamedSearch("weekly")
+		assert.Equal(clinote.ErrNoSavedSearch, err, "Wrong error returned")
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		assert.NoError(db.SaveNamedSearch("weekly", expected), "Should not fail when storing the search")
+		assert.NoError(db.SaveNamedSearch("other", &clinote.NoteFilter{Words: "other"}), "Should not fail when storing the search")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		actual, err := db.GetNamedSearch("weekly")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, actual, "Wrong filter returned from store")
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		replaced := &clinote.NoteFilter{Words: "replaced"}
+		assert.NoError(db.SaveNamedSearch("weekly", replaced), "Should not fail when replacing the search")
+		actual, err := db.GetNamedSearch("weekly")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(replaced, actual, "Search should be replaced")
+	})
+}
+
 func TestRecoveryPoint(t *testing.T) {
 	assert := assert.New(t)
 	db, tmpDir := setupTestDB(t)
@@ -116,6 +297,27 @@ func TestRecoveryPoint(t *testing.T) {
 		assert.NoError(err, "Should not fail to return recovery point")
 		assert.Equal(expectedNote, actual, "Wrong note returned")
 	})
+
+	first := &clinote.Note{Title: "First note", GUID: "GUID1"}
+	second := &clinote.Note{Title: "Second note", GUID: "GUID2"}
+	t.Run("List", func(t *testing.T) {
+		assert.NoError(db.SaveNoteRecoveryPoint(first), "Should not fail to save")
+		assert.NoError(db.SaveNoteRecoveryPoint(second), "Should not fail to save")
+		actual, err := db.ListRecoveryPoints()
+		assert.NoError(err, "Should not fail to list the recovery points")
+		assert.Equal([]*clinote.Note{first, second}, actual, "Wrong notes returned")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		err := db.RemoveRecoveryPoint(second.GUID)
+		assert.NoError(err, "Should not fail to remove the recovery point")
+		actual, err := db.ListRecoveryPoints()
+		assert.NoError(err, "Should not fail to list the recovery points")
+		assert.Equal([]*clinote.Note{first}, actual, "Recovery point not removed")
+		last, err := db.GetNoteRecoveryPoint()
+		assert.NoError(err, "Should not fail to return recovery point")
+		assert.Equal("", last.GUID, "Last recovery point should be removed")
+	})
 }
 
 func TestCredentialStore(t *testing.T) {
@@ -0,0 +1,86 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+// syncChunkSize is the maximum number of changes fetched in one sync chunk.
+const syncChunkSize = 100
+
+// SyncChunk holds the changes in the user's account after an update
+// sequence number (USN).
+type SyncChunk struct {
+	// Notes are the notes created or changed, without their content.
+	Notes []*Note
+	// ExpungedNotes are the GUIDs of the notes permanently removed.
+	ExpungedNotes []string
+	// ChunkHighUSN is the highest USN of the changes in the chunk. It's
+	// zero if there are no more changes.
+	ChunkHighUSN int32
+	// UpdateCount is the highest USN of the account.
+	UpdateCount int32
+}
+
+// SyncChanges returns the notes changed since the last sync. The update
+// sequence number (USN) of the last sync is kept in the storage and updated
+// once all the changes have been fetched. The first sync returns all the
+// notes. Notes moved to the trash have Deleted set, and notes that have
+// been expunged are returned with only the GUID and Deleted set.
+func SyncChanges(db Storager, ns NotestoreClient) ([]*Note, error) {
+	usn, err := db.GetSyncUSN()
+	if err != nil {
+		return nil, err
+	}
+	return SyncChangesSince(db, ns, usn)
+}
+
+// SyncChangesSince returns the notes changed after the update sequence
+// number, like SyncChanges does, and saves the highest USN as the last sync.
+func SyncChangesSince(db Storager, ns NotestoreClient, usn int32) ([]*Note, error) {
+	var notes []*Note
+	index := make(map[string]int)
+	add := func(n *Note) {
+		if i, ok := index[n.GUID]; ok {
+			notes[i] = n
+			return
+		}
+		index[n.GUID] = len(notes)
+		notes = append(notes, n)
+	}
+	for {
+		chunk, err := ns.GetSyncChunk(usn, syncChunkSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range chunk.Notes {
+			add(n)
+		}
+		for _, guid := range chunk.ExpungedNotes {
+			add(&Note{GUID: guid, Deleted: true})
+		}
+		if chunk.ChunkHighUSN == 0 || chunk.ChunkHighUSN >= chunk.UpdateCount {
+			if chunk.UpdateCount > usn {
+				usn = chunk.UpdateCount
+			}
+			break
+		}
+		usn = chunk.ChunkHighUSN
+	}
+	if err := db.SaveSyncUSN(usn); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
@@ -0,0 +1,101 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncChanges(t *testing.T) {
+	assert := assert.New(t)
+	var savedUSN int32
+	store := &mockStore{
+		getSyncUSN:  func() (int32, error) { return 10, nil },
+		saveSyncUSN: func(usn int32) error { savedUSN = usn; return nil },
+	}
+
+	t.Run("fetch all chunks", func(t *testing.T) {
+		savedUSN = 0
+		var after []int32
+		ns := new(mockNS)
+		ns.getSyncChunk = func(usn, max int32) (*SyncChunk, error) {
+			after = append(after, usn)
+			if usn == 10 {
+				return &SyncChunk{
+					Notes:        []*Note{&Note{Title: "Note 1", GUID: "N1"}, &Note{Title: "Note 2", GUID: "N2"}},
+					ChunkHighUSN: 15,
+					UpdateCount:  20,
+				}, nil
+			}
+			return &SyncChunk{
+				Notes:         []*Note{&Note{Title: "Note 1 renamed", GUID: "N1"}},
+				ExpungedNotes: []string{"N3"},
+				ChunkHighUSN:  20,
+				UpdateCount:   20,
+			}, nil
+		}
+		notes, err := SyncChanges(store, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]int32{10, 15}, after, "Chunks should be fetched after the last USN")
+		assert.Equal([]*Note{
+			&Note{Title: "Note 1 renamed", GUID: "N1"},
+			&Note{Title: "Note 2", GUID: "N2"},
+			&Note{GUID: "N3", Deleted: true},
+		}, notes, "Wrong changes returned")
+		assert.Equal(int32(20), savedUSN, "The highest USN should be saved")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		savedUSN = 0
+		ns := new(mockNS)
+		ns.getSyncChunk = func(usn, max int32) (*SyncChunk, error) { return &SyncChunk{UpdateCount: 12}, nil }
+		notes, err := SyncChanges(store, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Empty(notes, "No changes should be returned")
+		assert.Equal(int32(12), savedUSN, "The update count should be saved")
+	})
+
+	t.Run("since USN", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.getSyncChunk = func(usn, max int32) (*SyncChunk, error) {
+			assert.Equal(int32(0), usn, "Wrong USN")
+			return &SyncChunk{UpdateCount: 12}, nil
+		}
+		_, err := SyncChangesSince(store, ns, 0)
+		assert.NoError(err, "Should not return an error")
+	})
+
+	t.Run("don't save USN on error", func(t *testing.T) {
+		savedUSN = 0
+		expectedError := errors.New("expected error")
+		ns := new(mockNS)
+		ns.getSyncChunk = func(usn, max int32) (*SyncChunk, error) {
+			if usn == 10 {
+				return &SyncChunk{Notes: []*Note{&Note{GUID: "N1"}}, ChunkHighUSN: 11, UpdateCount: 20}, nil
+			}
+			return nil, expectedError
+		}
+		notes, err := SyncChanges(store, ns)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Nil(notes, "No changes should be returned")
+		assert.Equal(int32(0), savedUSN, "USN should not be saved")
+	})
+}
@@ -0,0 +1,292 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoTagFound is returned if no matching tag was found.
+	ErrNoTagFound = errors.New("no tag found")
+	// ErrNoteNotPinned is returned when unpinning a note that isn't pinned.
+	ErrNoteNotPinned = errors.New("the note is not pinned")
+)
+
+// DefaultPinTag is the tag used to pin notes if the client doesn't have a
+// pin tag set.
+const DefaultPinTag = "favorite"
+
+// Tag is a struct for the note tag.
+type Tag struct {
+	// Name is the tag's name.
+	Name string
+	// GUID is the tag's GUID.
+	GUID string
+	// ParentGUID is the GUID of the tag's parent, if it has one.
+	ParentGUID string
+}
+
+// GetTags returns all the user's tags. The tags are cached in the
+// database and only fetched from the notestore when the cache is
+// empty or outdated. In offline mode, an outdated cache is used.
+func GetTags(db Storager, ns NotestoreClient) ([]*Tag, error) {
+	list, err := db.GetTagCache()
+	if err != nil {
+		return nil, err
+	}
+	if !list.IsOutdated() && len(list.Tags) > 0 {
+		return list.Tags, nil
+	}
+	ts, err := ns.ListTags()
+	if errors.Is(err, ErrOffline) && len(list.Tags) > 0 {
+		return list.Tags, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = db.StoreTagList(NewTagCacheList(ts)); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// FindTags returns the tags matching the names. Tag names are
+// case insensitive. If any of the names doesn't match a tag,
+// ErrNoTagFound is returned.
+func FindTags(ns NotestoreClient, names []string) ([]*Tag, error) {
+	ts, err := ns.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*Tag, 0, len(names))
+	for _, name := range names {
+		tag := findTagByName(ts, name)
+		if tag == nil {
+			return nil, ErrNoTagFound
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// AddTagToNote tags the note with the tag. The tag is created by the
+// notestore if the user doesn't have a tag with the name.
+func AddTagToNote(db Storager, ns NotestoreClient, title, tag string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return ErrNoTagFound
+	}
+	n, ts, err := noteWithTags(db, ns, title)
+	if err != nil {
+		return err
+	}
+	for _, name := range n.Tags {
+		if strings.EqualFold(name, tag) {
+			return nil
+		}
+	}
+	// Use the existing tag's name so the case matches.
+	if t := findTagByName(ts, tag); t != nil {
+		tag = t.Name
+	}
+	n.Tags = append(n.Tags, tag)
+	return ns.UpdateNote(n)
+}
+
+// RemoveTagFromNote removes the tag from the note. If the note isn't tagged
+// with the tag, ErrNoTagFound is returned.
+func RemoveTagFromNote(db Storager, ns NotestoreClient, title, tag string) error {
+	n, _, err := noteWithTags(db, ns, title)
+	if err != nil {
+		return err
+	}
+	tags := make([]string, 0, len(n.Tags))
+	for _, name := range n.Tags {
+		if !strings.EqualFold(name, strings.TrimSpace(tag)) {
+			tags = append(tags, name)
+		}
+	}
+	if len(tags) == len(n.Tags) {
+		return ErrNoTagFound
+	}
+	n.Tags = tags
+	return ns.UpdateNote(n)
+}
+
+// TagNotesMatching adds the tag to, or removes it from, all the notes
+// matching the filter. Notes that already have the tag, or don't have it
+// when it's removed, are left as they are. A note that fails to be updated
+// doesn't stop the rest from being updated, the failures are returned as
+// one error together with the number of modified notes.
+func TagNotesMatching(db Storager, ns NotestoreClient, filter *NoteFilter, tag string, add bool) (int, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return 0, ErrNoTagFound
+	}
+	notes, err := findAllNotes(ns, filter, 0)
+	if err != nil {
+		return 0, err
+	}
+	ts, err := ns.ListTags()
+	if err != nil {
+		return 0, err
+	}
+	// Use the existing tag's name so the case matches.
+	if t := findTagByName(ts, tag); t != nil {
+		tag = t.Name
+	}
+	modified := 0
+	var failed []string
+	for _, n := range notes {
+		changed, err := tagNote(ns, n, ts, tag, add)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %s", n.Title, err))
+			continue
+		}
+		if changed {
+			modified++
+		}
+	}
+	if len(failed) > 0 {
+		return modified, fmt.Errorf("failed to tag %d of %d notes: %s", len(failed), len(notes), strings.Join(failed, "; "))
+	}
+	return modified, nil
+}
+
+// tagNote adds or removes the tag and saves the note if its tags changed.
+func tagNote(ns NotestoreClient, n *Note, ts []*Tag, tag string, add bool) (bool, error) {
+	names := make([]string, 0, len(n.TagGUIDs)+1)
+	found := false
+	for _, guid := range n.TagGUIDs {
+		t := findTagByGUID(ts, guid)
+		if t == nil {
+			return false, ErrNoTagFound
+		}
+		if strings.EqualFold(t.Name, tag) {
+			found = true
+			if !add {
+				continue
+			}
+		}
+		names = append(names, t.Name)
+	}
+	if found == add {
+		return false, nil
+	}
+	if add {
+		names = append(names, tag)
+	}
+	n.Tags = names
+	return true, ns.UpdateNote(n)
+}
+
+// PinNote pins the note by tagging it with the client's pin tag. The tag is
+// created if the user doesn't have it.
+func PinNote(client *Client, title string) error {
+	return AddTagToNote(client.Store, client.NoteStore, title, client.PinTagName())
+}
+
+// UnpinNote removes the client's pin tag from the note. If the note isn't
+// pinned, ErrNoteNotPinned is returned.
+func UnpinNote(client *Client, title string) error {
+	err := RemoveTagFromNote(client.Store, client.NoteStore, title, client.PinTagName())
+	if err == ErrNoTagFound {
+		return ErrNoteNotPinned
+	}
+	return err
+}
+
+// noteWithTags returns the note with its tag names set together with all
+// the user's tags. The tags are saved as a complete list, so the note is
+// fetched again to not drop tags added since the search it was found in.
+// The server only returns the tag GUIDs, so the names are looked up from
+// the user's tags.
+func noteWithTags(db Storager, ns NotestoreClient, title string) (*Note, []*Tag, error) {
+	found, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	n, err := ns.GetNote(found.GUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ts, err := ns.ListTags()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(n.Tags) == 0 {
+		n.Tags = make([]string, 0, len(n.TagGUIDs))
+		for _, guid := range n.TagGUIDs {
+			t := findTagByGUID(ts, guid)
+			if t == nil {
+				return nil, nil, ErrNoTagFound
+			}
+			n.Tags = append(n.Tags, t.Name)
+		}
+	}
+	return n, ts, nil
+}
+
+// LoadTagNames sets the names of the note's tags from its tag GUIDs. The
+// notestore only returns the GUIDs, so the names have to be looked up before
+// the note's header is written.
+func LoadTagNames(ns NotestoreClient, n *Note) error {
+	if len(n.TagGUIDs) == 0 || len(n.Tags) != 0 {
+		return nil
+	}
+	ts, err := ns.ListTags()
+	if err != nil {
+		return err
+	}
+	setTagNames(n, ts)
+	return nil
+}
+
+// setTagNames sets the names of the note's tags from the tag GUIDs, unless
+// the names are already set. Unknown GUIDs are skipped.
+func setTagNames(n *Note, ts []*Tag) {
+	if len(n.Tags) != 0 {
+		return
+	}
+	for _, guid := range n.TagGUIDs {
+		if t := findTagByGUID(ts, guid); t != nil {
+			n.Tags = append(n.Tags, t.Name)
+		}
+	}
+}
+
+func findTagByGUID(ts []*Tag, guid string) *Tag {
+	for _, t := range ts {
+		if t.GUID == guid {
+			return t
+		}
+	}
+	return nil
+}
+
+func findTagByName(ts []*Tag, name string) *Tag {
+	for _, t := range ts {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
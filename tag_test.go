@@ -0,0 +1,295 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTags(t *testing.T) {
+	assert := assert.New(t)
+	tags := []*Tag{&Tag{Name: "work", GUID: "GUID1"}, &Tag{Name: "project", GUID: "GUID2", ParentGUID: "GUID1"}}
+	createMocks := func(cache *TagCacheList) (*mockNS, *mockStore, **TagCacheList) {
+		var stored *TagCacheList
+		ns := &mockNS{listTags: func() ([]*Tag, error) { return tags, nil }}
+		store := &mockStore{
+			getTagCache:  func() (*TagCacheList, error) { return cache, nil },
+			storeTagList: func(list *TagCacheList) error { stored = list; return nil },
+		}
+		return ns, store, &stored
+	}
+	t.Run("tags from notestore", func(t *testing.T) {
+		ns, db, stored := createMocks(&TagCacheList{})
+		ts, err := GetTags(db, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(tags, ts, "Wrong tags returned")
+		assert.Equal(tags, (*stored).Tags, "Wrong tags cached")
+	})
+	t.Run("refresh if expired", func(t *testing.T) {
+		cache := &TagCacheList{Tags: []*Tag{&Tag{Name: "old"}}, Timestamp: time.Now().Add(-2 * DefaultTagCacheTime), Limit: DefaultTagCacheTime}
+		ns, db, stored := createMocks(cache)
+		ts, err := GetTags(db, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(tags, ts, "Wrong tags returned")
+		assert.Equal(tags, (*stored).Tags, "Wrong tags cached")
+	})
+	t.Run("tags from cache", func(t *testing.T) {
+		cache := NewTagCacheList([]*Tag{&Tag{Name: "cached"}})
+		ns, db, stored := createMocks(cache)
+		ns.listTags = func() ([]*Tag, error) { t.Fatal("Should not query the notestore"); return nil, nil }
+		ts, err := GetTags(db, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(cache.Tags, ts, "Wrong tags returned")
+		assert.Nil(*stored, "Cache should not be updated")
+	})
+	t.Run("return error from ListTags", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns, db, _ := createMocks(&TagCacheList{})
+		ns.listTags = func() ([]*Tag, error) { return nil, expectedErr }
+		_, err := GetTags(db, ns)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestFindTags(t *testing.T) {
+	assert := assert.New(t)
+	tags := []*Tag{&Tag{Name: "work", GUID: "GUID1"}, &Tag{Name: "Urgent", GUID: "GUID2"}}
+	ns := &mockNS{listTags: func() ([]*Tag, error) { return tags, nil }}
+	t.Run("return tags", func(t *testing.T) {
+		ts, err := FindTags(ns, []string{"urgent", "work"})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Tag{tags[1], tags[0]}, ts, "Wrong tags returned")
+	})
+	t.Run("return error if no tag", func(t *testing.T) {
+		_, err := FindTags(ns, []string{"work", "missing"})
+		assert.Equal(ErrNoTagFound, err, "Wrong error returned")
+	})
+	t.Run("return error from ListTags", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := &mockNS{listTags: func() ([]*Tag, error) { return nil, expectedErr }}
+		_, err := FindTags(ns, []string{"work"})
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestNoteTagging(t *testing.T) {
+	assert := assert.New(t)
+	title := "Note title"
+	tags := []*Tag{{Name: "Work", GUID: "GUID1"}, {Name: "urgent", GUID: "GUID2"}}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	createNS := func(tagGUIDs ...string) (*mockNS, **Note) {
+		var saved *Note
+		ns := nsWithNote(&Note{Title: title, GUID: "NOTEGUID", Notebook: &Notebook{GUID: "NBGUID"}, TagGUIDs: tagGUIDs})
+		ns.listTags = func() ([]*Tag, error) { return tags, nil }
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		return ns, &saved
+	}
+
+	t.Run("add tag", func(t *testing.T) {
+		ns, saved := createNS("GUID2")
+		err := AddTagToNote(store, ns, title, "work")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"urgent", "Work"}, (*saved).Tags, "Existing tags should be kept")
+	})
+	t.Run("add new tag", func(t *testing.T) {
+		ns, saved := createNS()
+		err := AddTagToNote(store, ns, title, "new")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"new"}, (*saved).Tags, "Wrong tags saved")
+	})
+	t.Run("add existing tag", func(t *testing.T) {
+		ns, saved := createNS("GUID1")
+		err := AddTagToNote(store, ns, title, "WORK")
+		assert.NoError(err, "Should not return an error")
+		assert.Nil(*saved, "Note should not be saved")
+	})
+	t.Run("remove tag", func(t *testing.T) {
+		ns, saved := createNS("GUID1", "GUID2")
+		err := RemoveTagFromNote(store, ns, title, "Urgent")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"Work"}, (*saved).Tags, "Wrong tags saved")
+	})
+	t.Run("remove last tag", func(t *testing.T) {
+		ns, saved := createNS("GUID1")
+		err := RemoveTagFromNote(store, ns, title, "work")
+		assert.NoError(err, "Should not return an error")
+		assert.NotNil((*saved).Tags, "Tags should be cleared, not unset")
+		assert.Len((*saved).Tags, 0, "All tags should be removed")
+	})
+	t.Run("remove missing tag", func(t *testing.T) {
+		ns, saved := createNS("GUID1")
+		err := RemoveTagFromNote(store, ns, title, "urgent")
+		assert.Equal(ErrNoTagFound, err, "Wrong error returned")
+		assert.Nil(*saved, "Note should not be saved")
+	})
+	t.Run("return error from ListTags", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns, _ := createNS()
+		ns.listTags = func() ([]*Tag, error) { return nil, expectedErr }
+		err := AddTagToNote(store, ns, title, "work")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+	t.Run("stack adds to a note from the saved search", func(t *testing.T) {
+		// The saved search keeps the note as it was when it was listed,
+		// while the server has the tags added since.
+		server := &Note{Title: title, GUID: "NOTEGUID", Notebook: &Notebook{GUID: "NBGUID"}}
+		serverTags := []*Tag{}
+		ns := new(mockNS)
+		ns.getNote = func(guid string) (*Note, error) {
+			n := *server
+			return &n, nil
+		}
+		ns.listTags = func() ([]*Tag, error) { return serverTags, nil }
+		ns.updateNote = func(n *Note) error {
+			server.TagGUIDs = nil
+			for _, name := range n.Tags {
+				tag := findTagByName(serverTags, name)
+				if tag == nil {
+					tag = &Tag{Name: name, GUID: "GUID-" + name}
+					serverTags = append(serverTags, tag)
+				}
+				server.TagGUIDs = append(server.TagGUIDs, tag.GUID)
+			}
+			return nil
+		}
+		indexStore := &mockStore{getSearch: func() ([]*Note, error) {
+			return []*Note{{Title: title, GUID: "NOTEGUID", Notebook: &Notebook{GUID: "NBGUID"}}}, nil
+		}}
+
+		assert.NoError(AddTagToNote(indexStore, ns, "1", "a"), "Should not return an error")
+		assert.NoError(AddTagToNote(indexStore, ns, "1", "b"), "Should not return an error")
+		assert.Equal([]string{"GUID-a", "GUID-b"}, server.TagGUIDs, "The first tag should be kept")
+	})
+}
+
+func TestPinNote(t *testing.T) {
+	assert := assert.New(t)
+	title := "Note title"
+	tags := []*Tag{{Name: "Favorite", GUID: "GUID1"}, {Name: "star", GUID: "GUID2"}}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	createClient := func(pinTag string, tagGUIDs ...string) (*Client, **Note) {
+		var saved *Note
+		ns := nsWithNote(&Note{Title: title, GUID: "NOTEGUID", Notebook: &Notebook{GUID: "NBGUID"}, TagGUIDs: tagGUIDs})
+		ns.listTags = func() ([]*Tag, error) { return tags, nil }
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		c := NewClient(nil, store, ns, DefaultClientOptions)
+		c.PinTag = pinTag
+		return c, &saved
+	}
+
+	t.Run("pin with the default tag", func(t *testing.T) {
+		c, saved := createClient("")
+		assert.NoError(PinNote(c, title), "Should not return an error")
+		assert.Equal([]string{"Favorite"}, (*saved).Tags, "Should be tagged with the existing pin tag")
+	})
+	t.Run("pin with the configured tag", func(t *testing.T) {
+		c, saved := createClient("Star", "GUID1")
+		assert.NoError(PinNote(c, title), "Should not return an error")
+		assert.Equal([]string{"Favorite", "star"}, (*saved).Tags, "Should be tagged with the configured tag")
+	})
+	t.Run("pin pinned note", func(t *testing.T) {
+		c, saved := createClient("", "GUID1")
+		assert.NoError(PinNote(c, title), "Should not return an error")
+		assert.Nil(*saved, "Note should not be saved")
+	})
+	t.Run("unpin", func(t *testing.T) {
+		c, saved := createClient("star", "GUID1", "GUID2")
+		assert.NoError(UnpinNote(c, title), "Should not return an error")
+		assert.Equal([]string{"Favorite"}, (*saved).Tags, "Only the pin tag should be removed")
+	})
+	t.Run("unpin note that isn't pinned", func(t *testing.T) {
+		c, saved := createClient("", "GUID2")
+		assert.Equal(ErrNoteNotPinned, UnpinNote(c, title), "Wrong error returned")
+		assert.Nil(*saved, "Note should not be saved")
+	})
+}
+
+func TestTagNotesMatching(t *testing.T) {
+	assert := assert.New(t)
+	tags := []*Tag{{Name: "Archived", GUID: "GUID1"}, {Name: "project", GUID: "GUID2"}}
+	createNS := func(notes []*Note) (*mockNS, map[string][]string) {
+		saved := make(map[string][]string)
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, offset, count int) ([]*Note, error) {
+			if offset >= len(notes) {
+				return []*Note{}, nil
+			}
+			return notes[offset:], nil
+		}
+		ns.listTags = func() ([]*Tag, error) { return tags, nil }
+		ns.updateNote = func(n *Note) error {
+			if n.GUID == "FAIL" {
+				return errors.New("update failed")
+			}
+			saved[n.GUID] = n.Tags
+			return nil
+		}
+		return ns, saved
+	}
+
+	t.Run("add tag", func(t *testing.T) {
+		ns, saved := createNS([]*Note{
+			{Title: "One", GUID: "N1", TagGUIDs: []string{"GUID2"}},
+			{Title: "Two", GUID: "N2", TagGUIDs: []string{"GUID1", "GUID2"}},
+			{Title: "Three", GUID: "N3"},
+		})
+		n, err := TagNotesMatching(new(mockStore), ns, &NoteFilter{Words: "project x"}, "archived", true)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(2, n, "Notes already tagged should not be counted")
+		assert.Equal(map[string][]string{"N1": {"project", "Archived"}, "N3": {"Archived"}}, saved, "Wrong tags saved")
+	})
+
+	t.Run("remove tag", func(t *testing.T) {
+		ns, saved := createNS([]*Note{
+			{Title: "One", GUID: "N1", TagGUIDs: []string{"GUID1", "GUID2"}},
+			{Title: "Two", GUID: "N2", TagGUIDs: []string{"GUID2"}},
+		})
+		n, err := TagNotesMatching(new(mockStore), ns, &NoteFilter{}, "Archived", false)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(1, n, "Wrong number of modified notes")
+		assert.Equal(map[string][]string{"N1": {"project"}}, saved, "Wrong tags saved")
+	})
+
+	t.Run("collect the failures", func(t *testing.T) {
+		ns, saved := createNS([]*Note{
+			{Title: "Fails", GUID: "FAIL"},
+			{Title: "Unknown tag", GUID: "N1", TagGUIDs: []string{"UNKNOWN"}},
+			{Title: "Works", GUID: "N2"},
+		})
+		n, err := TagNotesMatching(new(mockStore), ns, &NoteFilter{}, "archived", true)
+		assert.Equal(1, n, "The other notes should be tagged")
+		assert.EqualError(err, `failed to tag 2 of 3 notes: "Fails": update failed; "Unknown tag": no tag found`)
+		assert.Contains(saved, "N2", "Note should be saved")
+	})
+
+	t.Run("empty tag", func(t *testing.T) {
+		n, err := TagNotesMatching(new(mockStore), new(mockNS), &NoteFilter{}, " ", true)
+		assert.Equal(ErrNoTagFound, err, "Wrong error returned")
+		assert.Equal(0, n)
+	})
+}
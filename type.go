@@ -28,14 +28,46 @@ type Storager interface {
 	GetNotebookCache() (*NotebookCacheList, error)
 	// StoreNotebookList saves the list to the database.
 	StoreNotebookList(list *NotebookCacheList) error
+	// UpdateNotebookCache calls fn with the stored NotebookCacheList and
+	// saves the list it returns, unless it's nil. Other updates of the
+	// cache, also from other processes, wait until it's done.
+	UpdateNotebookCache(fn func(*NotebookCacheList) (*NotebookCacheList, error)) error
+	// GetTagCache returns the stored TagCacheList.
+	GetTagCache() (*TagCacheList, error)
+	// StoreTagList saves the tag list to the database.
+	StoreTagList(list *TagCacheList) error
+	// GetNoteCountCache returns the stored NoteCountCacheList.
+	GetNoteCountCache() (*NoteCountCacheList, error)
+	// StoreNoteCountList saves the note counts to the database.
+	StoreNoteCountList(list *NoteCountCacheList) error
 	// SaveSearch stores a note search to the database.
 	SaveSearch([]*Note) error
 	// GetSearch returns a saved note search from the database.
 	GetSearch() ([]*Note, error)
+	// SaveNamedSearch stores the search filter with the name. A search
+	// already saved with the name is replaced.
+	SaveNamedSearch(name string, filter *NoteFilter) error
+	// GetNamedSearch returns the search filter saved with the name.
+	// ErrNoSavedSearch is returned if no search is saved with the name.
+	GetNamedSearch(name string) (*NoteFilter, error)
 	// SaveNoteRecoveryPoint saves the note as a recovery point.
 	SaveNoteRecoveryPoint(*Note) error
 	// GetNoteREcoveryPoint returns the saved note.
 	GetNoteRecoveryPoint() (*Note, error)
+	// ListRecoveryPoints returns all saved recovery points.
+	ListRecoveryPoints() ([]*Note, error)
+	// RemoveRecoveryPoint removes the recovery point for the note.
+	RemoveRecoveryPoint(guid string) error
+	// GetCachedContent returns the cached content for the note if it was
+	// cached at the updated time. An empty string is returned otherwise.
+	GetCachedContent(guid string, updated int64) (string, error)
+	// SaveCachedContent caches the content of the note at the updated time.
+	SaveCachedContent(guid string, updated int64, content string) error
+	// GetSyncUSN returns the update sequence number of the last sync.
+	// Zero is returned if the notes haven't been synced.
+	GetSyncUSN() (int32, error)
+	// SaveSyncUSN saves the update sequence number of the last sync.
+	SaveSyncUSN(usn int32) error
 }
 
 // UserCredentialStore provides an interface to a backend that stores
@@ -57,6 +89,17 @@ type Settings struct {
 	APIKey string
 	// Credential holds the user's credential data.
 	Credential *Credential
+	// DefaultNotebook is the name of the notebook new notes are saved to.
+	DefaultNotebook string
+	// CacheDir is the folder the cache files for editing are created in.
+	// An empty string means the configuration's cache folder.
+	CacheDir string
+	// PinTag is the tag used to pin notes. An empty string means
+	// DefaultPinTag.
+	PinTag string
+	// Opener is the command used to open notes. An empty string means the
+	// platform's default opener.
+	Opener string
 }
 
 // Credential is a struct that holds credential information.
@@ -22,15 +22,76 @@ import (
 )
 
 type mockNS struct {
-	findNotes       func(*NoteFilter, int, int) ([]*Note, error)
-	getAllNotebooks func() ([]*Notebook, error)
-	getNoteContent  func(guid string) (string, error)
-	updateNote      func(n *Note) error
-	deleteNote      func(guid string) error
-	saveNewNote     func(n *Note) error
-	createNote      func(n *Note) error
-	updateNotebook  func(b *Notebook) error
-	getNotebook     func(guid string) (*Notebook, error)
+	findNotes            func(*NoteFilter, int, int) ([]*Note, error)
+	getAllNotebooks      func() ([]*Notebook, error)
+	getNoteContent       func(guid string) (string, error)
+	updateNote           func(n *Note) error
+	deleteNote           func(guid string) error
+	saveNewNote          func(n *Note) error
+	createNote           func(n *Note) error
+	updateNotebook       func(b *Notebook) error
+	createNotebook       func(b *Notebook, defaultNotebook bool) error
+	getNotebook          func(guid string) (*Notebook, error)
+	listTags             func() ([]*Tag, error)
+	getNote              func(guid string) (*Note, error)
+	getNoteWithResources func(guid string) (*Note, error)
+	listTrash            func() ([]*Note, error)
+	restoreNote          func(guid string) error
+	getDefaultNB         func() (*Notebook, error)
+	noteCount            func(notebookGUID string) (int32, error)
+	expungeNotebook      func(guid string) error
+	expungeNote          func(guid string) error
+	listVersions         func(guid string) ([]NoteVersion, error)
+	getVersion           func(guid string, usn int32) (*Note, error)
+	getSyncChunk         func(afterUSN, maxEntries int32) (*SyncChunk, error)
+}
+
+func (s *mockNS) GetSyncChunk(afterUSN, maxEntries int32) (*SyncChunk, error) {
+	return s.getSyncChunk(afterUSN, maxEntries)
+}
+
+func (s *mockNS) ListNoteVersions(guid string) ([]NoteVersion, error) {
+	return s.listVersions(guid)
+}
+
+func (s *mockNS) GetNoteVersion(guid string, usn int32) (*Note, error) {
+	return s.getVersion(guid, usn)
+}
+
+func (s *mockNS) ExpungeNote(guid string) error {
+	return s.expungeNote(guid)
+}
+
+func (s *mockNS) ExpungeNotebook(guid string) error {
+	return s.expungeNotebook(guid)
+}
+
+func (s *mockNS) NoteCount(notebookGUID string) (int32, error) {
+	return s.noteCount(notebookGUID)
+}
+
+func (s *mockNS) ListTrash() ([]*Note, error) {
+	return s.listTrash()
+}
+
+func (s *mockNS) RestoreNote(guid string) error {
+	return s.restoreNote(guid)
+}
+
+func (s *mockNS) GetDefaultNotebook() (*Notebook, error) {
+	return s.getDefaultNB()
+}
+
+func (s *mockNS) GetNote(guid string) (*Note, error) {
+	return s.getNote(guid)
+}
+
+func (s *mockNS) GetNoteWithResources(guid string) (*Note, error) {
+	return s.getNoteWithResources(guid)
+}
+
+func (s *mockNS) ListTags() ([]*Tag, error) {
+	return s.listTags()
 }
 
 func (s *mockNS) UpdateNotebook(b *Notebook) error {
@@ -66,7 +127,7 @@ func (s *mockNS) GetAllNotebooks() ([]*Notebook, error) {
 }
 
 func (s *mockNS) CreateNotebook(b *Notebook, defaultNotebook bool) error {
-	panic("not implemented")
+	return s.createNotebook(b, defaultNotebook)
 }
 
 func (s *mockNS) GetNotebook(guid string) (*Notebook, error) {
@@ -76,9 +137,67 @@ func (s *mockNS) GetNotebook(guid string) (*Notebook, error) {
 type mockStore struct {
 	getNotebookCache      func() (*NotebookCacheList, error)
 	storeNotebookList     func(list *NotebookCacheList) error
+	updateNotebookCache   func(fn func(*NotebookCacheList) (*NotebookCacheList, error)) error
 	getSearch             func() ([]*Note, error)
+	getSettings           func() (*Settings, error)
+	saveNamedSearch       func(name string, filter *NoteFilter) error
+	getNamedSearch        func(name string) (*NoteFilter, error)
 	saveNoteRecoveryPoint func(*Note) error
 	getNoteRecoveryPoint  func() (*Note, error)
+	listRecoveryPoints    func() ([]*Note, error)
+	removeRecoveryPoint   func(guid string) error
+	getTagCache           func() (*TagCacheList, error)
+	storeTagList          func(list *TagCacheList) error
+	getNoteCountCache     func() (*NoteCountCacheList, error)
+	storeNoteCountList    func(list *NoteCountCacheList) error
+	getCachedContent      func(guid string, updated int64) (string, error)
+	saveCachedContent     func(guid string, updated int64, content string) error
+	getSyncUSN            func() (int32, error)
+	saveSyncUSN           func(usn int32) error
+}
+
+func (m *mockStore) GetSyncUSN() (int32, error) {
+	return m.getSyncUSN()
+}
+
+func (m *mockStore) SaveSyncUSN(usn int32) error {
+	return m.saveSyncUSN(usn)
+}
+
+func (m *mockStore) GetCachedContent(guid string, updated int64) (string, error) {
+	if m.getCachedContent == nil {
+		return "", nil
+	}
+	return m.getCachedContent(guid, updated)
+}
+
+func (m *mockStore) SaveCachedContent(guid string, updated int64, content string) error {
+	if m.saveCachedContent == nil {
+		return nil
+	}
+	return m.saveCachedContent(guid, updated, content)
+}
+
+func (m *mockStore) GetNoteCountCache() (*NoteCountCacheList, error) {
+	if m.getNoteCountCache == nil {
+		return &NoteCountCacheList{}, nil
+	}
+	return m.getNoteCountCache()
+}
+
+func (m *mockStore) StoreNoteCountList(list *NoteCountCacheList) error {
+	if m.storeNoteCountList == nil {
+		return nil
+	}
+	return m.storeNoteCountList(list)
+}
+
+func (m *mockStore) GetTagCache() (*TagCacheList, error) {
+	return m.getTagCache()
+}
+
+func (m *mockStore) StoreTagList(list *TagCacheList) error {
+	return m.storeTagList(list)
 }
 
 func (m *mockStore) SaveNoteRecoveryPoint(n *Note) error {
@@ -86,9 +205,23 @@ func (m *mockStore) SaveNoteRecoveryPoint(n *Note) error {
 }
 
 func (m *mockStore) GetNoteRecoveryPoint() (*Note, error) {
+	if m.getNoteRecoveryPoint == nil {
+		return new(Note), nil
+	}
 	return m.getNoteRecoveryPoint()
 }
 
+func (m *mockStore) ListRecoveryPoints() ([]*Note, error) {
+	return m.listRecoveryPoints()
+}
+
+func (m *mockStore) RemoveRecoveryPoint(guid string) error {
+	if m.removeRecoveryPoint == nil {
+		return nil
+	}
+	return m.removeRecoveryPoint(guid)
+}
+
 func (m *mockStore) SaveSearch([]*Note) error {
 	panic("not implemented")
 }
@@ -97,12 +230,29 @@ func (m *mockStore) GetSearch() ([]*Note, error) {
 	return m.getSearch()
 }
 
+func (m *mockStore) SaveNamedSearch(name string, filter *NoteFilter) error {
+	if m.saveNamedSearch == nil {
+		return nil
+	}
+	return m.saveNamedSearch(name, filter)
+}
+
+func (m *mockStore) GetNamedSearch(name string) (*NoteFilter, error) {
+	if m.getNamedSearch == nil {
+		return nil, ErrNoSavedSearch
+	}
+	return m.getNamedSearch(name)
+}
+
 func (m *mockStore) Close() error {
 	panic("not implemented")
 }
 
 func (m *mockStore) GetSettings() (*Settings, error) {
-	panic("not implemented")
+	if m.getSettings == nil {
+		return new(Settings), nil
+	}
+	return m.getSettings()
 }
 
 func (m *mockStore) StoreSettings(*Settings) error {
@@ -117,6 +267,21 @@ func (m *mockStore) StoreNotebookList(list *NotebookCacheList) error {
 	return m.storeNotebookList(list)
 }
 
+func (m *mockStore) UpdateNotebookCache(fn func(*NotebookCacheList) (*NotebookCacheList, error)) error {
+	if m.updateNotebookCache != nil {
+		return m.updateNotebookCache(fn)
+	}
+	list, err := m.GetNotebookCache()
+	if err != nil {
+		return err
+	}
+	updated, err := fn(list)
+	if err != nil || updated == nil {
+		return err
+	}
+	return m.StoreNotebookList(updated)
+}
+
 type mockEditor struct {
 	edit func(CacheFile) error
 }
@@ -125,6 +290,14 @@ func (m *mockEditor) Edit(file CacheFile) error {
 	return m.edit(file)
 }
 
+type mockClipboard struct {
+	readImage func() ([]byte, error)
+}
+
+func (m *mockClipboard) ReadImage() ([]byte, error) {
+	return m.readImage()
+}
+
 type mockCacheFile struct {
 	buffer *bytes.Buffer
 	write  func([]byte) (int, error)
@@ -191,3 +364,9 @@ func (m *mockCredentialStore) GetAll() ([]*Credential, error) {
 func (m *mockCredentialStore) GetByIndex(index int) (*Credential, error) {
 	return m.getByIndex(index)
 }
+
+// testXML returns the ENML body for the markdown.
+func testXML(md string) string {
+	body, _ := toXML(MarkdownConverter{}, md, nil, DefaultNoteOption)
+	return body
+}
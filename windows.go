@@ -24,6 +24,9 @@ import (
 	"path/filepath"
 )
 
+// defaultOpener is the command used to open links.
+const defaultOpener = "rundll32 url.dll,FileProtocolHandler"
+
 var (
 	// configDir is the folder used to store the configuration files.
 	configDir string
@@ -44,3 +47,15 @@ func init() {
 	}
 	cacheDir = filepath.Join(cache, "clinote")
 }
+
+// hasLinkHandler returns true since the opener fails by itself if no
+// application handles links with the scheme.
+func hasLinkHandler(scheme string) bool {
+	return true
+}
+
+// ReadImage returns ErrClipboardNotSupported, since images can't be read
+// from the clipboard on Windows.
+func (SystemClipboard) ReadImage() ([]byte, error) {
+	return nil, ErrClipboardNotSupported
+}
@@ -18,8 +18,11 @@
 package clinote
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
@@ -27,41 +30,299 @@ import (
 
 const timeFormat = "2006-01-02"
 
+// infoTimeFormat is the format used for the dates in the note info.
+const infoTimeFormat = "2006-01-02 15:04:05 MST"
+
 var (
 	noteListingHeader     = []string{"#", "Title", "Notebook", "Modified", "Created"}
-	notebookListingHeader = []string{"#", "Name"}
-	credentialHeader      = append(notebookListingHeader, "Type")
+	notebookListingHeader = []string{"#", "Name", "Stack"}
+	tagListingHeader      = []string{"#", "Name", "GUID"}
+	credentialHeader      = []string{"#", "Name", "Type"}
 	settingsHeader        = []string{"Setting", "Arguments", "Description"}
+	noteVersionHeader     = []string{"USN", "Title", "Modified", "Saved"}
+)
+
+// ListingOption are used for options around note listings.
+type ListingOption int32
+
+const (
+	// DefaultListingOption writes the listing with the default columns.
+	DefaultListingOption ListingOption = 0
+	// GUIDListing option includes the note GUIDs in the listing.
+	GUIDListing = 1 << iota
+	// RelativeTimeListing option shows the modified time relative to the
+	// current time, like "3 days ago".
+	RelativeTimeListing
 )
 
 // WriteNoteListing creates and writes a note listing table using the writer.
-func WriteNoteListing(w io.Writer, ns []*Note, nbs []*Notebook) {
+// The notebook names are looked up in nbs by the notebook GUID.
+func WriteNoteListing(w io.Writer, ns []*Note, nbs map[string]*Notebook) {
+	writeNoteList(w, ns, nbs, DefaultListingOption)
+}
+
+// WriteNoteListingWithGUID creates and writes a note listing table, including
+// the note GUIDs, using the writer.
+func WriteNoteListingWithGUID(w io.Writer, ns []*Note, nbs map[string]*Notebook) {
+	writeNoteList(w, ns, nbs, GUIDListing)
+}
+
+// WriteNoteListingWithOptions creates and writes a note listing table using
+// the writer. The columns are set by the listing options.
+func WriteNoteListingWithOptions(w io.Writer, ns []*Note, nbs map[string]*Notebook, opts ListingOption) {
+	writeNoteList(w, ns, nbs, opts)
+}
+
+func writeNoteList(w io.Writer, ns []*Note, nbs map[string]*Notebook, opts ListingOption) {
+	includeGUID := opts&GUIDListing != 0
 	table := tablewriter.NewWriter(w)
-	table.SetHeader(noteListingHeader)
+	header := noteListingHeader
+	if includeGUID {
+		header = append(header[:len(header):len(header)], "GUID")
+	}
+	table.SetHeader(header)
 
+	now := toMillis(time.Now())
 	for i, n := range ns {
 		index := strconv.Itoa(i + 1)
-		created := time.Unix(int64(n.Created)/1000, 0).Format(timeFormat)
-		modified := time.Unix(int64(n.Updated)/1000, 0).Format(timeFormat)
-		notebook := ""
-		for _, nb := range nbs {
-			if nb.GUID == n.Notebook.GUID {
-				notebook = nb.Name
-				break
+		created := noteTime(n.Created).Format(timeFormat)
+		modified := noteTime(n.Updated).Format(timeFormat)
+		if opts&RelativeTimeListing != 0 {
+			modified = humanizeDuration(now - n.Updated)
+		}
+		line := []string{index, n.Title, noteNotebookName(n, nbs), modified, created}
+		if includeGUID {
+			line = append(line, n.GUID)
+		}
+		table.Append(line)
+	}
+	table.Render()
+}
+
+type noteListingEntry struct {
+	Title    string `json:"title"`
+	GUID     string `json:"guid"`
+	Notebook string `json:"notebook"`
+	Created  string `json:"created"`
+	Updated  string `json:"updated"`
+}
+
+func noteListingEntries(ns []*Note, nbs map[string]*Notebook) []noteListingEntry {
+	entries := make([]noteListingEntry, 0, len(ns))
+	for _, n := range ns {
+		entries = append(entries, noteListingEntry{
+			Title:    n.Title,
+			GUID:     n.GUID,
+			Notebook: noteNotebookName(n, nbs),
+			Created:  noteTime(n.Created).Format(time.RFC3339),
+			Updated:  noteTime(n.Updated).Format(time.RFC3339),
+		})
+	}
+	return entries
+}
+
+// WriteNoteListingJSON writes the note listing as a JSON array using the writer.
+func WriteNoteListingJSON(w io.Writer, ns []*Note, nbs map[string]*Notebook) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(noteListingEntries(ns, nbs))
+}
+
+// WriteNoteListingYAML writes the note listing as a YAML sequence using the
+// writer. The entries have the same fields as the JSON listing.
+func WriteNoteListingYAML(w io.Writer, ns []*Note, nbs map[string]*Notebook) error {
+	entries := noteListingEntries(ns, nbs)
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	for _, e := range entries {
+		fields := [][2]string{
+			{"title", e.Title},
+			{"guid", e.GUID},
+			{"notebook", e.Notebook},
+			{"created", e.Created},
+			{"updated", e.Updated},
+		}
+		for i, f := range fields {
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
 			}
+			if _, err := fmt.Fprintf(w, "%s%s: %s\n", prefix, f[0], yamlQuote(f[1])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// yamlQuote returns the value as a double quoted YAML string. A JSON string
+// is a valid double quoted YAML string, so the JSON encoding is used. Quoting
+// all values keeps the timestamps and titles like "yes" as strings.
+func yamlQuote(s string) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// WriteNoteTitles writes the title of each note on its own line, without
+// any other formatting. Notes with a line break in the title are skipped
+// so each line is the title of one note.
+func WriteNoteTitles(w io.Writer, ns []*Note) error {
+	for _, n := range ns {
+		if strings.ContainsAny(n.Title, "\r\n") {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, n.Title); err != nil {
+			return err
 		}
-		table.Append([]string{index, n.Title, notebook, modified, created})
+	}
+	return nil
+}
+
+// WriteSearchResults writes the notes found by a search, each followed by
+// the snippet of its content, using the writer.
+func WriteSearchResults(w io.Writer, results []*SearchResult) error {
+	for i, r := range results {
+		if _, err := fmt.Fprintf(w, "%d. %s\n   %s\n\n", i+1, r.Note.Title, r.Snippet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteNoteStats writes the note's word and character count and
+// the created and updated dates using the writer.
+func WriteNoteStats(w io.Writer, n *Note) error {
+	words, chars := n.Stats()
+	_, err := fmt.Fprintf(w, "Words:      %d\nCharacters: %d\nCreated:    %s\nUpdated:    %s\n",
+		words, chars, noteTime(n.Created).Format(timeFormat), noteTime(n.Updated).Format(timeFormat))
+	return err
+}
+
+// WriteNoteInfo writes the note's metadata using the writer. The notebook
+// name is looked up by the notebook GUID. The dates are written in the
+// local timezone.
+func WriteNoteInfo(w io.Writer, n *Note, nbs map[string]*Notebook) error {
+	words, _ := n.Stats()
+	attachments := "No"
+	if len(n.Resources) > 0 {
+		attachments = fmt.Sprintf("Yes (%d)", len(n.Resources))
+	}
+	_, err := fmt.Fprintf(w, "Title:       %s\nGUID:        %s\nNotebook:    %s\nTags:        %s\nCreated:     %s\nUpdated:     %s\nWords:       %d\nAttachments: %s\n",
+		n.Title, n.GUID, noteNotebookName(n, nbs), strings.Join(n.Tags, ", "),
+		noteTime(n.Created).Format(infoTimeFormat), noteTime(n.Updated).Format(infoTimeFormat),
+		words, attachments)
+	return err
+}
+
+// noteTime converts a note timestamp in milliseconds to a time.
+func noteTime(ms int64) time.Time {
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// timeUnits are the units used by humanizeDuration, largest first.
+var timeUnits = []struct {
+	name string
+	d    time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+}
+
+// humanizeDuration returns the time that has passed, given in milliseconds,
+// as text like "3 days ago". Only the largest unit is used. Anything less
+// than a minute, including times in the future, is "just now".
+func humanizeDuration(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	for _, u := range timeUnits {
+		if d < u.d {
+			continue
+		}
+		n := int64(d / u.d)
+		if n == 1 {
+			return "1 " + u.name + " ago"
+		}
+		return fmt.Sprintf("%d %ss ago", n, u.name)
+	}
+	return "just now"
+}
+
+func noteNotebookName(n *Note, nbs map[string]*Notebook) string {
+	if n.Notebook == nil {
+		return ""
+	}
+	if nb := nbs[n.Notebook.GUID]; nb != nil {
+		return nb.Name
+	}
+	return ""
+}
+
+// WriteNoteVersionListing creates and writes a table of the note versions
+// using the writer.
+func WriteNoteVersionListing(w io.Writer, versions []NoteVersion) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(noteVersionHeader)
+	for _, v := range versions {
+		table.Append([]string{
+			strconv.Itoa(int(v.USN)),
+			v.Title,
+			noteTime(v.Updated).Format(infoTimeFormat),
+			noteTime(v.Saved).Format(infoTimeFormat),
+		})
 	}
 	table.Render()
 }
 
 // WriteNotebookListing creates and writes a notebook listing table using the writer.
 func WriteNotebookListing(w io.Writer, nbs []*Notebook) {
+	writeNotebookList(w, nbs, nil)
+}
+
+// WriteNotebookListingWithCounts creates and writes a notebook listing table,
+// including the number of notes in each notebook, using the writer. The counts
+// are looked up by the notebook GUID.
+func WriteNotebookListingWithCounts(w io.Writer, nbs []*Notebook, counts map[string]int32) {
+	writeNotebookList(w, nbs, counts)
+}
+
+func writeNotebookList(w io.Writer, nbs []*Notebook, counts map[string]int32) {
 	table := tablewriter.NewWriter(w)
-	table.SetHeader(notebookListingHeader)
+	header := notebookListingHeader
+	if counts != nil {
+		header = append(header[:len(header):len(header)], "Notes")
+	}
+	table.SetHeader(header)
 	for i, nb := range nbs {
 		index := strconv.Itoa(i + 1)
-		table.Append([]string{index, nb.Name})
+		line := []string{index, nb.Name, nb.Stack}
+		if counts != nil {
+			line = append(line, strconv.Itoa(int(counts[nb.GUID])))
+		}
+		table.Append(line)
+	}
+	table.Render()
+}
+
+// WriteTagListing creates and writes a tag listing table using the writer.
+func WriteTagListing(w io.Writer, tags []*Tag) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(tagListingHeader)
+	for i, t := range tags {
+		index := strconv.Itoa(i + 1)
+		table.Append([]string{index, t.Name, t.GUID})
 	}
 	table.Render()
 }
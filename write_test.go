@@ -19,7 +19,9 @@ package clinote
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,7 +29,7 @@ import (
 func TestWritingNoteAndNotebookTables(t *testing.T) {
 	assert := assert.New(t)
 	nbs := []*Notebook{
-		&Notebook{GUID: "GUID1", Name: "Notebook1"},
+		&Notebook{GUID: "GUID1", Name: "Notebook1", Stack: "Work"},
 		&Notebook{GUID: "GUID2", Name: "Notebook2"},
 		&Notebook{GUID: "GUID3", Name: "Notebook3"},
 	}
@@ -36,6 +38,10 @@ func TestWritingNoteAndNotebookTables(t *testing.T) {
 		&Note{Title: "Note2", Notebook: &Notebook{GUID: "GUID2"}, Created: int64(0), Updated: int64(0)},
 		&Note{Title: "Note3", Notebook: &Notebook{GUID: "GUID3"}, Created: int64(0), Updated: int64(0)},
 	}
+	nbMap := make(map[string]*Notebook)
+	for _, nb := range nbs {
+		nbMap[nb.GUID] = nb
+	}
 
 	t.Run("NotebookList", func(t *testing.T) {
 		buf := new(bytes.Buffer)
@@ -43,11 +49,199 @@ func TestWritingNoteAndNotebookTables(t *testing.T) {
 		assert.Equal(expectedNotebooklist, string(buf.Bytes()), "Notebook list table doesn't match")
 	})
 
+	t.Run("NotebookListWithCounts", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		WriteNotebookListingWithCounts(buf, nbs, map[string]int32{"GUID1": 12, "GUID2": 1})
+		assert.Equal(expectedNotebooklistWithCounts, string(buf.Bytes()), "Notebook list table doesn't match")
+	})
+
 	t.Run("NoteList", func(t *testing.T) {
 		buf := new(bytes.Buffer)
-		WriteNoteListing(buf, notes, nbs)
+		WriteNoteListing(buf, notes, nbMap)
 		assert.Equal(expectedNotelist, string(buf.Bytes()), "Note list table doesn't match")
 	})
+
+	t.Run("NoteListWithGUID", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		guidNotes := []*Note{
+			&Note{Title: "Note1", GUID: "NoteGUID1", Notebook: &Notebook{GUID: "GUID1"}, Created: int64(0), Updated: int64(0)},
+		}
+		WriteNoteListingWithGUID(buf, guidNotes, nbMap)
+		assert.Equal(expectedNotelistWithGUID, string(buf.Bytes()), "Note list table doesn't match")
+	})
+
+	t.Run("NoteListWithRelativeTime", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		updated := toMillis(time.Now().Add(-3*24*time.Hour - time.Minute))
+		relNotes := []*Note{
+			&Note{Title: "Note1", GUID: "NoteGUID1", Notebook: &Notebook{GUID: "GUID1"}, Created: int64(0), Updated: updated},
+		}
+		WriteNoteListingWithOptions(buf, relNotes, nbMap, GUIDListing|RelativeTimeListing)
+		assert.Contains(buf.String(), "| 3 days ago |", "Modified time should be relative")
+		assert.Contains(buf.String(), "| 1970-01-01 |", "Created time should be absolute")
+		assert.Contains(buf.String(), "NoteGUID1", "GUID should be included")
+	})
+
+	t.Run("NoteListJSON", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		jsonNotes := []*Note{
+			&Note{Title: "Note1", GUID: "NoteGUID1", Notebook: &Notebook{GUID: "GUID1"}, Created: int64(1500000000000), Updated: int64(1500000060000)},
+			&Note{Title: "Note2", GUID: "NoteGUID2", Notebook: &Notebook{GUID: "Unknown"}, Created: int64(0), Updated: int64(0)},
+		}
+		err := WriteNoteListingJSON(buf, jsonNotes, nbMap)
+		assert.NoError(err, "Should not return an error")
+
+		var actual []map[string]string
+		assert.NoError(json.Unmarshal(buf.Bytes(), &actual), "Should be valid JSON")
+		assert.Len(actual, 2, "Wrong number of notes")
+		assert.Equal("Note1", actual[0]["title"], "Wrong title")
+		assert.Equal("NoteGUID1", actual[0]["guid"], "Wrong GUID")
+		assert.Equal("Notebook1", actual[0]["notebook"], "Wrong notebook")
+		assert.Equal(time.Unix(1500000000, 0).Format(time.RFC3339), actual[0]["created"], "Wrong created time")
+		assert.Equal(time.Unix(1500000060, 0).Format(time.RFC3339), actual[0]["updated"], "Wrong updated time")
+		assert.Equal("", actual[1]["notebook"], "Unknown notebook should be empty")
+	})
+
+	t.Run("EmptyNoteListJSON", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := WriteNoteListingJSON(buf, nil, nbMap)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("[]\n", buf.String(), "Empty listing should be an empty array")
+	})
+
+	t.Run("NoteListYAML", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		yamlNotes := []*Note{
+			&Note{Title: "Note1", GUID: "NoteGUID1", Notebook: &Notebook{GUID: "GUID1"}, Created: int64(1500000000000), Updated: int64(1500000060000)},
+			&Note{Title: "Say \"yes\"", GUID: "NoteGUID2", Notebook: &Notebook{GUID: "Unknown"}, Created: int64(0), Updated: int64(0)},
+		}
+		err := WriteNoteListingYAML(buf, yamlNotes, nbMap)
+		assert.NoError(err, "Should not return an error")
+		expected := "- title: \"Note1\"\n" +
+			"  guid: \"NoteGUID1\"\n" +
+			"  notebook: \"Notebook1\"\n" +
+			"  created: \"" + time.Unix(1500000000, 0).Format(time.RFC3339) + "\"\n" +
+			"  updated: \"" + time.Unix(1500000060, 0).Format(time.RFC3339) + "\"\n" +
+			"- title: \"Say \\\"yes\\\"\"\n" +
+			"  guid: \"NoteGUID2\"\n" +
+			"  notebook: \"\"\n" +
+			"  created: \"" + time.Unix(0, 0).Format(time.RFC3339) + "\"\n" +
+			"  updated: \"" + time.Unix(0, 0).Format(time.RFC3339) + "\"\n"
+		assert.Equal(expected, buf.String(), "YAML listing doesn't match")
+	})
+
+	t.Run("EmptyNoteListYAML", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := WriteNoteListingYAML(buf, nil, nbMap)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("[]\n", buf.String(), "Empty listing should be an empty sequence")
+	})
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	assert := assert.New(t)
+	ms := func(d time.Duration) int64 { return int64(d / time.Millisecond) }
+	tests := []struct {
+		duration int64
+		expected string
+	}{
+		{ms(-time.Hour), "just now"},
+		{0, "just now"},
+		{ms(59 * time.Second), "just now"},
+		{ms(time.Minute), "1 minute ago"},
+		{ms(45 * time.Minute), "45 minutes ago"},
+		{ms(2*time.Hour + 30*time.Minute), "2 hours ago"},
+		{ms(24 * time.Hour), "1 day ago"},
+		{ms(3 * 24 * time.Hour), "3 days ago"},
+		{ms(65 * 24 * time.Hour), "2 months ago"},
+		{ms(400 * 24 * time.Hour), "1 year ago"},
+		{ms(3 * 365 * 24 * time.Hour), "3 years ago"},
+	}
+	for _, test := range tests {
+		assert.Equal(test.expected, humanizeDuration(test.duration), "Wrong text for %d ms", test.duration)
+	}
+}
+
+func TestWriteNoteTitles(t *testing.T) {
+	assert := assert.New(t)
+	buf := new(bytes.Buffer)
+	notes := []*Note{
+		&Note{Title: "Note 1"},
+		&Note{Title: "Two\nlines"},
+		&Note{Title: "Carriage\rreturn"},
+		&Note{Title: "Note | 2"},
+	}
+	err := WriteNoteTitles(buf, notes)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("Note 1\nNote | 2\n", buf.String(), "Wrong titles written")
+}
+
+func TestWriteSearchResults(t *testing.T) {
+	assert := assert.New(t)
+	buf := new(bytes.Buffer)
+	results := []*SearchResult{
+		&SearchResult{Note: &Note{Title: "Note1"}, Snippet: "...buy milk..."},
+		&SearchResult{Note: &Note{Title: "Note2"}, Snippet: "Milk is..."},
+	}
+	err := WriteSearchResults(buf, results)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("1. Note1\n   ...buy milk...\n\n2. Note2\n   Milk is...\n\n", buf.String())
+}
+
+func TestTagTable(t *testing.T) {
+	assert := assert.New(t)
+	tags := []*Tag{
+		&Tag{Name: "work", GUID: "GUID1"},
+		&Tag{Name: "home", GUID: "GUID2"},
+	}
+	buf := new(bytes.Buffer)
+	WriteTagListing(buf, tags)
+	assert.Equal(expectedTagList, string(buf.Bytes()), "Tag list table doesn't match")
+}
+
+func TestWriteNoteStats(t *testing.T) {
+	assert := assert.New(t)
+	buf := new(bytes.Buffer)
+	n := &Note{MD: "Three words here", Created: int64(0), Updated: int64(0)}
+	err := WriteNoteStats(buf, n)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("Words:      3\nCharacters: 16\nCreated:    1970-01-01\nUpdated:    1970-01-01\n", buf.String())
+}
+
+func TestWriteNoteInfo(t *testing.T) {
+	assert := assert.New(t)
+	nbs := map[string]*Notebook{"NB GUID": {Name: "Work", GUID: "NB GUID"}}
+	created := int64(1500000000000)
+	updated := int64(1500000060000)
+	times := "Created:     " + noteTime(created).Format(infoTimeFormat) + "\n" +
+		"Updated:     " + noteTime(updated).Format(infoTimeFormat) + "\n"
+
+	t.Run("with attachments", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		n := &Note{
+			Title:     "Title",
+			GUID:      "GUID",
+			MD:        "Three words here",
+			Notebook:  &Notebook{GUID: "NB GUID"},
+			Tags:      []string{"work", "urgent"},
+			Resources: []*Attachment{{Filename: "a.png"}},
+			Created:   created,
+			Updated:   updated,
+		}
+		err := WriteNoteInfo(buf, n, nbs)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Title:       Title\nGUID:        GUID\nNotebook:    Work\nTags:        work, urgent\n"+
+			times+"Words:       3\nAttachments: Yes (1)\n", buf.String())
+	})
+
+	t.Run("without attachments", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		n := &Note{Title: "Title", GUID: "GUID", Created: created, Updated: updated}
+		err := WriteNoteInfo(buf, n, nbs)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Title:       Title\nGUID:        GUID\nNotebook:    \nTags:        \n"+
+			times+"Words:       0\nAttachments: No\n", buf.String())
+	})
 }
 
 func TestCredentialTable(t *testing.T) {
@@ -78,13 +272,28 @@ func TestSettingsTable(t *testing.T) {
 	assert.Equal(expectedSettingList, string(buf.Bytes()))
 }
 
-const expectedNotebooklist = `+---+-----------+
-| # |   NAME    |
-+---+-----------+
-| 1 | Notebook1 |
-| 2 | Notebook2 |
-| 3 | Notebook3 |
-+---+-----------+
+const expectedNotebooklist = `+---+-----------+-------+
+| # |   NAME    | STACK |
++---+-----------+-------+
+| 1 | Notebook1 | Work  |
+| 2 | Notebook2 |       |
+| 3 | Notebook3 |       |
++---+-----------+-------+
+`
+const expectedNotebooklistWithCounts = `+---+-----------+-------+-------+
+| # |   NAME    | STACK | NOTES |
++---+-----------+-------+-------+
+| 1 | Notebook1 | Work  |    12 |
+| 2 | Notebook2 |       |     1 |
+| 3 | Notebook3 |       |     0 |
++---+-----------+-------+-------+
+`
+const expectedTagList = `+---+------+-------+
+| # | NAME | GUID  |
++---+------+-------+
+| 1 | work | GUID1 |
+| 2 | home | GUID2 |
++---+------+-------+
 `
 const expectedNotelist = `+---+-------+-----------+------------+------------+
 | # | TITLE | NOTEBOOK  |  MODIFIED  |  CREATED   |
@@ -94,6 +303,12 @@ const expectedNotelist = `+---+-------+-----------+------------+------------+
 | 3 | Note3 | Notebook3 | 1970-01-01 | 1970-01-01 |
 +---+-------+-----------+------------+------------+
 `
+const expectedNotelistWithGUID = `+---+-------+-----------+------------+------------+-----------+
+| # | TITLE | NOTEBOOK  |  MODIFIED  |  CREATED   |   GUID    |
++---+-------+-----------+------------+------------+-----------+
+| 1 | Note1 | Notebook1 | 1970-01-01 | 1970-01-01 | NoteGUID1 |
++---+-------+-----------+------------+------------+-----------+
+`
 const expectedCredentialList = `+---+-------+------------------+
 | # | NAME  |       TYPE       |
 +---+-------+------------------+